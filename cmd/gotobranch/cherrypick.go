@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runCherryPick(g *globals, args []string) error {
+	fs := newFlagSet("cherry-pick", g)
+	n := fs.Int("n", 1, "Cherry-pick the last `count` commits of the branch that the current one lacks")
+	abort := fs.Bool("abort", false, "Give up a cherry-pick stopped on conflicts")
+	commandUsage(fs, "cherry-pick")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *abort {
+		if len(args) > 0 {
+			return usageErrorf("--abort takes no branch")
+		}
+		if !core.CherryPickInProgress(g.repo) {
+			return i18n.Errorf("no cherry-pick in progress")
+		}
+		return core.AbortCherryPick(g.repo)
+	}
+	if len(args) != 1 {
+		return usageErrorf("expected exactly one branch name")
+	}
+	if *n <= 0 {
+		return usageErrorf("-n must be a positive number")
+	}
+	commits, err := core.TipCommits(g.repo, args[0], *n)
+	if err != nil {
+		return err
+	}
+	if err := core.CherryPick(g.repo, commits...); err != nil {
+		return err
+	}
+	fmt.Println(i18n.Sprintf("Cherry-picked %d commit(s) from %s", len(commits), args[0]))
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+// parseColumns compiles the computed columns of the config into g.
+func parseColumns(g *globals) error {
+	g.columns = nil
+	for _, c := range g.cfg.Columns {
+		col, err := tmpl.ParseColumn(c.Name, c.Template, c.Command)
+		if err != nil {
+			return usageErrorf("invalid column %s in config: %w", c.Name, err)
+		}
+		g.columns = append(g.columns, col)
+	}
+	return nil
+}
+
+// loadColumns compiles the computed columns of the config into g and adds
+// them to the picker, before those of plugins.
+func loadColumns(g *globals, opts *tui.Options) error {
+	if err := parseColumns(g); err != nil {
+		return err
+	}
+	for _, c := range g.cfg.Columns {
+		title, width := c.Title, c.Width
+		if title == "" {
+			title = c.Name
+		}
+		if width == 0 {
+			width = 12
+		}
+		opts.Columns = append(opts.Columns, tui.Column{ID: c.Name, Title: title, Width: width})
+	}
+	return nil
+}
+
+// computeColumns renders cols for branches in repo, keyed by full ref and
+// column name. Empty values are left out.
+func computeColumns(ctx context.Context, repo string, cols []*tmpl.Column, branches []core.Branch) (map[string]map[string]string, error) {
+	res := map[string]map[string]string{}
+	for _, b := range branches {
+		row := tmpl.NewRow(b, 0)
+		for _, c := range cols {
+			v, err := c.Value(ctx, repo, row)
+			if err != nil {
+				return res, err
+			}
+			if v == "" {
+				continue
+			}
+			if res[b.FullRef] == nil {
+				res[b.FullRef] = map[string]string{}
+			}
+			res[b.FullRef][c.Name] = v
+		}
+	}
+	return res, nil
+}
+
+// columnValues returns the picker's lookup of the values of the computed
+// columns and plugin columns in g, or nil when there are none. A failing
+// column does not keep the others' values from being shown.
+func columnValues(g *globals) func([]core.Branch) (map[string]map[string]string, error) {
+	var lookups []func([]core.Branch) (map[string]map[string]string, error)
+	repo := g.repo
+	if cols := g.columns; len(cols) > 0 {
+		lookups = append(lookups, func(branches []core.Branch) (map[string]map[string]string, error) {
+			return computeColumns(context.Background(), repo, cols, branches)
+		})
+	}
+	for _, p := range g.plugins {
+		if len(p.Columns) > 0 {
+			lookups = append(lookups, func(branches []core.Branch) (map[string]map[string]string, error) {
+				return p.Values(context.Background(), repo, branches)
+			})
+		}
+	}
+	if len(lookups) == 0 {
+		return nil
+	}
+	return func(branches []core.Branch) (map[string]map[string]string, error) {
+		res := map[string]map[string]string{}
+		var firstErr error
+		for _, lookup := range lookups {
+			vals, err := lookup(branches)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			for ref, cols := range vals {
+				if res[ref] == nil {
+					res[ref] = map[string]string{}
+				}
+				for id, v := range cols {
+					res[ref][id] = v
+				}
+			}
+		}
+		return res, firstErr
+	}
+}
+
+// addColumns sets the computed column values of branches, for list.
+func addColumns(g *globals, branches []core.Branch) error {
+	if err := parseColumns(g); err != nil || len(g.columns) == 0 {
+		return err
+	}
+	vals, err := computeColumns(context.Background(), g.repo, g.columns, branches)
+	if err != nil {
+		return err
+	}
+	for i := range branches {
+		branches[i].Columns = vals[branches[i].FullRef]
+	}
+	return nil
+}
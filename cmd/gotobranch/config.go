@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/kvnloughead/gotobranch/internal/config"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runConfig(g *globals, args []string) error {
+	fs := newFlagSet("config", g)
+	asJSON := fs.Bool("json", false, "Print the settings as JSON")
+	commandUsage(fs, "config")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	action := "show"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+	if *asJSON && action != "show" {
+		return usageErrorf("--json only applies to show")
+	}
+	switch action {
+	case "show":
+		if len(args) > 0 {
+			return usageErrorf("show takes no arguments")
+		}
+		return showConfig(g, *asJSON)
+	case "validate":
+		var paths []string
+		switch len(args) {
+		case 0:
+			if paths, err = configPaths(g); err != nil {
+				return err
+			}
+		case 1:
+			paths = args
+		default:
+			return usageErrorf("expected at most one file")
+		}
+		return validateConfig(paths...)
+	case "edit":
+		if len(args) > 0 {
+			return usageErrorf("edit takes no arguments")
+		}
+		return editConfig()
+	default:
+		return usageErrorf("unknown action %q; expected show, validate or edit", action)
+	}
+}
+
+// configCommand reports whether args run the config command, which has to
+// work with a broken config to be of any help with it.
+func configCommand(args []string) bool {
+	return len(args) > 0 && args[0] == "config"
+}
+
+// showConfig prints the settings in effect and where each came from.
+func showConfig(g *globals, asJSON bool) error {
+	settings, err := config.Settings(repoDir(g), os.Getenv)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		if settings == nil {
+			settings = []config.Setting{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(settings)
+	}
+	if len(settings) == 0 {
+		fmt.Println(i18n.T("No settings; everything is at its default."))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	return w.Flush()
+}
+
+// configPaths returns the user config file and, inside a repository, its
+// shared RepoFile when there is one.
+func configPaths(g *globals) ([]string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{path}
+	repo, err := config.RepoPath(repoDir(g))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(repo); repo != "" && err == nil {
+		paths = append(paths, repo)
+	}
+	return paths, nil
+}
+
+// validateConfig prints the problems with the config files at paths, each
+// as file:line: problem, and fails when there are any.
+func validateConfig(paths ...string) error {
+	n := 0
+	for _, path := range paths {
+		problems, err := config.Check(path)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			if p.Line > 0 {
+				fmt.Printf("%s:%d: %v\n", path, p.Line, p)
+			} else {
+				fmt.Printf("%s: %v\n", path, p)
+			}
+		}
+		n += len(problems)
+		if len(problems) == 0 {
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				fmt.Println(i18n.Sprintf("%s: no such file; the defaults apply", path))
+			} else {
+				fmt.Println(i18n.Sprintf("%s: ok", path))
+			}
+		}
+	}
+	if n > 0 {
+		return usageErrorf("%d problem(s) in the config", n)
+	}
+	return nil
+}
+
+// editConfig opens the user config file in $VISUAL or $EDITOR, creating it
+// first if need be, and validates it once the editor exits.
+func editConfig() error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte("{\n}\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Through the shell, as git runs it, since it may carry arguments
+	// (e.g. "code --wait").
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", editor, err)
+	}
+	return validateConfig(path)
+}
+
+// repoDir is the directory whose repository's shared config applies.
+func repoDir(g *globals) string {
+	if g.repo == "" {
+		return "."
+	}
+	return g.repo
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// editorProtocol is the version of the --editor protocol, sent in the
+// hello event. Plugins refuse versions they do not know.
+const editorProtocol = 1
+
+// editorEvent is a line gotobranch writes in editor mode.
+type editorEvent struct {
+	Event    string        `json:"event"` // hello, branches, switched, created, deleted, preview or error
+	Version  int           `json:"version,omitempty"`
+	Items    []core.Branch `json:"items,omitempty"`
+	Name     string        `json:"name,omitempty"`
+	Previous string        `json:"previous,omitempty"`
+	SHA      string        `json:"sha,omitempty"`
+	Text     string        `json:"text,omitempty"`
+	Action   string        `json:"action,omitempty"`  // the failed action, for errors
+	Message  string        `json:"message,omitempty"` // for errors, or a failed post hook of a done action
+}
+
+// editorAction is a line the editor sends back.
+type editorAction struct {
+	Action string `json:"action"` // list, switch, create, delete, preview or quit
+	Name   string `json:"name"`
+	From   string `json:"from"`
+	Force  bool   `json:"force"`
+}
+
+// runEditor speaks the editor protocol on stdin and stdout instead of
+// drawing the picker: one JSON object per line each way. The editor draws
+// the list from the branches event and sends actions back; every action is
+// answered with one event. Failed actions are reported as error events and
+// leave the session open. The session ends with a quit action or when
+// stdin is closed.
+func runEditor(g *globals, f *tuiFlags, req core.ListBranchesRequest) error {
+	if f.editor != "nvim" {
+		return usageErrorf("unknown --editor %q; supported: nvim", f.editor)
+	}
+	if err := fetchFirst(g, f.fetch); err != nil {
+		return err
+	}
+	out := json.NewEncoder(os.Stdout)
+	out.SetEscapeHTML(false)
+	if err := out.Encode(editorEvent{Event: "hello", Version: editorProtocol}); err != nil {
+		return err
+	}
+	if err := out.Encode(editorBranches(req)); err != nil {
+		return err
+	}
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 64*1024), 1<<20)
+	for in.Scan() {
+		line := strings.TrimSpace(in.Text())
+		if line == "" {
+			continue
+		}
+		var a editorAction
+		if err := json.Unmarshal([]byte(line), &a); err != nil {
+			if err := out.Encode(editorEvent{Event: "error", Message: "invalid action: " + err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if a.Action == "quit" {
+			return nil
+		}
+		if err := out.Encode(editorDispatch(g, req, a)); err != nil {
+			return err
+		}
+	}
+	if err := in.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// editorBranches lists the branches matching req as a branches event.
+func editorBranches(req core.ListBranchesRequest) editorEvent {
+	branches, err := listAll(req)
+	if err != nil {
+		return editorEvent{Event: "error", Action: "list", Message: err.Error()}
+	}
+	if branches == nil {
+		branches = []core.Branch{}
+	}
+	return editorEvent{Event: "branches", Items: branches}
+}
+
+// editorDispatch carries out a, returning the event answering it.
+func editorDispatch(g *globals, req core.ListBranchesRequest, a editorAction) editorEvent {
+	fail := func(err error) editorEvent {
+		return editorEvent{Event: "error", Action: a.Action, Name: a.Name, Message: err.Error()}
+	}
+	if a.Action != "list" && strings.TrimSpace(a.Name) == "" {
+		return fail(i18n.Errorf("%s needs a branch name", a.Action))
+	}
+	switch a.Action {
+	case "list":
+		return editorBranches(req)
+	case "switch":
+		prev, err := core.Checkout(g.repo, a.Name, false)
+		if err != nil && !core.PostHookFailed(err) {
+			return fail(err)
+		}
+		recordSwitch(g)
+		return editorEvent{Event: "switched", Name: a.Name, Previous: prev, Message: errText(err)}
+	case "create":
+		prev, created, err := core.CreateOrSwitch(g.repo, a.Name, a.From)
+		if err != nil && !core.PostHookFailed(err) {
+			return fail(err)
+		}
+		recordSwitch(g)
+		event := "switched"
+		if created {
+			event = "created"
+		}
+		return editorEvent{Event: event, Name: a.Name, Previous: prev, Message: errText(err)}
+	case "delete":
+		sha, err := core.DeleteBranch(g.repo, a.Name, a.Force)
+		if err != nil && !core.PostHookFailed(err) {
+			return fail(err)
+		}
+		return editorEvent{Event: "deleted", Name: a.Name, SHA: sha, Message: errText(err)}
+	case "preview":
+		text, err := core.Preview(g.repo, a.Name, false)
+		if err != nil {
+			return fail(err)
+		}
+		return editorEvent{Event: "preview", Name: a.Name, Text: text}
+	}
+	return fail(i18n.Errorf("unknown action %q", a.Action))
+}
+
+func errText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+// Exit codes are part of the command-line contract (see README); scripts
+// rely on them, so never renumber.
+const (
+	exitOK        = 0
+	exitError     = 1   // git or other runtime failure
+	exitUsage     = 2   // bad flags, arguments or configuration
+	exitNoMatch   = 3   // the pattern matched no branch
+	exitCancelled = 130 // the user backed out, as for Ctrl-C in a shell
+)
+
+var (
+	// errNoMatch is wrapped by errors for patterns that matched nothing.
+	errNoMatch = errors.New("no branch matches")
+	// errCancelled is returned when the user quits or declines; it is not
+	// reported as an error.
+	errCancelled = tui.ErrCancelled
+)
+
+// usageError marks errors caused by how gotobranch was invoked.
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// usageErrorf formats a usageError.
+func usageErrorf(format string, a ...any) error {
+	return usageError{i18n.Errorf(format, a...)}
+}
+
+// exitCode maps an error returned by run to the process exit code.
+func exitCode(err error) int {
+	var ue usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errCancelled):
+		return exitCancelled
+	case errors.Is(err, errNoMatch):
+		return exitNoMatch
+	case errors.As(err, &ue), errors.Is(err, core.ErrNamePolicy):
+		return exitUsage
+	default:
+		return exitError
+	}
+}
+
+// reportError prints err, and a hint when there is one, to stderr. Running
+// outside a repository fails on whatever git command comes first; that is
+// reported as such rather than as that command's failure.
+func reportError(g *globals, err error) {
+	if core.ErrorKind(err) == core.KindNotRepository {
+		if cerr := core.CheckRepo(g.repo); cerr != nil {
+			err = cerr
+		}
+	}
+	fmt.Fprintln(os.Stderr, i18n.Sprintf("error: %v", err))
+	if hint := core.Hint(err); hint != "" {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("hint: %s", i18n.T(hint)))
+	}
+}
+
+// logStart logs the command about to run and the flags it was given, but
+// not their values (see flagNames), since serve's --token is a secret.
+func logStart(g *globals) {
+	g.log.Debug("run", "command", g.command, "flags", flagNames(os.Args[1:]))
+}
+
+// logRun logs how the command ended: at error level when it failed, at
+// warn level when it was invoked wrongly, and otherwise at info level.
+func logRun(g *globals, code int, err error, d time.Duration) {
+	level := slog.LevelInfo
+	switch code {
+	case exitError:
+		level = slog.LevelError
+	case exitUsage:
+		level = slog.LevelWarn
+	}
+	args := []any{"command", g.command, "exit", code, "duration", d}
+	if err != nil && code != exitCancelled {
+		args = append(args, "err", err)
+	}
+	g.log.Log(context.Background(), level, "done", args...)
+}
+
+// flagError classifies an error from flag parsing; -h is not an error.
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return usageError{err}
+}
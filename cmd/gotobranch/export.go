@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// exportColumns are the CSV header, in order.
+var exportColumns = []string{
+	"name", "full_ref", "current", "remote", "upstream", "upstream_gone", "ahead", "behind",
+	"base", "merged", "head_sha", "head_commit_at", "author", "author_email", "subject",
+}
+
+func runExport(g *globals, args []string) error {
+	fs := newFlagSet("export", g)
+	out := fs.String("out", "-", "File to write; its extension (.csv or .json) picks the format unless --format is given. - is stdout")
+	format := fs.String("format", "", "csv or json (default: from --out, else csv)")
+	base := fs.String("base", "", "Branch that merged is measured against (default: the default branch)")
+	commandUsage(fs, "export")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageErrorf("too many arguments; expected at most one pattern")
+	}
+	if *format == "" {
+		*format = "csv"
+		if strings.EqualFold(filepath.Ext(*out), ".json") {
+			*format = "json"
+		}
+	}
+	if *format != "csv" && *format != "json" {
+		return usageErrorf("--format must be csv or json, not %q", *format)
+	}
+	scope, err := g.parseScope()
+	if err != nil {
+		return err
+	}
+	sortBy, sortDir, err := g.parseSort()
+	if err != nil {
+		return err
+	}
+	match, err := g.parseMatch()
+	if err != nil {
+		return err
+	}
+	req := core.ListBranchesRequest{
+		RepoPath: g.repo,
+		Scope:    scope,
+		Match:    match,
+		Exclude:  g.exclude,
+		SortBy:   sortBy,
+		SortDir:  sortDir,
+		Query:    g.filterQuery(),
+	}
+	if len(args) == 1 {
+		req.Pattern = args[0]
+	}
+	if err := g.checkFilter(match, req.Pattern); err != nil {
+		return err
+	}
+	branches, err := listAll(req)
+	if err != nil {
+		return err
+	}
+	details, err := core.Details(g.repo, branches, *base)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if details == nil {
+			details = []core.BranchDetails{}
+		}
+		return enc.Encode(details)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return err
+	}
+	for _, d := range details {
+		if err := cw.Write(exportRecord(d)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportRecord renders d as a CSV row matching exportColumns. Unknown
+// values are empty.
+func exportRecord(d core.BranchDetails) []string {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	var at string
+	if d.HeadCommitAt != nil {
+		at = d.HeadCommitAt.Format(time.RFC3339)
+	}
+	return []string{
+		d.Name, d.FullRef, strconv.FormatBool(d.IsCurrent), strconv.FormatBool(d.IsRemote),
+		str(d.Upstream), strconv.FormatBool(d.UpstreamGone), strconv.Itoa(d.Ahead), strconv.Itoa(d.Behind),
+		d.Base, strconv.FormatBool(d.Merged), str(d.HeadCommitSHA), at,
+		str(d.Author), str(d.AuthorEmail), str(d.LastCommitMessage),
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runFetch(g *globals, args []string) error {
+	fs := newFlagSet("fetch", g)
+	noPrune := fs.Bool("no-prune", false, "Keep remote-tracking branches that were deleted on the remote")
+	history := fs.Bool("history", false, "Fetch the history a shallow clone lacks")
+	commandUsage(fs, "fetch")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageErrorf("expected at most one remote")
+	}
+	if *history {
+		if len(args) > 0 {
+			return usageErrorf("--history takes no remote")
+		}
+		return fetchHistory(g)
+	}
+	var remote string
+	if len(args) == 1 {
+		remote = args[0]
+	}
+	if err := core.Fetch(g.repo, remote, !*noPrune); err != nil {
+		return err
+	}
+	if remote == "" {
+		remote = "all remotes"
+	}
+	fmt.Println(i18n.Sprintf("Fetched %s", remote))
+	return nil
+}
+
+// fetchHistory unshallows the repository, or says it need not.
+func fetchHistory(g *globals) error {
+	c, err := core.Clone(g.repo)
+	if err != nil {
+		return err
+	}
+	if !c.Shallow {
+		fmt.Println(i18n.T("History is already complete"))
+		return nil
+	}
+	cmd := core.FetchHistoryCommand(g.repo)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return i18n.Errorf("fetching history: %w", err)
+	}
+	fmt.Println(i18n.T("Fetched the full history"))
+	return nil
+}
+
+// fetchFlag is a boolean flag that optionally names a remote:
+// --fetch fetches all remotes, --fetch=origin only origin.
+type fetchFlag struct {
+	enabled bool
+	remote  string
+}
+
+func (f *fetchFlag) String() string {
+	if f == nil || !f.enabled {
+		return ""
+	}
+	if f.remote == "" {
+		return "true"
+	}
+	return f.remote
+}
+
+func (f *fetchFlag) Set(s string) error {
+	switch s {
+	case "true":
+		f.enabled, f.remote = true, ""
+	case "false":
+		f.enabled, f.remote = false, ""
+	default:
+		f.enabled, f.remote = true, s
+	}
+	return nil
+}
+
+func (f *fetchFlag) IsBoolFlag() bool { return true }
+
+func registerFetchFlag(fs *flag.FlagSet) *fetchFlag {
+	var f fetchFlag
+	fs.Var(&f, "fetch", "Run 'git fetch --prune' first (all remotes, or --fetch=<remote>)")
+	return &f
+}
+
+// fetchFirst runs the fetch requested by f for non-interactive commands,
+// reporting progress on stderr so stdout stays machine-readable.
+func fetchFirst(g *globals, f *fetchFlag) error {
+	if !f.enabled {
+		return nil
+	}
+	what := f.remote
+	if what == "" {
+		what = "all remotes"
+	}
+	fmt.Fprintln(os.Stderr, i18n.Sprintf("Fetching %s...", what))
+	if err := core.Fetch(g.repo, f.remote, true); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, i18n.T("Fetch complete."))
+	return nil
+}
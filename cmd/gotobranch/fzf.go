@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// fzfPipeline wires the fzf and preview commands into fzf. The first field
+// is the branch name, so {1} can be used in further bindings.
+const fzfPipeline = `gotobranch fzf | fzf --ansi --delimiter '\t' --with-nth 2,1,3.. --preview 'gotobranch preview --color {1}' | cut -f1 | xargs -r gotobranch switch`
+
+func runFzf(g *globals, args []string) error {
+	fs := newFlagSet("fzf", g)
+	pipeline := fs.Bool("pipeline", false, "Print a ready-made fzf command line instead of the branches")
+	commandUsage(fs, "fzf")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageErrorf("too many arguments; expected at most one pattern")
+	}
+	if *pipeline {
+		fmt.Println(fzfPipeline)
+		return nil
+	}
+	scope, err := g.parseScope()
+	if err != nil {
+		return err
+	}
+	sortBy, sortDir, err := g.parseSort()
+	if err != nil {
+		return err
+	}
+	match, err := g.parseMatch()
+	if err != nil {
+		return err
+	}
+	req := core.ListBranchesRequest{
+		RepoPath: g.repo,
+		Scope:    scope,
+		Match:    match,
+		Exclude:  g.exclude,
+		SortBy:   sortBy,
+		SortDir:  sortDir,
+		Query:    g.filterQuery(),
+	}
+	if len(args) == 1 {
+		req.Pattern = args[0]
+	}
+	if err := g.checkFilter(match, req.Pattern); err != nil {
+		return err
+	}
+	branches, err := listAll(req)
+	if err != nil {
+		return err
+	}
+	// name, current marker, age, author, subject; tabs in free text would
+	// shift the fields.
+	clean := strings.NewReplacer("\t", " ").Replace
+	now := time.Now()
+	for _, b := range branches {
+		r := tmpl.NewRow(b, 0)
+		mark := " "
+		if b.IsCurrent {
+			mark = "*"
+		}
+		var author string
+		if b.Author != nil {
+			author = *b.Author
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", b.Name, mark, tmpl.Age(r.HeadCommitAt, now), clean(author), clean(r.Subject))
+	}
+	return nil
+}
+
+func runPreview(g *globals, args []string) error {
+	fs := newFlagSet("preview", g)
+	color := fs.Bool("color", false, "Color the output (for fzf --ansi)")
+	commandUsage(fs, "preview")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageErrorf("expected one ref")
+	}
+	out, err := core.Preview(g.repo, args[0], *color)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
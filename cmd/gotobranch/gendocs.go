@@ -0,0 +1,251 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/config"
+)
+
+// hiddenCommands work like commands but are left out of the help output.
+var hiddenCommands []command
+
+func init() {
+	hiddenCommands = []command{
+		{"gen-docs", "", "Generate man pages and markdown reference docs", runGenDocs},
+		{"gen-openapi", "", "Generate the OpenAPI document of the serve API", runGenOpenAPI},
+		{"hook", "<name> [args]", "Handle a git hook installed by install", runHook},
+	}
+}
+
+// describing, when set, receives the flag set of the command being run
+// instead of parsing arguments; see flagsOf.
+var describing func(fs *flag.FlagSet)
+
+// flagsOf returns the flags command c accepts. The flags are defined inside
+// each command's run function, so c is run with describing set, which makes
+// parseArgs hand over the flag set and stop.
+func flagsOf(c command) *flag.FlagSet {
+	var got *flag.FlagSet
+	describing = func(fs *flag.FlagSet) { got = fs }
+	defer func() { describing = nil }()
+	_ = c.run(newGlobals(config.Config{}), nil)
+	return got
+}
+
+// rootFlags returns the flags accepted without a command.
+func rootFlags() *flag.FlagSet {
+	fs := newFlagSet("gotobranch", newGlobals(config.Config{}))
+	registerTUIFlags(fs)
+	return fs
+}
+
+// docFlag is a flag as presented in the docs.
+type docFlag struct {
+	spelling string // e.g. "--page-size n" or "-v"
+	usage    string
+	def      string // empty when not worth mentioning
+}
+
+func docFlags(fs *flag.FlagSet) []docFlag {
+	var res []docFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		arg, usage := flag.UnquoteUsage(f)
+		spelling := "--" + f.Name
+		if len(f.Name) == 1 {
+			spelling = "-" + f.Name
+		}
+		if arg != "" {
+			spelling += " " + arg
+		}
+		d := docFlag{spelling: spelling, usage: usage}
+		switch f.DefValue {
+		case "", "false", "0":
+		default:
+			d.def = f.DefValue
+		}
+		res = append(res, d)
+	})
+	return res
+}
+
+// documented lists the commands that get their own page.
+func documented() []command {
+	var res []command
+	for _, c := range commands {
+		if c.name != "help" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+var docEnv = []struct{ name, desc string }{
+	{config.EnvRepo, "Repository to operate on."},
+	{config.EnvScope, "Default branch scope."},
+	{config.EnvSort, "Default ordering."},
+	{config.EnvTheme, "Color theme."},
+	{config.EnvGitBin, "git executable to run."},
+	{config.EnvLockWait, "How long to retry git commands blocked by another git process's lock, e.g. 10s."},
+	{config.EnvLocalTimeout, "How long a local git command may run before it is killed, e.g. 1m; 0 for no limit."},
+	{config.EnvNetworkTimeout, "How long a git command talking to a remote may run before it is killed, e.g. 5m; 0 for no limit."},
+	{config.EnvNoTUI, "Print the list instead of opening the picker."},
+	{config.EnvTrace, "Log git commands to stderr (1) or to the named file."},
+	{config.EnvLog, "Write diagnostics of this level and above (debug, info, warn, error) to the log file."},
+	{config.EnvProfile, "Profile applied when --profile is not given."},
+}
+
+var docExitCodes = []struct {
+	code int
+	desc string
+}{
+	{exitOK, "Success, including switching branches."},
+	{exitError, "git or other runtime error."},
+	{exitUsage, "Usage error: bad flags, arguments, config or pattern."},
+	{exitNoMatch, "The pattern matched no branch."},
+	{exitCancelled, "Cancelled: the picker was quit or a prompt declined."},
+}
+
+func runGenDocs(g *globals, args []string) error {
+	fs := newFlagSet("gen-docs", g)
+	out := fs.String("out", "docs", "Directory to write man/ and md/ into")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "usage: gotobranch gen-docs [--out dir]\n\nGenerate man pages and markdown reference docs.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("gen-docs takes no arguments")
+	}
+	manDir, mdDir := filepath.Join(*out, "man"), filepath.Join(*out, "md")
+	for _, dir := range []string{manDir, mdDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	files := map[string]string{
+		filepath.Join(manDir, "gotobranch.1"): rootMan(),
+		filepath.Join(mdDir, "gotobranch.md"): rootMarkdown(),
+	}
+	for _, c := range documented() {
+		files[filepath.Join(manDir, "gotobranch-"+c.name+".1")] = commandMan(c)
+		files[filepath.Join(mdDir, "gotobranch_"+c.name+".md")] = commandMarkdown(c)
+	}
+	for path, text := range files {
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Wrote %d files to %s\n", len(files), *out)
+	return nil
+}
+
+// synopsisArgs is what follows the command name in its synopsis.
+func synopsisArgs(c command) string {
+	return strings.TrimSpace("[flags] " + c.args)
+}
+
+// roff escapes s for use as man page text.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func manHeader(b *strings.Builder, title, name, short string) {
+	fmt.Fprintf(b, ".TH %s 1 \"\" \"gotobranch %s\" \"gotobranch manual\"\n", strings.ToUpper(title), roff(getBuildInfo().Version))
+	fmt.Fprintf(b, ".SH NAME\n%s \\- %s\n", roff(name), roff(short))
+}
+
+func manFlags(b *strings.Builder, fs *flag.FlagSet) {
+	b.WriteString(".SH OPTIONS\n")
+	for _, f := range docFlags(fs) {
+		fmt.Fprintf(b, ".TP\n.B %s\n%s\n", roff(f.spelling), roff(f.usage))
+		if f.def != "" {
+			fmt.Fprintf(b, "(default: %s)\n", roff(f.def))
+		}
+	}
+}
+
+func rootMan() string {
+	var b strings.Builder
+	manHeader(&b, "gotobranch", "gotobranch", "interactive git branch navigator")
+	b.WriteString(".SH SYNOPSIS\n.B gotobranch\n[flags] [pattern]\n.br\n.B gotobranch\n<command> [flags] [args]\n")
+	b.WriteString(".SH DESCRIPTION\nWithout a command, opens the interactive branch picker, filtered by\n.IR pattern .\nA pattern naming a single branch switches to it directly.\n")
+	b.WriteString(".SH COMMANDS\n")
+	for _, c := range documented() {
+		fmt.Fprintf(&b, ".TP\n.BR gotobranch\\-%s (1)\n%s\n", roff(c.name), roff(c.short))
+	}
+	manFlags(&b, rootFlags())
+	b.WriteString(".SH ENVIRONMENT\nThese override the config file and are overridden by flags.\n")
+	for _, e := range docEnv {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roff(e.name), roff(e.desc))
+	}
+	b.WriteString(".SH FILES\n.TP\n.I ~/.config/gotobranch/config.json\nConfiguration file on Linux; $XDG_CONFIG_HOME replaces ~/.config when set. On macOS it is in ~/Library/Application Support/gotobranch, on Windows in %AppData%\\\\gotobranch.\n")
+	b.WriteString(".SH EXIT STATUS\n")
+	for _, e := range docExitCodes {
+		fmt.Fprintf(&b, ".TP\n.B %d\n%s\n", e.code, roff(e.desc))
+	}
+	return b.String()
+}
+
+func commandMan(c command) string {
+	var b strings.Builder
+	name := "gotobranch-" + c.name
+	manHeader(&b, name, name, c.short)
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B gotobranch %s\n%s\n", roff(c.name), roff(synopsisArgs(c)))
+	manFlags(&b, flagsOf(c))
+	b.WriteString(".SH SEE ALSO\n.BR gotobranch (1)\n")
+	return b.String()
+}
+
+func markdownFlags(b *strings.Builder, fs *flag.FlagSet) {
+	b.WriteString("## Flags\n\n")
+	for _, f := range docFlags(fs) {
+		fmt.Fprintf(b, "- `%s`: %s", f.spelling, f.usage)
+		if f.def != "" {
+			fmt.Fprintf(b, " (default: `%s`)", f.def)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func rootMarkdown() string {
+	var b strings.Builder
+	b.WriteString("# gotobranch\n\nInteractive git branch navigator.\n\n")
+	b.WriteString("```\ngotobranch [flags] [pattern]\ngotobranch <command> [flags] [args]\n```\n\n")
+	b.WriteString("Without a command, opens the interactive branch picker, filtered by pattern. A pattern naming a single branch switches to it directly.\n\n")
+	b.WriteString("## Commands\n\n")
+	for _, c := range documented() {
+		fmt.Fprintf(&b, "- [%s](gotobranch_%s.md): %s\n", c.name, c.name, c.short)
+	}
+	b.WriteString("\n")
+	markdownFlags(&b, rootFlags())
+	b.WriteString("\n## Environment\n\nThese override the config file and are overridden by flags.\n\n")
+	for _, e := range docEnv {
+		fmt.Fprintf(&b, "- `%s`: %s\n", e.name, e.desc)
+	}
+	b.WriteString("\n## Exit status\n\n")
+	for _, e := range docExitCodes {
+		fmt.Fprintf(&b, "- `%d`: %s\n", e.code, e.desc)
+	}
+	return b.String()
+}
+
+func commandMarkdown(c command) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# gotobranch %s\n\n%s.\n\n", c.name, c.short)
+	fmt.Fprintf(&b, "```\ngotobranch %s %s\n```\n\n", c.name, synopsisArgs(c))
+	markdownFlags(&b, flagsOf(c))
+	b.WriteString("\nSee also [gotobranch](gotobranch.md).\n")
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/state"
+)
+
+func runHistory(g *globals, args []string) error {
+	fs := newFlagSet("history", g)
+	all := fs.Bool("all", false, "Show the operations in every repository, not just this one")
+	asJSON := fs.Bool("json", false, "Print the operations as JSON lines, oldest first")
+	n := fs.Int("n", 20, "Show the last `n` operations")
+	commandUsage(fs, "history")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *n <= 0 {
+		return usageErrorf("-n must be a positive number")
+	}
+	var branch string
+	switch len(args) {
+	case 0:
+	case 1:
+		branch = args[0]
+	default:
+		return usageErrorf("expected at most one branch")
+	}
+	repo := ""
+	if !*all {
+		if repo, err = core.TopLevel(g.repo); err != nil {
+			return err
+		}
+	}
+	entries, err := state.Audit(repo)
+	if err != nil {
+		return err
+	}
+	if branch != "" {
+		entries = slices.DeleteFunc(entries, func(e state.AuditEntry) bool {
+			return e.Branch != branch && e.Previous != branch
+		})
+	}
+	entries = entries[max(len(entries)-*n, 0):]
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Println(i18n.T("No operations recorded."))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s", e.At.Local().Format("2006-01-02 15:04:05"), e.Op, describeOperation(e.Operation), shaChange(e.Operation), who(e))
+		if *all {
+			fmt.Fprintf(w, "\t%s", e.Repo)
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+// describeOperation names the branches op involved, e.g. "main → feat/x"
+// for a switch.
+func describeOperation(op core.Operation) string {
+	var s string
+	switch op.Op {
+	case core.ActionSwitch, core.ActionRename:
+		prev := op.Previous
+		if prev == "" {
+			prev = "(detached)"
+		}
+		s = prev + " → " + op.Branch
+	case core.OpPush:
+		s = op.Branch + " → " + op.Remote
+	default:
+		s = op.Branch
+	}
+	if op.Force {
+		s += " (forced)"
+	}
+	return s
+}
+
+// shaChange shows the commits op moved between, e.g. "1a2b3c4 → 5d6e7f8",
+// "-" standing for none.
+func shaChange(op core.Operation) string {
+	short := func(sha string) string {
+		if sha == "" {
+			return "-"
+		}
+		return sha[:min(7, len(sha))]
+	}
+	if op.Old == op.New {
+		return short(op.New)
+	}
+	return short(op.Old) + " → " + short(op.New)
+}
+
+// who describes who made e and how, e.g. "alice <a@example.com> via picker".
+func who(e state.AuditEntry) string {
+	s := e.User
+	if e.Git != "" {
+		s += " <" + e.Git + ">"
+	}
+	if e.Via != "" {
+		s += " via " + e.Via
+	}
+	return s
+}
+
+// auditor returns the core.SetAudit callback recording operations in the
+// audit log that history reads, as made by the current user through the
+// command g runs. Like recordSwitch it is best effort.
+func auditor(g *globals) func(repoPath string, op core.Operation) {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	var mu sync.Mutex
+	emails := map[string]string{}
+	return func(repoPath string, op core.Operation) {
+		top, err := core.TopLevel(repoPath)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		email, ok := emails[top]
+		if !ok {
+			email, _ = core.UserEmail(top)
+			emails[top] = email
+		}
+		mu.Unlock()
+		_ = state.RecordAudit(state.AuditEntry{
+			At:        time.Now(),
+			Repo:      top,
+			User:      name,
+			Git:       email,
+			Via:       g.command,
+			Operation: op,
+		})
+	}
+}
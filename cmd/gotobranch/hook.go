@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/state"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+// hookBlock is what install adds to the post-checkout hook. Failures are
+// swallowed: a missing gotobranch must never make a checkout fail.
+const hookBlock = rcBegin + `
+command -v gotobranch >/dev/null 2>&1 && gotobranch hook post-checkout "$@" >/dev/null 2>&1 || :
+` + rcEnd + "\n"
+
+// runHook receives the events of the git hooks install sets up.
+func runHook(g *globals, args []string) error {
+	fs := newFlagSet("hook", g)
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] != "post-checkout" {
+		return usageErrorf("expected a hook name: post-checkout")
+	}
+	// post-checkout gets the old and new HEAD and whether branches (1) or
+	// files (0) were checked out.
+	if len(args) != 4 || args[3] != "1" {
+		return nil
+	}
+	recordSwitch(g)
+	return nil
+}
+
+// recordSwitch notes in the state store that the current branch was just
+// switched to. It is best effort: the switch itself already happened.
+func recordSwitch(g *globals) {
+	b, err := core.GetCurrentBranch(g.repo)
+	if err != nil {
+		return
+	}
+	top, err := core.TopLevel(g.repo)
+	if err != nil {
+		return
+	}
+	_ = state.Record(state.Switch{Repo: top, Branch: b.Name, At: time.Now()})
+}
+
+// recordedSwitches returns the switches recorded for the repository, for
+// core.RecentBranches.
+func recordedSwitches(g *globals) []core.RecentBranch {
+	top, err := core.TopLevel(g.repo)
+	if err != nil {
+		return nil
+	}
+	switches, err := state.Switches(top)
+	if err != nil {
+		return nil
+	}
+	res := make([]core.RecentBranch, len(switches))
+	for i, s := range switches {
+		res[i] = core.RecentBranch{Name: s.Branch, At: s.At}
+	}
+	return res
+}
+
+// hookStep adds hookBlock to the repository's post-checkout hook, creating
+// the hook if needed. The block goes right after the shebang of an existing
+// hook so that an early exit cannot skip it.
+func hookStep(path string) installStep {
+	return installStep{
+		what: i18n.Sprintf("post-checkout hook in %s", path),
+		installed: func() (bool, error) {
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return strings.Contains(string(data), rcBegin), err
+		},
+		install: func() error {
+			data, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			s := string(data)
+			switch {
+			case s == "":
+				s = "#!/bin/sh\n" + hookBlock
+			case strings.HasPrefix(s, "#!"):
+				shebang, rest, _ := strings.Cut(s, "\n")
+				s = shebang + "\n" + hookBlock + rest
+			default:
+				s = hookBlock + s
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(s), 0o755); err != nil {
+				return err
+			}
+			// WriteFile keeps the mode of an existing file.
+			return os.Chmod(path, 0o755)
+		},
+		uninstall: func() error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			s := removeBlock(string(data))
+			if strings.TrimSpace(s) == "#!/bin/sh" {
+				return os.Remove(path)
+			}
+			return os.WriteFile(path, []byte(s), 0o755)
+		},
+	}
+}
+
+// captureHooks collects the output of the configured hooks while the
+// full-screen UI p owns the terminal, sending it p line by line as
+// tui.HookOutputMsg. The returned function stops collecting and prints
+// what was collected.
+func (g *globals) captureHooks(p *tea.Program) (restore func()) {
+	if g.hooks == nil {
+		return func() {}
+	}
+	w := &hookOutput{send: p.Send}
+	prev := g.hooks.SetOutput(w)
+	return func() {
+		g.hooks.SetOutput(prev)
+		_, _ = os.Stderr.Write(w.all.Bytes())
+	}
+}
+
+// hookOutput keeps all the hook output written to it, and sends each line
+// as it is completed.
+type hookOutput struct {
+	send func(tea.Msg)
+	all  bytes.Buffer
+	line []byte // the incomplete last line
+}
+
+func (w *hookOutput) Write(p []byte) (int, error) {
+	w.all.Write(p)
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(w.line[:i])); line != "" {
+			w.send(tui.HookOutputMsg(line))
+		}
+		w.line = w.line[i+1:]
+	}
+	return len(p), nil
+}
+
+// frecencyScores returns the frecency of the branches recorded as switched
+// to in the repository at repoPath, for core.SetFrecency.
+func frecencyScores(repoPath string) map[string]float64 {
+	top, err := core.TopLevel(repoPath)
+	if err != nil {
+		return nil
+	}
+	scores, _ := state.Frecency(top, time.Now())
+	return scores
+}
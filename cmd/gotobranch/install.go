@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// installStep is one piece of shell or git integration that install can set
+// up and remove again.
+type installStep struct {
+	what      string // e.g. "git goto alias"
+	installed func() (bool, error)
+	install   func() error
+	uninstall func() error
+}
+
+const (
+	gitAliasKey   = "alias.goto"
+	gitAliasValue = "!gotobranch"
+
+	rcBegin = "# >>> gotobranch >>>"
+	rcEnd   = "# <<< gotobranch <<<"
+)
+
+func runInstall(g *globals, args []string) error {
+	fs := newFlagSet("install", g)
+	shell := fs.String("shell", filepath.Base(os.Getenv("SHELL")), "Shell whose startup file gets the wrapper function: bash|zsh|fish")
+	name := fs.String("cmd", "gotobranch", "Name of the shell function to define")
+	yes := fs.Bool("yes", false, "Do not ask before each step")
+	uninstall := fs.Bool("uninstall", false, "Remove everything install sets up")
+	commandUsage(fs, "install")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("install takes no arguments")
+	}
+
+	steps := []installStep{aliasStep()}
+	if _, ok := shellInit[*shell]; ok {
+		rc, err := rcFile(*shell)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, wrapperStep(*shell, *name, rc))
+	} else {
+		fmt.Println(i18n.Sprintf("Skipping the shell wrapper: unsupported shell %q (use --shell bash|zsh|fish)", *shell))
+	}
+	// The hook is per repository, so it is only offered inside one.
+	if path, err := core.HookPath(g.repo, "post-checkout"); err == nil {
+		steps = append(steps, hookStep(path))
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for _, s := range steps {
+		done, err := s.installed()
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.what, err)
+		}
+		if done != *uninstall {
+			// Nothing to do in this direction.
+			if *uninstall {
+				fmt.Println(i18n.Sprintf("%s: not installed", s.what))
+			} else {
+				fmt.Println(i18n.Sprintf("%s: already installed", s.what))
+			}
+			continue
+		}
+		question, act := i18n.Sprintf("Install %s? [Y/n] ", s.what), s.install
+		if *uninstall {
+			question, act = i18n.Sprintf("Remove %s? [Y/n] ", s.what), s.uninstall
+		}
+		if !*yes {
+			fmt.Print(question)
+			answer, _ := in.ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+				continue
+			}
+		}
+		if err := act(); err != nil {
+			return fmt.Errorf("%s: %w", s.what, err)
+		}
+		if *uninstall {
+			fmt.Println(i18n.Sprintf("%s: removed", s.what))
+		} else {
+			fmt.Println(i18n.Sprintf("%s: installed", s.what))
+		}
+	}
+	return nil
+}
+
+// aliasStep adds `git goto` as an alias for gotobranch to the global git
+// config. An alias.goto the user defined themselves is never touched.
+func aliasStep() installStep {
+	return installStep{
+		what: i18n.T("git goto alias"),
+		installed: func() (bool, error) {
+			v, ok, err := core.GlobalConfig(gitAliasKey)
+			if err != nil {
+				return false, err
+			}
+			if ok && v != gitAliasValue {
+				return false, i18n.Errorf("%s is already set to %q; leaving it alone", gitAliasKey, v)
+			}
+			return ok, nil
+		},
+		install:   func() error { return core.SetGlobalConfig(gitAliasKey, gitAliasValue) },
+		uninstall: func() error { return core.UnsetGlobalConfig(gitAliasKey) },
+	}
+}
+
+// wrapperStep loads the shell wrapper printed by "gotobranch init" from the
+// shell's startup file, inside a marked block that uninstall removes.
+func wrapperStep(shell, name, rc string) installStep {
+	cmd := "gotobranch init " + shell
+	if name != "gotobranch" {
+		cmd += " --cmd " + name
+	}
+	line := `eval "$(` + cmd + `)"`
+	if shell == "fish" {
+		line = cmd + " | source"
+	}
+	block := rcBegin + "\n" + line + "\n" + rcEnd + "\n"
+	return installStep{
+		what: i18n.Sprintf("shell wrapper in %s", rc),
+		installed: func() (bool, error) {
+			data, err := os.ReadFile(rc)
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return strings.Contains(string(data), rcBegin), err
+		},
+		install: func() error {
+			if err := os.MkdirAll(filepath.Dir(rc), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(rc, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err := f.WriteString("\n" + block); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		},
+		uninstall: func() error {
+			data, err := os.ReadFile(rc)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(rc, []byte(removeBlock(string(data))), 0o644)
+		},
+	}
+}
+
+// removeBlock drops the lines from rcBegin to rcEnd, and the blank line
+// install put before them.
+func removeBlock(s string) string {
+	start := strings.Index(s, rcBegin)
+	if start < 0 {
+		return s
+	}
+	end := strings.Index(s[start:], rcEnd)
+	if end < 0 {
+		return s
+	}
+	end += start + len(rcEnd)
+	if end < len(s) && s[end] == '\n' {
+		end++
+	}
+	if strings.HasSuffix(s[:start], "\n\n") {
+		start--
+	}
+	return s[:start] + s[end:]
+}
+
+// rcFile returns the startup file of shell.
+func rcFile(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch shell {
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			dir = filepath.Join(home, ".config")
+		}
+		return filepath.Join(dir, "fish", "config.fish"), nil
+	default:
+		return filepath.Join(home, ".bashrc"), nil
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/github"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// defaultIssueBranch names issue branches when the config does not.
+const defaultIssueBranch = `feat/{{.Number}}-{{.Title | slug}}`
+
+func runIssue(g *globals, args []string) error {
+	fs := newFlagSet("issue", g)
+	from := fs.String("from", "", "Start the branch at this ref instead of the default branch")
+	dryRun := fs.Bool("dry-run", false, "Print the branch name without creating it")
+	commandUsage(fs, "issue")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageErrorf("expected one issue number")
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil || n <= 0 {
+		return usageErrorf("invalid issue number %q", args[0])
+	}
+	name, err := issueBranchName(g, n)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Println(name)
+		return nil
+	}
+	return createIssueBranch(g, name, *from)
+}
+
+// issueBranchName looks up issue n and names a branch after it with the
+// configured template.
+func issueBranchName(g *globals, n int) (string, error) {
+	format := g.cfg.IssueBranch
+	if format == "" {
+		format = defaultIssueBranch
+	}
+	t, err := tmpl.Parse("issueBranch", format)
+	if err != nil {
+		return "", usageErrorf("invalid issueBranch in config: %w", err)
+	}
+	slug, err := github.Slug(g.repo)
+	if err != nil {
+		return "", err
+	}
+	issue, err := github.GetIssue(context.Background(), slug, n)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, issue); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(b.String())
+	if name == "" {
+		return "", i18n.Errorf("issueBranch rendered an empty name for #%d", n)
+	}
+	return name, nil
+}
+
+// createIssueBranch creates name from start, or from issueStart when start
+// is empty, and switches to it.
+func createIssueBranch(g *globals, name, start string) error {
+	if start == "" {
+		var err error
+		if start, err = issueStart(g); err != nil {
+			return err
+		}
+	}
+	return createOrSwitch(g, name, start)
+}
+
+// issueStart is where issue branches start: the default branch, or its
+// remote-tracking branch when there is no local one (without making that
+// the new branch's upstream).
+func issueStart(g *globals) (string, error) {
+	base, err := core.DefaultBranch(g.repo)
+	if err != nil {
+		return "", err
+	}
+	if !core.LocalBranchExists(g.repo, base) {
+		return "origin/" + base + "^{commit}", nil
+	}
+	return base, nil
+}
+
+// pickerIssueBranch backs the picker's issue key.
+func pickerIssueBranch(g *globals) func(n int) (string, error) {
+	return func(n int) (string, error) {
+		name, err := issueBranchName(g, n)
+		if err != nil {
+			return "", err
+		}
+		start, err := issueStart(g)
+		if err != nil {
+			return "", err
+		}
+		_, _, err = core.CreateOrSwitch(g.repo, name, start)
+		return name, err
+	}
+}
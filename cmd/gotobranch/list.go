@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/kvnloughead/gotobranch/internal/ci"
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/github"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+func runList(g *globals, args []string) error {
+	fs := newFlagSet("list", g)
+	asJSON := fs.Bool("json", false, "Print the ListBranchesResponse as JSON")
+	format := fs.String("format", "", "Go template evaluated per branch, e.g. '{{.Name}}\\t{{.HeadCommitSHA | short}}'")
+	page := fs.Int("page", 0, "Print only this 1-based page (default: all branches)")
+	pageSize := fs.Int("page-size", 50, "Page size used with --page")
+	withCI := fs.Bool("ci", g.cfg.CIStatus, "Look up the CI status of each branch's head commit (GitHub or GitLab)")
+	sigs := fs.Bool("signatures", g.cfg.Signatures, "Verify the signature of each branch's head commit (runs gpg or ssh-keygen)")
+	commits := fs.Bool("commits", g.cfg.Commits, "Count each branch's commits that the default branch lacks")
+	prs := fs.Bool("prs", g.cfg.PullRequests, "Look up each branch's GitHub pull request (via gh, or GH_TOKEN/GITHUB_TOKEN)")
+	fetch := registerFetchFlag(fs)
+	commandUsage(fs, "list")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageErrorf("too many arguments; expected at most one pattern")
+	}
+	if *asJSON && *format != "" {
+		return usageErrorf("--json and --format are mutually exclusive")
+	}
+	scope, err := g.parseScope()
+	if err != nil {
+		return err
+	}
+	sortBy, sortDir, err := g.parseSort()
+	if err != nil {
+		return err
+	}
+	match, err := g.parseMatch()
+	if err != nil {
+		return err
+	}
+	if err := fetchFirst(g, fetch); err != nil {
+		return err
+	}
+	var rowTmpl *template.Template
+	if *format != "" {
+		if rowTmpl, err = tmpl.Parse("format", unescape(*format)); err != nil {
+			return err
+		}
+	}
+	req := core.ListBranchesRequest{
+		RepoPath: g.repo,
+		Scope:    scope,
+		Match:    match,
+		Exclude:  g.exclude,
+		SortBy:   sortBy,
+		SortDir:  sortDir,
+		Query:    g.filterQuery(),
+
+		Signatures: *sigs,
+		Commits:    *commits,
+	}
+	if len(args) == 1 {
+		req.Pattern = args[0]
+	}
+	if err := g.checkFilter(match, req.Pattern); err != nil {
+		return err
+	}
+	if *prs {
+		if req.PullRequests, err = github.PullRequests(context.Background(), g.repo); err != nil {
+			return err
+		}
+	}
+	var resp core.ListBranchesResponse
+	if *page > 0 {
+		req.Page, req.PageSize = *page, *pageSize
+		if resp, err = core.ListBranches(req); err != nil {
+			return err
+		}
+	} else {
+		all, err := listAll(req)
+		if err != nil {
+			return err
+		}
+		// A single page holding everything.
+		resp = core.ListBranchesResponse{
+			Items:    all,
+			Page:     1,
+			PageSize: max(len(all), 1),
+			Total:    len(all),
+		}
+	}
+	if *withCI {
+		if err := addCIStatuses(g, resp.Items); err != nil {
+			return err
+		}
+	}
+	if *asJSON || rowTmpl != nil {
+		if err := addColumns(g, resp.Items); err != nil {
+			return err
+		}
+	}
+	// An empty listing is only a failure when the user asked for something.
+	var noMatch error
+	if filter := strings.TrimSpace(req.Pattern + " " + req.Query); filter != "" && resp.Total == 0 {
+		noMatch = fmt.Errorf("%w %q", errNoMatch, filter)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		return noMatch
+	}
+	start := (resp.Page - 1) * resp.PageSize
+	for i, b := range resp.Items {
+		if rowTmpl == nil {
+			fmt.Println(b.Name)
+			continue
+		}
+		line, err := tmpl.Execute(rowTmpl, tmpl.NewRow(b, start+i+1))
+		if err != nil {
+			return err
+		}
+		fmt.Println(line)
+	}
+	return noMatch
+}
+
+// unescape expands \t, \n and \\ so formats can be written inside single
+// quotes on the command line.
+func unescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n").Replace(s)
+}
+
+// addCIStatuses looks up and sets the CI status of branches.
+func addCIStatuses(g *globals, branches []core.Branch) error {
+	var shas []string
+	for _, b := range branches {
+		if b.HeadCommitSHA != nil {
+			shas = append(shas, *b.HeadCommitSHA)
+		}
+	}
+	statuses, err := ci.Statuses(context.Background(), g.repo, shas)
+	if err != nil {
+		return err
+	}
+	for i, b := range branches {
+		if b.HeadCommitSHA != nil {
+			branches[i].CIStatus = statuses[*b.HeadCommitSHA]
+		}
+	}
+	return nil
+}
+
+// listAll collects every page of a listing.
+func listAll(req core.ListBranchesRequest) ([]core.Branch, error) {
+	req.Page = 1
+	if req.PageSize <= 0 {
+		req.PageSize = 200
+	}
+	var all []core.Branch
+	for {
+		resp, err := core.ListBranches(req)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Items...)
+		if !resp.HasNext {
+			return all, nil
+		}
+		req.Page++
+	}
+}
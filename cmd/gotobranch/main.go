@@ -3,44 +3,637 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
-
-	"gotobranch/internal/core"
-	"gotobranch/internal/tui"
+	"github.com/kvnloughead/gotobranch/internal/config"
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/hooks"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/logging"
+	"github.com/kvnloughead/gotobranch/internal/metrics"
+	"github.com/kvnloughead/gotobranch/internal/plugin"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+	"github.com/kvnloughead/gotobranch/internal/tui"
 )
 
-func main() {
-	repo := flag.String("repo", "", "Path to git repository (defaults to CWD)")
-	scopeFlag := flag.String("scope", "local", "Branch scope: local|remote|all")
-	pageSize := flag.Int("page-size", 50, "Page size for pagination")
-	flag.Parse()
+// globals are the flags shared by every command. They may be given before
+// the command name or among the command's own flags.
+// Their defaults come from the config file and environment (see package
+// config), so explicit flags take precedence over both.
+type globals struct {
+	repo    string
+	profile string
+	view
+	unprofiled view // view before applying the profile
+
+	cfg config.Config
+
+	hooks *hooks.Runner // nil without configured hooks
+
+	columns []*tmpl.Column   // the config's computed columns, once parsed
+	plugins []*plugin.Plugin // loaded by the picker
+
+	metrics *metrics.Recorder // nil unless metrics are enabled
+	command string            // the command run, for metrics, the audit log and the log
+	log     *slog.Logger      // logging.Discard unless the log setting is set
+}
+
+// view holds the settings a profile can change.
+type view struct {
+	scope   string
+	sort    string
+	match   string
+	query   string
+	author  string
+	since   string
+	until   string
+	exclude stringsFlag
+}
+
+func newGlobals(cfg config.Config) *globals {
+	g := &globals{repo: cfg.Repo, view: view{scope: cfg.Scope, sort: cfg.Sort, match: cfg.Match, exclude: cfg.Exclude}, cfg: cfg, log: logging.Discard}
+	if g.scope == "" {
+		g.scope = "local"
+	}
+	if g.sort == "" {
+		g.sort = "frecency"
+	}
+	if g.match == "" {
+		g.match = "contains"
+	}
+	return g
+}
+
+// withProfile returns v with the settings of profile p applied.
+func (v view) withProfile(p config.Profile) view {
+	if p.Query != "" {
+		v.query = p.Query
+	}
+	if p.Author != "" {
+		v.author = p.Author
+	}
+	if p.Since != "" {
+		v.since = p.Since
+	}
+	if p.Until != "" {
+		v.until = p.Until
+	}
+	if p.Scope != "" {
+		v.scope = p.Scope
+	}
+	if p.Sort != "" {
+		v.sort = p.Sort
+	}
+	if p.Match != "" {
+		v.match = p.Match
+	}
+	if p.Exclude != nil {
+		v.exclude = p.Exclude
+	}
+	return v
+}
+
+// selectProfile loads the profiles shared in the repository and applies the
+// one named by --profile, before flags are registered so that they become
+// the flag defaults and explicit flags still win. Both flags are looked up
+// ahead of parsing for that reason.
+func (g *globals) selectProfile(args []string) error {
+	repo := g.repo
+	if r := flagValue(args, "repo"); r != "" {
+		repo = r
+	}
+	if repo == "" {
+		repo = "."
+	}
+	shared, err := config.LoadRepo(repo)
+	if err != nil {
+		return usageError{err}
+	}
+	if err := shared.Validate(); err != nil {
+		return usageErrorf("%s: %w", config.RepoFile, err)
+	}
+	g.cfg.MergeProfiles(shared)
+
+	g.profile = g.cfg.DefaultProfile
+	if name := flagValue(args, "profile"); name != "" {
+		g.profile = name
+	}
+	g.unprofiled = g.view
+	if g.profile == "" {
+		return nil
+	}
+	p, ok := g.cfg.Profiles[g.profile]
+	if !ok {
+		return usageErrorf("unknown profile %q; available: %s", g.profile, strings.Join(g.profileNames(), ", "))
+	}
+	g.view = g.view.withProfile(p)
+	return nil
+}
+
+// tuiProfiles returns the views the picker cycles through: no profile, then
+// every profile in name order, with the active one reflecting explicit
+// flags too. The second result is the index of the active view.
+func (g *globals) tuiProfiles() ([]tui.Profile, int, error) {
+	names := append([]string{""}, g.profileNames()...)
+	res := make([]tui.Profile, 0, len(names))
+	active := 0
+	for i, name := range names {
+		v := g.unprofiled.withProfile(g.cfg.Profiles[name])
+		if name == g.profile {
+			v, active = g.view, i
+		}
+		p, err := v.tuiProfile(name)
+		if err != nil {
+			if name != "" {
+				err = i18n.Errorf("profile %s: %w", name, err)
+			}
+			return nil, 0, err
+		}
+		res = append(res, p)
+	}
+	return res, active, nil
+}
+
+func (v view) tuiProfile(name string) (tui.Profile, error) {
+	scope, err := v.parseScope()
+	if err != nil {
+		return tui.Profile{}, err
+	}
+	sortBy, sortDir, err := v.parseSort()
+	if err != nil {
+		return tui.Profile{}, err
+	}
+	match, err := v.parseMatch()
+	if err != nil {
+		return tui.Profile{}, err
+	}
+	return tui.Profile{
+		Name:    name,
+		Query:   v.filterQuery(),
+		Scope:   scope,
+		SortBy:  sortBy,
+		SortDir: sortDir,
+		Match:   match,
+		Exclude: v.exclude,
+	}, nil
+}
+
+// profileNames returns the configured profile names in order.
+func (g *globals) profileNames() []string {
+	names := make([]string, 0, len(g.cfg.Profiles))
+	for name := range g.cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flagValue returns the value of the last --name flag in args, without
+// parsing them; "" if there is none.
+func flagValue(args []string, name string) string {
+	var v string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+		trimmed := strings.TrimLeft(a, "-")
+		if len(a)-len(trimmed) != 1 && len(a)-len(trimmed) != 2 {
+			continue
+		}
+		if k, val, ok := strings.Cut(trimmed, "="); ok && k == name {
+			v = val
+		} else if trimmed == name && i+1 < len(args) {
+			v = args[i+1]
+			i++
+		}
+	}
+	return v
+}
+
+func (g *globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.repo, "repo", g.repo, "Path to git repository (defaults to CWD)")
+	fs.StringVar(&g.profile, "profile", g.profile, "Apply a profile from the config (query, scope, sort, match, exclude)")
+	fs.StringVar(&g.scope, "scope", g.scope, "Branch scope: local|remote|all")
+	fs.StringVar(&g.sort, "sort", g.sort, "Sort by frecency|name|recency|commits, optionally with :asc or :desc")
+	fs.StringVar(&g.match, "match", g.match, "How the pattern matches: contains|glob|regex|fuzzy")
+	fs.StringVar(&g.query, "query", g.query, "Filter query, e.g. 'author:alice before:2024-01-01 merged:false feat'")
+	fs.StringVar(&g.author, "author", g.author, "Only branches whose head commit author name or email contains this (\"me\" for your user.email)")
+	fs.StringVar(&g.since, "since", g.since, "Only branches whose head commit is no older than this date or age (2024-01-31, 2w, 6mo)")
+	fs.StringVar(&g.until, "until", g.until, "Only branches whose head commit is no newer than this date or age")
+	fs.StringVar(&g.cfg.Theme, "theme", g.cfg.Theme, "Color theme: default|mono|deuteranopia|high-contrast")
+	fs.Var(&g.exclude, "exclude", "Hide branches matching this glob (repeatable; adds to the config's list, an empty value clears it)")
+	fs.Var(verboseFlag{}, "verbose", "Log every git command, its duration and exit status to stderr")
+	fs.Var(verboseFlag{}, "v", "Shorthand for --verbose")
+}
+
+// stringsFlag is a repeatable string flag. An empty value resets the list,
+// which lets the command line drop entries inherited from the config.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	if v == "" {
+		*s = nil
+	} else {
+		*s = append(*s, v)
+	}
+	return nil
+}
+
+// verboseFlag turns on git tracing as soon as it is parsed, wherever it
+// appears on the command line.
+type verboseFlag struct{}
+
+func (verboseFlag) String() string   { return "" }
+func (verboseFlag) IsBoolFlag() bool { return true }
+
+func (verboseFlag) Set(s string) error {
+	on, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if on {
+		core.SetTrace(os.Stderr)
+	} else {
+		core.SetTrace(nil)
+	}
+	return nil
+}
+
+// setupLog opens the log file when the log setting asks for it, and has
+// core log there too; the returned function closes it.
+func setupLog(g *globals) (func() error, error) {
+	if g.cfg.Log == "" {
+		return func() error { return nil }, nil
+	}
+	level, _ := logging.ParseLevel(g.cfg.Log) // validated by Resolve
+	l, closeLog, err := logging.Open(level)
+	if err != nil {
+		return nil, err
+	}
+	g.log = l
+	core.SetLogger(l)
+	return closeLog, nil
+}
+
+// setupTrace enables tracing from the config/environment value: "stderr" or
+// a boolean for standard error, otherwise a log file to append to.
+func setupTrace(target string) error {
+	if target == "" {
+		return nil
+	}
+	if target == "stderr" {
+		core.SetTrace(os.Stderr)
+		return nil
+	}
+	if on, err := strconv.ParseBool(target); err == nil {
+		if on {
+			core.SetTrace(os.Stderr)
+		}
+		return nil
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	core.SetTrace(f)
+	return nil
+}
+
+func (v view) parseMatch() (core.MatchMode, error) {
+	m, err := core.ParseMatchMode(v.match)
+	if err != nil {
+		return 0, usageError{err}
+	}
+	return m, nil
+}
+
+func (v view) parseSort() (by, dir string, err error) {
+	if by, dir, err = config.ParseSort(v.sort); err != nil {
+		return "", "", usageError{err}
+	}
+	return by, dir, nil
+}
 
-	var scope core.Scope
-	switch *scopeFlag {
+// filterQuery is the query combining --query with the other filter flags.
+func (v view) filterQuery() string {
+	terms := []string{v.query}
+	for _, t := range []struct{ key, value string }{
+		{"author", v.author},
+		{"since", v.since},
+		{"until", v.until},
+	} {
+		if t.value == "" {
+			continue
+		}
+		value := t.value
+		if strings.ContainsAny(value, " \t") {
+			value = `"` + value + `"`
+		}
+		terms = append(terms, t.key+":"+value)
+	}
+	return strings.TrimSpace(strings.Join(terms, " "))
+}
+
+// checkFilter validates pattern and the --query for match, reporting
+// mistakes as usage errors.
+func (g *globals) checkFilter(match core.MatchMode, pattern string) error {
+	if _, err := core.NewMatcher(match, pattern); err != nil {
+		return usageError{err}
+	}
+	if _, err := core.CompileQuery(g.repo, g.filterQuery(), match); err != nil {
+		return usageError{err}
+	}
+	return nil
+}
+
+func (v view) parseScope() (core.Scope, error) {
+	switch v.scope {
 	case "local":
-		scope = core.ScopeLocal
+		return core.ScopeLocal, nil
 	case "remote":
-		scope = core.ScopeRemote
+		return core.ScopeRemote, nil
 	case "all":
-		scope = core.ScopeAll
+		return core.ScopeAll, nil
 	default:
-		fmt.Println("invalid --scope; use local|remote|all")
-		return
+		return 0, usageErrorf("invalid --scope; use local|remote|all")
+	}
+}
+
+// command is a gotobranch subcommand. run receives the arguments following
+// the command name and is responsible for parsing its own flags.
+type command struct {
+	name  string
+	args  string // synopsis of positional arguments
+	short string
+	run   func(g *globals, args []string) error
+}
+
+var commands []command
+
+func init() {
+	// Registered here rather than in a composite literal so that the help
+	// command can refer to the table without an initialization cycle.
+	commands = []command{
+		{"list", "[pattern]", "Print branches matching pattern", runList},
+		{"switch", "[--detach] <name>", "Switch to a branch, or check out a ref with a detached HEAD", runSwitch},
+		{"create", "[name]", "Create a branch (named by a branch template without name) and switch to it", runCreate},
+		{"issue", "<n>", "Create a branch for a GitHub issue and switch to it", runIssue},
+		{"delete", "<name>...", "Delete local branches", runDelete},
+		{"rename", "[old] <new> | --from <pattern> --to <pattern>", "Rename a local branch (default: the current one), or all matching a pattern", runRename},
+		{"undo", "", "Undo the last switch, delete or rename, one step further back each time", runUndo},
+		{"history", "[branch]", "Show the branch operations made with gotobranch, newest first", runHistory},
+		{"cherry-pick", "<branch>", "Apply the head commit(s) of a branch onto the current one", runCherryPick},
+		{"prune", "", "Pick merged, gone or stale branches to delete", runPrune},
+		{"sync", "", "Fetch, fast-forward the default branch and report newly prunable branches", runSync},
+		{"push", "[branch]", "Push a branch (default: the current one), setting its upstream if it has none", runPush},
+		{"track", "[branch]", "Track a remote branch (creating the local one), or stop tracking with --unset", runTrack},
+		{"reset", "[branch]", "Hard-reset a local branch (default: the current one) to its upstream", runReset},
+		{"fetch", "[remote]", "Fetch remotes and prune deleted remote branches", runFetch},
+		{"stats", "", "Summarize branches by prefix, age, author and merge status", runStats},
+		{"export", "[pattern]", "Write branches with all their metadata to a CSV or JSON file", runExport},
+		{"recent", "[n]", "Print or switch to recently checked out branches", runRecent},
+		{"stash", "[list|apply|pop|drop] [n]", "List stashes with the branch they were made on, or apply, pop or drop one", runStash},
+		{"prompt", "", "Print a one-line status (branch, ahead/behind, dirty) for shell prompts", runPrompt},
+		{"tmux", "[pattern]", "Open the picker in a tmux popup", runTmux},
+		{"fzf", "[pattern]", "Print branches as tab-separated lines for fzf (--pipeline shows how)", runFzf},
+		{"preview", "<ref>", "Print the log and diffstat of a ref, e.g. for an fzf preview window", runPreview},
+		{"serve", "", "Serve the branch API over HTTP on localhost for editors and dashboards", runServe},
+		{"mcp", "", "Serve branch tools to AI coding assistants over the Model Context Protocol (stdio)", runMCP},
+		{"init", "<shell>", "Print a shell wrapper function (bash, zsh, fish)", runInit},
+		{"install", "", "Set up the git goto alias and shell wrapper (--uninstall removes them)", runInstall},
+		{"config", "[show|validate|edit] [file]", "Show the settings in effect and where they come from, validate the config files or edit yours", runConfig},
+		{"self-update", "", "Replace this binary with the latest release", runSelfUpdate},
+		{"version", "", "Print version and build information", runVersion},
+		{"help", "", "Show this help", runHelp},
+	}
+}
+
+func lookup(name string) (command, bool) {
+	for _, c := range append(commands, hiddenCommands...) {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func main() {
+	cfg, err := config.Resolve()
+	i18n.SetLocale(i18n.Detect(cfg.Locale))
+	if err != nil {
+		if !configCommand(os.Args[1:]) {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("error: config: %v", err))
+			os.Exit(exitUsage)
+		}
+		cfg = config.Config{}
+	}
+	if cfg.GitBin != "" {
+		core.GitBin = cfg.GitBin
+	}
+	if cfg.LockWait != "" {
+		core.LockWait, _ = time.ParseDuration(cfg.LockWait) // validated by Resolve
+	}
+	if cfg.NamePolicy != "" {
+		core.NamePolicy = regexp.MustCompile(cfg.NamePolicy) // validated by Resolve
+	}
+	core.SquashMerges = !cfg.NoSquashMerges
+	if cfg.LocalTimeout != "" {
+		core.LocalTimeout, _ = time.ParseDuration(cfg.LocalTimeout)
+	}
+	if cfg.NetworkTimeout != "" {
+		core.NetworkTimeout, _ = time.ParseDuration(cfg.NetworkTimeout)
 	}
-	var pattern string
-	if flag.NArg() > 0 {
-		pattern = flag.Arg(0)
+	if err := setupTrace(cfg.Trace); err != nil {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("error: trace: %v", err))
+		os.Exit(exitError)
 	}
+	g := newGlobals(cfg)
+	closeLog, err := setupLog(g)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("error: log: %v", err))
+		os.Exit(exitError)
+	}
+	if hooks.Enabled(cfg.Hooks) {
+		g.hooks = hooks.New(cfg.Hooks, os.Stderr)
+		core.SetHooks(g.hooks.Run)
+	}
+	core.SetJournal(journalAction)
+	core.SetAudit(auditor(g))
+	core.SetFrecency(frecencyScores)
+	if cfg.Metrics {
+		g.metrics = metrics.New()
+		core.SetGitTimer(g.metrics.Git)
+		for _, f := range flagNames(os.Args[1:]) {
+			g.metrics.Use(f)
+		}
+	}
+	start := time.Now()
+	err = run(g, os.Args[1:])
+	code := exitCode(err)
+	logRun(g, code, err, time.Since(start))
+	if g.metrics != nil {
+		core.SetGitTimer(nil) // metricsRepo's git is not the command's
+		// Failing to record metrics is not worth bothering anyone with.
+		_ = g.metrics.Finish(g.command, metricsRepo(g), code)
+	}
+	closeLog()
+	if code != exitOK {
+		if code != exitCancelled {
+			reportError(g, err)
+		}
+		os.Exit(code)
+	}
+}
+
+// run dispatches to a subcommand, or to the interactive UI when the first
+// positional argument is not a command name (it is then the filter pattern).
+// Use "--" to filter by a pattern that collides with a command name.
+func run(g *globals, args []string) error {
+	if err := g.selectProfile(args); err != nil && !configCommand(args) {
+		return err
+	}
+	fs := newFlagSet("gotobranch", g)
+	tf := registerTUIFlags(fs)
+	fs.Usage = usage
+	// Stop at the first positional so command flags are left for the command.
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	rest := fs.Args()
+	if dashDashBefore(args, rest) {
+		g.command = "picker"
+		logStart(g)
+		return runTUI(g, tf, fs, append([]string{"--"}, rest...))
+	}
+	if len(rest) > 0 {
+		if c, ok := lookup(rest[0]); ok {
+			g.command = c.name
+			logStart(g)
+			return c.run(g, rest[1:])
+		}
+	}
+	g.command = "picker"
+	logStart(g)
+	return runTUI(g, tf, fs, rest)
+}
+
+// flagNames returns the names of the flags in args, such as --ci, for
+// metrics and the log; their values, which may be secrets, are left out.
+func flagNames(args []string) []string {
+	var res []string
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if name, ok := strings.CutPrefix(a, "-"); ok && name != "" {
+			name, _, _ = strings.Cut(strings.TrimPrefix(name, "-"), "=")
+			res = append(res, "--"+name)
+		}
+	}
+	return res
+}
+
+// metricsRepo returns the repository a run worked in, for metrics, or ""
+// outside one.
+func metricsRepo(g *globals) string {
+	top, err := core.TopLevel(g.repo)
+	if err != nil {
+		return ""
+	}
+	return top
+}
+
+// dashDashBefore reports whether flag parsing of args stopped at an explicit
+// "--" preceding rest.
+func dashDashBefore(args, rest []string) bool {
+	i := len(args) - len(rest) - 1
+	return i >= 0 && args[i] == "--"
+}
+
+func newFlagSet(name string, g *globals) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	g.register(fs)
+	return fs
+}
 
-	m := tui.New(tui.Options{
-		RepoPath: *repo,
-		Scope:    scope,
-		PageSize: *pageSize,
-		Pattern:  pattern,
-	})
+// parseArgs parses flags that may appear before, after, or between
+// positional arguments. Everything after "--" is positional.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	if describing != nil {
+		describing(fs)
+		return nil, flag.ErrHelp
+	}
+	var tail []string
+	for i, a := range args {
+		if a == "--" {
+			args, tail = args[:i], args[i+1:]
+			break
+		}
+	}
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, flagError(err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+	return append(pos, tail...), nil
+}
+
+// commandUsage sets fs.Usage to print the synopsis of c and its flags.
+func commandUsage(fs *flag.FlagSet, c string) {
+	cmd, _ := lookup(c)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "%s gotobranch %s [flags] %s\n\n%s.\n\n%s\n", i18n.T("usage:"), cmd.name, cmd.args, i18n.T(cmd.short), i18n.T("Flags:"))
+		printDefaults(fs)
+	}
+}
+
+func usage() {
+	var b strings.Builder
+	prefix := i18n.T("usage:")
+	fmt.Fprintf(&b, "%s gotobranch [flags] [pattern]\n", prefix)
+	fmt.Fprintf(&b, "%*s gotobranch <command> [flags] [args]\n\n", len([]rune(prefix)), "")
+	fmt.Fprintf(&b, "%s\n\n%s\n", i18n.T("Without a command, opens the interactive branch picker."), i18n.T("Commands:"))
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-8s %-12s %s\n", c.name, c.args, i18n.T(c.short))
+	}
+	fmt.Fprintf(&b, "\n%s\n", i18n.T("Flags:"))
+	fmt.Fprint(os.Stderr, b.String())
+	fs := newFlagSet("gotobranch", newGlobals(config.Config{}))
+	registerTUIFlags(fs)
+	fs.SetOutput(os.Stderr)
+	printDefaults(fs)
+	fmt.Fprintf(os.Stderr, "\n%s\n", i18n.T("Run 'gotobranch <command> -h' for command flags."))
+}
+
+// printDefaults is fs.PrintDefaults with the flags' usage translated.
+func printDefaults(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) { f.Usage = i18n.T(f.Usage) })
+	fs.PrintDefaults()
+}
 
-	if _, err := tea.NewProgram(m).Run(); err != nil {
-		fmt.Printf("error: %v\n", err)
+func runHelp(g *globals, args []string) error {
+	if len(args) > 0 {
+		if c, ok := lookup(args[0]); ok {
+			return c.run(g, []string{"-h"})
+		}
 	}
+	usage()
+	return nil
 }
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+func runDelete(g *globals, args []string) error {
+	fs := newFlagSet("delete", g)
+	force := fs.Bool("force", false, "Delete even if not fully merged")
+	fs.BoolVar(force, "f", false, "Shorthand for --force")
+	commandUsage(fs, "delete")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageErrorf("expected at least one branch name")
+	}
+	var failed, hookFailed int
+	for _, name := range args {
+		sha, err := core.DeleteBranch(g.repo, name, *force)
+		if err != nil && !core.PostHookFailed(err) {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("error: %s: %v", name, err))
+			failed++
+			continue
+		}
+		fmt.Println(i18n.Sprintf("Deleted branch %s (was %s)", name, shortSHA(sha)))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("error: %s: %v", name, err))
+			hookFailed++
+		}
+	}
+	if failed > 0 {
+		return i18n.Errorf("%d of %d branches not deleted", failed, len(args))
+	}
+	if hookFailed > 0 {
+		return i18n.Errorf("post-delete hook failed for %d of %d branches", hookFailed, len(args))
+	}
+	return nil
+}
+
+func runRename(g *globals, args []string) error {
+	fs := newFlagSet("rename", g)
+	from := fs.String("from", "", "Rename every local branch matching this pattern, e.g. 'old/*' (with --to)")
+	to := fs.String("to", "", "New names for the branches matching --from, with * replaced by what it matched, e.g. 'new/*'")
+	dryRun := fs.Bool("dry-run", false, "With --from, only print the renames")
+	upstream := fs.Bool("upstream", false, "With --from, push renamed branches that have an upstream under their new names and track those")
+	commandUsage(fs, "rename")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *from != "" || *to != "" {
+		if *from == "" || *to == "" {
+			return usageErrorf("--from and --to go together")
+		}
+		if len(args) > 0 {
+			return usageErrorf("--from renames the branches matching it; no branch names expected")
+		}
+		return renameMatching(g, *from, *to, *dryRun, *upstream)
+	}
+	var oldName, newName string
+	switch len(args) {
+	case 1:
+		newName = args[0]
+	case 2:
+		oldName, newName = args[0], args[1]
+	default:
+		return usageErrorf("expected [old] <new>")
+	}
+	if err := core.RenameBranch(g.repo, oldName, newName); err != nil {
+		return err
+	}
+	if oldName == "" {
+		oldName = "current branch"
+	}
+	fmt.Println(i18n.Sprintf("Renamed %s to %s", oldName, newName))
+	return nil
+}
+
+// renameMatching renames the local branches matching the pattern from after
+// the pattern to (see core.PlanRenames), all of them or none. With upstream,
+// branches whose upstream is on a remote are then pushed under their new
+// names, which become their upstreams; the old remote branches are left
+// for the user to delete. A failed push leaves the branches renamed.
+func renameMatching(g *globals, from, to string, dryRun, upstream bool) error {
+	plan, err := core.PlanRenames(g.repo, from, to)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		for _, r := range plan {
+			fmt.Printf("  %s -> %s\n", r.Old, r.New)
+		}
+		return nil
+	}
+	if err := core.RenameAll(g.repo, plan); err != nil {
+		return err
+	}
+	for _, r := range plan {
+		fmt.Println(i18n.Sprintf("Renamed %s to %s", r.Old, r.New))
+	}
+	if !upstream {
+		return nil
+	}
+	var pushes, failed int
+	var stale []string
+	for _, r := range plan {
+		if r.Remote == "" {
+			continue
+		}
+		pushes++
+		if err := core.Push(g.repo, r.New, true, false); err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("error: pushing %s: %v", r.New, err))
+			failed++
+			continue
+		}
+		fmt.Println(i18n.Sprintf("Pushed %s to %s and set its upstream", r.New, r.Remote))
+		stale = append(stale, fmt.Sprintf("  git push %s --delete %s", r.Remote, r.RemoteBranch))
+	}
+	if len(stale) > 0 {
+		fmt.Println("\n" + i18n.T("The old remote branches are still there; to delete them:"))
+		fmt.Println(strings.Join(stale, "\n"))
+	}
+	if failed > 0 {
+		return i18n.Errorf("all branches renamed, but %d of %d not pushed", failed, pushes)
+	}
+	return nil
+}
+
+func runPrune(g *globals, args []string) error {
+	fs := newFlagSet("prune", g)
+	base := fs.String("base", "", "Branch merged candidates are compared against (default: current branch)")
+	staleDays := fs.Int("stale", 0, "Also offer branches without commits for this many `days` (0 disables)")
+	dryRun := fs.Bool("dry-run", false, "Only print what would be deleted")
+	yes := fs.Bool("yes", false, "Do not ask for confirmation")
+	force := fs.Bool("force", false, "Also delete gone and stale branches that are not fully merged")
+	noTUI := fs.Bool("no-tui", false, "Ask for confirmation on the command line instead of opening the selection UI")
+	commandUsage(fs, "prune")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("prune takes no arguments")
+	}
+	if *staleDays < 0 {
+		return usageErrorf("--stale must not be negative")
+	}
+	stale := time.Duration(*staleDays) * 24 * time.Hour
+	candidates, err := core.PruneCandidates(g.repo, *base, stale)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		fmt.Println(i18n.T("Nothing to prune."))
+		return nil
+	}
+
+	if !*dryRun && !*yes && !*noTUI && canPruneInteractively(g) {
+		return pruneInteractively(g, candidates)
+	}
+
+	for _, c := range candidates {
+		fmt.Printf("  %-7s %s\n", c.Reason, c.Branch.Name)
+	}
+	if *dryRun {
+		return nil
+	}
+	if !*yes {
+		fmt.Print(i18n.Sprintf("Delete %d branches? [y/N] ", len(candidates)))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println(i18n.T("Aborted."))
+			return errCancelled
+		}
+	}
+	results := make([]tui.PruneResult, 0, len(candidates))
+	for _, c := range candidates {
+		sha, err := core.DeleteBranch(g.repo, c.Branch.Name, *force || c.Reason == core.PruneSquashed)
+		results = append(results, tui.PruneResult{Name: c.Branch.Name, Reason: c.Reason, SHA: sha, Err: err})
+	}
+	if err := printPruned(results); err != nil {
+		return i18n.Errorf("%w (use --force for unmerged gone or stale branches)", err)
+	}
+	return nil
+}
+
+// canPruneInteractively reports whether the prune UI can be shown.
+func canPruneInteractively(g *globals) bool {
+	return !g.cfg.NoTUI && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// pruneInteractively lets the user pick which candidates to delete in the
+// prune UI and reports the outcome.
+func pruneInteractively(g *globals, candidates []core.PruneCandidate) error {
+	p := tea.NewProgram(tui.NewPrune(g.repo, candidates, g.cfg.Theme), tea.WithAltScreen())
+	restore := g.captureHooks(p)
+	final, err := p.Run()
+	restore()
+	if err != nil {
+		return err
+	}
+	results := final.(tui.PruneModel).Results()
+	if results == nil {
+		fmt.Println(i18n.T("Aborted."))
+		return errCancelled
+	}
+	return printPruned(results)
+}
+
+// printPruned reports deleted branches along with the command that restores
+// each of them, followed by any failures.
+func printPruned(results []tui.PruneResult) error {
+	var failed, hookFailed int
+	for _, r := range results {
+		if !r.Deleted() {
+			continue
+		}
+		fmt.Println(i18n.Sprintf("Deleted branch %s (was %s)", r.Name, shortSHA(r.SHA)))
+	}
+	var restore []string
+	for _, r := range results {
+		if r.Deleted() {
+			restore = append(restore, fmt.Sprintf("  git branch %s %s", r.Name, r.SHA))
+		}
+	}
+	if len(restore) > 0 {
+		fmt.Println("\n" + i18n.T("To restore:"))
+		fmt.Println(strings.Join(restore, "\n"))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, i18n.Sprintf("error: %s: %v", r.Name, r.Err))
+			if r.Deleted() {
+				hookFailed++
+			} else {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return i18n.Errorf("%d of %d branches not deleted", failed, len(results))
+	}
+	if hookFailed > 0 {
+		return i18n.Errorf("post-delete hook failed for %d of %d branches", hookFailed, len(results))
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+
+	"github.com/kvnloughead/gotobranch/internal/mcp"
+)
+
+func runMCP(g *globals, args []string) error {
+	fs := newFlagSet("mcp", g)
+	commandUsage(fs, "mcp")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("unexpected arguments")
+	}
+	return mcp.Serve(os.Stdin, os.Stdout, mcp.Options{
+		RepoPath:  g.repo,
+		Exclude:   g.exclude,
+		Protected: g.cfg.Protected,
+		Version:   getBuildInfo().Version,
+		OnSwitch:  func() { recordSwitch(g) },
+	})
+}
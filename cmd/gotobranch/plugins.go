@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os/exec"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/plugin"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+// loadPlugins loads the plugins enabled in the config into g and sets the
+// picker actions and columns they add. Their column values depend on the
+// repository; see columnValues.
+func loadPlugins(g *globals, opts *tui.Options) error {
+	plugins, err := plugin.Load(g.cfg.Plugins)
+	if err != nil {
+		return err
+	}
+	g.plugins = plugins
+	for _, p := range plugins {
+		for _, a := range p.Actions {
+			opts.PluginActions = append(opts.PluginActions, tui.PluginAction{
+				Title: a.Title,
+				Key:   a.Key,
+				Command: func(repoPath string, b core.Branch) *exec.Cmd {
+					return p.ActionCommand(a.ID, repoPath, b)
+				},
+			})
+		}
+		for _, c := range p.Columns {
+			opts.Columns = append(opts.Columns, tui.Column{ID: p.ColumnID(c.ID), Title: c.Title, Width: c.Width})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/prompt"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// defaultPromptFormat renders e.g. "feat/login ↑1↓2 *?".
+const defaultPromptFormat = `{{if .Detached}}@{{.SHA}}{{else}}{{.Branch}}{{end}}` +
+	`{{if or .Ahead .Behind}} {{if .Ahead}}↑{{.Ahead}}{{end}}{{if .Behind}}↓{{.Behind}}{{end}}{{end}}` +
+	`{{if or .Dirty .Untracked}} {{if .Dirty}}*{{end}}{{if .Untracked}}?{{end}}{{end}}`
+
+// promptFields are the fields of the prompt template.
+type promptFields struct {
+	Branch    string // "" when detached
+	Detached  bool
+	SHA       string // HEAD, abbreviated; "" before the first commit
+	Upstream  string
+	Ahead     int
+	Behind    int
+	Dirty     bool // tracked files are modified or staged
+	Untracked bool
+}
+
+func runPrompt(g *globals, args []string) error {
+	fs := newFlagSet("prompt", g)
+	format := fs.String("format", defaultPromptFormat, "Go template for the line, with fields Branch, Detached, SHA, Upstream, Ahead, Behind, Dirty and Untracked")
+	ttl := fs.Duration("ttl", prompt.DefaultTTL, "Reuse a cached status this long while HEAD, the index and refs are unchanged")
+	commandUsage(fs, "prompt")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("prompt takes no arguments")
+	}
+	t, err := tmpl.Parse("prompt", *format)
+	if err != nil {
+		return usageErrorf("invalid --format: %w", err)
+	}
+	dir := g.repo
+	if dir == "" {
+		dir = "."
+	}
+	s, ok, err := prompt.Status(dir, *ttl)
+	if err != nil || !ok {
+		// Outside a repository the prompt shows nothing.
+		return err
+	}
+	f := promptFields{
+		Branch:    s.Branch,
+		Detached:  s.Branch == "",
+		SHA:       s.SHA,
+		Upstream:  s.Upstream,
+		Ahead:     s.Ahead,
+		Behind:    s.Behind,
+		Dirty:     s.Dirty,
+		Untracked: s.Untracked,
+	}
+	if len(f.SHA) > 7 {
+		f.SHA = f.SHA[:7]
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, f); err != nil {
+		return err
+	}
+	fmt.Println(b.String())
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runPush(g *globals, args []string) error {
+	fs := newFlagSet("push", g)
+	force := fs.Bool("force", false, "Overwrite the remote branch if it is where it was last fetched (--force-with-lease)")
+	fs.BoolVar(force, "f", false, "Shorthand for --force")
+	commandUsage(fs, "push")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	var name string
+	switch len(args) {
+	case 0:
+		cur, err := core.GetCurrentBranch(g.repo)
+		if errors.Is(err, core.ErrDetachedHead) {
+			return usageErrorf("HEAD is detached; name the branch to push")
+		}
+		if err != nil {
+			return err
+		}
+		name = cur.Name
+	case 1:
+		name = args[0]
+	default:
+		return usageErrorf("expected at most one branch name")
+	}
+	upstream := !core.HasUpstream(g.repo, name)
+	if err := core.Push(g.repo, name, upstream, *force); err != nil {
+		return err
+	}
+	remote, _ := core.PushRemote(g.repo, name)
+	if upstream {
+		fmt.Println(i18n.Sprintf("Pushed '%s' to %s and set it as its upstream", name, remote))
+	} else {
+		fmt.Println(i18n.Sprintf("Pushed '%s' to %s", name, remote))
+	}
+	return nil
+}
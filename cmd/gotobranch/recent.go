@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+func runRecent(g *globals, args []string) error {
+	fs := newFlagSet("recent", g)
+	to := fs.Int("switch", 0, "Switch to the `n`th most recent branch instead of listing")
+	commandUsage(fs, "recent")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	n := 10
+	switch len(args) {
+	case 0:
+	case 1:
+		if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 {
+			return usageErrorf("n must be a positive number")
+		}
+	default:
+		return usageErrorf("expected at most one count")
+	}
+	if *to < 0 {
+		return usageErrorf("--switch must be a positive number")
+	}
+	if *to > 0 {
+		n = *to
+	}
+	recent, err := core.RecentBranches(g.repo, n, recordedSwitches(g))
+	if err != nil {
+		return err
+	}
+
+	if *to > 0 {
+		if *to > len(recent) {
+			return usageErrorf("only %d recent branches", len(recent))
+		}
+		name := recent[*to-1].Name
+		prev, err := core.Checkout(g.repo, name, false)
+		if err != nil && !core.PostHookFailed(err) {
+			return err
+		}
+		recordSwitch(g)
+		printSwitched(name, prev)
+		offerStash(g, name)
+		return err
+	}
+
+	var cur string
+	if b, err := core.GetCurrentBranch(g.repo); err == nil {
+		cur = b.Name
+	}
+	width := 0
+	for _, r := range recent {
+		width = max(width, runewidth.StringWidth(r.Name))
+	}
+	now := time.Now()
+	for i, r := range recent {
+		mark := " "
+		if r.Name == cur {
+			mark = "*"
+		}
+		fmt.Printf("%2d %s %s  %s\n", i+1, mark, runewidth.FillRight(r.Name, width), tmpl.Age(r.At, now))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runReset(g *globals, args []string) error {
+	fs := newFlagSet("reset", g)
+	yes := fs.Bool("yes", false, "Do not ask for confirmation")
+	fs.BoolVar(yes, "y", false, "Shorthand for --yes")
+	commandUsage(fs, "reset")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	var name string
+	switch len(args) {
+	case 0:
+		cur, err := core.GetCurrentBranch(g.repo)
+		if errors.Is(err, core.ErrDetachedHead) {
+			return usageErrorf("HEAD is detached; name the branch to reset")
+		}
+		if err != nil {
+			return err
+		}
+		name = cur.Name
+	case 1:
+		name = args[0]
+	default:
+		return usageErrorf("expected at most one branch name")
+	}
+	plan, err := core.PlanUpstreamReset(g.repo, name)
+	if err != nil {
+		return err
+	}
+	if plan.From == plan.To {
+		fmt.Println(i18n.Sprintf("'%s' is already at '%s'", name, plan.Upstream))
+		return nil
+	}
+	if !*yes {
+		fmt.Print(i18n.Sprintf("Reset '%s' to '%s' (%s), dropping %d commit(s) only on it? [y/N] ", name, plan.Upstream, shortSHA(plan.To), plan.Dropped))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println(i18n.T("Aborted."))
+			return errCancelled
+		}
+	}
+	if err := core.ResetToUpstream(g.repo, plan); err != nil {
+		return err
+	}
+	fmt.Println(i18n.Sprintf("Reset '%s' to '%s' (was %s)", name, plan.Upstream, shortSHA(plan.From)))
+	return nil
+}
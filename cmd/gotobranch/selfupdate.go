@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/update"
+)
+
+func runSelfUpdate(g *globals, args []string) error {
+	fs := newFlagSet("self-update", g)
+	check := fs.Bool("check", false, "Only report whether an update is available")
+	force := fs.Bool("force", false, "Install the latest release even if it is not newer (e.g. over a development build)")
+	insecure := fs.Bool("insecure", false, "Install a release even if its signature cannot be verified, checking its checksums only")
+	commandUsage(fs, "self-update")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("self-update takes no arguments")
+	}
+	current := getBuildInfo().Version
+	ctx := context.Background()
+	rel, err := update.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if !update.Newer(rel.TagName, current) && !*force {
+		fmt.Println(i18n.Sprintf("gotobranch %s is up to date (latest release: %s)", current, rel.TagName))
+		return nil
+	}
+	asset := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	if *check {
+		if _, ok := rel.Asset(asset); !ok {
+			return i18n.Errorf("release %s has no binary for %s/%s", rel.TagName, runtime.GOOS, runtime.GOARCH)
+		}
+		fmt.Println(i18n.Sprintf("Would update gotobranch %s to %s (%s)", current, rel.TagName, asset))
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fmt.Println(i18n.Sprintf("Downloading %s %s...", rel.TagName, asset))
+	data, err := update.Download(ctx, rel, *insecure)
+	if errors.Is(err, update.ErrUnverified) {
+		return i18n.Errorf("%w; pass --insecure to install it checking its checksums only", err)
+	}
+	if err != nil {
+		return err
+	}
+	if err := update.Replace(exe, data); err != nil {
+		return i18n.Errorf("replacing %s: %w", exe, err)
+	}
+	fmt.Println(i18n.Sprintf("Updated gotobranch %s to %s", current, rel.TagName))
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/server"
+)
+
+func runServe(g *globals, args []string) error {
+	fs := newFlagSet("serve", g)
+	addr := fs.String("addr", "127.0.0.1:9999", "Loopback address to listen on")
+	token := fs.String("token", os.Getenv("GOTOBRANCH_TOKEN"), "Bearer token clients must send (default: $GOTOBRANCH_TOKEN, else a random one printed at startup)")
+	commandUsage(fs, "serve")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("unexpected arguments")
+	}
+	if err := server.CheckLoopback(*addr); err != nil {
+		return usageError{err}
+	}
+	generated := *token == ""
+	if generated {
+		if *token, err = server.NewToken(); err != nil {
+			return err
+		}
+	}
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{
+		Handler: server.Handler(server.Options{
+			RepoPath: g.repo,
+			Token:    *token,
+			Exclude:  g.exclude,
+			OnSwitch: func(repo string) { recordSwitch(&globals{repo: repo}) },
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Event streams never finish on their own; end them on shutdown.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	fmt.Fprintln(os.Stderr, i18n.Sprintf("Serving on http://%s", ln.Addr()))
+	if generated {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Token: %s", *token))
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// runGenOpenAPI writes the document served at /openapi.json, so that it
+// can be checked in for generating clients.
+func runGenOpenAPI(g *globals, args []string) error {
+	fs := newFlagSet("gen-openapi", g)
+	out := fs.String("out", "spec/openapi.json", "File to write, or - for stdout")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "usage: gotobranch gen-openapi [--out file]\n\nGenerate the OpenAPI document of the serve API.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("gen-openapi takes no arguments")
+	}
+	data, err := json.MarshalIndent(server.OpenAPI(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// cdFileEnv names the file the shell wrapper installed by "gotobranch init"
+// reads after each run. Commands that want the parent shell to change
+// directory (e.g. into a worktree) write the target path there, since a
+// child process cannot change its parent's directory.
+const cdFileEnv = "GOTOBRANCH_CD_FILE"
+
+// requestCD asks the wrapping shell function to cd into dir. Without the
+// wrapper, it tells the user how to get there instead.
+func requestCD(dir string) error {
+	if path := os.Getenv(cdFileEnv); path != "" {
+		return os.WriteFile(path, []byte(dir), 0o600)
+	}
+	fmt.Printf("cd %s\n", dir)
+	return nil
+}
+
+// shellInit holds wrapper functions keyed by shell. {{cmd}} is replaced with
+// the function name.
+var shellInit = map[string]string{
+	"bash": posixInit,
+	"zsh":  posixInit,
+	"fish": `function {{cmd}} --description 'Switch git branches'
+    set -l cd_file (mktemp)
+    env GOTOBRANCH_CD_FILE=$cd_file command gotobranch $argv
+    set -l code $status
+    set -l dir (cat $cd_file)
+    rm -f $cd_file
+    if test -n "$dir"
+        cd $dir
+    end
+    return $code
+end
+`,
+}
+
+const posixInit = `{{cmd}}() {
+    local cd_file code dir
+    cd_file="$(mktemp)" || return
+    GOTOBRANCH_CD_FILE="$cd_file" command gotobranch "$@"
+    code=$?
+    dir="$(cat "$cd_file")"
+    rm -f "$cd_file"
+    if [ -n "$dir" ]; then
+        cd -- "$dir" || return
+    fi
+    return $code
+}
+`
+
+func runInit(g *globals, args []string) error {
+	fs := newFlagSet("init", g)
+	name := fs.String("cmd", "gotobranch", "Name of the shell function to define")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `usage: gotobranch init [--cmd name] <bash|zsh|fish>
+
+Print a shell function wrapping gotobranch so that it can change the
+shell's directory. Add one of these to your shell's startup file:
+
+  eval "$(gotobranch init zsh)"          # ~/.zshrc
+  eval "$(gotobranch init bash)"         # ~/.bashrc
+  gotobranch init fish | source          # ~/.config/fish/config.fish
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		fs.Usage()
+		return usageErrorf("expected a shell: bash, zsh or fish")
+	}
+	script, ok := shellInit[args[0]]
+	if !ok {
+		return usageErrorf("unsupported shell %q; use bash, zsh or fish", args[0])
+	}
+	fmt.Print(strings.ReplaceAll(script, "{{cmd}}", *name))
+	return nil
+}
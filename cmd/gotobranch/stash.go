@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+func runStash(g *globals, args []string) error {
+	fs := newFlagSet("stash", g)
+	asJSON := fs.Bool("json", false, "Print the stashes as JSON")
+	commandUsage(fs, "stash")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	action := "list"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+	stashes, err := core.Stashes(g.repo)
+	if err != nil {
+		return err
+	}
+	if action == "list" {
+		if len(args) > 0 {
+			return usageErrorf("list takes no arguments")
+		}
+		return printStashes(stashes, *asJSON)
+	}
+	if *asJSON {
+		return usageErrorf("--json only applies to list")
+	}
+	n := 0
+	switch len(args) {
+	case 0:
+	case 1:
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 0 {
+			return usageErrorf("n must be a stash number, as in stash@{n}")
+		}
+	default:
+		return usageErrorf("expected at most one stash number")
+	}
+	if n >= len(stashes) {
+		return i18n.Errorf("no stash@{%d}", n)
+	}
+	s := stashes[n]
+	switch action {
+	case "apply", "pop":
+		if err := core.ApplyStash(g.repo, s, action == "pop"); err != nil {
+			return err
+		}
+		fmt.Println(i18n.Sprintf("Applied %s (%s)", s.Ref(), s.Message))
+	case "drop":
+		if err := core.DropStash(g.repo, s); err != nil {
+			return err
+		}
+		fmt.Println(i18n.Sprintf("Dropped %s (was %s)", s.Ref(), shortSHA(s.SHA)))
+	default:
+		return usageErrorf("unknown action %q; expected list, apply, pop or drop", action)
+	}
+	return nil
+}
+
+func printStashes(stashes []core.Stash, asJSON bool) error {
+	if asJSON {
+		if stashes == nil {
+			stashes = []core.Stash{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stashes)
+	}
+	now := time.Now()
+	for _, s := range stashes {
+		var age string
+		if s.At != nil {
+			age = tmpl.Age(*s.At, now)
+		}
+		branch := s.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		fmt.Printf("%-10s %-4s %s: %s\n", s.Ref(), age, branch, s.Message)
+	}
+	return nil
+}
+
+// offerStash follows a switch to branch: when the changes left on it were
+// stashed by the picker on the way out, it asks whether to restore them, or
+// just mentions them when there is no terminal to ask on.
+func offerStash(g *globals, branch string) {
+	s, ok := core.StashFor(g.repo, branch)
+	if !ok {
+		return
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		fmt.Fprintln(os.Stderr, i18n.Sprintf("Changes stashed when leaving %s are in %s; restore them with `gotobranch stash pop %d`.", branch, s.Ref(), s.Index))
+		return
+	}
+	fmt.Fprint(os.Stderr, i18n.Sprintf("Restore changes stashed when leaving %s (%s)? [y/N] ", branch, s.Ref()))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		return
+	}
+	if err := core.ApplyStash(g.repo, s, true); err != nil {
+		reportError(g, i18n.Errorf("restoring %s: %w", s.Ref(), err))
+		return
+	}
+	fmt.Fprintln(os.Stderr, i18n.Sprintf("Restored %s", s.Ref()))
+}
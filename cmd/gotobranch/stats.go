@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/metrics"
+	"github.com/kvnloughead/gotobranch/internal/state"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+func runStats(g *globals, args []string) error {
+	fs := newFlagSet("stats", g)
+	asJSON := fs.Bool("json", false, "Print the statistics as JSON")
+	base := fs.String("base", "", "Branch merged counts are measured against (default: the default branch)")
+	stalest := fs.Int("stalest", 10, "How many of the oldest branches (or with --self, slowest runs) to list")
+	self := fs.Bool("self", false, "Summarize gotobranch's own recorded metrics (see the metrics setting) instead")
+	commandUsage(fs, "stats")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("stats takes no arguments")
+	}
+	if *stalest <= 0 {
+		return usageErrorf("--stalest must be a positive number")
+	}
+	if *self {
+		return selfStats(g, *asJSON, *stalest)
+	}
+	scope, err := g.parseScope()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	s, err := core.BranchStats(core.StatsRequest{
+		RepoPath: g.repo,
+		Scope:    scope,
+		Exclude:  g.exclude,
+		Base:     *base,
+		Stalest:  *stalest,
+	}, now)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.Sprintf("Branches (%s):\t%d", g.scope, s.Total))
+	fmt.Fprintln(w, i18n.Sprintf("Merged into %s:\t%d", s.Base, s.Merged))
+	fmt.Fprintln(w, i18n.Sprintf("Unmerged:\t%d", s.Unmerged))
+	for _, section := range []struct {
+		title  string
+		counts []core.Count
+	}{
+		{i18n.T("By prefix"), s.Prefixes},
+		{i18n.T("By age"), s.Ages},
+		{i18n.T("By author"), s.Authors},
+	} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		for _, c := range section.counts {
+			fmt.Fprintf(w, "  %s\t%d\n", c.Key, c.Count)
+		}
+	}
+	if len(s.Stalest) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("Stalest:"))
+		for _, b := range s.Stalest {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", b.Name, tmpl.Age(*b.HeadCommitAt, now), b.HeadCommitAt.Format(time.DateOnly))
+		}
+	}
+	return w.Flush()
+}
+
+// selfStats prints the summary of the metrics recorded, listing the
+// slowest n runs.
+func selfStats(g *globals, asJSON bool, n int) error {
+	runs, err := state.Runs()
+	if err != nil {
+		return err
+	}
+	s := metrics.Summarize(runs, n)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	if s.Runs == 0 {
+		if !g.cfg.Metrics {
+			fmt.Println(i18n.T(`No metrics recorded; set "metrics": true in the config to record them.`))
+		} else {
+			fmt.Println(i18n.T("No metrics recorded yet."))
+		}
+		return nil
+	}
+	ms := func(d time.Duration) string { return d.Round(time.Millisecond).String() }
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.Sprintf("Runs since %s:\t%d", s.Since.Format(time.DateOnly), s.Runs))
+	fmt.Fprintf(w, "\n%s\n", i18n.T("Commands:\tRuns\tMedian\t90%\tMax"))
+	for _, c := range s.Commands {
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%s\t%s\n", c.Command, c.Runs, ms(c.Median), ms(c.P90), ms(c.Max))
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("Git commands:\tCalls\tTotal\tMean\tMax"))
+	for _, c := range s.Git {
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%s\t%s\n", c.Command, c.Calls, ms(c.Total), ms(c.Mean), ms(c.Max))
+	}
+	if len(s.Features) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("Features:\tRuns"))
+		for _, f := range s.Features {
+			fmt.Fprintf(w, "  %s\t%d\n", f.Feature, f.Runs)
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("Slowest runs:"))
+	for _, r := range s.Slowest {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", r.At.Format("2006-01-02 15:04"), r.Command, ms(r.Duration), r.Repo)
+	}
+	return w.Flush()
+}
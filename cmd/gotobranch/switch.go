@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runSwitch(g *globals, args []string) error {
+	fs := newFlagSet("switch", g)
+	detach := fs.Bool("detach", false, "Check out the branch, tag or commit with a detached HEAD instead of switching to a local branch")
+	fs.BoolVar(detach, "d", false, "Shorthand for --detach")
+	commandUsage(fs, "switch")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageErrorf("expected exactly one branch name")
+	}
+	if *detach {
+		prev, err := core.Detach(g.repo, args[0])
+		if err != nil && !core.PostHookFailed(err) {
+			return err
+		}
+		if prev != "" {
+			fmt.Println(i18n.Sprintf("Detached HEAD at '%s' (from '%s')", args[0], prev))
+		} else {
+			fmt.Println(i18n.Sprintf("Detached HEAD at '%s'", args[0]))
+		}
+		return err
+	}
+	prev, err := core.Checkout(g.repo, args[0], false)
+	if err != nil && !core.PostHookFailed(err) {
+		return err
+	}
+	recordSwitch(g)
+	printSwitched(args[0], prev)
+	offerStash(g, args[0])
+	return err
+}
+
+func runCreate(g *globals, args []string) error {
+	fs := newFlagSet("create", g)
+	from := fs.String("from", "", "Start the branch at this ref instead of HEAD")
+	template := fs.String("template", "", "Generate the name with this branch template from the config, asking for its variables")
+	fs.StringVar(template, "t", "", "Shorthand for --template")
+	var sets stringsFlag
+	fs.Var(&sets, "set", "Set a template variable instead of being asked, as name=value (repeatable)")
+	dryRun := fs.Bool("dry-run", false, "With a template, print the name without creating the branch")
+	commandUsage(fs, "create")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	useTemplate := *template != "" || len(sets) > 0 || (len(args) == 0 && len(g.cfg.BranchTemplates) > 0)
+	if !useTemplate {
+		if len(args) != 1 {
+			return usageErrorf("expected exactly one branch name")
+		}
+		return createOrSwitch(g, args[0], *from)
+	}
+	if len(args) > 0 {
+		return usageErrorf("a template generates the name; no branch name expected")
+	}
+	ts, err := branchTemplates(g)
+	if err != nil {
+		return err
+	}
+	t, err := findTemplate(ts, *template)
+	if err != nil {
+		return err
+	}
+	vals, err := parseSets(sets)
+	if err != nil {
+		return err
+	}
+	name, err := templateName(g, t, vals, os.Stdin, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Println(name)
+		return nil
+	}
+	return createOrSwitch(g, name, *from)
+}
+
+// createOrSwitch implements create-or-switch semantics: an existing branch
+// is simply switched to.
+func createOrSwitch(g *globals, name, from string) error {
+	prev, created, err := core.CreateOrSwitch(g.repo, name, from)
+	if err != nil && !core.PostHookFailed(err) {
+		return err
+	}
+	recordSwitch(g)
+	if created {
+		fmt.Println(i18n.Sprintf("Switched to a new branch '%s'", name))
+		return err
+	}
+	printSwitched(name, prev)
+	offerStash(g, name)
+	return err
+}
+
+func printSwitched(name, prev string) {
+	if prev != "" {
+		fmt.Println(i18n.Sprintf("Switched to '%s' (from '%s')", name, prev))
+	} else {
+		fmt.Println(i18n.Sprintf("Switched to '%s'", name))
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// runSync fetches all remotes, fast-forwards the default branch and reports
+// the branches that became merged or gone as a result, optionally opening
+// the prune UI for them.
+func runSync(g *globals, args []string) error {
+	fs := newFlagSet("sync", g)
+	prune := fs.Bool("prune", false, "Open the prune UI for the branches that became merged or gone")
+	commandUsage(fs, "sync")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("sync takes no arguments")
+	}
+	base, err := core.DefaultBranch(g.repo)
+	if err != nil {
+		return err
+	}
+	before, err := core.PruneCandidates(g.repo, base, 0)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(i18n.T("Fetching all remotes..."))
+	if err := core.Fetch(g.repo, "", true); err != nil {
+		return err
+	}
+	moved, err := core.FastForward(g.repo, base)
+	switch {
+	case err != nil:
+		// Still worth reporting what the fetch alone revealed.
+		fmt.Println(i18n.Sprintf("warning: not updating %s: %v", base, err))
+	case moved:
+		fmt.Println(i18n.Sprintf("Fast-forwarded %s", base))
+	default:
+		fmt.Println(i18n.Sprintf("%s is up to date", base))
+	}
+
+	after, err := core.PruneCandidates(g.repo, base, 0)
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, c := range before {
+		known[c.Branch.Name+"\x00"+string(c.Reason)] = true
+	}
+	var fresh []core.PruneCandidate
+	for _, c := range after {
+		if !known[c.Branch.Name+"\x00"+string(c.Reason)] {
+			fresh = append(fresh, c)
+		}
+	}
+	if len(fresh) == 0 {
+		fmt.Println(i18n.T("No branches became merged or gone."))
+		return nil
+	}
+	fmt.Println(i18n.Sprintf("%d branches became prunable:", len(fresh)))
+	for _, c := range fresh {
+		fmt.Printf("  %-7s %s\n", c.Reason, c.Branch.Name)
+	}
+	if !*prune {
+		return nil
+	}
+	if !canPruneInteractively(g) {
+		return i18n.Errorf("--prune needs a terminal; use `gotobranch prune --yes` instead")
+	}
+	return pruneInteractively(g, fresh)
+}
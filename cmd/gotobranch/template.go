@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+// branchTemplates compiles the configured branch templates, sorted by name.
+func branchTemplates(g *globals) ([]*tmpl.NameTemplate, error) {
+	names := make([]string, 0, len(g.cfg.BranchTemplates))
+	for name := range g.cfg.BranchTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]*tmpl.NameTemplate, 0, len(names))
+	for _, name := range names {
+		t, err := tmpl.ParseName(name, g.cfg.BranchTemplates[name])
+		if err != nil {
+			return nil, usageErrorf("invalid branchTemplates.%s in config: %w", name, err)
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// findTemplate returns the template called name, or the only one when name
+// is empty.
+func findTemplate(ts []*tmpl.NameTemplate, name string) (*tmpl.NameTemplate, error) {
+	if len(ts) == 0 {
+		return nil, usageErrorf("no branchTemplates in config")
+	}
+	if name == "" && len(ts) == 1 {
+		return ts[0], nil
+	}
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		if t.Name == name {
+			return t, nil
+		}
+		names[i] = t.Name
+	}
+	if name == "" {
+		return nil, usageErrorf("several branch templates; pick one of %s with --template", strings.Join(names, ", "))
+	}
+	return nil, usageErrorf("no branch template %q; available: %s", name, strings.Join(names, ", "))
+}
+
+// templateName fills in the variables of t missing from vals by asking on
+// out and reading lines from in, then renders and validates the name.
+func templateName(g *globals, t *tmpl.NameTemplate, vals map[string]string, in io.Reader, out io.Writer) (string, error) {
+	sc := bufio.NewScanner(in)
+	for _, v := range t.Vars {
+		if _, ok := vals[v]; ok {
+			continue
+		}
+		fmt.Fprintf(out, "%s: ", v)
+		if !sc.Scan() {
+			if err := sc.Err(); err != nil {
+				return "", err
+			}
+			return "", usageErrorf("no value for %s (pass --set %s=...)", v, v)
+		}
+		vals[v] = strings.TrimSpace(sc.Text())
+	}
+	name, err := t.Render(vals)
+	if err != nil {
+		return "", err
+	}
+	return name, core.ValidateName(g.repo, name)
+}
+
+// parseSets turns --set name=value flags into template variables.
+func parseSets(sets []string) (map[string]string, error) {
+	vals := map[string]string{}
+	for _, s := range sets {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok || k == "" {
+			return nil, usageErrorf("--set %q: expected name=value", s)
+		}
+		vals[k] = v
+	}
+	return vals, nil
+}
+
+// pickerTemplates backs the picker's template key.
+func pickerTemplates(g *globals) ([]tui.BranchTemplate, error) {
+	ts, err := branchTemplates(g)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]tui.BranchTemplate, len(ts))
+	for i, t := range ts {
+		res[i] = tui.BranchTemplate{
+			Name: t.Name,
+			Vars: t.Vars,
+			Render: func(vals map[string]string) (string, error) {
+				name, err := t.Render(vals)
+				if err != nil {
+					return "", err
+				}
+				return name, core.ValidateName(g.repo, name)
+			},
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// envInPopup marks the gotobranch started by popup, so that --popup in an
+// alias or shell binding does not open a popup within the popup.
+const envInPopup = "GOTOBRANCH_IN_POPUP"
+
+// runTmux opens the picker in a tmux popup. It accepts the same flags and
+// pattern as the picker itself.
+func runTmux(g *globals, args []string) error {
+	fs := newFlagSet("tmux", g)
+	registerTUIFlags(fs)
+	commandUsage(fs, "tmux")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if !inTmux() {
+		return i18n.Errorf("not running inside tmux")
+	}
+	// Run the same command line, minus the command name.
+	all := os.Args[1:]
+	i := len(all) - len(args) - 1
+	inner := append(append([]string(nil), all[:i]...), all[i+1:]...)
+	return popup(g, inner)
+}
+
+// inTmux reports whether we run inside a tmux client, outside of a popup
+// opened by popup.
+func inTmux() bool {
+	return os.Getenv("TMUX") != "" && os.Getenv(envInPopup) == ""
+}
+
+// popup runs gotobranch with args in a tmux popup in the repository's
+// directory. The popup closes when the picker exits.
+func popup(g *globals, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir := g.repo
+	if dir == "" {
+		dir = "."
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return err
+	}
+	words := []string{envInPopup + "=1", shellQuote(exe)}
+	for _, a := range args {
+		words = append(words, shellQuote(a))
+	}
+	cmd := exec.Command("tmux", "display-popup", "-E", "-w", "80%", "-h", popupHeight(g), "-d", dir, strings.Join(words, " "))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// popupHeight fits the popup to the branch list plus the picker's chrome,
+// up to 80% of the tmux window.
+func popupHeight(g *globals) string {
+	const fallback = "80%"
+	scope, err := g.parseScope()
+	if err != nil {
+		return fallback
+	}
+	resp, err := core.ListBranches(core.ListBranchesRequest{RepoPath: g.repo, Scope: scope, Exclude: g.exclude, PageSize: 1})
+	if err != nil {
+		return fallback
+	}
+	out, err := exec.Command("tmux", "display-message", "-p", "#{window_height}").Output()
+	if err != nil {
+		return fallback
+	}
+	window, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return fallback
+	}
+	// Filter line, blank lines, paginator, help and the popup border.
+	lines := resp.Total + 8
+	return strconv.Itoa(max(min(lines, window*8/10), 12))
+}
+
+// withoutPopup drops --popup from args.
+func withoutPopup(args []string) []string {
+	var res []string
+	for _, a := range args {
+		name, _, _ := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if strings.HasPrefix(a, "-") && name == "popup" {
+			continue
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,%@+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
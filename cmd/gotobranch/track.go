@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+func runTrack(g *globals, args []string) error {
+	fs := newFlagSet("track", g)
+	unset := fs.Bool("unset", false, "Stop the branch tracking its upstream")
+	commandUsage(fs, "track")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	var name string
+	switch len(args) {
+	case 0:
+		cur, err := core.GetCurrentBranch(g.repo)
+		if errors.Is(err, core.ErrDetachedHead) {
+			return usageErrorf("HEAD is detached; name the branch")
+		}
+		if err != nil {
+			return err
+		}
+		name = cur.Name
+	case 1:
+		name = args[0]
+	default:
+		return usageErrorf("expected at most one branch name")
+	}
+	local := core.LocalBranchExists(g.repo, name)
+	if *unset {
+		if !local {
+			return usageErrorf("%s is not a local branch", name)
+		}
+		if err := core.Untrack(g.repo, name); err != nil {
+			return err
+		}
+		fmt.Println(i18n.Sprintf("'%s' no longer tracks an upstream", name))
+		return nil
+	}
+	name, upstream, err := core.Track(g.repo, core.Branch{Name: name, IsRemote: !local})
+	if err != nil {
+		return err
+	}
+	fmt.Println(i18n.Sprintf("'%s' tracks '%s'", name, upstream))
+	return nil
+}
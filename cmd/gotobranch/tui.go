@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/ci"
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/github"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/state"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+	"github.com/kvnloughead/gotobranch/internal/tui"
+)
+
+type tuiFlags struct {
+	pageSize    int
+	accessible  bool
+	interactive bool
+	stdin       bool
+	create      string
+	from        string
+	noTUI       bool
+	json        bool
+	prs         bool
+	ci          bool
+	sigs        bool
+	commits     bool
+	table       bool
+	preview     bool
+	popup       bool
+	editor      string
+	tabs        stringsFlag
+	fetch       *fetchFlag
+}
+
+func registerTUIFlags(fs *flag.FlagSet) *tuiFlags {
+	f := tuiFlags{fetch: registerFetchFlag(fs)}
+	fs.IntVar(&f.pageSize, "page-size", 50, "Page size for pagination")
+	fs.BoolVar(&f.accessible, "accessible", false, "Plain prompt-and-response mode for screen readers (no full-screen UI)")
+	fs.BoolVar(&f.interactive, "interactive", false, "Always open the picker, even if the pattern matches a single branch")
+	fs.BoolVar(&f.interactive, "i", false, "Shorthand for --interactive")
+	fs.StringVar(&f.create, "create", "", "Create this branch and switch to it (switches if it already exists)")
+	fs.StringVar(&f.create, "b", "", "Shorthand for --create")
+	fs.StringVar(&f.from, "from", "", "Ref to start a branch created with --create at (default: HEAD)")
+	fs.BoolVar(&f.noTUI, "no-tui", false, "Print the matching branches instead of opening the picker (default when stdout is not a terminal)")
+	fs.BoolVar(&f.json, "json", false, "With --no-tui, print the list as JSON")
+	fs.BoolVar(&f.prs, "prs", false, "Show each branch's GitHub pull request (via gh, or GH_TOKEN/GITHUB_TOKEN)")
+	fs.BoolVar(&f.ci, "ci", false, "Show the CI status of each branch's head commit (GitHub or GitLab)")
+	fs.BoolVar(&f.sigs, "signatures", false, "Show whether each branch's head commit is signed, and by whom")
+	fs.BoolVar(&f.commits, "commits", false, "Show how many commits each branch has that the default branch lacks")
+	fs.BoolVar(&f.table, "table", false, "Show the branches in aligned columns under a header (ignores rowFormat)")
+	fs.BoolVar(&f.preview, "preview", false, "Show the highlighted branch's commits and changes next to the list (v toggles it, < and > resize it)")
+	fs.BoolVar(&f.popup, "popup", false, "Inside tmux, open the picker in a popup (ignored outside tmux)")
+	fs.StringVar(&f.editor, "editor", "", "Speak the JSON-lines protocol of an editor plugin on stdin/stdout instead of drawing the picker (nvim)")
+	fs.Var(&f.tabs, "tab", "Also open this repository in the picker, as a tab switched to with [ and ] (repeatable; adds to the config's workspace)")
+	fs.BoolVar(&f.stdin, "stdin", false, "Pick from newline-separated items read from stdin and print the selection")
+	return &f
+}
+
+// runTUI starts the interactive picker. args holds the optional pattern,
+// possibly followed by more flags.
+func runTUI(g *globals, f *tuiFlags, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return usageErrorf("too many arguments; expected at most one pattern")
+	}
+	// Fail before anything, the picker in particular, starts without data.
+	if err := core.CheckRepo(g.repo); err != nil {
+		return err
+	}
+	scope, err := g.parseScope()
+	if err != nil {
+		return err
+	}
+	match, err := g.parseMatch()
+	if err != nil {
+		return err
+	}
+	var pattern string
+	if len(args) > 0 {
+		pattern = args[0]
+	}
+	if err := g.checkFilter(match, pattern); err != nil {
+		return err
+	}
+	if f.editor != "" {
+		sortBy, sortDir, err := g.parseSort()
+		if err != nil {
+			return err
+		}
+		return runEditor(g, f, core.ListBranchesRequest{
+			RepoPath: g.repo,
+			Scope:    scope,
+			Pattern:  pattern,
+			Query:    g.filterQuery(),
+			Match:    match,
+			Exclude:  g.exclude,
+			SortBy:   sortBy,
+			SortDir:  sortDir,
+		})
+	}
+
+	if f.create != "" {
+		if pattern != "" {
+			return usageErrorf("--create takes the branch name as its value; no pattern expected")
+		}
+		return createOrSwitch(g, f.create, f.from)
+	}
+	if f.from != "" {
+		return usageErrorf("--from requires --create")
+	}
+	if f.stdin {
+		return runPicker(g, f, pattern)
+	}
+	// Explicitly asking for a list never switches; otherwise a unique match
+	// switches even without a terminal, as that is what a script expects.
+	printList := !f.accessible && (f.noTUI || f.json || g.cfg.NoTUI)
+	if pattern != "" && !f.interactive && !printList {
+		// The match must see fresh remote branches, so fetch up front.
+		if err := fetchFirst(g, f.fetch); err != nil {
+			return err
+		}
+		f.fetch.enabled = false
+		name, err := uniqueMatch(g, scope, match, pattern)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			prev, err := core.Checkout(g.repo, name, false)
+			if err != nil && !core.PostHookFailed(err) {
+				return err
+			}
+			recordSwitch(g)
+			printSwitched(name, prev)
+			offerStash(g, name)
+			return err
+		}
+	}
+
+	if printList || (!f.accessible && !isTerminal(os.Stdout)) {
+		// Escape codes are useless in a pipe or CI log; print the list.
+		listArgs := args
+		if f.json {
+			listArgs = append([]string{"--json"}, listArgs...)
+		}
+		if f.prs {
+			listArgs = append([]string{"--prs"}, listArgs...)
+		}
+		if f.ci {
+			listArgs = append([]string{"--ci"}, listArgs...)
+		}
+		if f.sigs {
+			listArgs = append([]string{"--signatures"}, listArgs...)
+		}
+		if f.commits {
+			listArgs = append([]string{"--commits"}, listArgs...)
+		}
+		if f.fetch.enabled {
+			listArgs = append([]string{"--fetch=" + f.fetch.String()}, listArgs...)
+		}
+		return runList(g, listArgs)
+	}
+	if f.popup && inTmux() {
+		return popup(g, withoutPopup(os.Args[1:]))
+	}
+	sortBy, sortDir, err := g.parseSort()
+	if err != nil {
+		return err
+	}
+	cfg := g.cfg
+	if err := tui.CheckTheme(cfg.Theme); err != nil {
+		return err
+	}
+	if cfg.RowFormat != "" {
+		if _, err := tmpl.Parse("row", cfg.RowFormat); err != nil {
+			return usageErrorf("invalid rowFormat in config: %w", err)
+		}
+	}
+	if cfg.WorktreePath != "" {
+		if _, err := tmpl.Parse("worktreePath", cfg.WorktreePath); err != nil {
+			return usageErrorf("invalid worktreePath in config: %w", err)
+		}
+	}
+
+	opts := tui.Options{
+		RepoPath:  g.repo,
+		Scope:     scope,
+		PageSize:  f.pageSize,
+		Pattern:   strings.TrimSpace(pattern + " " + g.filterQuery()),
+		Match:     match,
+		Exclude:   g.exclude,
+		SortBy:    sortBy,
+		SortDir:   sortDir,
+		Theme:     cfg.Theme,
+		RowFormat: cfg.RowFormat,
+		Table:     f.table || cfg.Table,
+
+		Signatures: f.sigs || cfg.Signatures,
+		Commits:    f.commits || cfg.Commits,
+		PolicyWarn: cfg.NamePolicyWarn,
+		StaleAfter: staleAfter(cfg.StaleDays),
+
+		Preview:          f.preview || cfg.Preview,
+		SavePreviewWidth: savePreviewWidth,
+		DiffPager:        cfg.DiffPager,
+		DiffTool:         cfg.DiffTool,
+
+		ScrollLines: cfg.Mouse.ScrollLines,
+		ScrollView:  cfg.Mouse.Scroll == "view",
+
+		Logger: g.log,
+	}
+	if s, err := state.LoadSettings(); err == nil {
+		opts.PreviewWidth = s.PreviewWidth
+	}
+	if opts.BranchTemplates, err = pickerTemplates(g); err != nil {
+		return err
+	}
+	if len(cfg.Profiles) > 0 {
+		if opts.Profiles, opts.Profile, err = g.tuiProfiles(); err != nil {
+			return err
+		}
+	}
+	if cfg.CheckUpdates {
+		opts.UpdateCheck = updateNotice
+	}
+	if g.metrics != nil {
+		opts.Used = g.metrics.Use
+	}
+	if err := loadColumns(g, &opts); err != nil {
+		return err
+	}
+	if err := loadPlugins(g, &opts); err != nil {
+		return err
+	}
+	repoOptions(g, f, &opts)
+	var base tui.Options // opts once complete, for other repositories
+	opts.Repos = func() ([]string, error) { return knownRepos(g) }
+	opts.OpenRepo = func(dir string) (tui.Options, error) {
+		if err := core.CheckRepo(dir); err != nil {
+			return tui.Options{}, err
+		}
+		tg := *g
+		tg.repo = dir
+		o := base
+		repoOptions(&tg, f, &o)
+		return o, nil
+	}
+	if f.accessible {
+		if err := fetchFirst(g, f.fetch); err != nil {
+			return err
+		}
+		return tui.RunAccessible(opts, os.Stdin, os.Stdout)
+	}
+	opts.Fetch, opts.FetchRemote = f.fetch.enabled, f.fetch.remote
+	base = opts
+
+	var picker tea.Model = tui.New(opts)
+	dirs, err := tabRepos(g, f)
+	if err != nil {
+		return err
+	}
+	if len(dirs) > 1 {
+		var all []tui.Options
+		for _, dir := range dirs {
+			tg := *g
+			tg.repo = dir
+			o := opts
+			repoOptions(&tg, f, &o)
+			all = append(all, o)
+		}
+		picker = tui.NewTabs(all)
+	}
+
+	progOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if !cfg.Mouse.Off {
+		progOpts = append(progOpts, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(picker, progOpts...)
+	restore := g.captureHooks(p)
+	stop := showBusy(p)
+	final, err := p.Run()
+	stop()
+	restore()
+	if err != nil {
+		return err
+	}
+	var models []tui.Model
+	active := 0
+	switch final := final.(type) {
+	case tui.Model:
+		models = []tui.Model{final}
+	case tui.Tabs:
+		models, active = final.Models(), final.Active()
+	}
+	var deleted []tui.PruneResult
+	for _, m := range models {
+		deleted = append(deleted, m.Deleted()...)
+	}
+	if len(deleted) > 0 {
+		// Failures were shown in the picker.
+		_ = printPruned(deleted)
+	}
+	m := models[active]
+	if m.RepoPath != g.repo {
+		// Shown as a tab or opened from the picker.
+		tg := *g
+		tg.repo = m.RepoPath
+		g = &tg
+	}
+	if dir := m.Worktree(); dir != "" {
+		return openWorktree(g, dir)
+	}
+	if m.Switched() == "" {
+		if len(deleted) > 0 {
+			return nil
+		}
+		return errCancelled
+	}
+	recordSwitch(g)
+	return m.HookErr()
+}
+
+// repoOptions sets the picker options that depend on the repository g
+// works in.
+func repoOptions(g *globals, f *tuiFlags, opts *tui.Options) {
+	cfg := g.cfg
+	opts.RepoPath = g.repo
+	opts.Worktree = pickerWorktree(g)
+	opts.Undo = func() (core.Action, error) { return undo(g) }
+	opts.PullRequests, opts.IssueBranch, opts.CIStatuses = nil, nil, nil
+	opts.ColumnValues = columnValues(g)
+	if f.prs || cfg.PullRequests {
+		opts.PullRequests = func() (map[string]core.PullRequest, error) {
+			return github.PullRequests(context.Background(), g.repo)
+		}
+	}
+	if _, err := github.Slug(g.repo); err == nil {
+		opts.IssueBranch = pickerIssueBranch(g)
+	}
+	if f.ci || cfg.CIStatus {
+		opts.CIStatuses = func(shas []string) (map[string]string, error) {
+			return ci.Statuses(context.Background(), g.repo, shas)
+		}
+	}
+}
+
+// knownRepos returns the working trees of the repositories branches were
+// recently switched in, then of those in the config's workspace and repos,
+// leaving out those gone since.
+func knownRepos(g *globals) ([]string, error) {
+	recent, err := state.Repos()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var res []string
+	for _, dir := range slices.Concat(recent, g.cfg.Workspace, g.cfg.Repos) {
+		if dir, err = expandHome(dir); err != nil {
+			return nil, err
+		}
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		if top, err := core.TopLevel(dir); err == nil && !seen[top] {
+			seen[top] = true
+			res = append(res, top)
+		}
+	}
+	return res, nil
+}
+
+// tabRepos returns the working trees of the repository g works in and of
+// those given with --tab and in the config's workspace, for the picker to
+// show as tabs, or nil when there are none of the latter.
+func tabRepos(g *globals, f *tuiFlags) ([]string, error) {
+	if len(f.tabs) == 0 && len(g.cfg.Workspace) == 0 {
+		return nil, nil
+	}
+	cur, err := core.TopLevel(g.repo)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{cur: true}
+	res := []string{cur}
+	for _, dir := range slices.Concat([]string(f.tabs), g.cfg.Workspace) {
+		dir, err := expandHome(dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := core.CheckRepo(dir); err != nil {
+			return nil, err
+		}
+		top, err := core.TopLevel(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		if !seen[top] {
+			seen[top] = true
+			res = append(res, top)
+		}
+	}
+	return res, nil
+}
+
+// uniqueMatch returns the branch pattern unambiguously refers to: a branch
+// named exactly pattern, or else the only branch matching it. It returns ""
+// when the user needs to choose.
+func uniqueMatch(g *globals, scope core.Scope, match core.MatchMode, pattern string) (string, error) {
+	matches, err := listAll(core.ListBranchesRequest{
+		RepoPath: g.repo,
+		Scope:    scope,
+		Pattern:  pattern,
+		Query:    g.filterQuery(),
+		Match:    match,
+		Exclude:  g.exclude,
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, b := range matches {
+		if b.Name == pattern {
+			return b.Name, nil
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0].Name, nil
+	}
+	return "", nil
+}
+
+// runPicker reads items from stdin and lets the user pick one. The UI is
+// drawn on stderr so that stdout carries only the selection, e.g. for
+// `git branch -r | gotobranch --stdin | xargs ...`.
+func runPicker(g *globals, f *tuiFlags, pattern string) error {
+	if f.accessible {
+		return usageErrorf("--stdin cannot be combined with --accessible")
+	}
+	if !isTerminal(os.Stderr) {
+		return i18n.Errorf("--stdin needs a terminal on stderr to draw the picker")
+	}
+	var items []string
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		// Tolerate `git branch` decorations for the current/worktree branch.
+		line := strings.TrimSpace(sc.Text())
+		line = strings.TrimPrefix(line, "* ")
+		line = strings.TrimPrefix(line, "+ ")
+		if line != "" {
+			items = append(items, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return usageErrorf("no items on stdin")
+	}
+
+	match, err := g.parseMatch()
+	if err != nil {
+		return err
+	}
+	m := tui.New(tui.Options{
+		RepoPath: g.repo,
+		PageSize: f.pageSize,
+		Pattern:  strings.TrimSpace(pattern + " " + g.filterQuery()),
+		Match:    match,
+		Theme:    g.cfg.Theme,
+		Items:    core.ResolveItems(g.repo, items),
+		Logger:   g.log,
+	})
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	restore := g.captureHooks(p)
+	stop := showBusy(p)
+	final, err := p.Run()
+	stop()
+	restore()
+	if err != nil {
+		return err
+	}
+	if picked := final.(tui.Model).Picked(); picked != "" {
+		fmt.Println(picked)
+		return nil
+	}
+	if final.(tui.Model).Switched() != "" {
+		recordSwitch(g)
+		return final.(tui.Model).HookErr()
+	}
+	return errCancelled
+}
+
+// showBusy makes the picker p say so while git waits for another git
+// process to release a lock. The returned function stops it.
+func showBusy(p *tea.Program) (stop func()) {
+	core.SetBusy(func(busy bool) { p.Send(tui.BusyMsg(busy)) })
+	return func() { core.SetBusy(nil) }
+}
+
+// isTerminal reports whether f is connected to a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// staleAfter turns the staleDays setting into tui.Options.StaleAfter.
+func staleAfter(days int) time.Duration {
+	switch {
+	case days < 0:
+		return 0
+	case days == 0:
+		days = 90
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// savePreviewWidth keeps the width of the picker's preview pane for the
+// next run.
+func savePreviewWidth(percent int) error {
+	s, err := state.LoadSettings()
+	if err != nil {
+		return err
+	}
+	s.PreviewWidth = percent
+	return state.SaveSettings(s)
+}
+
+// expandHome replaces a leading ~/ in dir with the home directory.
+func expandHome(dir string) (string, error) {
+	if !strings.HasPrefix(dir, "~/") {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, dir[2:]), nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/state"
+)
+
+func runUndo(g *globals, args []string) error {
+	fs := newFlagSet("undo", g)
+	dryRun := fs.Bool("dry-run", false, "Only print what would be undone")
+	commandUsage(fs, "undo")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageErrorf("undo takes no arguments")
+	}
+	if *dryRun {
+		a, err := lastAction(g)
+		if err != nil {
+			return err
+		}
+		fmt.Println(i18n.Sprintf("Would undo the %s", a.Action))
+		return nil
+	}
+	what, err := undo(g)
+	if err != nil && !core.PostHookFailed(err) {
+		return err
+	}
+	fmt.Println(i18n.Sprintf("Undid the %s", what))
+	return err
+}
+
+// errNothingToUndo is returned when the journal has no action for the
+// repository.
+var errNothingToUndo = errors.New("nothing to undo")
+
+// lastAction returns the most recent action journaled for the repository.
+func lastAction(g *globals) (state.Action, error) {
+	top, err := core.TopLevel(g.repo)
+	if err != nil {
+		return state.Action{}, err
+	}
+	a, ok, err := state.LastAction(top)
+	if err != nil {
+		return a, err
+	}
+	if !ok {
+		return a, errNothingToUndo
+	}
+	return a, nil
+}
+
+// undo reverses the most recent action journaled for the repository and
+// forgets it, so that the next undo goes one step further back. It returns
+// what was undone.
+func undo(g *globals) (core.Action, error) {
+	a, err := lastAction(g)
+	if err != nil {
+		return core.Action{}, err
+	}
+	err = core.Undo(g.repo, a.Action)
+	if err != nil && !core.PostHookFailed(err) {
+		return a.Action, err
+	}
+	if a.Kind == core.ActionSwitch {
+		recordSwitch(g)
+	}
+	if ferr := state.ForgetAction(a); ferr != nil && err == nil {
+		err = ferr
+	}
+	return a.Action, err
+}
+
+// journalAction records a in the journal undo reads. It is best effort,
+// like recordSwitch.
+func journalAction(repoPath string, a core.Action) {
+	top, err := core.TopLevel(repoPath)
+	if err != nil {
+		return
+	}
+	_ = state.RecordAction(state.Action{Repo: top, At: time.Now(), Action: a})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/update"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X main.version=v1.2.3 -X main.commit=abc1234 -X main.date=2025-01-02"
+//
+// Unset values fall back to the module and VCS info embedded by the Go
+// toolchain.
+var (
+	version = ""
+	commit  = ""
+	date    = ""
+)
+
+type buildInfo struct {
+	Version, Commit, Date string
+}
+
+func getBuildInfo() buildInfo {
+	bi := buildInfo{Version: version, Commit: commit, Date: date}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return bi
+	}
+	if bi.Version == "" {
+		bi.Version = info.Main.Version
+	}
+	var rev, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = shortSHA(s.Value)
+		case "vcs.modified":
+			modified = s.Value
+		case "vcs.time":
+			if bi.Date == "" {
+				bi.Date = s.Value
+			}
+		}
+	}
+	if bi.Commit == "" && rev != "" {
+		bi.Commit = rev
+		if modified == "true" {
+			bi.Commit += "-dirty"
+		}
+	}
+	if bi.Version == "" {
+		bi.Version = "(devel)"
+	}
+	return bi
+}
+
+func runVersion(g *globals, args []string) error {
+	fs := newFlagSet("version", g)
+	check := fs.Bool("check", false, "Check GitHub for a newer release")
+	commandUsage(fs, "version")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	bi := getBuildInfo()
+	fmt.Printf("gotobranch %s\n", bi.Version)
+	if bi.Commit != "" {
+		fmt.Printf("commit: %s\n", bi.Commit)
+	}
+	if bi.Date != "" {
+		fmt.Printf("built:  %s\n", bi.Date)
+	}
+	fmt.Printf("go:     %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if !*check {
+		return nil
+	}
+	rel, err := update.Latest(context.Background())
+	if err != nil {
+		return err
+	}
+	if update.Newer(rel.TagName, bi.Version) {
+		fmt.Println(i18n.Sprintf("A newer release is available: %s (%s)", rel.TagName, rel.HTMLURL))
+	} else {
+		fmt.Println(i18n.Sprintf("Latest release: %s", rel.TagName))
+	}
+	return nil
+}
+
+// updateNotice returns a one-line notice if a newer release exists, or ""
+// when up to date or when the check fails; it must never get in the way.
+func updateNotice() string {
+	rel, err := update.Latest(context.Background())
+	if err != nil {
+		return ""
+	}
+	if !update.Newer(rel.TagName, getBuildInfo().Version) {
+		return ""
+	}
+	return fmt.Sprintf("gotobranch %s is available (you have %s)", rel.TagName, getBuildInfo().Version)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/hooks"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// defaultWorktreePath places worktrees next to the main working tree, e.g.
+// ~/src/app-feat-login for feat/login in ~/src/app.
+const defaultWorktreePath = `{{.RepoName}}-{{.Branch | slug}}`
+
+// worktreeFields are the fields of the worktreePath template.
+type worktreeFields struct {
+	Repo     string // the main working tree
+	RepoName string // its base name
+	Branch   string // the local branch to check out
+}
+
+// pickerWorktree backs the picker's worktree key: it returns the working
+// tree b is checked out in, first creating one if there is none, along
+// with its submodules.
+func pickerWorktree(g *globals) func(ctx context.Context, b core.Branch, report func(core.Progress)) (string, error) {
+	return func(ctx context.Context, b core.Branch, report func(core.Progress)) (string, error) {
+		trees, err := core.Worktrees(g.repo)
+		if err != nil {
+			return "", err
+		}
+		if len(trees) == 0 {
+			return "", i18n.Errorf("no working trees")
+		}
+		name := core.HeadName(b)
+		for _, t := range trees {
+			if t.Branch == name {
+				return t.Path, nil
+			}
+		}
+		dir, err := worktreePath(g, trees[0].Path, name)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(dir); err == nil {
+			return "", i18n.Errorf("%s already exists; remove it or change worktreePath", dir)
+		}
+		if _, err := core.AddWorktree(ctx, g.repo, dir, b, report); err != nil {
+			return "", err
+		}
+		if core.HasSubmodules(dir) {
+			if err := core.UpdateSubmodules(ctx, dir, report); err != nil {
+				return "", i18n.Errorf("created %s, but updating its submodules failed: %w", dir, err)
+			}
+		}
+		return dir, nil
+	}
+}
+
+// worktreePath renders the configured worktreePath for branch, resolving
+// relative paths against the parent of the main working tree main.
+func worktreePath(g *globals, main, branch string) (string, error) {
+	format := g.cfg.WorktreePath
+	if format == "" {
+		format = defaultWorktreePath
+	}
+	t, err := tmpl.Parse("worktreePath", format)
+	if err != nil {
+		return "", usageErrorf("invalid worktreePath in config: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, worktreeFields{Repo: main, RepoName: filepath.Base(main), Branch: branch}); err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(b.String())
+	if dir == "" {
+		return "", i18n.Errorf("worktreePath rendered an empty path for %s", branch)
+	}
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(main), dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// openWorktree runs the configured worktreeOpen command in dir, or else
+// asks the shell to change into it.
+func openWorktree(g *globals, dir string) error {
+	if g.cfg.WorktreeOpen == "" {
+		return requestCD(dir)
+	}
+	cmd := hooks.Shell(g.cfg.WorktreeOpen)
+	cmd.Dir = dir
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return i18n.Errorf("worktreeOpen `%s` failed: %v", g.cfg.WorktreeOpen, err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+-- Branch picker backed by `gotobranch --editor nvim`, which lists the
+-- branches and carries out actions over a JSON-lines channel on the job's
+-- stdin and stdout. Uses Telescope when installed, else vim.ui.select.
+local M = {}
+
+local PROTOCOL = 1
+
+M.config = {
+  cmd = "gotobranch",
+  -- Extra arguments, e.g. { "--scope", "all" } or { "--profile", "mine" }.
+  args = {},
+}
+
+function M.setup(opts)
+  M.config = vim.tbl_deep_extend("force", M.config, opts or {})
+end
+
+-- session starts gotobranch and returns an object to send actions with.
+-- on_branches receives the initial branch list; request() sends an action
+-- and calls cb with the event answering it (actions are answered in order).
+local function session(pattern, on_branches)
+  local s = { pending = {} }
+  local cmd = { M.config.cmd, "--editor", "nvim" }
+  vim.list_extend(cmd, M.config.args)
+  if pattern then
+    table.insert(cmd, pattern)
+  end
+
+  local partial = ""
+  local function handle(line)
+    local ok, ev = pcall(vim.json.decode, line)
+    if not ok then
+      return
+    end
+    if ev.event == "hello" then
+      if ev.version ~= PROTOCOL then
+        vim.notify("gotobranch: unsupported protocol version " .. tostring(ev.version), vim.log.levels.ERROR)
+        s.close()
+      end
+    elseif ev.event == "branches" and not s.listed then
+      s.listed = true
+      on_branches(ev.items or {})
+    elseif ev.event == "error" and #s.pending == 0 then
+      vim.notify("gotobranch: " .. (ev.message or "error"), vim.log.levels.ERROR)
+    else
+      local cb = table.remove(s.pending, 1)
+      if cb then
+        cb(ev)
+      end
+    end
+  end
+
+  local stderr = {}
+  s.job = vim.fn.jobstart(cmd, {
+    cwd = vim.fn.getcwd(),
+    on_stdout = function(_, data)
+      data[1] = partial .. data[1]
+      partial = table.remove(data)
+      for _, line in ipairs(data) do
+        if line ~= "" then
+          vim.schedule(function()
+            handle(line)
+          end)
+        end
+      end
+    end,
+    on_stderr = function(_, data)
+      vim.list_extend(stderr, data)
+    end,
+    on_exit = function(_, code)
+      if code ~= 0 then
+        vim.schedule(function()
+          local msg = vim.trim(table.concat(stderr, "\n"))
+          vim.notify("gotobranch: " .. (msg ~= "" and msg or ("exit " .. code)), vim.log.levels.ERROR)
+        end)
+      end
+    end,
+  })
+  if s.job <= 0 then
+    vim.notify("gotobranch: cannot run " .. M.config.cmd, vim.log.levels.ERROR)
+    return nil
+  end
+
+  function s.request(action, cb)
+    table.insert(s.pending, cb or function() end)
+    vim.fn.chansend(s.job, vim.json.encode(action) .. "\n")
+  end
+
+  function s.close()
+    pcall(vim.fn.chansend, s.job, vim.json.encode({ action = "quit" }) .. "\n")
+    pcall(vim.fn.chanclose, s.job, "stdin")
+  end
+
+  return s
+end
+
+-- report tells the user how an action went; buffers are reloaded after a
+-- switch since files may have changed on disk.
+local function report(ev)
+  if ev.event == "error" then
+    vim.notify("gotobranch: " .. vim.trim(ev.message or ""), vim.log.levels.ERROR)
+  elseif ev.event == "switched" or ev.event == "created" then
+    vim.cmd("checktime")
+    local verb = ev.event == "created" and "Switched to a new branch" or "Switched to"
+    vim.notify(string.format("%s '%s'", verb, ev.name))
+  elseif ev.event == "deleted" then
+    vim.notify(string.format("Deleted '%s' (restore with: git branch %s %s)", ev.name, ev.name, ev.sha))
+  end
+end
+
+local function label(b)
+  return (b.isCurrent and "* " or "  ") .. b.name .. (b.lastCommitMessage and ("  " .. b.lastCommitMessage) or "")
+end
+
+local function pick_select(s, items)
+  vim.ui.select(items, { prompt = "Branch", format_item = label }, function(choice)
+    if not choice then
+      s.close()
+      return
+    end
+    s.request({ action = "switch", name = choice.name }, function(ev)
+      report(ev)
+      s.close()
+    end)
+  end)
+end
+
+local function pick_telescope(s, items)
+  local pickers = require("telescope.pickers")
+  local finders = require("telescope.finders")
+  local previewers = require("telescope.previewers")
+  local conf = require("telescope.config").values
+  local actions = require("telescope.actions")
+  local state = require("telescope.actions.state")
+
+  local function finder(list)
+    return finders.new_table({
+      results = list,
+      entry_maker = function(b)
+        return { value = b, display = label(b), ordinal = b.name }
+      end,
+    })
+  end
+
+  local done = false
+  pickers
+    .new({}, {
+      prompt_title = "Branches",
+      finder = finder(items),
+      sorter = conf.generic_sorter({}),
+      previewer = previewers.new_buffer_previewer({
+        title = "Log",
+        define_preview = function(self, entry)
+          local bufnr = self.state.bufnr
+          s.request({ action = "preview", name = entry.value.name }, function(ev)
+            if vim.api.nvim_buf_is_valid(bufnr) then
+              local text = ev.text or ev.message or ""
+              vim.api.nvim_buf_set_lines(bufnr, 0, -1, false, vim.split(text, "\n"))
+            end
+          end)
+        end,
+      }),
+      attach_mappings = function(prompt_bufnr, map)
+        actions.select_default:replace(function()
+          local entry = state.get_selected_entry()
+          done = true
+          actions.close(prompt_bufnr)
+          if not entry then
+            s.close()
+            return
+          end
+          s.request({ action = "switch", name = entry.value.name }, function(ev)
+            report(ev)
+            s.close()
+          end)
+        end)
+        -- <C-d> deletes the highlighted branch; <C-f> forces it.
+        for key, force in pairs({ ["<C-d>"] = false, ["<C-f>"] = true }) do
+          map({ "i", "n" }, key, function()
+            local entry = state.get_selected_entry()
+            if not entry then
+              return
+            end
+            s.request({ action = "delete", name = entry.value.name, force = force }, function(ev)
+              report(ev)
+              s.request({ action = "list" }, function(list)
+                if list.event == "branches" then
+                  state.get_current_picker(prompt_bufnr):refresh(finder(list.items or {}), { reset_prompt = false })
+                end
+              end)
+            end)
+          end)
+        end
+        actions.close:enhance({
+          post = function()
+            if not done then
+              s.close()
+            end
+          end,
+        })
+        return true
+      end,
+    })
+    :find()
+end
+
+-- pick opens the branch picker. opts.pattern narrows the list like the
+-- gotobranch [pattern] argument.
+function M.pick(opts)
+  opts = opts or {}
+  local s
+  s = session(opts.pattern, function(items)
+    if #items == 0 then
+      vim.notify("gotobranch: no branch matches", vim.log.levels.WARN)
+      s.close()
+    elseif pcall(require, "telescope") then
+      pick_telescope(s, items)
+    else
+      pick_select(s, items)
+    end
+  end)
+end
+
+return M
@@ -0,0 +1,8 @@
+if vim.g.loaded_gotobranch then
+  return
+end
+vim.g.loaded_gotobranch = true
+
+vim.api.nvim_create_user_command("GotoBranch", function(opts)
+  require("gotobranch").pick({ pattern = opts.args ~= "" and opts.args or nil })
+end, { nargs = "?", desc = "Pick a git branch with gotobranch" })
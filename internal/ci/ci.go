@@ -0,0 +1,257 @@
+// Package ci looks up the CI status of commits on the service hosting a
+// repository's origin remote: GitHub check runs and commit statuses (see
+// package github), or GitLab pipelines.
+//
+// Finished results never change for a commit and are cached on disk
+// indefinitely; pending ones, and commits without CI so far (e.g. not yet
+// pushed), are asked for again after RecheckAfter.
+package ci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/github"
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// RecheckAfter is how long a pending or missing status is reused.
+const RecheckAfter = time.Minute
+
+// parallel bounds the lookups in flight at once.
+const parallel = 8
+
+// maxCached bounds the cache file; the oldest entries are dropped.
+const maxCached = 2000
+
+// Glyph renders a status for a narrow column: ✓ passed, ✗ failed,
+// ● pending, and nothing for commits without CI.
+func Glyph(status string) string {
+	switch status {
+	case core.CISuccess:
+		return "✓"
+	case core.CIFailure:
+		return "✗"
+	case core.CIPending:
+		return "●"
+	}
+	return ""
+}
+
+// lookup returns the status of one commit.
+type lookup func(ctx context.Context, sha string) (string, error)
+
+// Statuses returns the CI status of each of shas, keyed by SHA. Commits
+// without CI map to "". The first lookup error stops the remaining ones;
+// statuses found until then are returned with it.
+func Statuses(ctx context.Context, repoPath string, shas []string) (map[string]string, error) {
+	remote, err := core.RemoteURL(repoPath, "origin")
+	if err != nil {
+		return nil, err
+	}
+	var get lookup
+	if slug, err := github.Slug(repoPath); err == nil {
+		get = func(ctx context.Context, sha string) (string, error) {
+			return github.CommitStatus(ctx, slug, sha)
+		}
+	} else if host, project, ok := gitlabProject(remote); ok {
+		get = func(ctx context.Context, sha string) (string, error) {
+			return gitlabStatus(ctx, host, project, sha)
+		}
+	} else {
+		return nil, fmt.Errorf("no CI provider for origin %s; GitHub and GitLab are supported", remote)
+	}
+
+	c := loadCache(remote)
+	res := map[string]string{}
+	var missing []string
+	for _, sha := range shas {
+		if _, ok := res[sha]; ok || slices.Contains(missing, sha) {
+			continue
+		}
+		if status, ok := c.get(sha); ok {
+			res[sha] = status
+		} else {
+			missing = append(missing, sha)
+		}
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		sem      = make(chan struct{}, parallel)
+	)
+	for _, sha := range missing {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			status, err := get(ctx, sha)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			res[sha] = status
+			c.put(sha, status)
+		}()
+	}
+	wg.Wait()
+	c.save()
+	return res, firstErr
+}
+
+// gitlabPattern extracts the host and project path from the https, ssh and
+// scp-like URLs of a remote on a host with "gitlab" in its name.
+var gitlabPattern = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?([^/:]*gitlab[^/:]*)(?::\d+)?[:/](.+?)(?:\.git)?/?$`)
+
+func gitlabProject(remote string) (host, project string, ok bool) {
+	m := gitlabPattern.FindStringSubmatch(remote)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// gitlabStatus returns the status of the latest pipeline for sha. Private
+// projects need a token in GITLAB_TOKEN.
+func gitlabStatus(ctx context.Context, host, project, sha string) (string, error) {
+	u := fmt.Sprintf("https://%s/api/v4/projects/%s/repository/commits/%s", host, url.PathEscape(project), sha)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// Commits not pushed yet have no pipeline either.
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitLab API: %s", resp.Status)
+	}
+	var commit struct {
+		LastPipeline *struct {
+			Status string `json:"status"`
+		} `json:"last_pipeline"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&commit); err != nil {
+		return "", err
+	}
+	if commit.LastPipeline == nil {
+		return "", nil
+	}
+	switch commit.LastPipeline.Status {
+	case "success":
+		return core.CISuccess, nil
+	case "failed":
+		return core.CIFailure, nil
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return core.CIPending, nil
+	}
+	// Canceled, skipped or manual pipelines say nothing about the commit.
+	return "", nil
+}
+
+// cache holds the statuses looked up for one remote.
+type cache struct {
+	path    string
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// loadCache reads the cache of remote. A missing or unreadable cache is
+// simply empty.
+func loadCache(remote string) *cache {
+	c := &cache{entries: map[string]cacheEntry{}}
+	dir, err := paths.Cache()
+	if err != nil {
+		return c
+	}
+	// The URL may carry credentials, which stay out of the file name; the
+	// same remote reached with another token shares the cache.
+	if u, err := url.Parse(remote); err == nil && u.User != nil {
+		u.User = nil
+		remote = u.String()
+	}
+	sum := sha256.Sum256([]byte(remote))
+	c.path = filepath.Join(dir, "ci", hex.EncodeToString(sum[:8])+".json")
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+func (c *cache) get(sha string) (string, bool) {
+	e, ok := c.entries[sha]
+	final := e.Status == core.CISuccess || e.Status == core.CIFailure
+	if !ok || (!final && time.Since(e.At) > RecheckAfter) {
+		return "", false
+	}
+	return e.Status, true
+}
+
+func (c *cache) put(sha, status string) {
+	c.entries[sha] = cacheEntry{Status: status, At: time.Now()}
+	c.dirty = true
+}
+
+// save writes the cache back if it changed. Failing to cache is not worth
+// reporting; the statuses are simply looked up again next time.
+func (c *cache) save() {
+	if !c.dirty || c.path == "" {
+		return
+	}
+	if len(c.entries) > maxCached {
+		shas := make([]string, 0, len(c.entries))
+		for sha := range c.entries {
+			shas = append(shas, sha)
+		}
+		sort.Slice(shas, func(i, j int) bool { return c.entries[shas[i]].At.After(c.entries[shas[j]].At) })
+		for _, sha := range shas[maxCached:] {
+			delete(c.entries, sha)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(c.path), 0o755) == nil {
+		_ = os.WriteFile(c.path, data, 0o644)
+	}
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Problem is something wrong with a config file: a syntax error, a setting
+// gotobranch does not know, or a value that can never be valid.
+type Problem struct {
+	Key  string // the setting, e.g. "sort" or "profiles.mine"; empty for syntax errors
+	Line int    // where in the file it is, from 1; 0 when unknown
+	Err  error
+
+	path string // the offending value's JSON path, e.g. "columns.2.width"
+}
+
+func (p Problem) Error() string {
+	if p.Key == "" {
+		return p.Err.Error()
+	}
+	return p.Key + ": " + p.Err.Error()
+}
+
+func (p Problem) Unwrap() error { return p.Err }
+
+// Check returns the problems with the config file at path, in the order
+// they appear: its syntax error if it has one, or else the settings it
+// does not know and the values of the wrong type, or else the values
+// Validate rejects. A missing file has none.
+func Check(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return check(data), nil
+}
+
+func check(data []byte) []Problem {
+	line := func(offset int64) int { return bytes.Count(data[:offset], []byte("\n")) + 1 }
+	var se *json.SyntaxError
+	if err := json.Unmarshal(data, new(any)); errors.As(err, &se) {
+		return []Problem{{Line: line(se.Offset), Err: err}}
+	} else if err != nil {
+		return []Problem{{Err: err}}
+	}
+	w := walker{dec: json.NewDecoder(bytes.NewReader(data)), line: line, lines: map[string]int{}}
+	w.value(reflect.TypeOf(Config{}), "")
+	if len(w.problems) > 0 {
+		// Values of the wrong type keep the file from being decoded.
+		return w.problems
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return []Problem{{Err: err}}
+	}
+	ps := cfg.problems()
+	for i := range ps {
+		ps[i].Line = w.lineOf(ps[i].path)
+	}
+	return ps
+}
+
+// walker walks the JSON of a config file along the type it decodes into,
+// noting the line each value is on and the values that do not fit.
+type walker struct {
+	dec      *json.Decoder
+	line     func(offset int64) int
+	lines    map[string]int // by JSON path
+	problems []Problem
+}
+
+// value reads the value at path, which decodes into a t. The file's syntax
+// is known to be valid.
+func (w *walker) value(t reflect.Type, path string) {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return
+	}
+	if _, ok := w.lines[path]; !ok {
+		w.lines[path] = w.line(w.dec.InputOffset())
+	}
+	if tok == nil {
+		return // null leaves the setting unset
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		if tok != json.Delim('{') {
+			w.mismatch(tok, path, "an object")
+			return
+		}
+		for w.dec.More() {
+			k, _ := w.dec.Token()
+			key := join(path, k.(string))
+			w.lines[key] = w.line(w.dec.InputOffset())
+			if t.Kind() == reflect.Map {
+				w.value(t.Elem(), key)
+			} else if f, ok := field(t, k.(string)); ok {
+				w.value(f.Type, key)
+			} else {
+				w.problems = append(w.problems, Problem{Key: key, Line: w.lines[key], Err: errors.New("no such setting")})
+				w.skip()
+			}
+		}
+		w.dec.Token()
+	case reflect.Slice:
+		if tok != json.Delim('[') {
+			w.mismatch(tok, path, "an array")
+			return
+		}
+		for i := 0; w.dec.More(); i++ {
+			w.value(t.Elem(), join(path, strconv.Itoa(i)))
+		}
+		w.dec.Token()
+	case reflect.String:
+		if _, ok := tok.(string); !ok {
+			w.mismatch(tok, path, "a string")
+		}
+	case reflect.Bool:
+		if _, ok := tok.(bool); !ok {
+			w.mismatch(tok, path, "true or false")
+		}
+	case reflect.Int:
+		if f, ok := tok.(float64); !ok || f != math.Trunc(f) {
+			w.mismatch(tok, path, "a whole number")
+		}
+	}
+}
+
+// mismatch notes that the value at path, starting with tok, is not what,
+// and skips the rest of it.
+func (w *walker) mismatch(tok json.Token, path, what string) {
+	var got string
+	switch tok {
+	case json.Delim('{'):
+		got = "an object"
+	case json.Delim('['):
+		got = "an array"
+	default:
+		b, _ := json.Marshal(tok)
+		got = string(b)
+	}
+	w.problems = append(w.problems, Problem{Key: path, Line: w.lines[path], Err: fmt.Errorf("%s is not %s", got, what)})
+	if d, ok := tok.(json.Delim); ok && (d == '{' || d == '[') {
+		w.skipRest()
+	}
+}
+
+// skip reads the next value.
+func (w *walker) skip() {
+	tok, _ := w.dec.Token()
+	if d, ok := tok.(json.Delim); ok && (d == '{' || d == '[') {
+		w.skipRest()
+	}
+}
+
+// skipRest reads the rest of the object or array just opened.
+func (w *walker) skipRest() {
+	for depth := 1; depth > 0; {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// lineOf returns the line of the value at path, or of the nearest one
+// enclosing it that is in the file.
+func (w *walker) lineOf(path string) int {
+	for path != "" {
+		if l, ok := w.lines[path]; ok {
+			return l
+		}
+		i := strings.LastIndexByte(path, '.')
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return 0
+}
+
+// field returns the field of the struct type t that the JSON key decodes
+// into, matching names without regard to case as encoding/json does.
+func field(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold *reflect.StructField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f, true
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = &f
+		}
+	}
+	if fold != nil {
+		return *fold, true
+	}
+	return reflect.StructField{}, false
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
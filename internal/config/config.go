@@ -0,0 +1,336 @@
+// Package config loads user preferences for gotobranch.
+//
+// Settings are resolved from three layers, highest precedence first:
+// command-line flags, GOTOBRANCH_* environment variables (see ApplyEnv), and
+// a JSON file, config.json in the config directory (see package paths:
+// ~/.config/gotobranch on Linux). This package handles the last two;
+// callers use the result as flag defaults so explicit flags win. Every field
+// is optional; a missing file yields the zero Config.
+//
+// Named profiles may also come from a RepoFile checked into a repository, so
+// a team can share them (see LoadRepo).
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// Config is the on-disk configuration.
+type Config struct {
+	// RowFormat is a text/template rendered for each branch row in the TUI,
+	// e.g. `{{.Index}} {{.Name}} {{.Age}} {{.Subject | trunc 40}}`. See
+	// package tmpl for the available fields and functions.
+	RowFormat string `json:"rowFormat,omitempty"`
+
+	// Table shows the picker's rows in aligned columns under a header,
+	// instead of through RowFormat.
+	Table bool `json:"table,omitempty"`
+
+	// Preview shows the picker's preview pane at startup.
+	Preview bool `json:"preview,omitempty"`
+
+	// Workspace lists repositories, besides the current one, that the
+	// picker opens in tabs. A leading ~/ stands for the home directory.
+	Workspace []string `json:"workspace,omitempty"`
+
+	// Repos lists repositories the picker offers to switch to, besides
+	// those in Workspace and those recently switched branches in.
+	Repos []string `json:"repos,omitempty"`
+
+	// Mouse sets how the picker uses the mouse.
+	Mouse Mouse `json:"mouse,omitempty"`
+
+	// DiffPager is the pager, e.g. "delta", and DiffTool the external diff
+	// command, e.g. "difft", that show the diffs the picker opens.
+	DiffPager string `json:"diffPager,omitempty"`
+	DiffTool  string `json:"diffTool,omitempty"`
+
+	// Scope is the default branch scope: local, remote or all.
+	Scope string `json:"scope,omitempty"`
+
+	// Sort is the default ordering, "frecency" (the default), "name",
+	// "recency" or "commits", optionally suffixed with ":asc" or ":desc"
+	// (see ParseSort).
+	Sort string `json:"sort,omitempty"`
+
+	// Match is the default pattern matching mode: contains, glob, regex or
+	// fuzzy.
+	Match string `json:"match,omitempty"`
+
+	// Exclude lists globs of branches hidden from every listing, e.g.
+	// ["dependabot/*", "renovate/*"].
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Protected lists globs of branches that tools acting for the user (see
+	// package mcp) refuse to delete, e.g. ["release/*"]. The default branch
+	// is always protected.
+	Protected []string `json:"protected,omitempty"`
+
+	// IssueBranch is a text/template naming branches created for GitHub
+	// issues, with the fields Number, Title and Labels and the functions of
+	// package tmpl, e.g. `{{.Number}}-{{.Title | slug}}`.
+	IssueBranch string `json:"issueBranch,omitempty"`
+
+	// WorktreePath is a text/template for the directory the picker's
+	// worktree key checks a branch out in, with the fields Repo (the main
+	// working tree), RepoName and Branch and the functions of package tmpl.
+	// Relative paths are taken from Repo's parent.
+	WorktreePath string `json:"worktreePath,omitempty"`
+
+	// WorktreeOpen is a shell command run in a new worktree, e.g. "code .".
+	// Without it the shell is asked to change into the worktree instead
+	// (see "gotobranch init").
+	WorktreeOpen string `json:"worktreeOpen,omitempty"`
+
+	// Hooks are shell commands run around switching and deleting branches.
+	Hooks Hooks `json:"hooks,omitempty"`
+
+	// Theme names the TUI color theme.
+	Theme string `json:"theme,omitempty"`
+
+	// GitBin is the git executable to run.
+	GitBin string `json:"gitBin,omitempty"`
+
+	// LockWait is how long to keep retrying git commands that fail because
+	// another git process holds a lock in the repository, e.g. "10s"; "0"
+	// fails at once. Empty keeps the default (see core.LockWait).
+	LockWait string `json:"lockWait,omitempty"`
+
+	// LocalTimeout and NetworkTimeout bound how long a git command may run,
+	// e.g. "1m": NetworkTimeout those talking to remotes, LocalTimeout the
+	// rest. "0" means no limit; empty keeps the defaults (see
+	// core.LocalTimeout).
+	LocalTimeout   string `json:"localTimeout,omitempty"`
+	NetworkTimeout string `json:"networkTimeout,omitempty"`
+
+	// CheckUpdates opts in to checking GitHub for a newer release when the
+	// picker starts; the result is shown in the footer.
+	CheckUpdates bool `json:"checkUpdates,omitempty"`
+
+	// PullRequests looks up the GitHub pull request of each branch (see
+	// package github) and shows it in the picker and JSON output.
+	PullRequests bool `json:"pullRequests,omitempty"`
+
+	// CIStatus looks up the CI status of each branch's head commit (see
+	// package ci) and shows it in the picker and JSON output.
+	CIStatus bool `json:"ciStatus,omitempty"`
+
+	// Columns are computed columns shown in the picker and available to
+	// templates as {{.Column "name"}}, e.g. a ticket taken from the name:
+	// {"name": "ticket", "template": "{{match \"[A-Z]+-[0-9]+\" .Name}}"}.
+	Columns []Column `json:"columns,omitempty"`
+
+	// ColumnCommands are the programs, besides git, that columns may run.
+	ColumnCommands []string `json:"columnCommands,omitempty"`
+
+	// Plugins enables the plugins with these names: executables named
+	// gotobranch-<name> on PATH adding actions and columns to the picker
+	// (see package plugin).
+	Plugins []string `json:"plugins,omitempty"`
+
+	// BranchTemplates are named text/templates generating the names of new
+	// branches from variables prompted for, e.g. "feature":
+	// "feat/{{ticket}}-{{.summary | slug}}" (see tmpl.ParseName).
+	BranchTemplates map[string]string `json:"branchTemplates,omitempty"`
+
+	// NamePolicy is a regular expression names of newly created and renamed
+	// branches must match, e.g. "^(feat|fix|chore)/[a-z0-9-]+$".
+	NamePolicy string `json:"namePolicy,omitempty"`
+
+	// NamePolicyWarn highlights existing branches not following NamePolicy
+	// in the picker.
+	NamePolicyWarn bool `json:"namePolicyWarn,omitempty"`
+
+	// StaleDays is how many days without commits make the picker color a
+	// branch as stale: 90 when 0, never when negative.
+	StaleDays int `json:"staleDays,omitempty"`
+
+	// Signatures verifies the signature of each branch's head commit and
+	// shows it in the picker and JSON output.
+	Signatures bool `json:"signatures,omitempty"`
+
+	// Commits counts each branch's commits that the default branch lacks
+	// and shows the count in the picker and JSON output.
+	Commits bool `json:"commits,omitempty"`
+
+	// NoSquashMerges counts only branches reachable from the base as
+	// merged, skipping the detection of squash-merged ones (see
+	// core.SquashMerges).
+	NoSquashMerges bool `json:"noSquashMerges,omitempty"`
+
+	// NoTUI prints a plain list instead of opening the interactive picker.
+	NoTUI bool `json:"noTui,omitempty"`
+
+	// Trace logs every git command: "1" or "stderr" for standard error,
+	// anything else is a file to append to.
+	Trace string `json:"trace,omitempty"`
+
+	// Log is the least severe level of the diagnostics written to the log
+	// file (see package logging): debug, info, warn or error. Unset, none
+	// are.
+	Log string `json:"log,omitempty"`
+
+	// Metrics records how long commands and the git commands they run take
+	// and which features are used, locally (see package metrics).
+	Metrics bool `json:"metrics,omitempty"`
+
+	// Locale is the language of messages, e.g. "de"; when unset it follows
+	// LC_ALL, LC_MESSAGES or LANG (see package i18n).
+	Locale string `json:"locale,omitempty"`
+
+	// Profiles are named filter/sort/scope combinations selected with
+	// --profile or cycled through in the picker.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+
+	// DefaultProfile names the profile applied when --profile is not given.
+	DefaultProfile string `json:"profile,omitempty"`
+
+	// Repo is the repository to operate on. It is only read from the
+	// environment; a fixed path in the user config would make no sense.
+	Repo string `json:"-"`
+}
+
+// Hooks are shell commands run in the repository's top-level directory with
+// the branch in GOTOBRANCH_BRANCH (see package hooks). Each list runs in
+// order, stopping at the first failure.
+type Hooks struct {
+	PreSwitch  []string `json:"preSwitch,omitempty"`  // before switching; GOTOBRANCH_PREVIOUS_BRANCH is the current branch
+	PostSwitch []string `json:"postSwitch,omitempty"` // after switching, e.g. "npm install"
+	PostDelete []string `json:"postDelete,omitempty"` // after deleting; GOTOBRANCH_SHA is where the branch pointed
+
+	// AbortOnFailure makes a failing preSwitch hook cancel the switch and a
+	// failing post hook fail the command. Otherwise failures are only
+	// reported.
+	AbortOnFailure bool `json:"abortOnFailure,omitempty"`
+}
+
+// Column is a computed column (see tmpl.Column).
+type Column struct {
+	Name     string `json:"name"`            // referred to by templates; letters, digits, - and _
+	Title    string `json:"title,omitempty"` // its header in the table layout; Name when empty
+	Width    int    `json:"width,omitempty"` // in terminal columns; 12 when 0
+	Template string `json:"template"`        // rendered per branch, with the command's output as .Output
+
+	// Command, if set, is run per branch in the repository, its arguments
+	// rendered like Template, e.g. ["git", "notes", "show", "{{.HeadCommitSHA}}"].
+	// Its program must be git or one of ColumnCommands.
+	Command []string `json:"command,omitempty"`
+}
+
+// Mouse configures the picker's mouse handling.
+type Mouse struct {
+	// Off leaves the mouse to the terminal, so that text can be selected
+	// and copied as usual; the picker then sees no clicks or wheel turns.
+	Off bool `json:"off,omitempty"`
+
+	// ScrollLines is how many rows a notch of the wheel moves: 1 when 0.
+	ScrollLines int `json:"scrollLines,omitempty"`
+
+	// Scroll is what the wheel moves: "cursor" (the default) or "view",
+	// which scrolls the list and leaves the cursor where it is.
+	Scroll string `json:"scroll,omitempty"`
+}
+
+// Profile is a saved view of the branch list. Empty fields leave the
+// corresponding setting alone.
+type Profile struct {
+	Query   string   `json:"query,omitempty"`   // filter query, e.g. "author:alice merged:false"
+	Author  string   `json:"author,omitempty"`  // as --author, e.g. "me"
+	Since   string   `json:"since,omitempty"`   // as --since, e.g. "2w"
+	Until   string   `json:"until,omitempty"`   // as --until, e.g. "6mo"
+	Scope   string   `json:"scope,omitempty"`   // local, remote or all
+	Sort    string   `json:"sort,omitempty"`    // as Config.Sort
+	Match   string   `json:"match,omitempty"`   // as Config.Match
+	Exclude []string `json:"exclude,omitempty"` // replaces Config.Exclude when set
+}
+
+// RepoFile is the name of the per-repository config file, looked up in the
+// repository's top-level directory. Only its profiles are used.
+const RepoFile = ".gotobranch.json"
+
+// Path returns the location of the user config file.
+func Path() (string, error) {
+	dir, err := paths.Config()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load reads the user config file. A missing file is not an error.
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the config at path. A missing file is not an error.
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadRepo reads the RepoFile of the repository containing dir, if any.
+// The repository root is the nearest directory, starting at dir, that has a
+// .git entry. Settings other than profiles are ignored so that a cloned
+// repository cannot change how gotobranch runs (e.g. its gitBin).
+func LoadRepo(dir string) (Config, error) {
+	path, err := RepoPath(dir)
+	if err != nil || path == "" {
+		return Config{}, err
+	}
+	cfg, err := LoadFile(path)
+	return Config{Profiles: cfg.Profiles}, err
+}
+
+// RepoPath returns where the RepoFile of the repository containing dir
+// would be, or "" when dir is not in a repository.
+func RepoPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return filepath.Join(dir, RepoFile), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// MergeProfiles adds the profiles of repo to c. Profiles the user defined
+// themselves take precedence over shared ones with the same name.
+func (c *Config) MergeProfiles(repo Config) {
+	for name, p := range repo.Profiles {
+		if _, ok := c.Profiles[name]; ok {
+			continue
+		}
+		if c.Profiles == nil {
+			c.Profiles = map[string]Profile{}
+		}
+		c.Profiles[name] = p
+	}
+}
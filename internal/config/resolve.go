@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/logging"
+)
+
+// Environment variables consulted by ApplyEnv.
+const (
+	EnvRepo     = "GOTOBRANCH_REPO"
+	EnvScope    = "GOTOBRANCH_SCOPE"
+	EnvSort     = "GOTOBRANCH_SORT"
+	EnvTheme    = "GOTOBRANCH_THEME"
+	EnvGitBin   = "GOTOBRANCH_GIT_BIN"
+	EnvLockWait = "GOTOBRANCH_LOCK_WAIT"
+	EnvNoTUI    = "GOTOBRANCH_NO_TUI"
+	EnvTrace    = "GOTOBRANCH_TRACE"
+	EnvLog      = "GOTOBRANCH_LOG"
+	EnvProfile  = "GOTOBRANCH_PROFILE"
+
+	EnvLocalTimeout   = "GOTOBRANCH_LOCAL_TIMEOUT"
+	EnvNetworkTimeout = "GOTOBRANCH_NETWORK_TIMEOUT"
+)
+
+// Resolve loads the user config file and overlays the environment.
+func Resolve() (Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return cfg, err
+	}
+	if err := ApplyEnv(&cfg, os.Getenv); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// ApplyEnv overrides cfg with any GOTOBRANCH_* variables set (non-empty) in
+// getenv.
+func ApplyEnv(cfg *Config, getenv func(string) string) error {
+	for name, dst := range map[string]*string{
+		EnvRepo:     &cfg.Repo,
+		EnvScope:    &cfg.Scope,
+		EnvSort:     &cfg.Sort,
+		EnvTheme:    &cfg.Theme,
+		EnvGitBin:   &cfg.GitBin,
+		EnvLockWait: &cfg.LockWait,
+		EnvTrace:    &cfg.Trace,
+		EnvLog:      &cfg.Log,
+		EnvProfile:  &cfg.DefaultProfile,
+
+		EnvLocalTimeout:   &cfg.LocalTimeout,
+		EnvNetworkTimeout: &cfg.NetworkTimeout,
+	} {
+		if v := getenv(name); v != "" {
+			*dst = v
+		}
+	}
+	if v := getenv(EnvNoTUI); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", EnvNoTUI, v)
+		}
+		cfg.NoTUI = b
+	}
+	return nil
+}
+
+// Validate reports values that can never be valid: the first of the
+// problems Check would report.
+func (c Config) Validate() error {
+	if ps := c.problems(); len(ps) > 0 {
+		return ps[0]
+	}
+	return nil
+}
+
+// problems returns the values of c that can never be valid, in the order
+// of c's fields.
+func (c Config) problems() []Problem {
+	var ps []Problem
+	addAt := func(path, key, format string, a ...any) {
+		ps = append(ps, Problem{Key: key, Err: fmt.Errorf(format, a...), path: path})
+	}
+	add := func(key, format string, a ...any) { addAt(key, key, format, a...) }
+	switch c.Scope {
+	case "", "local", "remote", "all":
+	default:
+		add("scope", "%q is not one of local, remote, all", c.Scope)
+	}
+	switch c.Mouse.Scroll {
+	case "", "cursor", "view":
+	default:
+		add("mouse.scroll", "%q is not one of cursor, view", c.Mouse.Scroll)
+	}
+	if c.Mouse.ScrollLines < 0 {
+		add("mouse.scrollLines", "%d is negative", c.Mouse.ScrollLines)
+	}
+	if _, _, err := ParseSort(c.Sort); c.Sort != "" && err != nil {
+		add("sort", "%w", err)
+	}
+	if _, err := logging.ParseLevel(c.Log); c.Log != "" && err != nil {
+		add("log", "%q is not one of debug, info, warn, error", c.Log)
+	}
+	if !i18n.Supported(c.Locale) {
+		add("locale", "no translation into %q; use one of %s", c.Locale, strings.Join(i18n.Locales(), ", "))
+	}
+	for _, d := range []struct{ key, v string }{
+		{"lockWait", c.LockWait},
+		{"localTimeout", c.LocalTimeout},
+		{"networkTimeout", c.NetworkTimeout},
+	} {
+		if t, err := time.ParseDuration(d.v); d.v != "" && (err != nil || t < 0) {
+			add(d.key, "%q is not a duration such as 10s", d.v)
+		}
+	}
+	seen := map[string]bool{}
+	for i, col := range c.Columns {
+		at, key := fmt.Sprintf("columns.%d", i), "columns."+col.Name
+		switch {
+		case !columnName.MatchString(col.Name):
+			addAt(at+".name", "columns", "%q is not a column name; use letters, digits, - and _", col.Name)
+		case seen[col.Name]:
+			addAt(at+".name", "columns", "%s is defined twice", col.Name)
+		case col.Template == "":
+			addAt(at, key, "template is empty")
+		case col.Width < 0:
+			addAt(at+".width", key, "width %d is negative", col.Width)
+		case len(col.Command) > 0 && col.Command[0] != "git" && !slices.Contains(c.ColumnCommands, col.Command[0]):
+			addAt(at+".command", key, "%s is not git or one of columnCommands", col.Command[0])
+		}
+		seen[col.Name] = true
+	}
+	for i, name := range c.Plugins {
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			addAt(fmt.Sprintf("plugins.%d", i), "plugins", "%q is not a plugin name", name)
+		}
+	}
+	if _, err := regexp.Compile(c.NamePolicy); err != nil {
+		add("namePolicy", "%w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		p := c.Profiles[name]
+		for _, pp := range (Config{Scope: p.Scope, Sort: p.Sort}).problems() {
+			ps = append(ps, Problem{Key: "profiles." + name, Err: pp, path: "profiles." + name + "." + pp.path})
+		}
+	}
+	return ps
+}
+
+// columnName matches the names of computed columns, which cannot be
+// mistaken for those of plugin columns, which hold a dot.
+var columnName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ParseSort splits a sort spec such as "name", "recency:asc" into its field
+// and direction. Without a direction, name sorts ascending, and recency
+// (newest first), commits (most commits first) and frecency (most used
+// first) descending.
+func ParseSort(spec string) (by, dir string, err error) {
+	by, dir, _ = strings.Cut(spec, ":")
+	switch by {
+	case "name":
+		if dir == "" {
+			dir = "asc"
+		}
+	case "recency", "commits", "frecency":
+		if dir == "" {
+			dir = "desc"
+		}
+	default:
+		return "", "", fmt.Errorf("unknown sort %q; use frecency, name, recency or commits", by)
+	}
+	if dir != "asc" && dir != "desc" {
+		return "", "", fmt.Errorf("unknown sort direction %q; use asc or desc", dir)
+	}
+	return by, dir, nil
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// env returns a getenv reading vars.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestApplyEnv(t *testing.T) {
+	file := Config{
+		Scope: "local", Sort: "name", Theme: "mono", GitBin: "/usr/bin/git",
+		LockWait: "1s", Trace: "stderr", Log: "warn", DefaultProfile: "file",
+		LocalTimeout: "5s", NetworkTimeout: "30s",
+	}
+	tests := []struct {
+		name, value string
+		field       func(Config) any
+		want        any
+	}{
+		{EnvRepo, "/src/app", func(c Config) any { return c.Repo }, "/src/app"},
+		{EnvScope, "remote", func(c Config) any { return c.Scope }, "remote"},
+		{EnvSort, "recency:asc", func(c Config) any { return c.Sort }, "recency:asc"},
+		{EnvTheme, "high-contrast", func(c Config) any { return c.Theme }, "high-contrast"},
+		{EnvGitBin, "/opt/git", func(c Config) any { return c.GitBin }, "/opt/git"},
+		{EnvLockWait, "3s", func(c Config) any { return c.LockWait }, "3s"},
+		{EnvNoTUI, "true", func(c Config) any { return c.NoTUI }, true},
+		{EnvNoTUI, "0", func(c Config) any { return c.NoTUI }, false},
+		{EnvTrace, "/tmp/trace.log", func(c Config) any { return c.Trace }, "/tmp/trace.log"},
+		{EnvLog, "debug", func(c Config) any { return c.Log }, "debug"},
+		{EnvProfile, "mine", func(c Config) any { return c.DefaultProfile }, "mine"},
+		{EnvLocalTimeout, "10s", func(c Config) any { return c.LocalTimeout }, "10s"},
+		{EnvNetworkTimeout, "2m", func(c Config) any { return c.NetworkTimeout }, "2m"},
+		// Empty variables count as unset.
+		{EnvScope, "", func(c Config) any { return c.Scope }, "local"},
+		{EnvSort, "", func(c Config) any { return c.Sort }, "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			cfg := file
+			if err := ApplyEnv(&cfg, env(map[string]string{tt.name: tt.value})); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.field(cfg); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name, value string
+		key         string // the setting Validate reports; empty when ApplyEnv fails
+	}{
+		{EnvNoTUI, "maybe", ""},
+		{EnvScope, "bogus", "scope"},
+		{EnvSort, "bogus", "sort"},
+		{EnvSort, "name:up", "sort"},
+		{EnvLog, "verbose", "log"},
+		{EnvLockWait, "soon", "lockWait"},
+		{EnvLocalTimeout, "-1s", "localTimeout"},
+		{EnvNetworkTimeout, "forever", "networkTimeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			var cfg Config
+			err := ApplyEnv(&cfg, env(map[string]string{tt.name: tt.value}))
+			if tt.key == "" {
+				if err == nil || !strings.Contains(err.Error(), tt.name) {
+					t.Errorf("ApplyEnv: got %v, want an error naming %s", err, tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyEnv: %v", err)
+			}
+			err = cfg.Validate()
+			if p, ok := err.(Problem); !ok || p.Key != tt.key {
+				t.Errorf("Validate: got %v, want a problem with %s", err, tt.key)
+			}
+		})
+	}
+}
+
+// TestPrecedence checks that the environment overrides the config file,
+// and flags, which take their defaults from the result as the command line
+// does, override both.
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"scope": "local", "sort": "name", "theme": "mono"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		env   map[string]string
+		flags []string
+		want  Config
+	}{
+		{nil, nil, Config{Scope: "local", Sort: "name", Theme: "mono"}},
+		{map[string]string{EnvScope: "remote", EnvSort: "recency"}, nil, Config{Scope: "remote", Sort: "recency", Theme: "mono"}},
+		{map[string]string{EnvScope: "remote"}, []string{"--scope=all"}, Config{Scope: "all", Sort: "name", Theme: "mono"}},
+		{map[string]string{EnvScope: "remote", EnvSort: "recency"}, []string{"--sort", "commits", "--theme=default"}, Config{Scope: "remote", Sort: "commits", Theme: "default"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.flags, " "), func(t *testing.T) {
+			cfg, err := LoadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ApplyEnv(&cfg, env(tt.env)); err != nil {
+				t.Fatal(err)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.StringVar(&cfg.Scope, "scope", cfg.Scope, "")
+			fs.StringVar(&cfg.Sort, "sort", cfg.Sort, "")
+			fs.StringVar(&cfg.Theme, "theme", cfg.Theme, "")
+			if err := fs.Parse(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Scope != tt.want.Scope || cfg.Sort != tt.want.Sort || cfg.Theme != tt.want.Theme {
+				t.Errorf("got scope %q, sort %q, theme %q; want %q, %q, %q",
+					cfg.Scope, cfg.Sort, cfg.Theme, tt.want.Scope, tt.want.Sort, tt.want.Theme)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		spec, by, dir string
+		ok            bool
+	}{
+		{"name", "name", "asc", true},
+		{"name:desc", "name", "desc", true},
+		{"recency", "recency", "desc", true},
+		{"recency:asc", "recency", "asc", true},
+		{"commits", "commits", "desc", true},
+		{"frecency", "frecency", "desc", true},
+		{"", "", "", false},
+		{"bogus", "", "", false},
+		{"name:up", "", "", false},
+	}
+	for _, tt := range tests {
+		by, dir, err := ParseSort(tt.spec)
+		if (err == nil) != tt.ok || by != tt.by || dir != tt.dir {
+			t.Errorf("ParseSort(%q) = %q, %q, %v; want %q, %q, ok %v", tt.spec, by, dir, err, tt.by, tt.dir, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Setting is a setting in effect, and where its value came from.
+type Setting struct {
+	Key    string          `json:"key"` // its JSON name, e.g. "sort"; profiles are listed one by one, e.g. "profiles.mine"
+	Value  json.RawMessage `json:"value"`
+	Source string          `json:"source"` // the file it was read from, or the environment variable
+}
+
+// envKeys maps the environment variables ApplyEnv reads to the settings
+// they override.
+var envKeys = map[string]string{
+	EnvRepo:           "repo",
+	EnvScope:          "scope",
+	EnvSort:           "sort",
+	EnvTheme:          "theme",
+	EnvGitBin:         "gitBin",
+	EnvLockWait:       "lockWait",
+	EnvNoTUI:          "noTui",
+	EnvTrace:          "trace",
+	EnvLog:            "log",
+	EnvProfile:        "profile",
+	EnvLocalTimeout:   "localTimeout",
+	EnvNetworkTimeout: "networkTimeout",
+}
+
+// Settings returns the settings in effect in the repository containing
+// dir, as Resolve and LoadRepo would merge them, in the order of Config's
+// fields. Settings left at their defaults are not listed.
+func Settings(dir string, getenv func(string) string) ([]Setting, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	user := maps.Clone(cfg.Profiles)
+	if err := ApplyEnv(&cfg, getenv); err != nil {
+		return nil, err
+	}
+	repoPath, err := RepoPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if repoPath != "" {
+		shared, err := LoadRepo(dir)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MergeProfiles(shared)
+	}
+	source := func(key string) string {
+		for env, k := range envKeys {
+			if k == key && getenv(env) != "" {
+				return env
+			}
+		}
+		return path
+	}
+
+	var res []Setting
+	add := func(key string, v any, source string) {
+		b, _ := json.Marshal(v)
+		res = append(res, Setting{Key: key, Value: b, Source: source})
+	}
+	if cfg.Repo != "" {
+		add("repo", cfg.Repo, EnvRepo)
+	}
+	t, v := reflect.TypeOf(cfg), reflect.ValueOf(cfg)
+	for i := range t.NumField() {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if key == "-" || v.Field(i).IsZero() {
+			continue
+		}
+		if key != "profiles" {
+			add(key, v.Field(i).Interface(), source(key))
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+			from := repoPath
+			if _, ok := user[name]; ok {
+				from = path
+			}
+			add("profiles."+name, cfg.Profiles[name], from)
+		}
+	}
+	return res, nil
+}
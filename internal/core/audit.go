@@ -0,0 +1,75 @@
+package core
+
+import (
+	"strings"
+	"sync"
+)
+
+// Kinds of Operation, besides ActionSwitch, ActionDelete and ActionRename.
+const (
+	OpCreate      = "create"       // a branch created, and switched to unless by Track or Undo
+	OpDetach      = "detach"       // a ref checked out with a detached HEAD
+	OpFastForward = "fast-forward" // a branch moved to its upstream
+	OpReset       = "reset"        // a branch reset to its upstream
+	OpCherryPick  = "cherry-pick"  // commits applied onto the current branch
+	OpPush        = "push"         // a branch pushed to a remote
+)
+
+// Operation is a change gotobranch made to a repository's branches, for
+// the audit log (see SetAudit). Unlike an Action it is only ever recorded,
+// never undone.
+type Operation struct {
+	Op       string `json:"op"`                 // ActionSwitch, ActionDelete, ActionRename or one of the Op* constants
+	Branch   string `json:"branch"`             // the branch switched to, created, deleted, moved, pushed, or renamed to
+	Previous string `json:"previous,omitempty"` // switches: the branch switched away from; renames: the old name
+	Remote   string `json:"remote,omitempty"`   // pushes: the remote pushed to
+	Old      string `json:"old,omitempty"`      // the commit the branch (HEAD for switches) was at before
+	New      string `json:"new,omitempty"`      // the commit it is at after
+	Force    bool   `json:"force,omitempty"`
+}
+
+var (
+	auditMu sync.Mutex
+	auditFn func(repoPath string, op Operation)
+)
+
+// SetAudit makes every change gotobranch makes to branches call record
+// once it is done: switches and checkouts, creates, deletes, renames,
+// fast-forwards, resets, cherry-picks and pushes, undos included. A nil
+// record turns auditing off, which also saves the git commands looking up
+// the commits involved.
+func SetAudit(record func(repoPath string, op Operation)) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	auditFn = record
+}
+
+func auditing() bool {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	return auditFn != nil
+}
+
+func audit(repoPath string, op Operation) {
+	logger().Info("operation", "repo", repoPath, "op", op.Op, "branch", op.Branch, "previous", op.Previous,
+		"remote", op.Remote, "old", op.Old, "new", op.New, "force", op.Force)
+	auditMu.Lock()
+	record := auditFn
+	auditMu.Unlock()
+	if record != nil {
+		record(repoPath, op)
+	}
+}
+
+// auditSHA returns the commit ref points to, or "" when it does not exist
+// or no audit log is kept.
+func auditSHA(repoPath, ref string) string {
+	if !auditing() {
+		return ""
+	}
+	out, err := git(repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
@@ -3,8 +3,10 @@ package core
 import (
 	"errors"
 	"fmt"
-	"os/exec"
+	"path"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -23,39 +25,131 @@ const (
 )
 
 // Branch represents a git branch with minimal metadata.
+// JSON field names follow the OpenAPI schema.
 type Branch struct {
-	Name              string // short name, e.g., feature/x
-	FullRef           string // e.g., refs/heads/feature/x or refs/remotes/origin/x
-	IsCurrent         bool
-	IsRemote          bool
-	Upstream          *string
-	HeadCommitSHA     *string
-	HeadCommitAt      *time.Time
-	LastCommitMessage *string
+	Name              string     `json:"name"`    // short name, e.g., feature/x
+	FullRef           string     `json:"fullRef"` // e.g., refs/heads/feature/x or refs/remotes/origin/x
+	IsCurrent         bool       `json:"isCurrent"`
+	IsRemote          bool       `json:"isRemote"`
+	Upstream          *string    `json:"upstream"`
+	HeadCommitSHA     *string    `json:"headCommitSha"`
+	HeadCommitAt      *time.Time `json:"headCommitAt"`
+	LastCommitMessage *string    `json:"lastCommitMessage"`
+	Author            *string    `json:"author"`      // head commit author name
+	AuthorEmail       *string    `json:"authorEmail"` // without angle brackets
+
+	// PullRequest is the pull request opened from the branch, when pull
+	// requests were looked up (see ListBranchesRequest.PullRequests).
+	PullRequest *PullRequest `json:"pullRequest,omitempty"`
+
+	// CIStatus is the CI result for the head commit (one of the CI*
+	// constants), when CI statuses were looked up.
+	CIStatus string `json:"ciStatus,omitempty"`
+
+	// Signature is the head commit's signature, when signatures were
+	// looked up (see ListBranchesRequest.Signatures).
+	Signature *Signature `json:"signature,omitempty"`
+
+	// Divergence compares the branch with HEAD, when divergences were
+	// looked up (see ListBranchesRequest.Divergences).
+	Divergence *Divergence `json:"divergence,omitempty"`
+
+	// Commits counts the commits on the branch that the default branch
+	// lacks, up to MaxCommitCount, when counted (see
+	// ListBranchesRequest.Commits).
+	Commits *int `json:"commits,omitempty"`
+
+	// Tracking compares a local branch with its upstream, when looked up
+	// (see ListBranchesRequest.Tracking) and it has one.
+	Tracking *Tracking `json:"tracking,omitempty"`
+
+	// Shallow is set when the branch's history is cut off by a shallow
+	// clone, so counts and merge status derived from it may be wrong.
+	Shallow bool `json:"shallow,omitempty"`
+
+	// Frecency ranks how often and how recently the branch was switched
+	// to, when sorting by it (see SetFrecency).
+	Frecency float64 `json:"frecency,omitempty"`
+
+	// Columns are the values of extra columns, such as those of plugins,
+	// by column id, when looked up (see ListBranchesRequest.Columns).
+	Columns map[string]string `json:"columns,omitempty"`
+}
+
+// CI statuses of a commit. A commit without any checks has no status.
+const (
+	CISuccess = "success"
+	CIFailure = "failure"
+	CIPending = "pending"
+)
+
+// PullRequest is a code review request on the hosting service whose head is
+// a branch.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	State  string `json:"state"`  // open, closed or merged
+	Review string `json:"review"` // approved, changes_requested, review_required or empty
+	URL    string `json:"url"`
 }
 
 // ListBranchesRequest mirrors listBranches params.
 type ListBranchesRequest struct {
 	RepoPath string
 	Pattern  string
+	Query    string    // filter query (see CompileQuery), applied on top of Pattern
+	Match    MatchMode // how Pattern and query words are applied; contains by default
+	Exclude  []string  // globs hiding branches (see Excluded)
 	Scope    Scope
-	SortBy   string // "name" | "recency"
+	SortBy   string // "name" | "recency" | "commits" | "frecency"
 	SortDir  string // "asc" | "desc"
 	Page     int
 	PageSize int
+
+	// PullRequests, keyed by head branch name, are attached to the listed
+	// branches. Remote branches are looked up without their remote name.
+	PullRequests map[string]PullRequest
+
+	// CIStatuses, keyed by commit SHA, are attached to the listed branches
+	// by their head commit.
+	CIStatuses map[string]string
+
+	// Divergences, keyed by commit SHA, are attached to the listed
+	// branches by their head commit (see Divergences).
+	Divergences map[string]Divergence
+
+	// Columns, keyed by full ref, are the extra column values attached to
+	// the listed branches.
+	Columns map[string]map[string]string
+
+	// Signatures verifies the signatures of the listed branches' head
+	// commits. It costs a gpg or ssh-keygen run per signed commit.
+	Signatures bool
+
+	// Commits counts the commits each listed branch has that the default
+	// branch lacks (see CommitCounts). Sorting by "commits" counts every
+	// branch, not just the listed ones.
+	Commits bool
+
+	// Tracking compares the listed local branches with their upstreams.
+	Tracking bool
 }
 
 // ListBranchesResponse mirrors the OpenAPI response.
 type ListBranchesResponse struct {
-	Items    []Branch
-	Page     int
-	PageSize int
-	Total    int
-	HasPrev  bool
-	HasNext  bool
+	Items    []Branch `json:"items"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"pageSize"`
+	Total    int      `json:"total"`
+	HasPrev  bool     `json:"hasPrev"`
+	HasNext  bool     `json:"hasNext"`
 }
 
-// GetCurrentBranch returns the current branch, or an error if detached.
+// ErrDetachedHead is returned by GetCurrentBranch when HEAD is not on a
+// branch.
+var ErrDetachedHead = errors.New("detached HEAD")
+
+// GetCurrentBranch returns the current branch, or ErrDetachedHead.
 func GetCurrentBranch(repoPath string) (*Branch, error) {
 	name, err := git(repoPath, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
@@ -63,7 +157,7 @@ func GetCurrentBranch(repoPath string) (*Branch, error) {
 	}
 	name = strings.TrimSpace(name)
 	if name == "HEAD" {
-		return nil, errors.New("detached HEAD")
+		return nil, ErrDetachedHead
 	}
 	return &Branch{
 		Name:      name,
@@ -73,36 +167,107 @@ func GetCurrentBranch(repoPath string) (*Branch, error) {
 	}, nil
 }
 
+// HeadState is what HEAD points to: a branch, or a commit when detached.
+type HeadState struct {
+	Branch string // "" when detached
+	SHA    string // "" on a branch without commits yet
+}
+
+// Detached reports whether HEAD is not on a branch.
+func (h HeadState) Detached() bool {
+	return h.Branch == ""
+}
+
+// String describes h as git does, e.g. "main" or "(detached @ 1a2b3c4)".
+func (h HeadState) String() string {
+	if !h.Detached() {
+		return h.Branch
+	}
+	sha := h.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return fmt.Sprintf("(detached @ %s)", sha)
+}
+
+// Head returns the state of HEAD.
+func Head(repoPath string) (HeadState, error) {
+	var h HeadState
+	if out, err := git(repoPath, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		h.Branch = strings.TrimSpace(out)
+	}
+	out, err := git(repoPath, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err == nil {
+		h.SHA = strings.TrimSpace(out)
+	} else if h.Detached() {
+		return h, err
+	}
+	return h, nil
+}
+
 // ListBranches lists branches with filtering and pagination.
 func ListBranches(req ListBranchesRequest) (ListBranchesResponse, error) {
-	if req.Page <= 0 {
-		req.Page = 1
+	if _, err := NewMatcher(req.Match, req.Pattern); err != nil {
+		return ListBranchesResponse{}, err
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = 50
+	if _, err := CompileQuery(req.RepoPath, req.Query, req.Match); err != nil {
+		return ListBranchesResponse{}, err
 	}
+	if req.SortBy == "" {
+		req.SortBy = "recency"
+	}
+	branches, err := collectBranches(req.RepoPath, req.Scope)
+	if err != nil {
+		return ListBranchesResponse{}, err
+	}
+	switch req.SortBy {
+	case "commits":
+		addCommitCounts(req.RepoPath, branches)
+	case "frecency":
+		addFrecency(req.RepoPath, branches)
+	}
+	resp := PageBranches(branches, req)
+	if req.Commits && req.SortBy != "commits" {
+		addCommitCounts(req.RepoPath, resp.Items)
+	}
+	if req.Signatures {
+		if err := addSignatures(req.RepoPath, resp.Items); err != nil {
+			return resp, err
+		}
+	}
+	if req.Tracking {
+		if err := addTracking(req.RepoPath, resp.Items); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
 
+// collectBranches reads all branches in scope and marks the current one.
+func collectBranches(repoPath string, scope Scope) ([]Branch, error) {
 	var branches []Branch
 
 	// Local branches
-	if req.Scope == ScopeLocal || req.Scope == ScopeAll {
-		out, err := git(req.RepoPath, "for-each-ref", "--format=%(refname)\t%(objectname)\t%(committerdate:iso-strict)\t%(contents:subject)", "refs/heads/")
+	if scope == ScopeLocal || scope == ScopeAll {
+		out, err := git(repoPath, "for-each-ref", "--format="+refFormat, "refs/heads/")
 		if err != nil {
-			return ListBranchesResponse{}, err
+			return nil, err
 		}
 		branches = append(branches, parseForEachRef(out, false)...)
 	}
 	// Remote branches
-	if req.Scope == ScopeRemote || req.Scope == ScopeAll {
-		out, err := git(req.RepoPath, "for-each-ref", "--format=%(refname)\t%(objectname)\t%(committerdate:iso-strict)\t%(contents:subject)", "refs/remotes/")
+	if scope == ScopeRemote || scope == ScopeAll {
+		out, err := git(repoPath, "for-each-ref", "--format="+refFormat, "refs/remotes/")
 		if err != nil {
-			return ListBranchesResponse{}, err
+			return nil, err
 		}
 		branches = append(branches, parseForEachRef(out, true)...)
 	}
 
+	markShallow(repoPath, branches)
+
 	// Mark current
-	if cur, err := GetCurrentBranch(req.RepoPath); err == nil {
+	if cur, err := GetCurrentBranch(repoPath); err == nil {
 		for i := range branches {
 			if !branches[i].IsRemote && branches[i].Name == cur.Name {
 				branches[i].IsCurrent = true
@@ -110,39 +275,67 @@ func ListBranches(req ListBranchesRequest) (ListBranchesResponse, error) {
 		}
 	}
 
-	// Filter by pattern (case-insensitive contains)
+	return branches, nil
+}
+
+// PageBranches applies the filtering, sorting and pagination of req to an
+// already collected set of branches. An empty SortBy keeps the input order,
+// as do branches that compare equal. The slice is modified in place.
+func PageBranches(branches []Branch, req ListBranchesRequest) ListBranchesResponse {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 50
+	}
+
+	// Filter by pattern (case-insensitive, per req.Match)
 	if req.Pattern != "" {
-		needle := strings.ToLower(req.Pattern)
+		match, err := NewMatcher(req.Match, req.Pattern)
+		if err != nil {
+			// An invalid pattern matches nothing; ListBranches reports it.
+			match = func(string) bool { return false }
+		}
 		filtered := branches[:0]
 		for _, b := range branches {
-			if strings.Contains(strings.ToLower(b.Name), needle) {
+			if match(b.Name) {
 				filtered = append(filtered, b)
 			}
 		}
 		branches = filtered
 	}
 
-	// Sort
-	sort.Slice(branches, func(i, j int) bool {
-		if req.SortBy == "name" {
-			if req.SortDir == "asc" {
-				return branches[i].Name < branches[j].Name
-			}
-			return branches[i].Name > branches[j].Name
-		}
-		// recency by HeadCommitAt (nil last)
-		var ti, tj time.Time
-		if branches[i].HeadCommitAt != nil {
-			ti = *branches[i].HeadCommitAt
+	// Filter by query
+	if req.Query != "" {
+		match, err := CompileQuery(req.RepoPath, req.Query, req.Match)
+		if err != nil {
+			// As for the pattern; ListBranches reports it.
+			match = func(Branch) bool { return false }
 		}
-		if branches[j].HeadCommitAt != nil {
-			tj = *branches[j].HeadCommitAt
+		filtered := branches[:0]
+		for _, b := range branches {
+			if match(b) {
+				filtered = append(filtered, b)
+			}
 		}
-		if req.SortDir == "asc" {
-			return ti.Before(tj)
+		branches = filtered
+	}
+
+	// Drop excluded branches
+	if len(req.Exclude) > 0 {
+		kept := branches[:0]
+		for _, b := range branches {
+			if !Excluded(b, req.Exclude) {
+				kept = append(kept, b)
+			}
 		}
-		return ti.After(tj)
-	})
+		branches = kept
+	}
+
+	// Sort
+	if req.SortBy != "" {
+		sortBranches(branches, req.SortBy, req.SortDir)
+	}
 
 	// Paginate
 	total := len(branches)
@@ -155,6 +348,34 @@ func ListBranches(req ListBranchesRequest) (ListBranchesResponse, error) {
 		end = total
 	}
 	pageItems := append([]Branch(nil), branches[start:end]...)
+	if req.PullRequests != nil {
+		for i := range pageItems {
+			if pr, ok := req.PullRequests[HeadName(pageItems[i])]; ok {
+				pageItems[i].PullRequest = &pr
+			}
+		}
+	}
+	if req.CIStatuses != nil {
+		for i := range pageItems {
+			if sha := pageItems[i].HeadCommitSHA; sha != nil {
+				pageItems[i].CIStatus = req.CIStatuses[*sha]
+			}
+		}
+	}
+	if req.Columns != nil {
+		for i := range pageItems {
+			pageItems[i].Columns = req.Columns[pageItems[i].FullRef]
+		}
+	}
+	if req.Divergences != nil {
+		for i := range pageItems {
+			if sha := pageItems[i].HeadCommitSHA; sha != nil {
+				if d, ok := req.Divergences[*sha]; ok {
+					pageItems[i].Divergence = &d
+				}
+			}
+		}
+	}
 
 	resp := ListBranchesResponse{
 		Items:    pageItems,
@@ -164,14 +385,114 @@ func ListBranches(req ListBranchesRequest) (ListBranchesResponse, error) {
 		HasPrev:  req.Page > 1,
 		HasNext:  end < total,
 	}
-	return resp, nil
+	return resp
 }
 
-// Checkout switches to a branch (optionally creating/tracking).
+// HeadName is the name b has on the remote: its own name for local
+// branches, and the name without the remote for remote-tracking ones.
+func HeadName(b Branch) string {
+	if !b.IsRemote {
+		return b.Name
+	}
+	_, name, _ := strings.Cut(b.Name, "/")
+	return name
+}
+
+// Excluded reports whether b matches any of the globs (path.Match syntax).
+// A glob also excludes everything below a matching prefix, so "archive/*"
+// hides "archive/2023/x" too. Remote branches are matched both with and
+// without their remote name, so "dependabot/*" hides origin/dependabot/npm.
+func Excluded(b Branch, globs []string) bool {
+	names := []string{b.Name}
+	if b.IsRemote {
+		if _, rest, ok := strings.Cut(b.Name, "/"); ok {
+			names = append(names, rest)
+		}
+	}
+	for _, g := range globs {
+		for _, name := range names {
+			for prefix := name; ; {
+				if ok, _ := path.Match(g, prefix); ok {
+					return true
+				}
+				i := strings.LastIndex(prefix, "/")
+				if i < 0 {
+					break
+				}
+				prefix = prefix[:i]
+			}
+		}
+	}
+	return false
+}
+
+// sortBranches orders branches by name, commit count, frecency or recency
+// (HeadCommitAt), keeping the input order of ties. Branches as frecent
+// are ordered by recency.
+func sortBranches(branches []Branch, sortBy, sortDir string) {
+	sort.SliceStable(branches, func(i, j int) bool {
+		switch sortBy {
+		case "frecency":
+			fi, fj := branches[i].Frecency, branches[j].Frecency
+			if fi != fj {
+				if sortDir == "asc" {
+					return fi < fj
+				}
+				return fi > fj
+			}
+		case "name":
+			if sortDir == "asc" {
+				return branches[i].Name < branches[j].Name
+			}
+			return branches[i].Name > branches[j].Name
+		case "commits":
+			// uncounted branches last
+			ci, cj := -1, -1
+			if branches[i].Commits != nil {
+				ci = *branches[i].Commits
+			}
+			if branches[j].Commits != nil {
+				cj = *branches[j].Commits
+			}
+			if ci < 0 || cj < 0 {
+				return cj < 0 && ci >= 0
+			}
+			if sortDir == "asc" {
+				return ci < cj
+			}
+			return ci > cj
+		}
+		// recency by HeadCommitAt (nil last)
+		var ti, tj time.Time
+		if branches[i].HeadCommitAt != nil {
+			ti = *branches[i].HeadCommitAt
+		}
+		if branches[j].HeadCommitAt != nil {
+			tj = *branches[j].HeadCommitAt
+		}
+		if sortDir == "asc" {
+			return ti.Before(tj)
+		}
+		return ti.After(tj)
+	})
+}
+
+// Checkout switches to a branch (optionally creating/tracking), running the
+// switch hooks (see SetHooks).
 func Checkout(repoPath, name string, create bool) (string, error) {
 	if strings.TrimSpace(name) == "" {
 		return "", errors.New("branch name required")
 	}
+	if err := checkArg(name); err != nil {
+		return "", err
+	}
+	if create {
+		if err := CheckName(name); err != nil {
+			return "", err
+		}
+	} else if err := resolveBranch(repoPath, name); err != nil {
+		return "", err
+	}
 	var prev string
 	if cur, err := GetCurrentBranch(repoPath); err == nil && cur != nil {
 		prev = cur.Name
@@ -181,12 +502,235 @@ func Checkout(repoPath, name string, create bool) (string, error) {
 	if create {
 		args = []string{"switch", "-c", name}
 	} else {
-		args = []string{"switch", name}
+		args = []string{"switch", "--end-of-options", name}
 	}
-	if _, err := git(repoPath, args...); err != nil {
+	return prev, switchWithHooks(repoPath, name, prev, args)
+}
+
+// resolveBranch checks that git switch would find a branch for name: a
+// local branch, or a remote one to create it from. Its error is a GitError
+// of KindNotFound, as git's own would be, so that fetching is offered.
+func resolveBranch(repoPath, name string) error {
+	if LocalBranchExists(repoPath, name) {
+		return nil
+	}
+	out, err := git(repoPath, "for-each-ref", "--count=1", "--format=%(refname)", "refs/remotes/*/"+name)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) != "" {
+		return nil
+	}
+	return &GitError{
+		Args:   []string{"switch", name},
+		Output: fmt.Sprintf("fatal: invalid reference: %s", name),
+		Kind:   KindNotFound,
+		Err:    errors.New("no such branch"),
+	}
+}
+
+// Detach checks out ref (a local or remote branch, a tag or a commit) with
+// a detached HEAD, e.g. to look at a remote branch without creating a local
+// one, running the switch hooks with ref as the branch. It returns the
+// previous branch.
+func Detach(repoPath, ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		return "", errors.New("ref required")
+	}
+	if err := checkArg(ref); err != nil {
+		return "", err
+	}
+	prev, _ := currentName(repoPath)
+	return prev, switchWithHooks(repoPath, ref, prev, []string{"switch", "--detach", "--end-of-options", ref})
+}
+
+// ErrMergeConflicts is wrapped by SwitchWith errors when RemedyMerge
+// switched but could not carry every local change over cleanly, leaving
+// conflict markers in the files named.
+var ErrMergeConflicts = errors.New("local changes conflict with the branch")
+
+// SwitchWith applies r to get past a failed switch to name (see
+// GitError.Remedies) and switches again, returning the previous branch.
+// RemedyStash stashes local changes, untracked files included, restoring
+// them if the switch still fails; RemedyMerge merges them into the branch
+// switched to; RemedyForce discards them; RemedyFetch fetches all remotes
+// first, for branches new on a remote.
+func SwitchWith(repoPath, name string, r Remedy) (string, error) {
+	if err := checkArg(name); err != nil {
+		return "", err
+	}
+	switch r {
+	case RemedyStash:
+		prev, _ := currentName(repoPath)
+		if _, err := git(repoPath, "stash", "push", "--include-untracked", "-m", fmt.Sprintf("%s%s to %s", switchStashPrefix, prev, name)); err != nil {
+			return "", err
+		}
+		prev, err := Checkout(repoPath, name, false)
+		if err != nil && !PostHookFailed(err) {
+			if _, perr := git(repoPath, "stash", "pop"); perr != nil {
+				return prev, fmt.Errorf("%w (and restoring the stashed changes failed: %v)", err, perr)
+			}
+		}
 		return prev, err
+	case RemedyMerge:
+		prev, _ := currentName(repoPath)
+		if err := switchWithHooks(repoPath, name, prev, []string{"switch", "--merge", "--end-of-options", name}); err != nil {
+			return prev, err
+		}
+		// git switches even when the merge conflicts, and exits 0.
+		out, err := git(repoPath, "diff", "--name-only", "--diff-filter=U")
+		if err != nil {
+			return prev, err
+		}
+		if out = strings.TrimSpace(out); out != "" {
+			files := strings.Split(out, "\n")
+			return prev, fmt.Errorf("switched to %s, but %w in %s", name, ErrMergeConflicts, strings.Join(files, ", "))
+		}
+		return prev, nil
+	case RemedyForce:
+		prev, _ := currentName(repoPath)
+		return prev, switchWithHooks(repoPath, name, prev, []string{"switch", "--discard-changes", "--end-of-options", name})
+	case RemedyFetch:
+		if err := FetchNoPrompt(repoPath, "", false); err != nil {
+			return "", err
+		}
+		return Checkout(repoPath, name, false)
+	case RemedySwitch:
+		return Checkout(repoPath, name, false)
+	}
+	return "", fmt.Errorf("unknown remedy %q", r)
+}
+
+// currentName returns the name of the current branch, or "" when detached.
+func currentName(repoPath string) (string, error) {
+	cur, err := GetCurrentBranch(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return cur.Name, nil
+}
+
+// CreateOrSwitch switches to name, first creating it from the from ref (HEAD
+// when empty) if no such local branch exists. It returns the previous branch
+// and whether the branch was created.
+func CreateOrSwitch(repoPath, name, from string) (prev string, created bool, err error) {
+	if strings.TrimSpace(name) == "" {
+		return "", false, errors.New("branch name required")
+	}
+	if err := checkArg(name); err != nil {
+		return "", false, err
+	}
+	if LocalBranchExists(repoPath, name) {
+		prev, err := Checkout(repoPath, name, false)
+		return prev, false, err
+	}
+	if err := CheckName(name); err != nil {
+		return "", false, err
+	}
+	if cur, err := GetCurrentBranch(repoPath); err == nil {
+		prev = cur.Name
+	}
+	args := []string{"switch", "-c", name}
+	if from != "" {
+		if err := checkArg(from); err != nil {
+			return "", false, err
+		}
+		if _, err := git(repoPath, "rev-parse", "--verify", "--quiet", "--end-of-options", from+"^{commit}"); err != nil {
+			return "", false, fmt.Errorf("%q is not a commit", from)
+		}
+		args = append(args, "--end-of-options", from)
+	}
+	err = switchWithHooks(repoPath, name, prev, args)
+	var hookErr *HookError
+	created = err == nil || (errors.As(err, &hookErr) && hookErr.Hook == HookPostSwitch)
+	return prev, created, err
+}
+
+// switchWithHooks runs the git command args switching from prev to name
+// between the pre- and post-switch hooks, and records the switch in the
+// journal (see SetJournal) and the audit log (see SetAudit).
+func switchWithHooks(repoPath, name, prev string, args []string) error {
+	return switchBranch(repoPath, name, prev, args, true)
+}
+
+// switchBranch is switchWithHooks, recording the switch in the journal
+// only if record is set.
+func switchBranch(repoPath, name, prev string, args []string, record bool) error {
+	ev := HookEvent{RepoPath: repoPath, Branch: name, Previous: prev}
+	if name == prev {
+		_, err := git(repoPath, args...)
+		return err
+	}
+	ev.Hook = HookPreSwitch
+	if err := runHook(ev); err != nil {
+		return err
+	}
+	op := Operation{Op: ActionSwitch, Branch: name, Previous: prev, Old: auditSHA(repoPath, "HEAD")}
+	switch {
+	case slices.Contains(args, "-c"):
+		op.Op = OpCreate
+	case slices.Contains(args, "--detach"):
+		op.Op = OpDetach
+	}
+	if _, err := git(repoPath, args...); err != nil {
+		return err
+	}
+	op.New = auditSHA(repoPath, "HEAD")
+	audit(repoPath, op)
+	if record && prev != "" {
+		journal(repoPath, Action{Kind: ActionSwitch, Branch: name, Previous: prev})
+	}
+	ev.Hook = HookPostSwitch
+	return runHook(ev)
+}
+
+// LocalBranchExists reports whether refs/heads/name exists.
+func LocalBranchExists(repoPath, name string) bool {
+	_, err := git(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// refFormat is the for-each-ref format parsed by parseForEachRef. The subject
+// comes last because it is the only field that may itself contain tabs.
+const refFormat = "%(refname)\t%(objectname)\t%(committerdate:iso-strict)\t%(upstream:short)\t%(authorname)\t%(authoremail:trim)\t%(contents:subject)"
+
+// gitDateLayouts are the date formats git prints in its various --date
+// modes and versions, strictest first.
+var gitDateLayouts = []string{
+	time.RFC3339,                     // iso-strict: 2024-01-31T15:04:05+01:00
+	"2006-01-02T15:04:05-0700",       // iso-strict from some versions
+	"2006-01-02 15:04:05 -0700",      // iso
+	time.RFC1123Z,                    // rfc: Wed, 31 Jan 2024 15:04:05 +0100
+	"Mon, 2 Jan 2006 15:04:05 -0700", // rfc with a single-digit day
+	"Mon Jan 2 15:04:05 2006 -0700",  // default
+	"2006-01-02",                     // short
+}
+
+// parseGitDate parses a date as printed by git, tolerating the formats of
+// gitDateLayouts as well as raw and unix timestamps ("1706710000 +0100",
+// "1706710000"), so that a git version or configuration printing dates
+// differently does not silently lose them (and with them the recency
+// order).
+func parseGitDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range gitDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	secs, zone, _ := strings.Cut(strings.TrimPrefix(s, "@"), " ")
+	n, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t := time.Unix(n, 0)
+	if z, err := time.Parse("-0700", zone); err == nil {
+		t = t.In(z.Location())
 	}
-	return prev, nil
+	return t, true
 }
 
 func parseForEachRef(out string, isRemote bool) []Branch {
@@ -196,17 +740,19 @@ func parseForEachRef(out string, isRemote bool) []Branch {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
-		parts := strings.SplitN(line, "\t", 4)
-		if len(parts) < 4 {
+		parts := strings.SplitN(line, "\t", 7)
+		if len(parts) < 7 {
 			continue
 		}
 		fullRef := parts[0]
 		sha := parts[1]
 		dateStr := parts[2]
-		msg := parts[3]
+		upstream := parts[3]
+		author := parts[4]
+		email := parts[5]
+		msg := parts[6]
 		var tPtr *time.Time
-		// iso8601 from git is typically RFC3339 or close enough
-		if ts, err := time.Parse(time.RFC3339, dateStr); err == nil {
+		if ts, ok := parseGitDate(dateStr); ok {
 			tPtr = &ts
 		}
 		name := fullRef
@@ -217,28 +763,23 @@ func parseForEachRef(out string, isRemote bool) []Branch {
 		}
 		shaCopy := sha
 		msgCopy := msg
+		var upPtr *string
+		if upstream != "" {
+			upPtr = &upstream
+		}
 		b := Branch{
 			Name:              name,
 			FullRef:           fullRef,
 			IsCurrent:         false,
 			IsRemote:          isRemote,
+			Upstream:          upPtr,
 			HeadCommitSHA:     &shaCopy,
 			HeadCommitAt:      tPtr,
 			LastCommitMessage: &msgCopy,
+			Author:            &author,
+			AuthorEmail:       &email,
 		}
 		res = append(res, b)
 	}
 	return res
 }
-
-func git(repoPath string, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	if repoPath != "" {
-		cmd.Dir = repoPath
-	}
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("git %v failed: %w: %s", args, err, string(out))
-	}
-	return string(out), nil
-}
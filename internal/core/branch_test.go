@@ -0,0 +1,50 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGitDate(t *testing.T) {
+	// 2024-01-31 15:04:05 +0100, the moment every format below denotes.
+	want := time.Date(2024, 1, 31, 14, 4, 5, 0, time.UTC)
+	const offset = 3600
+	tests := []struct {
+		in      string
+		want    time.Time
+		offset  int // the zone offset the result must keep, in seconds
+		ok      bool
+		comment string
+	}{
+		{"2024-01-31T15:04:05+01:00", want, offset, true, "iso-strict"},
+		{"2024-01-31T14:04:05Z", want, 0, true, "iso-strict in UTC"},
+		{"2024-01-31T15:04:05+0100", want, offset, true, "iso-strict from some versions"},
+		{"2024-01-31 15:04:05 +0100", want, offset, true, "iso"},
+		{"Wed, 31 Jan 2024 15:04:05 +0100", want, offset, true, "rfc"},
+		{"Thu, 1 Feb 2024 15:04:05 +0100", want.AddDate(0, 0, 1), offset, true, "rfc with a single-digit day"},
+		{"Wed Jan 31 15:04:05 2024 +0100", want, offset, true, "default"},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), 0, true, "short"},
+		{"1706709845 +0100", want, offset, true, "raw"},
+		{"@1706709845 +0100", want, offset, true, "raw with @"},
+		{"1706709845", want, -1, true, "unix"},
+		{"  2024-01-31T15:04:05+01:00\n", want, offset, true, "surrounded by space"},
+		{"", time.Time{}, 0, false, "empty"},
+		{"yesterday", time.Time{}, 0, false, "garbage"},
+		{"31/01/2024", time.Time{}, 0, false, "unsupported layout"},
+		{"2024-01-31T15:04:05", time.Time{}, 0, false, "no zone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			got, ok := parseGitDate(tt.in)
+			if ok != tt.ok {
+				t.Fatalf("parseGitDate(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseGitDate(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			if _, off := got.Zone(); ok && tt.offset >= 0 && off != tt.offset {
+				t.Errorf("parseGitDate(%q) has offset %d, want %d", tt.in, off, tt.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TipCommits returns the last n commits of ref that HEAD lacks, oldest
+// first, which is the order to cherry-pick them in. Merge commits are
+// skipped.
+func TipCommits(repoPath, ref string, n int) ([]string, error) {
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("unknown ref %q", ref)
+	}
+	out, err := git(repoPath, "rev-list", "--no-merges", "-n", fmt.Sprint(n), "HEAD.."+ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	shas := strings.Fields(out)
+	if len(shas) == 0 {
+		return nil, fmt.Errorf("nothing to cherry-pick: the commits of %s are already on HEAD", ref)
+	}
+	for i, j := 0, len(shas)-1; i < j; i, j = i+1, j-1 {
+		shas[i], shas[j] = shas[j], shas[i]
+	}
+	return shas, nil
+}
+
+// CherryPick applies commits onto the current branch in order. When they do
+// not apply cleanly it returns a KindConflict error and leaves the
+// cherry-pick in progress, to be resolved and continued with git or given
+// up with AbortCherryPick.
+func CherryPick(repoPath string, commits ...string) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	op := Operation{Op: OpCherryPick, Old: auditSHA(repoPath, "HEAD")}
+	op.Branch, _ = currentName(repoPath)
+	_, err := git(repoPath, append([]string{"cherry-pick"}, commits...)...)
+	// Commits picked before a conflict are on the branch already.
+	if op.New = auditSHA(repoPath, "HEAD"); op.New != op.Old {
+		audit(repoPath, op)
+	}
+	if err != nil && ErrorKind(err) == KindUnknown && CherryPickInProgress(repoPath) {
+		// Some conflicts (e.g. modify/delete) are reported in words
+		// classify does not know; the stopped cherry-pick tells.
+		var ge *GitError
+		if errors.As(err, &ge) {
+			ge.Kind = KindConflict
+		}
+	}
+	return err
+}
+
+// CherryPickInProgress reports whether a cherry-pick stopped part way.
+func CherryPickInProgress(repoPath string) bool {
+	_, err := git(repoPath, "rev-parse", "-q", "--verify", "CHERRY_PICK_HEAD")
+	return err == nil
+}
+
+// AbortCherryPick gives up the cherry-pick in progress, restoring the
+// branch and working tree to where they were before it.
+func AbortCherryPick(repoPath string) error {
+	_, err := git(repoPath, "cherry-pick", "--abort")
+	return err
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// CloneInfo tells how much of a repository's history is local. Shallow
+// clones lack the commits beyond their shallow boundary, so their branches'
+// ages, ahead/behind counts and merge status can be wrong; partial clones
+// have all commits but fetch missing objects (typically blobs) on demand,
+// which makes commands touching file contents slow.
+type CloneInfo struct {
+	Shallow bool   `json:"shallow"`
+	Filter  string `json:"filter,omitempty"` // partial clone filter, e.g. "blob:none"; "" for a full clone
+}
+
+// Partial reports whether objects are fetched on demand.
+func (c CloneInfo) Partial() bool { return c.Filter != "" }
+
+// Clone describes how the repository at repoPath was cloned.
+func Clone(repoPath string) (CloneInfo, error) {
+	var c CloneInfo
+	shallow, err := shallowCommits(repoPath)
+	if err != nil {
+		return c, err
+	}
+	c.Shallow = len(shallow) > 0
+	out, _ := git(repoPath, "config", "--get-regexp", `^remote\..*\.partialclonefilter$`)
+	if _, filter, ok := strings.Cut(strings.TrimSpace(out), " "); ok {
+		c.Filter, _, _ = strings.Cut(filter, "\n")
+	} else if out, _ := git(repoPath, "config", "extensions.partialClone"); strings.TrimSpace(out) != "" {
+		// A promisor remote without a recorded filter.
+		c.Filter = "unknown"
+	}
+	return c, nil
+}
+
+// shallowCommits returns the commits at the shallow boundary, none for a
+// complete history. Git lists them in $GIT_DIR/shallow.
+func shallowCommits(repoPath string) ([]string, error) {
+	path, err := git(repoPath, "rev-parse", "--path-format=absolute", "--git-path", "shallow")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return nil, nil
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// markShallow sets Branch.Shallow on the branches whose history reaches a
+// shallow boundary. In a complete clone it costs one cheap git call.
+func markShallow(repoPath string, branches []Branch) {
+	shallow, err := shallowCommits(repoPath)
+	if err != nil || len(shallow) == 0 {
+		return
+	}
+	args := []string{"for-each-ref", "--format=%(refname)"}
+	for _, sha := range shallow {
+		args = append(args, "--contains="+sha)
+	}
+	out, err := gitLocal(repoPath, append(args, "refs/heads/", "refs/remotes/")...)
+	if err != nil {
+		return
+	}
+	cut := map[string]bool{}
+	for _, ref := range strings.Fields(out) {
+		cut[ref] = true
+	}
+	for i := range branches {
+		branches[i].Shallow = cut[branches[i].FullRef]
+	}
+}
+
+// gitLocal is git for queries that must work from local objects only. In a
+// partial clone git would otherwise fetch every missing object the query
+// touches, one round trip at a time; with lazy fetching disabled (git 2.44
+// and later honor this) such a query fails fast instead.
+func gitLocal(repoPath string, args ...string) (string, error) {
+	return gitEnv(repoPath, []string{"GIT_NO_LAZY_FETCH=1"}, args...)
+}
+
+// FetchHistory fetches the commits a shallow clone lacks, making it
+// complete, reporting git's progress as it goes; see gitProgress. Like
+// FetchNoPrompt it fails rather than prompting for credentials; retry with
+// FetchHistoryCommand attached to the terminal. It stops fetching when ctx
+// is done.
+func FetchHistory(ctx context.Context, repoPath string, report func(Progress)) error {
+	args := append(historyArgs(), "--progress")
+	_, err := gitProgress(ctx, repoPath, noPromptEnv(repoPath), report, args...)
+	return authRequired(err)
+}
+
+// FetchHistoryCommand returns the command FetchHistory runs, for running
+// with the terminal attached.
+func FetchHistoryCommand(repoPath string) *exec.Cmd {
+	cmd := exec.Command(GitBin, historyArgs()...)
+	cmd.Dir = repoPath
+	return cmd
+}
+
+func historyArgs() []string {
+	return []string{"fetch", "--unshallow"}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxCommitCount bounds the commits counted per branch by CommitCounts, so
+// a branch with unrelated history does not walk all of it. Counts reaching
+// it mean "at least this many".
+const MaxCommitCount = 1000
+
+// CommitCounts counts the commits of each branch that the default branch
+// (see DefaultBranch) does not have, up to MaxCommitCount, keyed by full
+// ref. It tells a one-commit fix from a long-lived branch. Counting is one
+// git command per branch, so counts are remembered for as long as neither
+// side moves; branches that cannot be counted are left out.
+func CommitCounts(repoPath string, branches []Branch) (map[string]int, error) {
+	base, err := defaultBranchRef(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	out, err := gitLocal(repoPath, "rev-parse", "--verify", "--quiet", base+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	baseSHA := strings.TrimSpace(out)
+	res := make(map[string]int, len(branches))
+	for _, b := range branches {
+		tip := b.FullRef
+		if b.HeadCommitSHA != nil {
+			tip = *b.HeadCommitSHA
+		}
+		key := baseSHA + ".." + tip
+		commitCountsMu.Lock()
+		n, ok := commitCounts[key]
+		commitCountsMu.Unlock()
+		if !ok {
+			out, err := gitLocal(repoPath, "rev-list", "--count", "--max-count="+strconv.Itoa(MaxCommitCount), key, "--")
+			if err != nil {
+				continue
+			}
+			if n, err = strconv.Atoi(strings.TrimSpace(out)); err != nil {
+				continue
+			}
+			if b.HeadCommitSHA != nil {
+				commitCountsMu.Lock()
+				commitCounts[key] = n
+				commitCountsMu.Unlock()
+			}
+		}
+		res[b.FullRef] = n
+	}
+	return res, nil
+}
+
+// commitCounts remembers counts by "<base sha>..<tip sha>", which never
+// change.
+var (
+	commitCountsMu sync.Mutex
+	commitCounts   = map[string]int{}
+)
+
+// defaultBranchRef returns the full ref of the default branch: the local
+// branch, or its remote-tracking branch on origin when there is none.
+func defaultBranchRef(repoPath string) (string, error) {
+	name, err := DefaultBranch(repoPath)
+	if err != nil {
+		return "", err
+	}
+	ref := "refs/heads/" + name
+	if _, err := git(repoPath, "rev-parse", "--verify", "--quiet", ref); err != nil {
+		ref = "refs/remotes/origin/" + name
+	}
+	return ref, nil
+}
+
+// addCommitCounts sets the commit counts of branches. Without a default
+// branch to count against they are left unknown.
+func addCommitCounts(repoPath string, branches []Branch) {
+	counts, err := CommitCounts(repoPath, branches)
+	if err != nil {
+		return
+	}
+	for i, b := range branches {
+		if n, ok := counts[b.FullRef]; ok {
+			branches[i].Commits = &n
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+)
+
+// DiffCommand returns `git diff HEAD...ref`, the changes ref made since it
+// forked from HEAD, for running with the terminal attached. A non-empty
+// pager (e.g. "delta") replaces git's core.pager, and a non-empty tool
+// (e.g. "difft") renders the diff as GIT_EXTERNAL_DIFF. Unless LESS is set,
+// less is told not to quit when the diff fits on the screen, which would
+// hand the terminal back before it could be read.
+func DiffCommand(repoPath, ref, pager, tool string) *exec.Cmd {
+	var args []string
+	if pager != "" {
+		args = append(args, "-c", "core.pager="+pager)
+	}
+	args = append(args, "diff")
+	if tool != "" {
+		args = append(args, "--ext-diff")
+	}
+	args = append(args, "HEAD..."+ref, "--")
+	cmd := exec.Command(GitBin, args...)
+	cmd.Dir = repoPath
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=R")
+	}
+	if tool != "" {
+		cmd.Env = append(cmd.Env, "GIT_EXTERNAL_DIFF="+tool)
+	}
+	return cmd
+}
@@ -0,0 +1,30 @@
+package core
+
+import "fmt"
+
+// Divergence counts the commits a branch and HEAD do not share.
+type Divergence struct {
+	Ahead  int `json:"ahead"`  // commits only on the branch
+	Behind int `json:"behind"` // commits only on HEAD
+}
+
+// Divergences compares each of the commits shas with the commit head
+// (HEAD's SHA, see Head), keyed by SHA. Counting walks the history back to
+// the merge base, one git command per commit, so callers should ask for
+// what is shown and keep the results while HEAD stays put. Commits that
+// cannot be compared, e.g. for history missing in a shallow clone, are
+// left out.
+func Divergences(repoPath, head string, shas []string) map[string]Divergence {
+	res := make(map[string]Divergence, len(shas))
+	for _, sha := range shas {
+		out, err := gitLocal(repoPath, "rev-list", "--left-right", "--count", sha+"..."+head, "--")
+		if err != nil {
+			continue
+		}
+		var d Divergence
+		if _, err := fmt.Sscan(out, &d.Ahead, &d.Behind); err == nil {
+			res[sha] = d
+		}
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BranchDetails is a branch with the relations an inventory of branches
+// reports on. They cost extra git calls, so listings leave them out.
+type BranchDetails struct {
+	Branch
+	Ahead        int    `json:"ahead"`        // commits not in the upstream
+	Behind       int    `json:"behind"`       // upstream commits not in the branch
+	UpstreamGone bool   `json:"upstreamGone"` // the upstream was deleted on the remote
+	Merged       bool   `json:"merged"`       // reachable from Base, or squash-merged into it (see MergedInto)
+	Base         string `json:"base"`
+}
+
+// Details computes BranchDetails for branches, measuring merges against
+// base (the default branch when empty).
+func Details(repoPath string, branches []Branch, base string) ([]BranchDetails, error) {
+	if base == "" {
+		var err error
+		if base, err = DefaultBranch(repoPath); err != nil {
+			return nil, err
+		}
+	}
+	merged, err := MergedInto(repoPath, base, "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return nil, err
+	}
+	out, err := gitLocal(repoPath, "for-each-ref", "--format=%(refname)\t%(upstream:track,nobracket)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	track := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if ref, t, ok := strings.Cut(line, "\t"); ok {
+			track[ref] = t
+		}
+	}
+
+	res := make([]BranchDetails, len(branches))
+	for i, b := range branches {
+		d := BranchDetails{Branch: b, Merged: merged[b.FullRef], Base: base}
+		d.Ahead, d.Behind, d.UpstreamGone = parseTrack(track[b.FullRef])
+		res[i] = d
+	}
+	return res, nil
+}
+
+// parseTrack reads %(upstream:track,nobracket): "ahead 1, behind 2",
+// "behind 3", "gone" or "".
+func parseTrack(t string) (ahead, behind int, gone bool) {
+	if t == "gone" {
+		return 0, 0, true
+	}
+	for _, part := range strings.Split(t, ", ") {
+		word, n, ok := strings.Cut(part, " ")
+		if !ok {
+			continue
+		}
+		count, _ := strconv.Atoi(n)
+		switch word {
+		case "ahead":
+			ahead = count
+		case "behind":
+			behind = count
+		}
+	}
+	return ahead, behind, false
+}
@@ -0,0 +1,33 @@
+package core
+
+import "sync"
+
+var (
+	frecencyMu sync.Mutex
+	frecencyFn func(repoPath string) map[string]float64
+)
+
+// SetFrecency makes sorting by "frecency" rank branches by scores, keyed
+// by branch name, that lookup returns for a repository: how often and how
+// recently each was switched to. Without it, or for branches it has no
+// score for, frecency sorts like recency. A nil lookup turns this off.
+func SetFrecency(lookup func(repoPath string) map[string]float64) {
+	frecencyMu.Lock()
+	defer frecencyMu.Unlock()
+	frecencyFn = lookup
+}
+
+// addFrecency sets the frecency of branches, remote ones by the name of
+// the local branch they correspond to.
+func addFrecency(repoPath string, branches []Branch) {
+	frecencyMu.Lock()
+	lookup := frecencyFn
+	frecencyMu.Unlock()
+	if lookup == nil {
+		return
+	}
+	scores := lookup(repoPath)
+	for i := range branches {
+		branches[i].Frecency = scores[HeadName(branches[i])]
+	}
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/logging"
+)
+
+// GitBin is the git executable used for every operation. It may be a bare
+// name resolved via PATH or an absolute path.
+var GitBin = "git"
+
+// LockWait is how long git commands that fail because another git process
+// holds a lock in the repository (e.g. .git/index.lock, taken by IDEs and
+// background fetchers all the time) are retried before giving up with
+// ErrRepoBusy. Zero disables retrying.
+var LockWait = 3 * time.Second
+
+// LocalTimeout and NetworkTimeout bound how long a single git command may
+// run before it is killed with ErrTimeout: NetworkTimeout for commands
+// talking to remotes (fetch, push, ls-remote), LocalTimeout for the rest.
+// Zero means no limit.
+var (
+	LocalTimeout   = 30 * time.Second
+	NetworkTimeout = 2 * time.Minute
+)
+
+// ErrTimeout is wrapped by errors of git commands that ran longer than
+// LocalTimeout or NetworkTimeout.
+var ErrTimeout = errors.New("timed out")
+
+// networkCommands are the git commands bounded by NetworkTimeout.
+var networkCommands = map[string]bool{"fetch": true, "push": true, "pull": true, "ls-remote": true, "clone": true, "submodule": true}
+
+// timeout returns the limit for the git command args.
+func timeout(args []string) time.Duration {
+	if len(args) > 0 && networkCommands[args[0]] {
+		return NetworkTimeout
+	}
+	return LocalTimeout
+}
+
+// ErrRepoBusy is wrapped by errors of git commands that kept failing for
+// LockWait because another git process held a lock.
+var ErrRepoBusy = errors.New("repository busy")
+
+var (
+	traceMu sync.Mutex
+	traceW  io.Writer
+
+	busyMu sync.Mutex
+	busyFn func(busy bool)
+
+	timerMu sync.Mutex
+	timerFn func(args []string, d time.Duration)
+
+	logMu sync.Mutex
+	logL  = logging.Discard
+)
+
+// SetBusy makes git commands call fn with true when they start waiting for
+// a lock held by another git process and with false when they stop, e.g. to
+// show that the repository is busy. A nil fn turns this off.
+func SetBusy(fn func(busy bool)) {
+	busyMu.Lock()
+	defer busyMu.Unlock()
+	busyFn = fn
+}
+
+func reportBusy(busy bool) {
+	busyMu.Lock()
+	fn := busyFn
+	busyMu.Unlock()
+	if fn != nil {
+		fn(busy)
+	}
+}
+
+// SetTrace makes every git invocation log its arguments, duration and exit
+// status to w. A nil w turns tracing off.
+func SetTrace(w io.Writer) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	traceW = w
+}
+
+// SetLogger makes core log to l: every git command at debug level, waits
+// for locks held by other git processes and changes to branches (those
+// SetAudit reports) at info, and git commands timing out at warn. A nil l
+// turns logging off.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = logging.Discard
+	}
+	logMu.Lock()
+	defer logMu.Unlock()
+	logL = l
+}
+
+func logger() *slog.Logger {
+	logMu.Lock()
+	defer logMu.Unlock()
+	return logL
+}
+
+// SetGitTimer makes every git invocation call fn with its arguments and
+// how long it ran, e.g. to collect metrics. A nil fn turns this off.
+func SetGitTimer(fn func(args []string, d time.Duration)) {
+	timerMu.Lock()
+	defer timerMu.Unlock()
+	timerFn = fn
+}
+
+func trace(repoPath string, args []string, d time.Duration, err error) {
+	timerMu.Lock()
+	fn := timerFn
+	timerMu.Unlock()
+	if fn != nil {
+		fn(args, d)
+	}
+	status := "exit 0"
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		status = fmt.Sprintf("exit %d", exitErr.ExitCode())
+	case err != nil:
+		status = err.Error()
+	}
+	logger().Debug("git", "args", strings.Join(args, " "), "dir", repoPath, "status", status, "duration", d)
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if traceW == nil {
+		return
+	}
+	dir := ""
+	if repoPath != "" {
+		dir = " (in " + repoPath + ")"
+	}
+	fmt.Fprintf(traceW, "%s git %s%s: %s in %s\n",
+		time.Now().Format("15:04:05.000"), strings.Join(args, " "), dir, status, d.Round(time.Microsecond))
+}
+
+func git(repoPath string, args ...string) (string, error) {
+	return gitEnv(repoPath, nil, args...)
+}
+
+// fixedEnv makes git's messages (which classify parses) English and its
+// dates independent of the user's time zone.
+var fixedEnv = []string{"LC_ALL=C", "TZ=UTC"}
+
+// gitEnv is git with env added to the environment. Commands failing on a
+// lock held by another git process are retried with backoff for LockWait.
+func gitEnv(repoPath string, env []string, args ...string) (string, error) {
+	deadline := time.Now().Add(LockWait)
+	backoff := 50 * time.Millisecond
+	waiting := false
+	defer func() {
+		if waiting {
+			reportBusy(false)
+		}
+	}()
+	for {
+		out, err := runGit(repoPath, env, args)
+		if err == nil {
+			return out, nil
+		}
+		lock, locked := lockFile(out)
+		if !locked {
+			return "", err
+		}
+		if !time.Now().Add(backoff).Before(deadline) {
+			return "", fmt.Errorf("%w: another git process holds %s (delete it if none is running)", ErrRepoBusy, lock)
+		}
+		if !waiting {
+			waiting = true
+			logger().Info("waiting for a lock held by another git process", "lock", lock)
+			reportBusy(true)
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 400*time.Millisecond)
+	}
+}
+
+// lockFile returns the lock file git complained about in out, if any, as in
+// "fatal: Unable to create '/repo/.git/index.lock': File exists."
+func lockFile(out string) (string, bool) {
+	const prefix, suffix = "Unable to create '", ".lock': File exists"
+	i := strings.Index(out, prefix)
+	if i < 0 {
+		return "", false
+	}
+	rest := out[i+len(prefix):]
+	j := strings.Index(rest, suffix)
+	if j < 0 {
+		return "", false
+	}
+	return rest[:j] + ".lock", true
+}
+
+// runGit runs git once, returning its combined output, which failures also
+// quote.
+func runGit(repoPath string, env, args []string) (string, error) {
+	ctx := context.Background()
+	limit := timeout(args)
+	if limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limit)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, GitBin, args...)
+	// Killing git leaves helpers such as ssh holding its output open; stop
+	// waiting for them shortly after.
+	cmd.WaitDelay = time.Second
+	if repoPath != "" {
+		cmd.Dir = repoPath
+	}
+	cmd.Env = append(append(os.Environ(), fixedEnv...), env...)
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	trace(repoPath, args, time.Since(start), err)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("%w after %s", ErrTimeout, limit)
+		logger().Warn("git timed out", "args", strings.Join(args, " "), "dir", repoPath, "limit", limit)
+		return string(out), &GitError{Args: args, Output: string(out), Kind: KindTimeout, Err: err}
+	}
+	if err != nil {
+		return string(out), &GitError{Args: args, Output: string(out), Kind: classify(string(out)), Err: err}
+	}
+	return string(out), nil
+}
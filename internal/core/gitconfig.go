@@ -0,0 +1,34 @@
+package core
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// GlobalConfig returns the value of key in the user's global git config and
+// whether it is set.
+func GlobalConfig(key string) (string, bool, error) {
+	out, err := git("", "config", "--global", "--get", key)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// Exit status 1 means the key is not set.
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(out), true, nil
+}
+
+// SetGlobalConfig sets key to value in the user's global git config.
+func SetGlobalConfig(key, value string) error {
+	_, err := git("", "config", "--global", key, value)
+	return err
+}
+
+// UnsetGlobalConfig removes key from the user's global git config.
+func UnsetGlobalConfig(key string) error {
+	_, err := git("", "config", "--global", "--unset", key)
+	return err
+}
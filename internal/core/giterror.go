@@ -0,0 +1,194 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitErrorKind classifies why a git command failed.
+type GitErrorKind string
+
+const (
+	KindUnknown        GitErrorKind = ""
+	KindBranchExists   GitErrorKind = "branch-exists"    // creating a branch that exists
+	KindNotFound       GitErrorKind = "not-found"        // no such branch, ref or path
+	KindLocalChanges   GitErrorKind = "local-changes"    // uncommitted changes would be overwritten
+	KindNotFastForward GitErrorKind = "not-fast-forward" // the branch has diverged from the one it should follow
+	KindNotMerged      GitErrorKind = "not-merged"       // deleting a branch with unmerged commits
+	KindCheckedOut     GitErrorKind = "checked-out"      // the branch is checked out in another worktree
+	KindAuth           GitErrorKind = "auth"             // the remote needs credentials git could not ask for
+	KindNotRepository  GitErrorKind = "not-repository"   // not inside a git repository
+	KindTimeout        GitErrorKind = "timeout"          // git ran longer than its timeout and was killed
+	KindConflict       GitErrorKind = "conflict"         // a cherry-pick stopped on conflicts
+)
+
+// Remedy is a way out of a failed git command that a UI can offer.
+type Remedy string
+
+const (
+	RemedyStash  Remedy = "stash"  // stash local changes, then retry
+	RemedyMerge  Remedy = "merge"  // retry, carrying local changes over with a three-way merge
+	RemedyForce  Remedy = "force"  // retry, discarding local changes or unmerged commits
+	RemedyFetch  Remedy = "fetch"  // fetch from the remotes, then retry
+	RemedySwitch Remedy = "switch" // switch to the existing branch instead of creating it
+)
+
+// GitError is a failed git command. Its message is git's own, prefixed
+// with the command; Kind and Remedies classify it for callers that want to
+// react to specific failures.
+type GitError struct {
+	Args   []string
+	Output string // combined stdout and stderr
+	Kind   GitErrorKind
+	Err    error // the exec error, e.g. an exit status
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("git %v failed: %v: %s", e.Args, e.Err, e.Output)
+}
+
+func (e *GitError) Unwrap() error { return e.Err }
+
+// Remedies lists the ways out of e, most appropriate first.
+func (e *GitError) Remedies() []Remedy {
+	switch e.Kind {
+	case KindLocalChanges:
+		if strings.Contains(e.Output, "untracked working tree files") {
+			// Merging only carries changes to tracked files over.
+			return []Remedy{RemedyStash, RemedyForce}
+		}
+		return []Remedy{RemedyStash, RemedyMerge, RemedyForce}
+	case KindNotFound:
+		return []Remedy{RemedyFetch}
+	case KindBranchExists:
+		return []Remedy{RemedySwitch}
+	case KindNotMerged:
+		return []Remedy{RemedyForce}
+	case KindNotFastForward:
+		return []Remedy{RemedyFetch}
+	}
+	return nil
+}
+
+// Files returns the files git listed as the reason for e, such as the
+// local changes a switch would overwrite.
+func (e *GitError) Files() []string {
+	var files []string
+	for _, line := range strings.Split(e.Output, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			files = append(files, strings.TrimSpace(line))
+		}
+	}
+	return files
+}
+
+// Hint suggests in a sentence what to do about e, or returns "".
+func (e *GitError) Hint() string {
+	switch e.Kind {
+	case KindLocalChanges:
+		return "commit or stash your changes first, merge them in with git switch --merge, or discard them"
+	case KindNotFound:
+		return "check the name, or fetch if the branch is new on the remote"
+	case KindBranchExists:
+		return "pick another name, or switch to the existing branch"
+	case KindNotFastForward:
+		return "the branches have diverged; merge or rebase instead"
+	case KindNotMerged:
+		return "merge it first, or force the deletion to drop its commits"
+	case KindCheckedOut:
+		return "switch that worktree to another branch first"
+	case KindAuth:
+		return "configure a credential helper or ssh-agent, or run the command in a terminal"
+	case KindNotRepository:
+		return notRepositoryHint
+	case KindTimeout:
+		return "check that the remote is reachable, or raise localTimeout/networkTimeout"
+	case KindConflict:
+		return "resolve the conflicts and run git cherry-pick --continue, or abort with gotobranch cherry-pick --abort"
+	}
+	return ""
+}
+
+// ErrNotRepository is wrapped by CheckRepo errors for directories outside
+// any git repository.
+var ErrNotRepository = errors.New("not a git repository")
+
+const notRepositoryHint = "run gotobranch inside a git repository, or point --repo at one"
+
+// CheckRepo reports whether repoPath (the working directory when empty) is
+// inside a git repository, with an error naming the directory otherwise, so
+// that callers can fail up front with a clear message instead of on their
+// first query.
+func CheckRepo(repoPath string) error {
+	dir := repoPath
+	if dir == "" {
+		dir = "."
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return fmt.Errorf("%s: no such directory", dir)
+	}
+	_, err := git(repoPath, "rev-parse", "--git-dir")
+	if ErrorKind(err) == KindNotRepository {
+		return fmt.Errorf("%s: %w", dir, ErrNotRepository)
+	}
+	return err
+}
+
+// Hint suggests what to do about err, or returns "".
+func Hint(err error) string {
+	var ge *GitError
+	switch {
+	case errors.Is(err, ErrNotRepository):
+		return notRepositoryHint
+	case errors.Is(err, ErrNamePolicy):
+		return "pick a name matching namePolicy in the config"
+	case errors.Is(err, ErrMergeConflicts):
+		return "edit the files to resolve the conflicts, then git add them"
+	case errors.As(err, &ge):
+		return ge.Hint()
+	}
+	return ""
+}
+
+// ErrorKind returns the kind of the GitError in err's chain, or KindUnknown.
+func ErrorKind(err error) GitErrorKind {
+	var ge *GitError
+	if errors.As(err, &ge) {
+		return ge.Kind
+	}
+	return KindUnknown
+}
+
+// gitMessages map what git says to the kind of failure, checked in order.
+var gitMessages = []struct {
+	kind GitErrorKind
+	says []string
+}{
+	{KindNotRepository, []string{"not a git repository"}},
+	{KindLocalChanges, []string{"would be overwritten by", "Your local changes", "untracked working tree files would be"}},
+	{KindConflict, []string{"could not apply", "after resolving the conflicts"}},
+	{KindBranchExists, []string{"already exists"}},
+	{KindCheckedOut, []string{"is already checked out at", "is already used by worktree", "checked out at '"}},
+	{KindNotMerged, []string{"is not fully merged"}},
+	{KindNotFastForward, []string{"Not possible to fast-forward", "not possible to fast-forward", "non-fast-forward", "(fetch first)"}},
+	{KindAuth, authFailures},
+	{KindNotFound, []string{"invalid reference", "did not match any", "not a valid object name", "unknown revision", "' not found", "no such branch", "couldn't find remote ref"}},
+}
+
+// classify tells from git's output why it failed.
+func classify(output string) GitErrorKind {
+	for _, m := range gitMessages {
+		for _, s := range m.says {
+			if strings.Contains(output, s) {
+				return m.kind
+			}
+		}
+	}
+	return KindUnknown
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// TestHintsTranslated checks that every hint Hint gives has a German
+// translation. The command line translates hints as it prints them, but
+// they are not literals passed to i18n, which the catalog's own test
+// looks for.
+func TestHintsTranslated(t *testing.T) {
+	errs := []error{
+		ErrNotRepository,
+		ErrNamePolicy,
+		ErrMergeConflicts,
+	}
+	for _, k := range []GitErrorKind{
+		KindBranchExists, KindNotFound, KindLocalChanges, KindNotFastForward, KindNotMerged,
+		KindCheckedOut, KindAuth, KindNotRepository, KindTimeout, KindConflict,
+	} {
+		errs = append(errs, &GitError{Kind: k, Err: fmt.Errorf("exit status 1")})
+	}
+	i18n.SetLocale("de")
+	defer i18n.SetLocale("")
+	for _, err := range errs {
+		hint := Hint(err)
+		if hint == "" {
+			t.Errorf("%v: no hint", err)
+			continue
+		}
+		if i18n.T(hint) == hint {
+			t.Errorf("no German translation of %q", hint)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook names, as passed in HookEvent.Hook.
+const (
+	HookPreSwitch  = "pre-switch"
+	HookPostSwitch = "post-switch"
+	HookPostDelete = "post-delete"
+)
+
+// HookEvent describes the branch operation a hook runs for.
+type HookEvent struct {
+	Hook     string
+	RepoPath string
+	Branch   string
+	Previous string // switches: the branch switched away from, if any
+	SHA      string // deletes: the commit the branch pointed to
+}
+
+// HookError is returned when a hook fails. After a post-switch or
+// post-delete hook the operation itself has already happened.
+type HookError struct {
+	Hook string
+	Err  error
+}
+
+func (e *HookError) Error() string { return fmt.Sprintf("%s hook: %v", e.Hook, e.Err) }
+func (e *HookError) Unwrap() error { return e.Err }
+
+var (
+	hooksMu sync.Mutex
+	hookFn  func(HookEvent) error
+)
+
+// SetHooks makes Checkout, CreateOrSwitch and DeleteBranch call run around
+// what they do: before switching, where an error aborts the switch, after
+// switching and after deleting. Errors are returned wrapped in a
+// *HookError. Switching to the current branch runs no hooks. A nil run
+// turns hooks off.
+func SetHooks(run func(HookEvent) error) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hookFn = run
+}
+
+func runHook(ev HookEvent) error {
+	hooksMu.Lock()
+	run := hookFn
+	hooksMu.Unlock()
+	if run == nil {
+		return nil
+	}
+	if err := run(ev); err != nil {
+		return &HookError{Hook: ev.Hook, Err: err}
+	}
+	return nil
+}
+
+// PostHookFailed reports whether err is the failure of a post-switch or
+// post-delete hook, meaning the operation itself succeeded.
+func PostHookFailed(err error) bool {
+	var h *HookError
+	return errors.As(err, &h) && h.Hook != HookPreSwitch
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// infoReflog is how many reflog entries BranchInfo holds.
+const infoReflog = 5
+
+// BranchInfo is what is known about a branch beyond its listing: the
+// facts a detail view shows, which cost a few git calls each.
+type BranchInfo struct {
+	Author      string    // "Name <email>" of the head commit
+	AuthoredAt  time.Time // when the head commit was written
+	Committer   string
+	CommittedAt time.Time
+
+	// Description is the branch's description, as set with
+	// `git branch --edit-description`; local branches only.
+	Description string
+
+	// Merged reports whether the branch is reachable from Base, the
+	// default branch, or was squash-merged into it (see MergedInto).
+	Merged bool
+	Base   string
+
+	// Worktree is the working tree the branch is checked out in, if any.
+	Worktree string
+
+	// Reflog holds the branch's latest reflog entries, newest first.
+	Reflog []ReflogEntry
+}
+
+// ReflogEntry is a change to a ref as its reflog records it.
+type ReflogEntry struct {
+	SHA     string // abbreviated
+	At      time.Time
+	Subject string // e.g. "commit: Fix crash" or "branch: Created from main"
+}
+
+// Info looks up the BranchInfo of b. Parts that cannot be looked up, such
+// as the merge status without a default branch, are left empty.
+func Info(repoPath string, b Branch) (BranchInfo, error) {
+	var info BranchInfo
+	out, err := gitLocal(repoPath, "log", "-1", "--format=%an <%ae>%x00%aI%x00%cn <%ce>%x00%cI", b.FullRef, "--")
+	if err != nil {
+		return info, err
+	}
+	if f := strings.Split(strings.TrimSpace(out), "\x00"); len(f) == 4 {
+		info.Author, info.Committer = f[0], f[2]
+		info.AuthoredAt, _ = parseGitDate(f[1])
+		info.CommittedAt, _ = parseGitDate(f[3])
+	}
+
+	if name, ok := strings.CutPrefix(b.FullRef, "refs/heads/"); ok {
+		out, err := gitLocal(repoPath, "config", "--get", "branch."+name+".description")
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+			// No description.
+		case err != nil:
+			return info, err
+		default:
+			info.Description = strings.TrimSpace(out)
+		}
+		if wts, err := Worktrees(repoPath); err == nil {
+			for _, wt := range wts {
+				if wt.Branch == name {
+					info.Worktree = wt.Path
+					break
+				}
+			}
+		}
+	}
+
+	if base, err := DefaultBranch(repoPath); err == nil {
+		if merged, err := MergedInto(repoPath, base, b.FullRef); err == nil {
+			info.Base, info.Merged = base, merged[b.FullRef]
+		}
+	}
+
+	// A ref without a reflog has nothing to show; that is not an error.
+	out, _ = gitLocal(repoPath, "reflog", "show", "--date=unix", "-n", strconv.Itoa(infoReflog), "--format=%h\t%gd\t%gs", b.FullRef, "--")
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.SplitN(line, "\t", 3)
+		if len(f) == 3 {
+			info.Reflog = append(info.Reflog, ReflogEntry{SHA: f[0], At: reflogTime(f[1]), Subject: f[2]})
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,30 @@
+package core
+
+import "strings"
+
+// ResolveItems turns arbitrary picker items into Branches. Items naming an
+// existing local or remote branch (as printed by `git branch -a`, including
+// a "remotes/" prefix) carry that branch's metadata while keeping the item
+// text as Name; anything else becomes a
+// Branch with only Name set and an empty FullRef. Outside a repository every
+// item is left unresolved.
+func ResolveItems(repoPath string, items []string) []Branch {
+	known := map[string]Branch{}
+	if branches, err := collectBranches(repoPath, ScopeAll); err == nil {
+		for _, b := range branches {
+			if _, dup := known[b.Name]; !dup || !b.IsRemote {
+				known[b.Name] = b
+			}
+		}
+	}
+	res := make([]Branch, 0, len(items))
+	for _, it := range items {
+		if b, ok := known[strings.TrimPrefix(it, "remotes/")]; ok {
+			b.Name = it
+			res = append(res, b)
+			continue
+		}
+		res = append(res, Branch{Name: it})
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Kinds of Action.
+const (
+	ActionSwitch = "switch"
+	ActionDelete = "delete"
+	ActionRename = "rename"
+)
+
+// Action is a change to a repository's branches that Undo can reverse.
+type Action struct {
+	Kind     string `json:"kind"`               // one of the Action* constants
+	Branch   string `json:"branch"`             // the branch switched to, deleted, or renamed to
+	Previous string `json:"previous,omitempty"` // switches: the branch switched away from; renames: the old name
+	SHA      string `json:"sha,omitempty"`      // deletes: the commit the branch pointed to
+}
+
+// String describes a, e.g. "switch from main to feat/x".
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionSwitch:
+		return fmt.Sprintf("switch from %s to %s", a.Previous, a.Branch)
+	case ActionDelete:
+		return fmt.Sprintf("delete of %s (was %s)", a.Branch, a.SHA[:min(7, len(a.SHA))])
+	case ActionRename:
+		return fmt.Sprintf("rename of %s to %s", a.Previous, a.Branch)
+	}
+	return a.Kind
+}
+
+var (
+	journalMu sync.Mutex
+	journalFn func(repoPath string, a Action)
+)
+
+// SetJournal makes every switch between branches, delete and rename call
+// record once it is done, for undoing it later with Undo. Switches from a
+// detached HEAD are not recorded, having no branch to go back to. A nil
+// record turns the journal off.
+func SetJournal(record func(repoPath string, a Action)) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	journalFn = record
+}
+
+func journal(repoPath string, a Action) {
+	journalMu.Lock()
+	record := journalFn
+	journalMu.Unlock()
+	if record != nil {
+		record(repoPath, a)
+	}
+}
+
+// Undo reverses a: it switches back to the previous branch (running the
+// switch hooks), recreates a deleted branch at its recorded SHA, or renames
+// a branch back. It refuses when the repository has moved on in a way that
+// makes the reversal wrong, e.g. when HEAD is no longer on the branch
+// switched to. Undoing is not itself journaled, but it is audited.
+func Undo(repoPath string, a Action) error {
+	switch a.Kind {
+	case ActionSwitch:
+		cur, err := currentName(repoPath)
+		if err != nil && !errors.Is(err, ErrDetachedHead) {
+			return err
+		}
+		if cur != a.Branch {
+			return fmt.Errorf("cannot undo the %s: HEAD is no longer on %s", a, a.Branch)
+		}
+		return switchBranch(repoPath, a.Previous, cur, []string{"switch", a.Previous}, false)
+	case ActionDelete:
+		if LocalBranchExists(repoPath, a.Branch) {
+			return fmt.Errorf("cannot undo the %s: a branch named %s exists again", a, a.Branch)
+		}
+		if _, err := git(repoPath, "branch", a.Branch, a.SHA); err != nil {
+			return err
+		}
+		audit(repoPath, Operation{Op: OpCreate, Branch: a.Branch, New: a.SHA})
+		return nil
+	case ActionRename:
+		if !LocalBranchExists(repoPath, a.Branch) {
+			return fmt.Errorf("cannot undo the %s: there is no branch %s anymore", a, a.Branch)
+		}
+		if LocalBranchExists(repoPath, a.Previous) {
+			return fmt.Errorf("cannot undo the %s: a branch named %s exists again", a, a.Previous)
+		}
+		if _, err := git(repoPath, "branch", "-m", a.Branch, a.Previous); err != nil {
+			return err
+		}
+		sha := auditSHA(repoPath, "refs/heads/"+a.Previous)
+		audit(repoPath, Operation{Op: ActionRename, Branch: a.Previous, Previous: a.Branch, Old: sha, New: sha})
+		return nil
+	}
+	return fmt.Errorf("cannot undo unknown action %q", strings.TrimSpace(a.Kind))
+}
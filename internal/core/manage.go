@@ -0,0 +1,450 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeleteBranch deletes a local branch and returns the SHA it pointed to so
+// callers can tell the user how to restore it. Without force, git refuses to
+// delete branches that are not fully merged. The post-delete hook runs
+// afterwards (see SetHooks).
+func DeleteBranch(repoPath, name string, force bool) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", errors.New("branch name required")
+	}
+	sha, err := git(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	if err != nil {
+		return "", errors.New("no such local branch: " + name)
+	}
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if _, err := git(repoPath, "branch", flag, name); err != nil {
+		return "", err
+	}
+	sha = strings.TrimSpace(sha)
+	audit(repoPath, Operation{Op: ActionDelete, Branch: name, Old: sha, Force: force})
+	journal(repoPath, Action{Kind: ActionDelete, Branch: name, SHA: sha})
+	return sha, runHook(HookEvent{Hook: HookPostDelete, RepoPath: repoPath, Branch: name, SHA: sha})
+}
+
+// Protected reports whether the local branch name is one that must not be
+// deleted behind the user's back: the default branch, or one matching any
+// of globs (matched as by Excluded).
+func Protected(repoPath, name string, globs []string) bool {
+	if def, err := DefaultBranch(repoPath); err == nil && def == name {
+		return true
+	}
+	return Excluded(Branch{Name: name}, globs)
+}
+
+// RenameBranch renames a local branch. An empty oldName renames the current
+// branch.
+func RenameBranch(repoPath, oldName, newName string) error {
+	if strings.TrimSpace(newName) == "" {
+		return errors.New("new branch name required")
+	}
+	if err := CheckName(newName); err != nil {
+		return err
+	}
+	if oldName == "" {
+		cur, err := currentName(repoPath)
+		if err != nil {
+			return err
+		}
+		oldName = cur
+	}
+	return renameBranch(repoPath, oldName, newName)
+}
+
+// renameBranch renames oldName to newName, recording the rename in the
+// audit log and the journal.
+func renameBranch(repoPath, oldName, newName string) error {
+	if _, err := git(repoPath, "branch", "-m", oldName, newName); err != nil {
+		return err
+	}
+	sha := auditSHA(repoPath, "refs/heads/"+newName)
+	audit(repoPath, Operation{Op: ActionRename, Branch: newName, Previous: oldName, Old: sha, New: sha})
+	journal(repoPath, Action{Kind: ActionRename, Branch: newName, Previous: oldName})
+	return nil
+}
+
+// Fetch fetches remote (or all remotes when empty), pruning deleted remote
+// branches when prune is set.
+func Fetch(repoPath, remote string, prune bool) error {
+	_, err := git(repoPath, fetchArgs(remote, prune)...)
+	return err
+}
+
+// ErrAuthRequired is wrapped by FetchNoPrompt and PushNoPrompt errors when a
+// remote asked for credentials or a key passphrase.
+var ErrAuthRequired = errors.New("the remote needs credentials")
+
+// FetchNoPrompt is Fetch for when git cannot use the terminal, e.g. under a
+// full-screen UI: instead of prompting for credentials or an SSH passphrase
+// (and waiting for input that never comes), git and ssh fail, with an error
+// wrapping ErrAuthRequired. Credential helpers and ssh-agent still work.
+// Retry with FetchCommand attached to the terminal to let the user answer.
+func FetchNoPrompt(repoPath, remote string, prune bool) error {
+	return gitNoPrompt(repoPath, fetchArgs(remote, prune))
+}
+
+// gitNoPrompt runs a network command, such as a fetch or a push, with
+// prompting disabled.
+func gitNoPrompt(repoPath string, args []string) error {
+	_, err := gitEnv(repoPath, noPromptEnv(repoPath), args...)
+	return authRequired(err)
+}
+
+// FetchProgress is FetchNoPrompt reporting git's progress as it goes; see
+// gitProgress. It stops fetching when ctx is done.
+func FetchProgress(ctx context.Context, repoPath, remote string, prune bool, report func(Progress)) error {
+	args := append([]string{"fetch", "--progress"}, fetchArgs(remote, prune)[1:]...)
+	_, err := gitProgress(ctx, repoPath, noPromptEnv(repoPath), report, args...)
+	return authRequired(err)
+}
+
+// noPromptEnv keeps git and ssh from prompting for credentials.
+func noPromptEnv(repoPath string) []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if os.Getenv("GIT_SSH_COMMAND") == "" && os.Getenv("GIT_SSH") == "" {
+		// A configured core.sshCommand may not be ssh; leave it alone.
+		if out, _ := git(repoPath, "config", "core.sshCommand"); strings.TrimSpace(out) == "" {
+			env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+		}
+	}
+	return env
+}
+
+// authRequired wraps err with ErrAuthRequired when git needed to prompt.
+func authRequired(err error) error {
+	if ErrorKind(err) == KindAuth {
+		return fmt.Errorf("%w: %v", ErrAuthRequired, err)
+	}
+	return err
+}
+
+// FetchCommand returns the command Fetch runs, for running with the
+// terminal attached so that git can ask for credentials.
+func FetchCommand(repoPath, remote string, prune bool) *exec.Cmd {
+	cmd := exec.Command(GitBin, fetchArgs(remote, prune)...)
+	cmd.Dir = repoPath
+	return cmd
+}
+
+func fetchArgs(remote string, prune bool) []string {
+	args := []string{"fetch"}
+	if prune {
+		args = append(args, "--prune")
+	}
+	if remote == "" {
+		return append(args, "--all")
+	}
+	return append(args, remote)
+}
+
+// authFailures are what git and ssh say when they needed to prompt.
+var authFailures = []string{
+	"terminal prompts disabled",
+	"could not read Username",
+	"could not read Password",
+	"Authentication failed",
+	"Permission denied (publickey",
+	"Host key verification failed",
+	"read_passphrase",
+}
+
+// PruneReason explains why a branch is a prune candidate.
+type PruneReason string
+
+const (
+	PruneMerged   PruneReason = "merged"   // fully merged into the base branch
+	PruneSquashed PruneReason = "squashed" // squash-merged into the base branch; deleting it needs force
+	PruneGone     PruneReason = "gone"     // upstream was deleted on the remote
+	PruneStale    PruneReason = "stale"    // no commits for longer than the stale threshold
+)
+
+// PruneCandidate is a local branch that is probably safe to delete.
+type PruneCandidate struct {
+	Branch Branch
+	Reason PruneReason
+}
+
+// PruneCandidates lists local branches that are merged or squash-merged into
+// base (the current branch when empty, or the default branch when detached),
+// whose upstream no longer exists, or, when staleAfter > 0, whose head
+// commit is older than staleAfter. The current branch and base itself are
+// never candidates.
+func PruneCandidates(repoPath, base string, staleAfter time.Duration) ([]PruneCandidate, error) {
+	if base == "" {
+		cur, err := GetCurrentBranch(repoPath)
+		switch {
+		case errors.Is(err, ErrDetachedHead):
+			if base, err = DefaultBranch(repoPath); err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, err
+		default:
+			base = cur.Name
+		}
+	}
+	out, err := git(repoPath, "for-each-ref", "--format="+refFormat, "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	branches := parseForEachRef(out, false)
+
+	mergedOut, err := git(repoPath, "for-each-ref", "--merged="+base, "--format=%(refname)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	merged := map[string]bool{}
+	for _, ref := range strings.Fields(mergedOut) {
+		merged[ref] = true
+	}
+	squashed := squashMergedRefs(repoPath, base, "refs/heads/")
+
+	trackOut, err := git(repoPath, "for-each-ref", "--format=%(refname)\t%(upstream:track)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	gone := map[string]bool{}
+	for _, line := range strings.Split(trackOut, "\n") {
+		ref, track, ok := strings.Cut(line, "\t")
+		if ok && track == "[gone]" {
+			gone[ref] = true
+		}
+	}
+
+	var cur string
+	if c, err := GetCurrentBranch(repoPath); err == nil {
+		cur = c.Name
+	}
+	var res []PruneCandidate
+	for _, b := range branches {
+		if b.Name == base || b.Name == cur {
+			continue
+		}
+		switch {
+		case merged[b.FullRef]:
+			res = append(res, PruneCandidate{Branch: b, Reason: PruneMerged})
+		case squashed[b.FullRef]:
+			res = append(res, PruneCandidate{Branch: b, Reason: PruneSquashed})
+		case gone[b.FullRef]:
+			res = append(res, PruneCandidate{Branch: b, Reason: PruneGone})
+		case staleAfter > 0 && b.HeadCommitAt != nil && time.Since(*b.HeadCommitAt) > staleAfter:
+			res = append(res, PruneCandidate{Branch: b, Reason: PruneStale})
+		}
+	}
+	return res, nil
+}
+
+// RecentBranch is a branch that HEAD was switched to.
+type RecentBranch struct {
+	Name string
+	At   time.Time
+}
+
+// RecentBranches returns up to n distinct branches most recently checked
+// out, newest first, skipping branches that no longer exist. Switches are
+// read from the reflog and from extra, which may add switches the reflog
+// no longer holds. n <= 0 means no limit.
+func RecentBranches(repoPath string, n int, extra []RecentBranch) ([]RecentBranch, error) {
+	out, err := git(repoPath, "reflog", "show", "--date=unix", "--format=%gd\t%gs", "HEAD", "--")
+	if err != nil {
+		return nil, err
+	}
+	existing, err := git(repoPath, "for-each-ref", "--format=%(refname:lstrip=2)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, name := range strings.Split(existing, "\n") {
+		exists[name] = true
+	}
+
+	switches := append([]RecentBranch(nil), extra...)
+	for _, line := range strings.Split(out, "\n") {
+		selector, subject, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		const marker = "checkout: moving from "
+		if !strings.HasPrefix(subject, marker) {
+			continue
+		}
+		if _, to, ok := strings.Cut(strings.TrimPrefix(subject, marker), " to "); ok {
+			switches = append(switches, RecentBranch{Name: to, At: reflogTime(selector)})
+		}
+	}
+	sort.SliceStable(switches, func(i, j int) bool { return switches[i].At.After(switches[j].At) })
+
+	seen := map[string]bool{}
+	var res []RecentBranch
+	for _, s := range switches {
+		if seen[s.Name] || !exists[s.Name] {
+			continue
+		}
+		seen[s.Name] = true
+		res = append(res, s)
+		if n > 0 && len(res) == n {
+			break
+		}
+	}
+	return res, nil
+}
+
+// TopLevel returns the absolute path of the working tree containing
+// repoPath.
+func TopLevel(repoPath string) (string, error) {
+	out, err := git(repoPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", errors.New("not inside a git working tree")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// HookPath returns where git looks for the hook called name, honoring
+// core.hooksPath.
+func HookPath(repoPath, name string) (string, error) {
+	out, err := git(repoPath, "rev-parse", "--path-format=absolute", "--git-path", "hooks/"+name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GitDirs returns the absolute git directory of the working tree at
+// repoPath, holding its HEAD, and the common directory holding the refs
+// shared by all worktrees. They are the same outside linked worktrees.
+func GitDirs(repoPath string) (gitDir, commonDir string, err error) {
+	out, err := git(repoPath, "rev-parse", "--path-format=absolute", "--git-dir", "--git-common-dir")
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		return "", "", fmt.Errorf("unexpected rev-parse output: %q", out)
+	}
+	return lines[0], lines[1], nil
+}
+
+// reflogTime extracts the timestamp from a selector such as
+// "HEAD@{1700000000}" as printed with --date=unix.
+func reflogTime(selector string) time.Time {
+	i := strings.Index(selector, "@{")
+	if i < 0 || !strings.HasSuffix(selector, "}") {
+		return time.Time{}
+	}
+	secs, err := strconv.ParseInt(selector[i+2:len(selector)-1], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
+// DefaultBranch returns the repository's default branch: the branch
+// origin/HEAD points at, or else main or master if one exists locally.
+func DefaultBranch(repoPath string) (string, error) {
+	out, err := git(repoPath, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD")
+	if err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(out), "refs/remotes/origin/"), nil
+	}
+	if ErrorKind(err) == KindNotRepository {
+		return "", err
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := git(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			return name, nil
+		}
+	}
+	return "", errors.New("cannot determine the default branch; set origin/HEAD with `git remote set-head origin --auto`")
+}
+
+// RemoteURL returns the fetch URL configured for remote.
+func RemoteURL(repoPath, remote string) (string, error) {
+	out, err := git(repoPath, "remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("no remote %q", remote)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// FastForward moves local branch name to its upstream if that is a
+// fast-forward. It reports whether the branch moved. A branch without an
+// upstream, or one that has diverged, is left alone and reported with an
+// error. The current branch is updated with `git merge --ff-only` so the
+// working tree follows.
+func FastForward(repoPath, name string) (bool, error) {
+	upstream, err := git(repoPath, "rev-parse", "--abbrev-ref", name+"@{upstream}")
+	if err != nil {
+		return false, fmt.Errorf("%s has no upstream", name)
+	}
+	upstream = strings.TrimSpace(upstream)
+	oldSHA, err := git(repoPath, "rev-parse", "refs/heads/"+name)
+	if err != nil {
+		return false, err
+	}
+	newSHA, err := git(repoPath, "rev-parse", upstream)
+	if err != nil {
+		return false, err
+	}
+	oldSHA, newSHA = strings.TrimSpace(oldSHA), strings.TrimSpace(newSHA)
+	if oldSHA == newSHA {
+		return false, nil
+	}
+	if _, err := git(repoPath, "merge-base", "--is-ancestor", oldSHA, newSHA); err != nil {
+		return false, fmt.Errorf("%s has diverged from %s", name, upstream)
+	}
+	if cur, err := GetCurrentBranch(repoPath); err == nil && cur.Name == name {
+		if _, err := git(repoPath, "merge", "--ff-only", "--quiet", upstream); err != nil {
+			return false, err
+		}
+	} else if _, err := git(repoPath, "update-ref", "refs/heads/"+name, newSHA, oldSHA); err != nil {
+		return false, err
+	}
+	audit(repoPath, Operation{Op: OpFastForward, Branch: name, Old: oldSHA, New: newSHA})
+	return true, nil
+}
+
+// Preview describes ref for a preview window: its last commits, then the
+// files it changed relative to the default branch (omitted for the default
+// branch itself or when there is none). color asks git for ANSI colors.
+// Partial clones list the changed files without line counts, which would
+// need every changed blob fetched.
+func Preview(repoPath, ref string, color bool) (string, error) {
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("unknown ref %q", ref)
+	}
+	colorArg := "--color=never"
+	if color {
+		colorArg = "--color=always"
+	}
+	log, err := git(repoPath, "log", colorArg, "--oneline", "--decorate", "-n", "20", ref, "--")
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %q", ref)
+	}
+	base, err := DefaultBranch(repoPath)
+	if err != nil || base == ref || "origin/"+base == ref {
+		return log, nil
+	}
+	statArg := "--stat"
+	if c, _ := Clone(repoPath); c.Partial() {
+		statArg = "--name-status"
+	}
+	stat, err := gitLocal(repoPath, "diff", colorArg, statArg, base+"..."+ref, "--")
+	if err != nil || stat == "" {
+		return log, nil
+	}
+	return log + "\nChanges since " + base + ":\n" + stat, nil
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchMode selects how ListBranchesRequest.Pattern is applied to branch
+// names. All modes are case-insensitive.
+type MatchMode int
+
+const (
+	MatchContains MatchMode = iota // substring anywhere in the name
+	MatchGlob                      // path.Match glob against the whole name, e.g. release/1.*
+	MatchRegex                     // regexp (RE2 syntax), unanchored
+	MatchFuzzy                     // pattern runes appear in order, e.g. "fl" matches feat/login
+)
+
+var matchModeNames = [...]string{"contains", "glob", "regex", "fuzzy"}
+
+func (m MatchMode) String() string {
+	if m < 0 || int(m) >= len(matchModeNames) {
+		return fmt.Sprintf("MatchMode(%d)", int(m))
+	}
+	return matchModeNames[m]
+}
+
+// ParseMatchMode parses a mode name as printed by MatchMode.String.
+func ParseMatchMode(s string) (MatchMode, error) {
+	for i, name := range matchModeNames {
+		if s == name {
+			return MatchMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown match mode %q; use contains, glob, regex or fuzzy", s)
+}
+
+// NewMatcher compiles pattern for mode into a predicate over branch names.
+// An empty pattern matches everything.
+func NewMatcher(mode MatchMode, pattern string) (func(name string) bool, error) {
+	if pattern == "" {
+		return func(string) bool { return true }, nil
+	}
+	switch mode {
+	case MatchGlob:
+		glob := strings.ToLower(pattern)
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		return func(name string) bool {
+			ok, _ := path.Match(glob, strings.ToLower(name))
+			return ok
+		}, nil
+	case MatchRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return regexp.MustCompile("(?i)" + pattern).MatchString, nil
+	case MatchFuzzy:
+		needle := strings.ToLower(pattern)
+		return func(name string) bool { return fuzzyMatch(needle, strings.ToLower(name)) }, nil
+	default:
+		needle := strings.ToLower(pattern)
+		return func(name string) bool { return strings.Contains(strings.ToLower(name), needle) }, nil
+	}
+}
+
+// fuzzyMatch reports whether the runes of needle occur in order in s,
+// ignoring whitespace in needle.
+func fuzzyMatch(needle, s string) bool {
+	for _, r := range needle {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// MatchIndexes returns the byte offsets in name of the characters that
+// pattern matches under mode, in order, so callers can show why a branch
+// matched. Globs mark their literal parts. It returns nil when pattern does
+// not match name, and when case folding changes the length of name.
+func MatchIndexes(mode MatchMode, pattern, name string) []int {
+	lower := strings.ToLower(name)
+	if pattern == "" || len(lower) != len(name) {
+		return nil
+	}
+	if match, err := NewMatcher(mode, pattern); err != nil || !match(name) {
+		return nil
+	}
+	var idx []int
+	mark := func(from, to int) {
+		for i := range name[from:to] {
+			idx = append(idx, from+i)
+		}
+	}
+	switch mode {
+	case MatchRegex:
+		re := regexp.MustCompile("(?i)" + pattern)
+		for _, loc := range re.FindAllStringIndex(name, -1) {
+			mark(loc[0], loc[1])
+		}
+	case MatchFuzzy:
+		at := 0
+		for _, r := range strings.ToLower(pattern) {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			i := strings.IndexRune(lower[at:], r)
+			if i < 0 {
+				return nil
+			}
+			idx = append(idx, at+i)
+			at += i + utf8.RuneLen(r)
+		}
+	case MatchGlob:
+		at := 0
+		for _, lit := range globLiterals(strings.ToLower(pattern)) {
+			i := strings.Index(lower[at:], lit)
+			if i < 0 {
+				break
+			}
+			mark(at+i, at+i+len(lit))
+			at += i + len(lit)
+		}
+	default:
+		needle := strings.ToLower(pattern)
+		for at := 0; ; {
+			i := strings.Index(lower[at:], needle)
+			if i < 0 {
+				break
+			}
+			mark(at+i, at+i+len(needle))
+			at += i + len(needle)
+		}
+	}
+	return idx
+}
+
+// globLiterals returns the runs of plain characters in a path.Match
+// pattern, between its wildcards and character classes.
+func globLiterals(glob string) []string {
+	var lits []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			lits = append(lits, cur.String())
+			cur.Reset()
+		}
+	}
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*', '?':
+			flush()
+		case '[':
+			flush()
+			if j := strings.IndexByte(glob[i+1:], ']'); j >= 0 {
+				i += j + 1
+			}
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				cur.WriteByte(glob[i])
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return lits
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NamePolicy, when set, is the pattern names of newly created and renamed
+// branches must match, e.g. ^(feat|fix|chore)/[a-z0-9-]+$. Branches checked
+// out from a remote keep their name regardless.
+var NamePolicy *regexp.Regexp
+
+// ErrNamePolicy is wrapped by errors for branch names NamePolicy rejects.
+var ErrNamePolicy = errors.New("branch name does not follow the naming policy")
+
+// ErrOptionLike is wrapped by errors for branch names and refs starting
+// with "-", which git would take for options.
+var ErrOptionLike = errors.New(`names starting with "-" are not allowed`)
+
+// checkArg rejects a branch name or ref that git would parse as an option.
+func checkArg(name string) error {
+	if strings.HasPrefix(name, "-") {
+		return fmt.Errorf("%w: %q", ErrOptionLike, name)
+	}
+	return nil
+}
+
+// CheckName reports whether name may be given to a new branch.
+func CheckName(name string) error {
+	if NamePolicy == nil || NamePolicy.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q does not match %s", ErrNamePolicy, name, NamePolicy)
+}
+
+// ValidateName reports whether name is a valid git branch name that
+// follows NamePolicy, for checking generated names before creating them.
+func ValidateName(repoPath, name string) error {
+	if _, err := git(repoPath, "check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	return CheckName(name)
+}
+
+// FollowsPolicy reports whether the branch b is named according to
+// NamePolicy, which is always the case without one. Remote branches are
+// judged without their remote name.
+func FollowsPolicy(b Branch) bool {
+	return NamePolicy == nil || NamePolicy.MatchString(HeadName(b))
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCanceled is wrapped by errors of git commands stopped through their
+// context, e.g. by the user.
+var ErrCanceled = errors.New("canceled")
+
+// Progress is a progress line git printed while running, such as
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s".
+type Progress struct {
+	Line    string
+	Percent int // the percentage in Line, -1 when there is none
+}
+
+// Phase splits a progress line with a percentage around it, as in
+// "Receiving objects" and "(450/1000), 1.20 MiB | 2.00 MiB/s".
+func (p Progress) Phase() (phase, rest string, ok bool) {
+	loc := percentRe.FindStringIndex(p.Line)
+	if loc == nil {
+		return "", "", false
+	}
+	return p.Line[:loc[0]], strings.TrimSpace(p.Line[loc[1]:]), true
+}
+
+// percentRe matches the percentage in a progress line.
+var percentRe = regexp.MustCompile(`:\s+(\d{1,3})%`)
+
+func parseProgress(line string) Progress {
+	p := Progress{Line: line, Percent: -1}
+	if m := percentRe.FindStringSubmatch(line); m != nil {
+		p.Percent, _ = strconv.Atoi(m[1])
+	}
+	return p
+}
+
+// gitProgress is gitEnv for long-running commands: instead of collecting
+// git's output silently it passes every line git writes to stderr, where
+// progress goes, to report as it arrives (report may be nil), and it stops
+// git with an error wrapping ErrCanceled when ctx is done. Lock failures
+// are not retried; the commands using it take locks only briefly, if at
+// all.
+func gitProgress(ctx context.Context, repoPath string, env []string, report func(Progress), args ...string) (string, error) {
+	limit := timeout(args)
+	run := ctx
+	if limit > 0 {
+		var cancel context.CancelFunc
+		run, cancel = context.WithTimeout(ctx, limit)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(run, GitBin, args...)
+	// Interrupt git as ^C would, letting it clean up (a half-made worktree,
+	// a fetch's temporary packs), and kill it if it does not exit shortly.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = time.Second
+	if repoPath != "" {
+		cmd.Dir = repoPath
+	}
+	cmd.Env = append(append(os.Environ(), fixedEnv...), env...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	// Read stderr through a pipe of our own rather than cmd.StderrPipe, so
+	// that WaitDelay also covers helpers (ssh, upload-pack) keeping it open
+	// after git exits.
+	pr, pw := io.Pipe()
+	cmd.Stderr = pw
+	var lines []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Progress lines are redrawn in place, ended by \r rather than \n;
+		// keep the last of them along with the other lines for error
+		// messages.
+		scan := bufio.NewScanner(pr)
+		scan.Split(scanProgress)
+		for scan.Scan() {
+			line := strings.TrimSpace(scan.Text())
+			if line == "" {
+				continue
+			}
+			if report != nil {
+				report(parseProgress(line))
+			}
+			if n := len(lines); n > 0 && samePhase(lines[n-1], line) {
+				lines[n-1] = line
+			} else {
+				lines = append(lines, line)
+			}
+		}
+		io.Copy(io.Discard, pr) // a line too long to scan
+	}()
+	start := time.Now()
+	err := cmd.Run()
+	pw.Close()
+	<-done
+	trace(repoPath, args, time.Since(start), err)
+	output := out.String()
+	if len(lines) > 0 {
+		output += strings.Join(lines, "\n") + "\n"
+	}
+	switch {
+	case ctx.Err() != nil:
+		return output, &GitError{Args: args, Output: output, Kind: KindUnknown, Err: ErrCanceled}
+	case run.Err() == context.DeadlineExceeded:
+		err = fmt.Errorf("%w after %s", ErrTimeout, limit)
+		return output, &GitError{Args: args, Output: output, Kind: KindTimeout, Err: err}
+	case err != nil:
+		return output, &GitError{Args: args, Output: output, Kind: classify(output), Err: err}
+	}
+	return output, nil
+}
+
+// samePhase reports whether two progress lines are updates of the same
+// step, such as two "Receiving objects:" lines.
+func samePhase(a, b string) bool {
+	pa, _, ok := Progress{Line: a}.Phase()
+	pb, _, _ := Progress{Line: b}.Phase()
+	return ok && pa == pb
+}
+
+// scanProgress is a bufio.SplitFunc splitting at \r as well as \n.
+func scanProgress(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
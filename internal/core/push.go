@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Push pushes the local branch to its remote, setting it as the branch's
+// upstream when setUpstream is set (for branches that have none yet, or
+// whose upstream should follow a rename). Otherwise a branch with an
+// upstream on that remote updates it, whatever its name; others push to a
+// remote branch of the same name. force overwrites the
+// remote branch, but only if it is where it was last fetched
+// (--force-with-lease), so that others' pushes are not lost.
+func Push(repoPath, branch string, setUpstream, force bool) error {
+	args, err := pushArgs(repoPath, branch, setUpstream, force)
+	if err != nil {
+		return err
+	}
+	old := auditSHA(repoPath, pushedRef(args))
+	if _, err = git(repoPath, args...); err != nil {
+		return err
+	}
+	auditPush(repoPath, branch, old, args, force)
+	return nil
+}
+
+// PushNoPrompt is Push for when git cannot use the terminal; see
+// FetchNoPrompt. Retry with PushCommand to let the user answer prompts.
+func PushNoPrompt(repoPath, branch string, setUpstream, force bool) error {
+	args, err := pushArgs(repoPath, branch, setUpstream, force)
+	if err != nil {
+		return err
+	}
+	old := auditSHA(repoPath, pushedRef(args))
+	if err := gitNoPrompt(repoPath, args); err != nil {
+		return err
+	}
+	auditPush(repoPath, branch, old, args, force)
+	return nil
+}
+
+// Pushed records in the audit log (see SetAudit) a push of branch made by
+// running PushCommand.
+func Pushed(repoPath, branch string, force bool) {
+	if args, err := pushArgs(repoPath, branch, false, force); err == nil {
+		auditPush(repoPath, branch, "", args, force)
+	}
+}
+
+// pushedRef returns the remote-tracking branch of the branch pushed by the
+// push command args, which the push updates.
+func pushedRef(args []string) string {
+	remote, refspec := args[len(args)-2], args[len(args)-1]
+	_, dst, _ := strings.Cut(refspec, ":")
+	return "refs/remotes/" + remote + "/" + strings.TrimPrefix(dst, "refs/heads/")
+}
+
+// auditPush records the push args of branch, whose remote-tracking branch
+// was at old before.
+func auditPush(repoPath, branch, old string, args []string, force bool) {
+	audit(repoPath, Operation{
+		Op:     OpPush,
+		Branch: branch,
+		Remote: args[len(args)-2],
+		Old:    old,
+		New:    auditSHA(repoPath, "refs/heads/"+branch),
+		Force:  force,
+	})
+}
+
+// PushCommand returns the command Push runs, for running with the terminal
+// attached so that git can ask for credentials. Once it succeeds, Pushed
+// records the push.
+func PushCommand(repoPath, branch string, setUpstream, force bool) (*exec.Cmd, error) {
+	args, err := pushArgs(repoPath, branch, setUpstream, force)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(GitBin, args...)
+	cmd.Dir = repoPath
+	return cmd, nil
+}
+
+func pushArgs(repoPath, branch string, setUpstream, force bool) ([]string, error) {
+	if branch == "" || strings.HasPrefix(branch, "-") {
+		return nil, errors.New("a local branch to push is required")
+	}
+	remote, err := PushRemote(repoPath, branch)
+	if err != nil {
+		return nil, err
+	}
+	dst := "refs/heads/" + branch
+	if merge := upstreamRef(repoPath, branch, remote); merge != "" && !setUpstream {
+		dst = merge
+	}
+	args := []string{"push"}
+	if setUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if force {
+		args = append(args, "--force-with-lease="+dst)
+	}
+	return append(args, remote, "refs/heads/"+branch+":"+dst), nil
+}
+
+// upstreamRef returns the branch on remote that branch tracks, e.g.
+// refs/heads/bugfix/123, or "" when it tracks none there.
+func upstreamRef(repoPath, branch, remote string) string {
+	if out, _ := git(repoPath, "config", "branch."+branch+".remote"); strings.TrimSpace(out) != remote {
+		return ""
+	}
+	out, _ := git(repoPath, "config", "branch."+branch+".merge")
+	if merge := strings.TrimSpace(out); strings.HasPrefix(merge, "refs/heads/") {
+		return merge
+	}
+	return ""
+}
+
+// ErrNoRemote is returned by PushRemote when the repository has no remote.
+var ErrNoRemote = errors.New("no remote to push to")
+
+// PushRemote returns the remote branch is pushed to, as git would choose
+// it: branch.<name>.pushRemote, remote.pushDefault or branch.<name>.remote,
+// falling back to origin or the only remote.
+func PushRemote(repoPath, branch string) (string, error) {
+	for _, key := range []string{"branch." + branch + ".pushRemote", "remote.pushDefault", "branch." + branch + ".remote"} {
+		if out, _ := git(repoPath, "config", key); strings.TrimSpace(out) != "" {
+			return strings.TrimSpace(out), nil
+		}
+	}
+	out, err := git(repoPath, "remote")
+	if err != nil {
+		return "", err
+	}
+	remotes := strings.Fields(out)
+	for _, r := range remotes {
+		if r == "origin" {
+			return r, nil
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0], nil
+	}
+	return "", ErrNoRemote
+}
+
+// HasUpstream reports whether the local branch has an upstream configured.
+func HasUpstream(repoPath, branch string) bool {
+	_, err := git(repoPath, "rev-parse", "--abbrev-ref", branch+"@{upstream}")
+	return err == nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo returns a clone of an empty bare repository, the clone's
+// remote, with a first commit on main pushed to it.
+func testRepo(t *testing.T) (clone, remote string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_STATE_HOME", home)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	dir := t.TempDir()
+	remote, clone = filepath.Join(dir, "remote.git"), filepath.Join(dir, "clone")
+	run(t, dir, "init", "-q", "--bare", "-b", "main", remote)
+	run(t, dir, "clone", "-q", remote, clone)
+	run(t, clone, "commit", "-q", "--allow-empty", "-m", "first")
+	run(t, clone, "push", "-q", "origin", "main")
+	return clone, remote
+}
+
+// run runs git in dir, failing the test if it fails, and returns its
+// trimmed output.
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestPushRenamedUpstream(t *testing.T) {
+	clone, remote := testRepo(t)
+	run(t, clone, "push", "-q", "origin", "main:bugfix/123")
+	run(t, clone, "fetch", "-q")
+	run(t, clone, "switch", "-q", "-c", "fix", "--track", "origin/bugfix/123")
+	run(t, clone, "commit", "-q", "--allow-empty", "-m", "fix it")
+
+	if err := Push(clone, "fix", false, false); err != nil {
+		t.Fatal(err)
+	}
+	head := run(t, clone, "rev-parse", "fix")
+	if got := run(t, remote, "rev-parse", "bugfix/123"); got != head {
+		t.Errorf("remote bugfix/123 is at %s, want %s", got, head)
+	}
+	if out := run(t, remote, "branch", "--list", "fix"); out != "" {
+		t.Errorf("push created a remote branch fix")
+	}
+	if got := run(t, clone, "rev-parse", "origin/bugfix/123"); got != head {
+		t.Errorf("origin/bugfix/123 is at %s, want %s", got, head)
+	}
+
+	// Forcing leases the upstream, which is where it was last fetched.
+	run(t, clone, "commit", "-q", "--amend", "--allow-empty", "-m", "fix it better")
+	if err := Push(clone, "fix", false, true); err != nil {
+		t.Fatal(err)
+	}
+	head = run(t, clone, "rev-parse", "fix")
+	if got := run(t, remote, "rev-parse", "bugfix/123"); got != head {
+		t.Errorf("after forcing, remote bugfix/123 is at %s, want %s", got, head)
+	}
+
+	// Setting the upstream moves it to a branch of the same name, as after
+	// renaming the branch.
+	if err := Push(clone, "fix", true, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(t, remote, "rev-parse", "fix"); got != head {
+		t.Errorf("remote fix is at %s, want %s", got, head)
+	}
+	if got := run(t, clone, "rev-parse", "--abbrev-ref", "fix@{upstream}"); got != "origin/fix" {
+		t.Errorf("fix tracks %s, want origin/fix", got)
+	}
+}
+
+func TestPushNewBranch(t *testing.T) {
+	clone, remote := testRepo(t)
+	run(t, clone, "switch", "-q", "-c", "feat")
+	run(t, clone, "commit", "-q", "--allow-empty", "-m", "feature")
+
+	if err := Push(clone, "feat", true, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := run(t, remote, "rev-parse", "feat"), run(t, clone, "rev-parse", "feat"); got != want {
+		t.Errorf("remote feat is at %s, want %s", got, want)
+	}
+	if got := run(t, clone, "rev-parse", "--abbrev-ref", "feat@{upstream}"); got != "origin/feat" {
+		t.Errorf("feat tracks %s, want origin/feat", got)
+	}
+}
@@ -0,0 +1,279 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryTerm is one whitespace-separated term of a filter query. Key is empty
+// for plain words, which are matched against branch names.
+type QueryTerm struct {
+	Key    string
+	Value  string
+	Negate bool // written with a leading "-"
+}
+
+// queryKeys are the keys understood in "key:value" terms. A term with any
+// other key is a plain word, so names containing ":" can still be searched.
+var queryKeys = map[string]bool{
+	"author": true, // author name or email contains value; "me" is user.email
+	"before": true, // head commit strictly before the date
+	"after":  true, // head commit on or after the date
+	"since":  true, // same as after
+	"until":  true, // head commit on or before the date (through its end for days)
+	"merged": true, // true/false: merged into the default branch
+	"remote": true, // true/false: remote-tracking branch
+}
+
+// ParseQuery splits a filter query such as
+//
+//	author:alice before:2024-01-01 merged:false feat
+//
+// into terms and checks their values. Terms are separated by whitespace;
+// double quotes group words (author:"Jane Doe"), and a leading "-" negates a
+// term. All terms must hold for a branch to match.
+func ParseQuery(q string) ([]QueryTerm, error) {
+	var terms []QueryTerm
+	for _, tok := range tokenize(q) {
+		var t QueryTerm
+		if len(tok) > 1 && strings.HasPrefix(tok, "-") {
+			t.Negate, tok = true, tok[1:]
+		}
+		if k, v, ok := strings.Cut(tok, ":"); ok && queryKeys[strings.ToLower(k)] {
+			t.Key, t.Value = strings.ToLower(k), unquote(v)
+		} else {
+			t.Value = unquote(tok)
+		}
+		if err := checkTerm(t); err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+func checkTerm(t QueryTerm) error {
+	switch t.Key {
+	case "before", "after", "since", "until":
+		_, _, err := ParseTime(t.Value, time.Now())
+		return err
+	case "merged", "remote":
+		if _, err := strconv.ParseBool(t.Value); err != nil {
+			return fmt.Errorf("%s: expects true or false, not %q", t.Key, t.Value)
+		}
+	case "author":
+		if t.Value == "" {
+			return fmt.Errorf("author: needs a value")
+		}
+	}
+	return nil
+}
+
+// tokenize splits q at whitespace outside double quotes. Quotes are kept
+// for unquote so that `"-x"` stays a word rather than a negation.
+func tokenize(q string) []string {
+	var toks []string
+	var cur strings.Builder
+	inQuote := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t'):
+			if cur.Len() > 0 {
+				toks = append(toks, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		toks = append(toks, cur.String())
+	}
+	return toks
+}
+
+func unquote(s string) string {
+	return strings.ReplaceAll(s, `"`, "")
+}
+
+// relativeUnits are the suffixes of relative times such as "2w" (two weeks
+// ago), in the order they are tried.
+var relativeUnits = []struct {
+	suffix string
+	apply  func(t time.Time, n int) time.Time
+}{
+	{"mo", func(t time.Time, n int) time.Time { return t.AddDate(0, -n, 0) }},
+	{"h", func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Hour) }},
+	{"d", func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -n) }},
+	{"w", func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -7*n) }},
+	{"y", func(t time.Time, n int) time.Time { return t.AddDate(-n, 0, 0) }},
+}
+
+// ParseTime parses a point in time given as a date (2006-01-02, local
+// time), an RFC 3339 timestamp, "today", "yesterday", or an amount of time
+// before now such as 36h, 14d, 2w, 6mo or 1y. day reports whether s named a
+// whole day, starting at the returned time.
+func ParseTime(s string, now time.Time) (t time.Time, day bool, err error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "today":
+		return midnight, true, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), true, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, u := range relativeUnits {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, u.suffix)); err == nil && strings.HasSuffix(s, u.suffix) && n >= 0 {
+			return u.apply(now, n), false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q; use YYYY-MM-DD, today, yesterday or an age such as 14d, 2w, 6mo", s)
+}
+
+// CompileQuery parses q (see ParseQuery) into a predicate over branches.
+// Plain words are matched against branch names using mode. Repository data
+// that some terms need, such as the set of merged branches, is read from
+// repoPath the first time it is used.
+func CompileQuery(repoPath, q string, mode MatchMode) (func(Branch) bool, error) {
+	terms, err := ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var merged map[string]bool
+	isMerged := func(b Branch) bool {
+		if merged == nil {
+			merged = mergedRefs(repoPath)
+		}
+		return merged[b.FullRef]
+	}
+
+	preds := make([]func(Branch) bool, 0, len(terms))
+	for _, t := range terms {
+		var p func(Branch) bool
+		switch t.Key {
+		case "":
+			m, err := NewMatcher(mode, t.Value)
+			if err != nil {
+				return nil, err
+			}
+			p = func(b Branch) bool { return m(b.Name) }
+		case "author":
+			if strings.EqualFold(t.Value, "me") {
+				email, err := UserEmail(repoPath)
+				if err != nil {
+					return nil, errors.New("author:me needs git's user.email to be set")
+				}
+				p = func(b Branch) bool { return b.AuthorEmail != nil && strings.EqualFold(*b.AuthorEmail, email) }
+				break
+			}
+			want := strings.ToLower(t.Value)
+			p = func(b Branch) bool {
+				return containsFold(b.Author, want) || containsFold(b.AuthorEmail, want)
+			}
+		case "before", "after", "since", "until":
+			at, day, _ := ParseTime(t.Value, now)
+			if t.Key == "until" && day {
+				// Through the end of that day.
+				at = at.AddDate(0, 0, 1)
+			}
+			// until is "before" with an inclusive bound; since is "after".
+			before := t.Key == "before" || t.Key == "until"
+			inclusive := t.Key == "until" && !day
+			p = func(b Branch) bool {
+				if b.HeadCommitAt == nil {
+					return false
+				}
+				if inclusive && b.HeadCommitAt.Equal(at) {
+					return true
+				}
+				return b.HeadCommitAt.Before(at) == before
+			}
+		case "merged":
+			want, _ := strconv.ParseBool(t.Value)
+			p = func(b Branch) bool { return isMerged(b) == want }
+		case "remote":
+			want, _ := strconv.ParseBool(t.Value)
+			p = func(b Branch) bool { return b.IsRemote == want }
+		}
+		if t.Negate {
+			inner := p
+			p = func(b Branch) bool { return !inner(b) }
+		}
+		preds = append(preds, p)
+	}
+	return func(b Branch) bool {
+		for _, p := range preds {
+			if !p(b) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// UserEmail returns the user.email git uses for commits in repoPath.
+func UserEmail(repoPath string) (string, error) {
+	out, err := git(repoPath, "config", "user.email")
+	email := strings.TrimSpace(out)
+	if err != nil || email == "" {
+		return "", errors.New("git's user.email is not set")
+	}
+	return email, nil
+}
+
+func containsFold(s *string, lowerSub string) bool {
+	return s != nil && strings.Contains(strings.ToLower(*s), lowerSub)
+}
+
+// mergedRefs returns the full refs of the local and remote branches merged
+// into the default branch. Errors leave the set empty, so merged:true
+// matches nothing.
+func mergedRefs(repoPath string) map[string]bool {
+	base, err := DefaultBranch(repoPath)
+	if err != nil {
+		return map[string]bool{}
+	}
+	res, err := MergedInto(repoPath, base, "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return map[string]bool{}
+	}
+	return res
+}
+
+// QueryMatchIndexes returns the byte offsets in name that the plain words
+// of query q match under mode (see MatchIndexes), in increasing order.
+// Other terms, such as author:, say nothing about the name.
+func QueryMatchIndexes(q string, mode MatchMode, name string) []int {
+	terms, err := ParseQuery(q)
+	if err != nil {
+		return nil
+	}
+	seen := map[int]bool{}
+	for _, t := range terms {
+		if t.Key != "" || t.Negate {
+			continue
+		}
+		for _, i := range MatchIndexes(mode, t.Value, name) {
+			seen[i] = true
+		}
+	}
+	idx := make([]int, 0, len(seen))
+	for i := range seen {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
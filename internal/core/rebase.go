@@ -0,0 +1,31 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RebaseCommand returns `git rebase -i onto` for the current branch, for
+// running with the terminal attached so that the user's editor can edit the
+// todo list.
+func RebaseCommand(repoPath, onto string) *exec.Cmd {
+	cmd := exec.Command(GitBin, "rebase", "--interactive", onto)
+	cmd.Dir = repoPath
+	return cmd
+}
+
+// RebaseInProgress reports whether a rebase stopped part way, e.g. on a
+// conflict or an edit, and waits for `git rebase --continue` or --abort.
+func RebaseInProgress(repoPath string) bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := git(repoPath, "rev-parse", "--path-format=absolute", "--git-path", dir)
+		if err != nil {
+			return false
+		}
+		if _, err := os.Stat(strings.TrimSpace(path)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckBranchName reports why name cannot be a branch name, following the
+// rules of `git check-ref-format --branch` without running git, so that it
+// can be checked as it is typed. It does not apply NamePolicy (see
+// CheckName).
+func CheckBranchName(name string) error {
+	reason := branchNameProblem(name)
+	if reason == "" {
+		return nil
+	}
+	return fmt.Errorf("%q is not a valid branch name: %s", name, reason)
+}
+
+func branchNameProblem(name string) string {
+	switch {
+	case name == "":
+		return "it is empty"
+	case name == "HEAD" || name == "@":
+		return "it is reserved"
+	case strings.HasPrefix(name, "-"):
+		return "it starts with -"
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return "it starts or ends with /"
+	case strings.HasSuffix(name, "."):
+		return "it ends with ."
+	case strings.Contains(name, "//"):
+		return "it contains //"
+	case strings.Contains(name, ".."):
+		return "it contains .."
+	case strings.Contains(name, "@{"):
+		return "it contains @{"
+	}
+	for _, r := range name {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			return "it contains a control character"
+		case r == ' ':
+			return "it contains a space"
+		case strings.ContainsRune(`~^:?*[\`, r):
+			return fmt.Sprintf("it contains %c", r)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		switch {
+		case strings.HasPrefix(part, "."):
+			return "a part of it starts with ."
+		case strings.HasSuffix(part, ".lock"):
+			return "a part of it ends with .lock"
+		}
+	}
+	return ""
+}
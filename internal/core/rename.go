@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BranchRename is one rename of a batch planned by PlanRenames.
+type BranchRename struct {
+	Old      string `json:"old"`
+	New      string `json:"new"`
+	Upstream string `json:"upstream,omitempty"` // e.g. origin/old/x; "" without one
+
+	// Remote and RemoteBranch locate an upstream on a remote, e.g. origin
+	// and old/x; both are "" without one, or for an upstream that is a
+	// local branch.
+	Remote       string `json:"remote,omitempty"`
+	RemoteBranch string `json:"remoteBranch,omitempty"`
+}
+
+// PlanRenames pairs each local branch matching the pattern from with its
+// new name, made from the pattern to. Each pattern holds one "*", which
+// stands for any part of a name, slashes included, so "old/*" and "new/*"
+// move every branch under old/ to new/. A pattern without "*" names a
+// single branch. The batch is refused if any new name is invalid (see
+// CheckName), already taken or given to two branches; RenameAll undoes the
+// renames made before one that fails regardless, so that a batch renames
+// all of the branches or none.
+func PlanRenames(repoPath, from, to string) ([]BranchRename, error) {
+	if strings.Count(from, "*") > 1 || strings.Count(to, "*") != strings.Count(from, "*") {
+		return nil, fmt.Errorf("--from and --to must both hold one * or neither")
+	}
+	prefix, suffix, wild := strings.Cut(from, "*")
+	out, err := git(repoPath, "for-each-ref", "--format=%(refname:lstrip=2)\t%(upstream:short)\t%(upstream:remotename)\t%(upstream:remoteref)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	var plan []BranchRename
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 || fields[0] == "" {
+			continue
+		}
+		name, upstream := fields[0], fields[1]
+		existing[name] = true
+		var newName string
+		switch {
+		case !wild && name == from:
+			newName = to
+		case wild && len(name) > len(prefix)+len(suffix) && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix):
+			newName = strings.Replace(to, "*", name[len(prefix):len(name)-len(suffix)], 1)
+		default:
+			continue
+		}
+		if newName != name {
+			r := BranchRename{Old: name, New: newName, Upstream: upstream}
+			// branch.<name>.remote is "." for an upstream in the repository.
+			if remote := fields[2]; remote != "" && remote != "." {
+				r.Remote, r.RemoteBranch = remote, strings.TrimPrefix(fields[3], "refs/heads/")
+			}
+			plan = append(plan, r)
+		}
+	}
+	if len(plan) == 0 {
+		return nil, fmt.Errorf("no local branch matches %s", from)
+	}
+	taken := map[string]string{}
+	for _, r := range plan {
+		if err := CheckName(r.New); err != nil {
+			return nil, fmt.Errorf("%s -> %s: %w", r.Old, r.New, err)
+		}
+		if other, ok := taken[r.New]; ok {
+			return nil, fmt.Errorf("%s and %s would both be renamed to %s", other, r.Old, r.New)
+		}
+		taken[r.New] = r.Old
+	}
+	for _, r := range plan {
+		// A name freed by another rename of the batch still blocks it:
+		// git renames one branch at a time.
+		if existing[r.New] {
+			return nil, fmt.Errorf("%s -> %s: a branch named %s already exists", r.Old, r.New, r.New)
+		}
+	}
+	return plan, nil
+}
+
+// RenameAll makes the renames of plan in order. If one fails, those made
+// before it are undone, and the error says which failed.
+func RenameAll(repoPath string, plan []BranchRename) error {
+	for i, r := range plan {
+		if err := RenameBranch(repoPath, r.Old, r.New); err != nil {
+			for _, done := range slices.Backward(plan[:i]) {
+				// Not RenameBranch: the old names need not follow NamePolicy.
+				if uerr := renameBranch(repoPath, done.New, done.Old); uerr != nil {
+					return fmt.Errorf("%s -> %s: %w (and renaming %s back failed: %v)", r.Old, r.New, err, done.New, uerr)
+				}
+			}
+			return fmt.Errorf("%s -> %s: %w; no branch was renamed", r.Old, r.New, err)
+		}
+	}
+	return nil
+}
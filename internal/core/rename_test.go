@@ -0,0 +1,61 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanRenamesUpstreams(t *testing.T) {
+	clone, _ := testRepo(t)
+	run(t, clone, "push", "-q", "origin", "main:old/x")
+	run(t, clone, "fetch", "-q")
+	run(t, clone, "branch", "-q", "--track", "old/remote", "origin/old/x")
+	run(t, clone, "branch", "-q", "--track", "old/local", "main")
+	run(t, clone, "branch", "-q", "old/none")
+
+	plan, err := PlanRenames(clone, "old/*", "new/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]BranchRename{
+		"old/local":  {Old: "old/local", New: "new/local", Upstream: "main"},
+		"old/none":   {Old: "old/none", New: "new/none"},
+		"old/remote": {Old: "old/remote", New: "new/remote", Upstream: "origin/old/x", Remote: "origin", RemoteBranch: "old/x"},
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("got %d renames, want %d: %+v", len(plan), len(want), plan)
+	}
+	for _, r := range plan {
+		if r != want[r.Old] {
+			t.Errorf("got %+v, want %+v", r, want[r.Old])
+		}
+	}
+}
+
+func TestRenameAllUndoesOnFailure(t *testing.T) {
+	clone, _ := testRepo(t)
+	run(t, clone, "branch", "-q", "old/a")
+	run(t, clone, "branch", "-q", "old/b")
+	run(t, clone, "branch", "-q", "taken")
+
+	// PlanRenames refuses taken names; a rename can fail regardless, e.g.
+	// when another process creates the branch in the meantime.
+	plan := []BranchRename{{Old: "old/a", New: "new/a"}, {Old: "old/b", New: "taken"}}
+	err := RenameAll(clone, plan)
+	if err == nil || !strings.Contains(err.Error(), "old/b -> taken") {
+		t.Fatalf("got %v, want the rename of old/b to fail", err)
+	}
+	branches := run(t, clone, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if got, want := strings.Fields(branches), []string{"main", "old/a", "old/b", "taken"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got branches %v, want %v", got, want)
+	}
+
+	plan[1].New = "new/b"
+	if err := RenameAll(clone, plan); err != nil {
+		t.Fatal(err)
+	}
+	branches = run(t, clone, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if got, want := strings.Fields(branches), []string{"main", "new/a", "new/b", "taken"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got branches %v, want %v", got, want)
+	}
+}
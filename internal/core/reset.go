@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UpstreamReset describes moving a local branch to its upstream, e.g. after
+// teammates force-pushed a rewritten history.
+type UpstreamReset struct {
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream"` // e.g. origin/feat/x
+	From     string `json:"from"`     // the SHA the branch is at
+	To       string `json:"to"`       // the SHA of the upstream
+	Dropped  int    `json:"dropped"`  // commits only on the branch, which the reset drops
+}
+
+// PlanUpstreamReset looks up what resetting the local branch name to its
+// upstream would do, for confirming it before calling ResetToUpstream.
+func PlanUpstreamReset(repoPath, name string) (UpstreamReset, error) {
+	r := UpstreamReset{Branch: name}
+	out, err := git(repoPath, "rev-parse", "--abbrev-ref", name+"@{upstream}")
+	if err != nil {
+		return r, fmt.Errorf("%s has no upstream", name)
+	}
+	r.Upstream = strings.TrimSpace(out)
+	if out, err = git(repoPath, "rev-parse", "refs/heads/"+name); err != nil {
+		return r, err
+	}
+	r.From = strings.TrimSpace(out)
+	if out, err = git(repoPath, "rev-parse", r.Upstream+"^{commit}"); err != nil {
+		return r, err
+	}
+	r.To = strings.TrimSpace(out)
+	if out, err = git(repoPath, "rev-list", "--count", r.To+".."+r.From); err != nil {
+		return r, err
+	}
+	r.Dropped, _ = strconv.Atoi(strings.TrimSpace(out))
+	return r, nil
+}
+
+// ResetToUpstream carries out r, provided the branch has not moved since it
+// was planned. The current branch is reset with `git reset --hard`, so the
+// working tree follows and uncommitted changes are lost; other branches are
+// moved with `git update-ref`. Either way the reflog notes the reset, so
+// `git reset --hard <branch>@{1}` or r.From undoes it.
+func ResetToUpstream(repoPath string, r UpstreamReset) error {
+	note := "gotobranch: reset to " + r.Upstream
+	cur, _ := currentName(repoPath)
+	if r.Branch == cur {
+		out, err := git(repoPath, "rev-parse", "HEAD")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(out) != r.From {
+			return fmt.Errorf("%s moved since the reset was planned", r.Branch)
+		}
+		if _, err = gitEnv(repoPath, []string{"GIT_REFLOG_ACTION=" + note}, "reset", "--hard", "--quiet", r.To); err != nil {
+			return err
+		}
+		audit(repoPath, Operation{Op: OpReset, Branch: r.Branch, Old: r.From, New: r.To})
+		return nil
+	}
+	if wts, err := Worktrees(repoPath); err == nil {
+		for _, wt := range wts {
+			if wt.Branch == r.Branch {
+				return fmt.Errorf("%s is checked out in %s; reset it there", r.Branch, wt.Path)
+			}
+		}
+	}
+	if _, err := git(repoPath, "update-ref", "-m", note, "refs/heads/"+r.Branch, r.To, r.From); err != nil {
+		return err
+	}
+	audit(repoPath, Operation{Op: OpReset, Branch: r.Branch, Old: r.From, New: r.To})
+	return nil
+}
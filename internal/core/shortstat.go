@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ShortStat sums up a diff as `git diff --shortstat` does.
+type ShortStat struct {
+	Files      int `json:"files"`
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+}
+
+// String renders s like git, e.g. "3 files changed, +10 -2".
+func (s ShortStat) String() string {
+	files := "files"
+	if s.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%d %s changed, +%d -%d", s.Files, files, s.Insertions, s.Deletions)
+}
+
+// BranchShortStat sums up what branch ref changes relative to the default
+// branch (see DefaultBranch), since it forked from it, and returns the
+// default branch's short name (e.g. main, or origin/main without a local
+// branch) with it. Results are remembered for as long as neither side
+// moves.
+func BranchShortStat(repoPath, ref string) (ShortStat, string, error) {
+	base, err := defaultBranchRef(repoPath)
+	if err != nil {
+		return ShortStat{}, "", err
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(base, "refs/heads/"), "refs/remotes/")
+	out, err := gitLocal(repoPath, "rev-parse", base+"^{commit}", ref+"^{commit}")
+	if err != nil {
+		return ShortStat{}, name, fmt.Errorf("cannot resolve %s", ref)
+	}
+	shas := strings.Fields(out)
+	if len(shas) != 2 {
+		return ShortStat{}, name, fmt.Errorf("unexpected rev-parse output: %q", out)
+	}
+	key := shas[0] + "..." + shas[1]
+	shortStatsMu.Lock()
+	s, ok := shortStats[key]
+	shortStatsMu.Unlock()
+	if ok {
+		return s, name, nil
+	}
+	if out, err = gitLocal(repoPath, "diff", "--shortstat", key, "--"); err != nil {
+		return ShortStat{}, name, err
+	}
+	s = parseShortStat(out)
+	shortStatsMu.Lock()
+	shortStats[key] = s
+	shortStatsMu.Unlock()
+	return s, name, nil
+}
+
+// shortStats remembers diffs by "<base sha>...<tip sha>", which never
+// change.
+var (
+	shortStatsMu sync.Mutex
+	shortStats   = map[string]ShortStat{}
+)
+
+var shortStatRe = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// parseShortStat reads a line such as " 3 files changed, 10 insertions(+),
+// 2 deletions(-)"; git leaves out the zero counts, and prints nothing for
+// an empty diff.
+func parseShortStat(out string) ShortStat {
+	var s ShortStat
+	for _, m := range shortStatRe.FindAllStringSubmatch(out, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "file":
+			s.Files = n
+		case "insertion":
+			s.Insertions = n
+		case "deletion":
+			s.Deletions = n
+		}
+	}
+	return s
+}
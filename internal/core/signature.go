@@ -0,0 +1,91 @@
+package core
+
+import "strings"
+
+// Signature is the GPG, SSH or X.509 signature of a commit as git verified
+// it.
+type Signature struct {
+	Status string `json:"status"`           // one of the Sig* constants
+	Signer string `json:"signer,omitempty"` // e.g. "Alice <alice@example.com>"; "" when unsigned or unknown
+	Key    string `json:"key,omitempty"`    // the key's fingerprint or ID
+}
+
+// Signature statuses, from git's %G? placeholder.
+const (
+	SigGood       = "good"       // valid, from a trusted key
+	SigUntrusted  = "untrusted"  // valid, from a key of unknown validity
+	SigExpired    = "expired"    // valid, but the signature or key has expired
+	SigRevoked    = "revoked"    // valid, from a revoked key
+	SigBad        = "bad"        // does not match the commit
+	SigUnverified = "unverified" // cannot be checked, e.g. the key is missing
+	SigUnsigned   = "unsigned"
+)
+
+// Signed reports whether the commit carries a signature at all.
+func (s Signature) Signed() bool { return s.Status != "" && s.Status != SigUnsigned }
+
+// sigStatuses maps %G? to the Sig* constants.
+var sigStatuses = map[string]string{
+	"G": SigGood,
+	"U": SigUntrusted,
+	"X": SigExpired,
+	"Y": SigExpired,
+	"R": SigRevoked,
+	"B": SigBad,
+	"E": SigUnverified,
+	"N": SigUnsigned,
+}
+
+// sigBatch bounds the commits verified per git invocation, keeping the
+// command line short.
+const sigBatch = 200
+
+// Signatures verifies the signatures of commits, keyed by SHA. Verifying
+// runs gpg (or ssh-keygen) per signed commit, so callers look up only the
+// commits they show.
+func Signatures(repoPath string, shas []string) (map[string]Signature, error) {
+	res := make(map[string]Signature, len(shas))
+	for len(shas) > 0 {
+		batch := shas[:min(sigBatch, len(shas))]
+		shas = shas[len(batch):]
+		args := append([]string{"log", "--no-walk=unsorted", "--format=%H%x09%G?%x09%GS%x09%GK"}, batch...)
+		out, err := gitLocal(repoPath, append(args, "--")...)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			parts := strings.SplitN(line, "\t", 4)
+			if len(parts) < 4 {
+				continue
+			}
+			res[parts[0]] = Signature{Status: sigStatuses[parts[1]], Signer: parts[2], Key: parts[3]}
+		}
+	}
+	return res, nil
+}
+
+// addSignatures attaches the signatures of branches' head commits.
+func addSignatures(repoPath string, branches []Branch) error {
+	var shas []string
+	for _, b := range branches {
+		if b.HeadCommitSHA != nil {
+			shas = append(shas, *b.HeadCommitSHA)
+		}
+	}
+	if len(shas) == 0 {
+		return nil
+	}
+	sigs, err := Signatures(repoPath, shas)
+	if err != nil {
+		return err
+	}
+	for i, b := range branches {
+		if b.HeadCommitSHA == nil {
+			continue
+		}
+		if s, ok := sigs[*b.HeadCommitSHA]; ok {
+			branches[i].Signature = &s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// SquashMerges makes MergedInto also count branches whose changes reached
+// the base through a squash merge or a rebase, as hosting services do them,
+// which leave the branch's own commits unmerged. Checking costs a few git
+// commands per unmerged branch, so results are cached on disk.
+var SquashMerges = true
+
+// MergedInto returns the full refs of the branches under prefixes (e.g.
+// "refs/heads/") that are merged into base: reachable from it or, with
+// SquashMerges, squash-merged into it.
+func MergedInto(repoPath, base string, prefixes ...string) (map[string]bool, error) {
+	args := append([]string{"for-each-ref", "--merged=" + base, "--format=%(refname)"}, prefixes...)
+	out, err := gitLocal(repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	merged := squashMergedRefs(repoPath, base, prefixes...)
+	for _, ref := range strings.Fields(out) {
+		merged[ref] = true
+	}
+	return merged, nil
+}
+
+// squashMergedRefs returns the full refs of the branches under prefixes
+// that are squash-merged into base but not reachable from it; none unless
+// SquashMerges is set. Errors leave the set empty.
+func squashMergedRefs(repoPath, base string, prefixes ...string) map[string]bool {
+	merged := map[string]bool{}
+	if !SquashMerges {
+		return merged
+	}
+	baseSHA, err := gitLocal(repoPath, "rev-parse", "--verify", "--quiet", base+"^{commit}")
+	if err != nil {
+		return merged
+	}
+	baseSHA = strings.TrimSpace(baseSHA)
+	args := append([]string{"for-each-ref", "--no-merged=" + base, "--format=%(refname)\t%(objectname)"}, prefixes...)
+	out, err := gitLocal(repoPath, args...)
+	if err != nil {
+		return merged
+	}
+	c := loadSquashCache(repoPath)
+	defer c.save()
+	for _, line := range strings.Split(out, "\n") {
+		ref, sha, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		key := sha + " " + base
+		e, ok := c.entries[key]
+		if !ok || (!e.Merged && e.Base != baseSHA) {
+			e = squashEntry{Merged: squashMerged(repoPath, baseSHA, sha), Base: baseSHA, At: time.Now()}
+			c.entries[key] = e
+			c.dirty = true
+		}
+		if e.Merged {
+			merged[ref] = true
+		}
+	}
+	return merged
+}
+
+// squashMerged reports whether the changes of commit tip since it forked
+// from base are in base as a single commit, the way a squash merge lands
+// them. It squashes them into a throwaway commit on the fork point and asks
+// git cherry whether base has an equivalent patch.
+func squashMerged(repoPath, base, tip string) bool {
+	fork, err := gitLocal(repoPath, "merge-base", base, tip)
+	if err != nil {
+		return false
+	}
+	// The throwaway commit needs an identity, which need not be configured.
+	env := []string{
+		"GIT_NO_LAZY_FETCH=1",
+		"GIT_AUTHOR_NAME=gotobranch", "GIT_AUTHOR_EMAIL=gotobranch@localhost", "GIT_AUTHOR_DATE=@0 +0000",
+		"GIT_COMMITTER_NAME=gotobranch", "GIT_COMMITTER_EMAIL=gotobranch@localhost", "GIT_COMMITTER_DATE=@0 +0000",
+	}
+	squashed, err := gitEnv(repoPath, env, "commit-tree", tip+"^{tree}", "-p", strings.TrimSpace(fork), "-m", "squash")
+	if err != nil {
+		return false
+	}
+	out, err := gitLocal(repoPath, "cherry", base, strings.TrimSpace(squashed))
+	return err == nil && strings.HasPrefix(strings.TrimSpace(out), "-")
+}
+
+// maxSquashCached bounds the squash cache file; the oldest entries are
+// dropped.
+const maxSquashCached = 5000
+
+// squashEntry is a cached squashMerged result for a branch tip. A positive
+// result holds as long as the tip does; a negative one only until the base
+// moves.
+type squashEntry struct {
+	Merged bool      `json:"merged"`
+	Base   string    `json:"base"` // the base commit checked against
+	At     time.Time `json:"at"`
+}
+
+type squashCache struct {
+	path    string
+	entries map[string]squashEntry // keyed by "<tip sha> <base>"
+	dirty   bool
+}
+
+// loadSquashCache reads the repository's cache from the squash directory
+// of the cache (see package paths); a missing or unreadable cache is empty.
+func loadSquashCache(repoPath string) *squashCache {
+	c := &squashCache{entries: map[string]squashEntry{}}
+	dir, err := paths.Cache()
+	if err != nil {
+		return c
+	}
+	common, err := git(repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return c
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(common)))
+	c.path = filepath.Join(dir, "squash", hex.EncodeToString(sum[:8])+".json")
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+// save writes the cache back if it changed. Failing to cache only costs
+// checking again next time.
+func (c *squashCache) save() {
+	if !c.dirty || c.path == "" {
+		return
+	}
+	if len(c.entries) > maxSquashCached {
+		keys := make([]string, 0, len(c.entries))
+		for k := range c.entries {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].At.After(c.entries[keys[j]].At) })
+		for _, k := range keys[maxSquashCached:] {
+			delete(c.entries, k)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(c.path), 0o755) == nil {
+		_ = os.WriteFile(c.path, data, 0o644)
+	}
+}
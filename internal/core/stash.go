@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stash is an entry of `git stash list`.
+type Stash struct {
+	Index   int        `json:"index"`   // n in stash@{n}; 0 is the latest
+	SHA     string     `json:"sha"`     // the stash commit
+	Branch  string     `json:"branch"`  // the branch it was made on; "" when HEAD was detached
+	Message string     `json:"message"` // without the "On <branch>: " prefix
+	At      *time.Time `json:"at"`
+}
+
+// Ref returns the stash's reflog name, e.g. "stash@{2}". It changes as
+// stashes are pushed and dropped.
+func (s Stash) Ref() string { return fmt.Sprintf("stash@{%d}", s.Index) }
+
+// switchStashPrefix starts the message of stashes SwitchWith makes; the
+// branch being left follows.
+const switchStashPrefix = "gotobranch: switching from "
+
+// Stashes lists the stashes, latest first.
+func Stashes(repoPath string) ([]Stash, error) {
+	out, err := git(repoPath, "stash", "list", "--format=%gd%x09%H%x09%ct%x09%gs")
+	if err != nil {
+		return nil, err
+	}
+	var res []Stash
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) < 4 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(parts[0], "stash@{"), "}"))
+		if err != nil {
+			continue
+		}
+		s := Stash{Index: n, SHA: parts[1]}
+		if t, ok := parseGitDate(parts[2]); ok {
+			s.At = &t
+		}
+		s.Branch, s.Message = parseStashSubject(parts[3])
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// parseStashSubject splits a stash's reflog subject, "WIP on main: abc1234
+// subject" or "On main: message", into the branch and the message.
+func parseStashSubject(subject string) (branch, msg string) {
+	rest, ok := strings.CutPrefix(subject, "WIP on ")
+	if !ok {
+		if rest, ok = strings.CutPrefix(subject, "On "); !ok {
+			return "", subject
+		}
+	}
+	branch, msg, ok = strings.Cut(rest, ": ")
+	if !ok {
+		return "", subject
+	}
+	if branch == "(no branch)" {
+		branch = ""
+	}
+	return branch, msg
+}
+
+// StashFor returns the latest stash gotobranch made when switching away
+// from branch with uncommitted changes (see SwitchWith), to offer applying
+// it on the way back.
+func StashFor(repoPath, branch string) (Stash, bool) {
+	stashes, err := Stashes(repoPath)
+	if err != nil {
+		return Stash{}, false
+	}
+	for _, s := range stashes {
+		if s.Branch == branch && strings.HasPrefix(s.Message, switchStashPrefix+branch+" ") {
+			return s, true
+		}
+	}
+	return Stash{}, false
+}
+
+// ApplyStash applies the stash s to the working tree, dropping it
+// afterwards when pop is set and it applied cleanly.
+func ApplyStash(repoPath string, s Stash, pop bool) error {
+	ref, err := stashRef(repoPath, s)
+	if err != nil {
+		return err
+	}
+	cmd := "apply"
+	if pop {
+		cmd = "pop"
+	}
+	_, err = git(repoPath, "stash", cmd, ref)
+	return err
+}
+
+// DropStash deletes the stash s.
+func DropStash(repoPath string, s Stash) error {
+	ref, err := stashRef(repoPath, s)
+	if err != nil {
+		return err
+	}
+	_, err = git(repoPath, "stash", "drop", ref)
+	return err
+}
+
+// stashRef returns the current reflog name of s, which may have moved since
+// it was listed; it fails if s is gone.
+func stashRef(repoPath string, s Stash) (string, error) {
+	stashes, err := Stashes(repoPath)
+	if err != nil {
+		return "", err
+	}
+	for _, cur := range stashes {
+		if cur.SHA == s.SHA {
+			return cur.Ref(), nil
+		}
+	}
+	return "", fmt.Errorf("%s no longer exists", s.Ref())
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsRequest selects the branches BranchStats summarizes.
+type StatsRequest struct {
+	RepoPath string
+	Scope    Scope
+	Exclude  []string // globs hiding branches (see Excluded)
+	Base     string   // branch "merged" is measured against; default branch when empty
+	Stalest  int      // how many of the oldest branches to list; 10 when <= 0
+}
+
+// Count is a number of branches sharing a key.
+type Count struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Stats summarizes the branches of a repository.
+type Stats struct {
+	Total    int      `json:"total"`
+	Base     string   `json:"base"`
+	Merged   int      `json:"merged"`
+	Unmerged int      `json:"unmerged"`
+	Prefixes []Count  `json:"prefixes"` // by the part before the first "/", most common first
+	Ages     []Count  `json:"ages"`     // by head commit age, youngest bucket first
+	Authors  []Count  `json:"authors"`  // by head commit author, most common first
+	Stalest  []Branch `json:"stalest"`  // oldest head commits first
+}
+
+// ageBuckets are the upper bounds of the Stats.Ages buckets; older branches
+// fall into the last, open-ended one.
+var ageBuckets = []struct {
+	key   string
+	limit time.Duration
+}{
+	{"< 1 week", 7 * 24 * time.Hour},
+	{"1-4 weeks", 28 * 24 * time.Hour},
+	{"1-3 months", 91 * 24 * time.Hour},
+	{"3-12 months", 365 * 24 * time.Hour},
+	{"> 1 year", 0},
+}
+
+// NoPrefix is the Stats.Prefixes key of branches without a "/".
+const NoPrefix = "(none)"
+
+// BranchStats counts the branches in req.Scope by prefix, age, author and
+// whether they are merged into req.Base, and lists the stalest ones.
+func BranchStats(req StatsRequest, now time.Time) (Stats, error) {
+	if req.Base == "" {
+		base, err := DefaultBranch(req.RepoPath)
+		if err != nil {
+			return Stats{}, err
+		}
+		req.Base = base
+	}
+	if req.Stalest <= 0 {
+		req.Stalest = 10
+	}
+	branches, err := collectBranches(req.RepoPath, req.Scope)
+	if err != nil {
+		return Stats{}, err
+	}
+	kept := branches[:0]
+	for _, b := range branches {
+		if !Excluded(b, req.Exclude) {
+			kept = append(kept, b)
+		}
+	}
+	branches = kept
+
+	merged, err := MergedInto(req.RepoPath, req.Base, "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return Stats{}, err
+	}
+	authorOut, err := gitLocal(req.RepoPath, "for-each-ref", "--format=%(refname)\t%(authorname)", "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return Stats{}, err
+	}
+	authorOf := map[string]string{}
+	for _, line := range strings.Split(authorOut, "\n") {
+		if ref, author, ok := strings.Cut(line, "\t"); ok {
+			authorOf[ref] = author
+		}
+	}
+
+	s := Stats{Total: len(branches), Base: req.Base}
+	prefixes, authors := map[string]int{}, map[string]int{}
+	ages := make([]int, len(ageBuckets))
+	for _, b := range branches {
+		if merged[b.FullRef] {
+			s.Merged++
+		} else {
+			s.Unmerged++
+		}
+		prefixes[branchPrefix(b)]++
+		if a := authorOf[b.FullRef]; a != "" {
+			authors[a]++
+		}
+		if b.HeadCommitAt != nil {
+			ages[ageBucket(now.Sub(*b.HeadCommitAt))]++
+		}
+	}
+	s.Prefixes = sortedCounts(prefixes)
+	s.Authors = sortedCounts(authors)
+	s.Ages = make([]Count, len(ageBuckets))
+	for i, bucket := range ageBuckets {
+		s.Ages[i] = Count{Key: bucket.key, Count: ages[i]}
+	}
+
+	stalest := append([]Branch(nil), branches...)
+	sortBranches(stalest, "recency", "asc")
+	// Branches without a date sort first when ascending; they are not stale.
+	for len(stalest) > 0 && stalest[0].HeadCommitAt == nil {
+		stalest = stalest[1:]
+	}
+	s.Stalest = stalest[:min(req.Stalest, len(stalest))]
+	return s, nil
+}
+
+// branchPrefix returns the part of b's name before the first "/", ignoring
+// the remote name of remote branches.
+func branchPrefix(b Branch) string {
+	name := b.Name
+	if b.IsRemote {
+		_, name, _ = strings.Cut(name, "/")
+	}
+	prefix, _, ok := strings.Cut(name, "/")
+	if !ok {
+		return NoPrefix
+	}
+	return prefix
+}
+
+func ageBucket(age time.Duration) int {
+	for i, bucket := range ageBuckets {
+		if bucket.limit == 0 || age < bucket.limit {
+			return i
+		}
+	}
+	return len(ageBuckets) - 1
+}
+
+// sortedCounts orders counts most common first, then by key.
+func sortedCounts(m map[string]int) []Count {
+	res := make([]Count, 0, len(m))
+	for k, n := range m {
+		res = append(res, Count{Key: k, Count: n})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WorkStatus summarizes the state of a working tree.
+type WorkStatus struct {
+	Branch    string `json:"branch"` // "" when detached
+	SHA       string `json:"sha"`    // HEAD, "" before the first commit
+	Upstream  string `json:"upstream,omitempty"`
+	Ahead     int    `json:"ahead"`
+	Behind    int    `json:"behind"`
+	Dirty     bool   `json:"dirty"`     // tracked files are modified or staged
+	Untracked bool   `json:"untracked"` // there are untracked files
+}
+
+// Status returns the status of the working tree at repoPath. It takes no
+// optional locks, so it is safe to run alongside other git commands, e.g.
+// from a shell prompt.
+func Status(repoPath string) (WorkStatus, error) {
+	out, err := git(repoPath, "--no-optional-locks", "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return WorkStatus{}, err
+	}
+	return parseStatus(out), nil
+}
+
+func parseStatus(out string) WorkStatus {
+	var s WorkStatus
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# branch.oid "):
+			if oid := strings.TrimPrefix(line, "# branch.oid "); oid != "(initial)" {
+				s.SHA = oid
+			}
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				s.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			s.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &s.Ahead, &s.Behind)
+		case strings.HasPrefix(line, "? "):
+			s.Untracked = true
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "! "):
+		default:
+			s.Dirty = true
+		}
+	}
+	return s
+}
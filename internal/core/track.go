@@ -0,0 +1,47 @@
+package core
+
+import "fmt"
+
+// Track makes a local branch track a remote one and returns the names of
+// both. For a remote-tracking branch b, the local branch of the same name is
+// created with --track, or set to track b if it exists. For a local branch
+// b, the branch of the same name on its push remote (see PushRemote) becomes
+// its upstream.
+func Track(repoPath string, b Branch) (local, upstream string, err error) {
+	local = b.Name
+	if b.IsRemote {
+		local, upstream = HeadName(b), b.Name
+	} else {
+		remote, err := PushRemote(repoPath, b.Name)
+		if err != nil {
+			return "", "", err
+		}
+		upstream = remote + "/" + b.Name
+		if _, err := git(repoPath, "rev-parse", "--verify", "--quiet", "refs/remotes/"+upstream); err != nil {
+			return "", "", fmt.Errorf("there is no %s to track; push %s first", upstream, b.Name)
+		}
+	}
+	for _, name := range []string{local, upstream} {
+		if err := checkArg(name); err != nil {
+			return "", "", err
+		}
+	}
+	if !LocalBranchExists(repoPath, local) {
+		if _, err = git(repoPath, "branch", "--track", local, upstream); err != nil {
+			return local, upstream, err
+		}
+		audit(repoPath, Operation{Op: OpCreate, Branch: local, New: auditSHA(repoPath, "refs/heads/"+local)})
+		return local, upstream, nil
+	}
+	_, err = git(repoPath, "branch", "--set-upstream-to="+upstream, local)
+	return local, upstream, err
+}
+
+// Untrack removes the upstream of the local branch name.
+func Untrack(repoPath, name string) error {
+	if err := checkArg(name); err != nil {
+		return err
+	}
+	_, err := git(repoPath, "branch", "--unset-upstream", name)
+	return err
+}
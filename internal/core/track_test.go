@@ -0,0 +1,27 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTrackOptionLike(t *testing.T) {
+	clone, _ := testRepo(t)
+	// git refuses to create such branches, but a remote can still have one.
+	run(t, clone, "update-ref", "refs/remotes/origin/--force", "HEAD")
+
+	for _, b := range []Branch{
+		{Name: "origin/--force", IsRemote: true},
+		{Name: "--force"},
+	} {
+		if _, _, err := Track(clone, b); !errors.Is(err, ErrOptionLike) {
+			t.Errorf("Track(%q): got %v, want ErrOptionLike", b.Name, err)
+		}
+	}
+	if err := Untrack(clone, "--force"); !errors.Is(err, ErrOptionLike) {
+		t.Errorf("Untrack: got %v, want ErrOptionLike", err)
+	}
+	if out := run(t, clone, "branch", "--list"); out != "* main" {
+		t.Errorf("branches after tracking: %q", out)
+	}
+}
@@ -0,0 +1,42 @@
+package core
+
+import "strings"
+
+// Tracking compares a local branch with its upstream.
+type Tracking struct {
+	Ahead  int  `json:"ahead"`          // commits not in the upstream
+	Behind int  `json:"behind"`         // upstream commits not in the branch
+	Gone   bool `json:"gone,omitempty"` // the upstream was deleted on the remote
+}
+
+// addTracking compares the local branches among branches that have an
+// upstream with it, in one git command.
+func addTracking(repoPath string, branches []Branch) error {
+	args := []string{"for-each-ref", "--format=%(refname)\t%(upstream:track,nobracket)"}
+	for _, b := range branches {
+		if !b.IsRemote && b.Upstream != nil {
+			args = append(args, b.FullRef)
+		}
+	}
+	if len(args) == 2 {
+		return nil
+	}
+	out, err := gitLocal(repoPath, args...)
+	if err != nil {
+		return err
+	}
+	track := map[string]Tracking{}
+	for _, line := range strings.Split(out, "\n") {
+		if ref, t, ok := strings.Cut(line, "\t"); ok {
+			var tr Tracking
+			tr.Ahead, tr.Behind, tr.Gone = parseTrack(t)
+			track[ref] = tr
+		}
+	}
+	for i, b := range branches {
+		if tr, ok := track[b.FullRef]; ok && !b.IsRemote {
+			branches[i].Tracking = &tr
+		}
+	}
+	return nil
+}
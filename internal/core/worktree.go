@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is a working tree of the repository.
+type Worktree struct {
+	Path   string
+	Branch string // the local branch checked out, "" when detached
+}
+
+// Worktrees lists the repository's working trees, the main one first.
+func Worktrees(repoPath string) ([]Worktree, error) {
+	out, err := git(repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var res []Worktree
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			res = append(res, Worktree{Path: strings.TrimPrefix(line, "worktree ")})
+		case strings.HasPrefix(line, "branch ") && len(res) > 0:
+			res[len(res)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+	return res, nil
+}
+
+// AddWorktree checks b out in a new working tree at dir. A remote-tracking
+// branch is checked out as a local branch tracking it, as git switch does,
+// unless a local branch of that name exists already. It returns the local
+// branch checked out. git's progress goes to report (which may be nil) as
+// it comes; see gitProgress. When ctx is done git is interrupted, removing
+// the working tree it started.
+func AddWorktree(ctx context.Context, repoPath, dir string, b Branch, report func(Progress)) (string, error) {
+	name := HeadName(b)
+	args := []string{"worktree", "add", dir, name}
+	create := b.IsRemote && !LocalBranchExists(repoPath, name)
+	if create {
+		args = []string{"worktree", "add", "--track", "-b", name, dir, b.Name}
+	}
+	if _, err := gitProgress(ctx, repoPath, nil, report, args...); err != nil {
+		return "", err
+	}
+	if create {
+		audit(repoPath, Operation{Op: OpCreate, Branch: name, New: auditSHA(repoPath, "refs/heads/"+name)})
+	}
+	return name, nil
+}
+
+// HasSubmodules reports whether the working tree at dir declares
+// submodules.
+func HasSubmodules(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".gitmodules"))
+	return err == nil
+}
+
+// UpdateSubmodules clones and checks out the submodules of the working tree
+// at dir, recursively, reporting git's progress as it goes; see
+// gitProgress. Like FetchNoPrompt it fails rather than prompting for
+// credentials.
+func UpdateSubmodules(ctx context.Context, dir string, report func(Progress)) error {
+	_, err := gitProgress(ctx, dir, noPromptEnv(dir), report, "submodule", "update", "--init", "--recursive", "--progress")
+	return authRequired(err)
+}
@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// CommitStatus returns the CI status of commit sha in the repository slug
+// (owner/name), combining check runs (GitHub Actions and other apps) with
+// legacy commit statuses: any failure fails the commit, otherwise anything
+// still running makes it pending. It returns "" when nothing ran.
+func CommitStatus(ctx context.Context, slug, sha string) (string, error) {
+	var runs struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	err := apiGet(ctx, fmt.Sprintf("repos/%s/commits/%s/check-runs?per_page=100", slug, sha), &runs)
+	if errors.Is(err, errNotFound) {
+		// Not pushed, so nothing ran.
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := apiGet(ctx, fmt.Sprintf("repos/%s/commits/%s/status", slug, sha), &combined); err != nil {
+		return "", err
+	}
+
+	var results []string
+	for _, r := range runs.CheckRuns {
+		switch {
+		case r.Status != "completed":
+			results = append(results, core.CIPending)
+		case r.Conclusion == "success" || r.Conclusion == "neutral" || r.Conclusion == "skipped":
+			results = append(results, core.CISuccess)
+		default:
+			results = append(results, core.CIFailure)
+		}
+	}
+	if combined.TotalCount > 0 {
+		switch combined.State {
+		case "success":
+			results = append(results, core.CISuccess)
+		case "pending":
+			results = append(results, core.CIPending)
+		default:
+			results = append(results, core.CIFailure)
+		}
+	}
+	status := ""
+	for _, r := range results {
+		switch {
+		case r == core.CIFailure:
+			return r, nil
+		case r == core.CIPending || status == "":
+			status = r
+		}
+	}
+	return status, nil
+}
+
+// errNotFound is returned by apiGet for resources GitHub does not know,
+// such as commits that were never pushed.
+var errNotFound = errors.New("not found on GitHub")
+
+// apiGet decodes the REST API resource at path (relative to APIURL) into v,
+// through `gh api` if it is installed, or else with the token from the
+// environment.
+func apiGet(ctx context.Context, path string, v any) error {
+	out, err := exec.CommandContext(ctx, "gh", "api", path).Output()
+	if err == nil {
+		return json.Unmarshal(out, v)
+	}
+	if token := envToken(); token != "" {
+		return getJSON(ctx, token, APIURL+"/"+path, v)
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		msg := strings.TrimSpace(string(exit.Stderr))
+		if strings.Contains(msg, "HTTP 404") || strings.Contains(msg, "HTTP 422") {
+			return errNotFound
+		}
+		return fmt.Errorf("gh api: %s", msg)
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrNoToken
+	}
+	return err
+}
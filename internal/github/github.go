@@ -0,0 +1,323 @@
+// Package github looks up the pull requests opened from a repository's
+// branches and the CI status of commits.
+//
+// The gh CLI is used when it is installed and logged in, as it already holds
+// the user's credentials; otherwise the REST API is queried with the token in
+// GH_TOKEN or GITHUB_TOKEN. Results are cached on disk for CacheTTL so that
+// opening the picker repeatedly does not run into rate limits.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// CacheTTL is how long looked up pull requests are reused.
+const CacheTTL = 5 * time.Minute
+
+// APIURL is the GitHub REST API root.
+const APIURL = "https://api.github.com"
+
+// limit is how many of the most recently updated pull requests are fetched.
+const limit = 200
+
+// ErrNoToken is returned when neither gh nor a token is available.
+var ErrNoToken = errors.New("GitHub lookups need the gh CLI or a GH_TOKEN/GITHUB_TOKEN")
+
+// PullRequests returns the pull requests of the GitHub repository behind
+// the origin remote of repoPath, keyed by head branch name. When several
+// pull requests share a head branch, the most recently updated one wins.
+func PullRequests(ctx context.Context, repoPath string) (map[string]core.PullRequest, error) {
+	slug, err := Slug(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if prs, ok := readCache(slug); ok {
+		return prs, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	prs, err := viaGH(ctx, slug)
+	if err != nil {
+		token := envToken()
+		if token == "" {
+			if errors.Is(err, exec.ErrNotFound) {
+				return nil, ErrNoToken
+			}
+			return nil, err
+		}
+		if prs, err = viaREST(ctx, slug, token); err != nil {
+			return nil, err
+		}
+	}
+	writeCache(slug, prs)
+	return prs, nil
+}
+
+// slugPattern extracts owner/name from the https, ssh and scp-like URLs of a
+// github.com remote.
+var slugPattern = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(?:\.git)?/?$`)
+
+// Slug returns the owner/name of the GitHub repository behind the origin
+// remote of repoPath.
+func Slug(repoPath string) (string, error) {
+	url, err := core.RemoteURL(repoPath, "origin")
+	if err != nil {
+		return "", err
+	}
+	m := slugPattern.FindStringSubmatch(url)
+	if m == nil {
+		return "", fmt.Errorf("origin is not a GitHub repository: %s", url)
+	}
+	return m[1], nil
+}
+
+func viaGH(ctx context.Context, slug string) (map[string]core.PullRequest, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "list", "--repo", slug, "--state", "all",
+		"--limit", fmt.Sprint(limit), "--json", "number,title,state,reviewDecision,headRefName,url,updatedAt")
+	out, err := cmd.Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return nil, fmt.Errorf("gh pr list: %s", strings.TrimSpace(string(exit.Stderr)))
+		}
+		return nil, err
+	}
+	var list []struct {
+		Number         int       `json:"number"`
+		Title          string    `json:"title"`
+		State          string    `json:"state"`
+		ReviewDecision string    `json:"reviewDecision"`
+		HeadRefName    string    `json:"headRefName"`
+		URL            string    `json:"url"`
+		UpdatedAt      time.Time `json:"updatedAt"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("gh pr list: %w", err)
+	}
+	prs := map[string]core.PullRequest{}
+	updated := map[string]time.Time{}
+	for _, p := range list {
+		if t, ok := updated[p.HeadRefName]; ok && !p.UpdatedAt.After(t) {
+			continue
+		}
+		updated[p.HeadRefName] = p.UpdatedAt
+		prs[p.HeadRefName] = core.PullRequest{
+			Number: p.Number,
+			Title:  p.Title,
+			State:  strings.ToLower(p.State),
+			Review: strings.ToLower(p.ReviewDecision),
+			URL:    p.URL,
+		}
+	}
+	return prs, nil
+}
+
+// viaREST lists pull requests, most recently updated first. The list
+// endpoint carries no review decision, so those of the open pull requests
+// are looked up together with a single GraphQL query.
+func viaREST(ctx context.Context, slug, token string) (map[string]core.PullRequest, error) {
+	type pull struct {
+		Number   int     `json:"number"`
+		Title    string  `json:"title"`
+		State    string  `json:"state"`
+		MergedAt *string `json:"merged_at"`
+		HTMLURL  string  `json:"html_url"`
+		Head     struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	}
+	var list []pull
+	for page := 1; len(list) < limit; page++ {
+		var batch []pull
+		url := fmt.Sprintf("%s/repos/%s/pulls?state=all&sort=updated&direction=desc&per_page=%d&page=%d", APIURL, slug, perPage, page)
+		if err := getJSON(ctx, token, url, &batch); err != nil {
+			return nil, err
+		}
+		list = append(list, batch...)
+		if len(batch) < perPage {
+			break
+		}
+	}
+	list = list[:min(len(list), limit)]
+
+	prs := map[string]core.PullRequest{}
+	var open []int
+	for _, p := range list {
+		if _, ok := prs[p.Head.Ref]; ok {
+			continue
+		}
+		pr := core.PullRequest{Number: p.Number, Title: p.Title, State: p.State, URL: p.HTMLURL}
+		if p.MergedAt != nil {
+			pr.State = "merged"
+		}
+		if pr.State == "open" {
+			open = append(open, p.Number)
+		}
+		prs[p.Head.Ref] = pr
+	}
+	decisions, err := reviewDecisions(ctx, slug, token, open)
+	if err != nil {
+		return nil, err
+	}
+	for ref, pr := range prs {
+		if d, ok := decisions[pr.Number]; ok {
+			pr.Review = d
+			prs[ref] = pr
+		}
+	}
+	return prs, nil
+}
+
+// perPage is the most items the REST API returns per page.
+const perPage = 100
+
+// reviewDecisions returns the review decisions of the numbered pull
+// requests of slug, lowercased as gh reports them, in one GraphQL query.
+func reviewDecisions(ctx context.Context, slug, token string, numbers []int) (map[int]string, error) {
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	owner, name, _ := strings.Cut(slug, "/")
+	var q strings.Builder
+	q.WriteString("query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) {")
+	for _, n := range numbers {
+		fmt.Fprintf(&q, " pr%d: pullRequest(number: %d) { reviewDecision }", n, n)
+	}
+	q.WriteString(" } }")
+	body, err := json.Marshal(map[string]any{
+		"query":     q.String(),
+		"variables": map[string]string{"owner": owner, "name": name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data struct {
+			Repository map[string]*struct {
+				ReviewDecision string `json:"reviewDecision"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := postJSON(ctx, token, APIURL+"/graphql", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("GitHub API: %s", resp.Errors[0].Message)
+	}
+	decisions := map[int]string{}
+	for _, n := range numbers {
+		if pr := resp.Data.Repository[fmt.Sprintf("pr%d", n)]; pr != nil {
+			decisions[n] = strings.ToLower(pr.ReviewDecision)
+		}
+	}
+	return decisions, nil
+}
+
+// envToken returns the API token from the environment, as gh reads it.
+func envToken() string {
+	if token := os.Getenv("GH_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+func getJSON(ctx context.Context, token, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(req, token, v)
+}
+
+// postJSON posts body to url, decoding the response into v.
+func postJSON(ctx context.Context, token, url string, body []byte, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(req, token, v)
+}
+
+func doJSON(req *http.Request, token string, v any) error {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// cacheEntry is the on-disk form of a lookup.
+type cacheEntry struct {
+	FetchedAt    time.Time                   `json:"fetchedAt"`
+	PullRequests map[string]core.PullRequest `json:"pullRequests"`
+}
+
+// cachePath returns where the pull requests of slug are cached.
+func cachePath(slug string) (string, error) {
+	dir, err := paths.Cache()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pulls", strings.ReplaceAll(slug, "/", "_")+".json"), nil
+}
+
+func readCache(slug string) (map[string]core.PullRequest, bool) {
+	path, err := cachePath(slug)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || time.Since(e.FetchedAt) > CacheTTL || e.PullRequests == nil {
+		return nil, false
+	}
+	return e.PullRequests, true
+}
+
+// writeCache stores prs for slug. Failing to cache is not worth reporting;
+// the next lookup simply asks GitHub again.
+func writeCache(slug string, prs map[string]core.PullRequest) {
+	path, err := cachePath(slug)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), PullRequests: prs})
+	if err != nil {
+		return
+	}
+	// Private repositories' pull request titles are not for other users;
+	// the mode of a cache written before it was private is fixed too.
+	if os.MkdirAll(filepath.Dir(path), 0o755) == nil && os.WriteFile(path, data, 0o600) == nil {
+		_ = os.Chmod(path, 0o600)
+	}
+}
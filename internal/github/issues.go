@@ -0,0 +1,37 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Issue is the subset of a GitHub issue used to name a branch after it.
+type Issue struct {
+	Number int
+	Title  string
+	Labels []string
+}
+
+// GetIssue fetches issue number n of the repository slug (owner/name).
+func GetIssue(ctx context.Context, slug string, n int) (Issue, error) {
+	var issue struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	err := apiGet(ctx, fmt.Sprintf("repos/%s/issues/%d", slug, n), &issue)
+	if errors.Is(err, errNotFound) {
+		return Issue{}, fmt.Errorf("%s has no issue #%d", slug, n)
+	}
+	if err != nil {
+		return Issue{}, err
+	}
+	res := Issue{Number: issue.Number, Title: issue.Title}
+	for _, l := range issue.Labels {
+		res.Labels = append(res.Labels, l.Name)
+	}
+	return res, nil
+}
@@ -0,0 +1,132 @@
+// Package hooks runs the shell commands configured to run around branch
+// operations (see config.Hooks). Install a Runner with core.SetHooks.
+//
+// Commands run in the repository's top-level directory with these variables
+// added to the environment:
+//
+//	GOTOBRANCH_HOOK             pre-switch, post-switch or post-delete
+//	GOTOBRANCH_BRANCH           the branch switched to or deleted
+//	GOTOBRANCH_PREVIOUS_BRANCH  switches: the branch switched away from
+//	GOTOBRANCH_SHA              deletes: the commit the branch pointed to
+//	GOTOBRANCH_REPO             the repository's top-level directory
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/kvnloughead/gotobranch/internal/config"
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Runner runs the configured hooks, writing their output to an output
+// writer that can be swapped while a full-screen UI owns the terminal.
+type Runner struct {
+	cfg config.Hooks
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// New returns a Runner for cfg writing hook output to out.
+func New(cfg config.Hooks, out io.Writer) *Runner {
+	return &Runner{cfg: cfg, out: out}
+}
+
+// Enabled reports whether any hook is configured.
+func Enabled(cfg config.Hooks) bool {
+	return len(cfg.PreSwitch)+len(cfg.PostSwitch)+len(cfg.PostDelete) > 0
+}
+
+// SetOutput replaces the writer receiving hook output and returns the
+// previous one.
+func (r *Runner) SetOutput(w io.Writer) io.Writer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := r.out
+	r.out = w
+	return prev
+}
+
+// Run runs the commands for ev.Hook, for core.SetHooks. With
+// AbortOnFailure, a failing command stops the rest and its failure is
+// returned; otherwise it is reported in the output and the rest run.
+func (r *Runner) Run(ev core.HookEvent) error {
+	var cmds []string
+	switch ev.Hook {
+	case core.HookPreSwitch:
+		cmds = r.cfg.PreSwitch
+	case core.HookPostSwitch:
+		cmds = r.cfg.PostSwitch
+	case core.HookPostDelete:
+		cmds = r.cfg.PostDelete
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	dir := ev.RepoPath
+	if top, err := core.TopLevel(ev.RepoPath); err == nil {
+		dir = top
+	}
+	env := append(os.Environ(),
+		"GOTOBRANCH_HOOK="+ev.Hook,
+		"GOTOBRANCH_BRANCH="+ev.Branch,
+		"GOTOBRANCH_PREVIOUS_BRANCH="+ev.Previous,
+		"GOTOBRANCH_SHA="+ev.SHA,
+		"GOTOBRANCH_REPO="+dir,
+	)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range cmds {
+		var tail lastLine
+		cmd := Shell(c)
+		cmd.Dir, cmd.Env = dir, env
+		cmd.Stdout = io.MultiWriter(r.out, &tail)
+		cmd.Stderr = cmd.Stdout
+		if err := cmd.Run(); err != nil {
+			err = fmt.Errorf("`%s` failed: %v", c, err)
+			if line := tail.String(); line != "" {
+				err = fmt.Errorf("%w: %s", err, line)
+			}
+			if r.cfg.AbortOnFailure {
+				return err
+			}
+			fmt.Fprintf(r.out, "warning: %s hook %v\n", ev.Hook, err)
+		}
+	}
+	return nil
+}
+
+// Shell returns the command running c with the platform's shell.
+func Shell(c string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", c)
+	}
+	return exec.Command("sh", "-c", c)
+}
+
+// lastLine keeps the last non-empty line written to it, so that errors can
+// quote what a failing command said last.
+type lastLine struct {
+	buf []byte
+}
+
+func (l *lastLine) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+	if len(l.buf) > 4096 {
+		l.buf = l.buf[len(l.buf)-4096:]
+	}
+	return len(p), nil
+}
+
+func (l *lastLine) String() string {
+	lines := strings.Split(strings.TrimSpace(string(bytes.ToValidUTF8(l.buf, nil))), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
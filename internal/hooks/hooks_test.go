@@ -0,0 +1,40 @@
+package hooks
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kvnloughead/gotobranch/internal/config"
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+func TestRunAfterFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands need sh")
+	}
+	ev := core.HookEvent{Hook: core.HookPostSwitch, Branch: "main", RepoPath: t.TempDir()}
+	cmds := []string{"echo first", "echo broken; exit 3", "echo second"}
+
+	var out bytes.Buffer
+	if err := New(config.Hooks{PostSwitch: cmds}, &out).Run(ev); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"first", "warning: post-switch hook `echo broken; exit 3` failed", "second"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q does not contain %q", got, want)
+		}
+	}
+
+	// Aborting on failure stops the remaining commands.
+	out.Reset()
+	err := New(config.Hooks{PostSwitch: cmds, AbortOnFailure: true}, &out).Run(ev)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Run with AbortOnFailure: got %v, want the failure", err)
+	}
+	if strings.Contains(out.String(), "second") {
+		t.Errorf("Run with AbortOnFailure ran the command after the failure: %q", out.String())
+	}
+}
@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestCatalogsComplete checks that every message the source translates, as
+// a literal passed to T, Sprintf or Errorf (or to cmd's usageErrorf, which
+// translates through Errorf), has a translation in every catalog.
+func TestCatalogsComplete(t *testing.T) {
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	type use struct {
+		msg string
+		pos token.Position
+	}
+	var uses []use
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || !translates(call.Fun) {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Errorf("%s: %v", fset.Position(lit.Pos()), err)
+				return true
+			}
+			uses = append(uses, use{msg, fset.Position(lit.Pos())})
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uses) == 0 {
+		t.Fatal("found no translated messages")
+	}
+	for locale, c := range catalogs {
+		for _, u := range uses {
+			if _, ok := c[u.msg]; !ok {
+				t.Errorf("%s: no %s translation of %q", u.pos, locale, u.msg)
+			}
+		}
+	}
+}
+
+// translates reports whether fun is one of the functions translating their
+// first argument.
+func translates(fun ast.Expr) bool {
+	switch f := fun.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := f.X.(*ast.Ident)
+		return ok && pkg.Name == "i18n" && (f.Sel.Name == "T" || f.Sel.Name == "Sprintf" || f.Sel.Name == "Errorf")
+	case *ast.Ident:
+		return f.Name == "usageErrorf"
+	}
+	return false
+}
@@ -0,0 +1,547 @@
+package i18n
+
+// de is the German catalog.
+var de = map[string]string{
+	// The command line's messages and prompts.
+	"no cherry-pick in progress":         "kein Cherry-Pick im Gange",
+	"Cherry-picked %d commit(s) from %s": "%d Commit(s) von %s übernommen",
+	"%s needs a branch name":             "%s braucht einen Branch-Namen",
+	"unknown action %q":                  "unbekannte Aktion %q",
+	"error: %v":                          "Fehler: %v",
+	"hint: %s":                           "Hinweis: %s",
+	"Fetched %s":                         "%s abgerufen",
+	"History is already complete":        "Die Historie ist bereits vollständig",
+	"fetching history: %w":               "Abrufen der Historie: %w",
+	"Fetched the full history":           "Die vollständige Historie wurde abgerufen",
+	"Fetching %s...":                     "Rufe %s ab...",
+	"Fetch complete.":                    "Abrufen abgeschlossen.",
+	"No operations recorded.":            "Keine Operationen aufgezeichnet.",
+	"post-checkout hook in %s":           "post-checkout-Hook in %s",
+	"Skipping the shell wrapper: unsupported shell %q (use --shell bash|zsh|fish)": "Überspringe den Shell-Wrapper: nicht unterstützte Shell %q (verwende --shell bash|zsh|fish)",
+	"%s: not installed":                          "%s: nicht installiert",
+	"%s: already installed":                      "%s: bereits installiert",
+	"Install %s? [Y/n] ":                         "%s installieren? [Y/n] ",
+	"Remove %s? [Y/n] ":                          "%s entfernen? [Y/n] ",
+	"%s: removed":                                "%s: entfernt",
+	"%s: installed":                              "%s: installiert",
+	"git goto alias":                             "git-goto-Alias",
+	"%s is already set to %q; leaving it alone":  "%s ist bereits auf %q gesetzt; wird nicht verändert",
+	"shell wrapper in %s":                        "Shell-Wrapper in %s",
+	"issueBranch rendered an empty name for #%d": "issueBranch ergab einen leeren Namen für #%d",
+	"profile %s: %w":                             "Profil %s: %w",
+	"error: config: %v":                          "Fehler: Konfiguration: %v",
+	"error: log: %v":                             "Fehler: Log: %v",
+	"error: trace: %v":                           "Fehler: Trace: %v",
+	"usage:":                                     "Aufruf:",
+	"Flags:":                                     "Optionen:",
+	"Without a command, opens the interactive branch picker.": "Ohne Befehl wird die interaktive Branch-Auswahl geöffnet.",
+	"Commands:": "Befehle:",
+	"Run 'gotobranch <command> -h' for command flags.": "'gotobranch <Befehl> -h' zeigt die Optionen eines Befehls.",
+	"error: %s: %v":                                                     "Fehler: %s: %v",
+	"Deleted branch %s (was %s)":                                        "Branch %s gelöscht (war %s)",
+	"%d of %d branches not deleted":                                     "%d von %d Branches nicht gelöscht",
+	"post-delete hook failed for %d of %d branches":                     "post-delete-Hook für %d von %d Branches fehlgeschlagen",
+	"Renamed %s to %s":                                                  "%s in %s umbenannt",
+	"error: pushing %s: %v":                                             "Fehler: Pushen von %s: %v",
+	"Pushed %s to %s and set its upstream":                              "%s nach %s gepusht und als Upstream gesetzt",
+	"The old remote branches are still there; to delete them:":          "Die alten Remote-Branches existieren noch; zum Löschen:",
+	"all branches renamed, but %d of %d not pushed":                     "alle Branches umbenannt, aber %d von %d nicht gepusht",
+	"Nothing to prune.":                                                 "Nichts aufzuräumen.",
+	"Delete %d branches? [y/N] ":                                        "%d Branches löschen? [y/N] ",
+	"Aborted.":                                                          "Abgebrochen.",
+	"%w (use --force for unmerged gone or stale branches)":              "%w (--force für nicht gemergte verschwundene oder veraltete Branches)",
+	"To restore:":                                                       "Zum Wiederherstellen:",
+	"Pushed '%s' to %s and set it as its upstream":                      "'%s' nach %s gepusht und als Upstream gesetzt",
+	"Pushed '%s' to %s":                                                 "'%s' nach %s gepusht",
+	"'%s' is already at '%s'":                                           "'%s' ist bereits auf '%s'",
+	"Reset '%s' to '%s' (%s), dropping %d commit(s) only on it? [y/N] ": "'%s' auf '%s' (%s) zurücksetzen und %d Commit(s) verwerfen, die nur darauf sind? [y/N] ",
+	"Reset '%s' to '%s' (was %s)":                                       "'%s' auf '%s' zurückgesetzt (war %s)",
+	"gotobranch %s is up to date (latest release: %s)":                  "gotobranch %s ist aktuell (neuestes Release: %s)",
+	"release %s has no binary for %s/%s":                                "Release %s hat kein Binary für %s/%s",
+	"Would update gotobranch %s to %s (%s)":                             "Würde gotobranch %s auf %s aktualisieren (%s)",
+	"Downloading %s %s...":                                              "Lade %s %s herunter...",
+	"%w; pass --insecure to install it checking its checksums only":     "%w; mit --insecure wird es nur anhand der Prüfsummen geprüft installiert",
+	"replacing %s: %w":                                                  "Ersetzen von %s: %w",
+	"Updated gotobranch %s to %s":                                       "gotobranch %s auf %s aktualisiert",
+	"Serving on http://%s":                                              "Bereitgestellt unter http://%s",
+	"no stash@{%d}":                                                     "kein stash@{%d}",
+	"Applied %s (%s)":                                                   "%s angewendet (%s)",
+	"Dropped %s (was %s)":                                               "%s verworfen (war %s)",
+	"Changes stashed when leaving %s are in %s; restore them with `gotobranch stash pop %d`.": "Beim Verlassen von %s gestashte Änderungen liegen in %s; mit `gotobranch stash pop %d` wiederherstellen.",
+	"Restore changes stashed when leaving %s (%s)? [y/N] ":                                    "Beim Verlassen von %s gestashte Änderungen wiederherstellen (%s)? [y/N] ",
+	"restoring %s: %w":    "Wiederherstellen von %s: %w",
+	"Restored %s":         "%s wiederhergestellt",
+	"Merged into %s:\t%d": "Gemergt in %s:\t%d",
+	"Unmerged:\t%d":       "Nicht gemergt:\t%d",
+	"By prefix":           "Nach Präfix",
+	"By age":              "Nach Alter",
+	"By author":           "Nach Autor",
+	"Stalest:":            "Am ältesten:",
+	"No metrics recorded; set \"metrics\": true in the config to record them.": "Keine Metriken aufgezeichnet; setze \"metrics\": true in der Konfiguration, um sie aufzuzeichnen.",
+	"No metrics recorded yet.":                                       "Noch keine Metriken aufgezeichnet.",
+	"Runs since %s:\t%d":                                             "Läufe seit %s:\t%d",
+	"Commands:\tRuns\tMedian\t90%\tMax":                              "Befehle:\tLäufe\tMedian\t90%\tMax",
+	"Git commands:\tCalls\tTotal\tMean\tMax":                         "Git-Befehle:\tAufrufe\tGesamt\tMittel\tMax",
+	"Features:\tRuns":                                                "Funktionen:\tLäufe",
+	"Slowest runs:":                                                  "Langsamste Läufe:",
+	"Detached HEAD at '%s' (from '%s')":                              "Losgelöster HEAD bei '%s' (von '%s')",
+	"Detached HEAD at '%s'":                                          "Losgelöster HEAD bei '%s'",
+	"Switched to a new branch '%s'":                                  "Zu neuem Branch '%s' gewechselt",
+	"Switched to '%s' (from '%s')":                                   "Zu '%s' gewechselt (von '%s')",
+	"Switched to '%s'":                                               "Zu '%s' gewechselt",
+	"Fetching all remotes...":                                        "Rufe alle Remotes ab...",
+	"warning: not updating %s: %v":                                   "Warnung: %s wird nicht aktualisiert: %v",
+	"Fast-forwarded %s":                                              "%s vorgespult",
+	"%s is up to date":                                               "%s ist aktuell",
+	"No branches became merged or gone.":                             "Keine Branches wurden gemergt oder verschwanden.",
+	"%d branches became prunable:":                                   "%d Branches können jetzt aufgeräumt werden:",
+	"--prune needs a terminal; use `gotobranch prune --yes` instead": "--prune braucht ein Terminal; verwende stattdessen `gotobranch prune --yes`",
+	"not running inside tmux":                                        "nicht innerhalb von tmux",
+	"'%s' no longer tracks an upstream":                              "'%s' folgt keinem Upstream mehr",
+	"'%s' tracks '%s'":                                               "'%s' folgt '%s'",
+	"--stdin needs a terminal on stderr to draw the picker":          "--stdin braucht ein Terminal auf stderr, um die Auswahl zu zeichnen",
+	"Would undo the %s":                                              "Würde rückgängig machen: %s",
+	"Undid the %s":                                                   "Rückgängig gemacht: %s",
+	"A newer release is available: %s (%s)":                          "Ein neueres Release ist verfügbar: %s (%s)",
+	"Latest release: %s":                                             "Neuestes Release: %s",
+	"no working trees":                                               "keine Arbeitsverzeichnisse",
+	"%s already exists; remove it or change worktreePath":            "%s existiert bereits; entferne es oder ändere worktreePath",
+	"created %s, but updating its submodules failed: %w":             "%s erstellt, aber das Aktualisieren seiner Submodule schlug fehl: %w",
+	"worktreePath rendered an empty path for %s":                     "worktreePath ergab einen leeren Pfad für %s",
+	"worktreeOpen `%s` failed: %v":                                   "worktreeOpen `%s` fehlgeschlagen: %v",
+	"No settings; everything is at its default.":                     "Keine Einstellungen; alles steht auf dem Standard.",
+	"%s: no such file; the defaults apply":                           "%s: Datei existiert nicht; es gelten die Standardwerte",
+	"%s: ok":                                                         "%s: in Ordnung",
+	"%d problem(s) in the config":                                    "%d Problem(e) in der Konfiguration",
+	"unknown action %q; expected show, validate or edit":             "unbekannte Aktion %q; erwartet show, validate oder edit",
+	"Token: %s":          "Token: %s",
+	"Branches (%s):\t%d": "Branches (%s):\t%d",
+	"%s: %w":             "%s: %w",
+	"too many arguments; expected at most one pattern":                  "zu viele Argumente; erwartet höchstens ein Muster",
+	"expected exactly one branch name":                                  "erwartet genau einen Branch-Namen",
+	"expected at most one branch name":                                  "erwartet höchstens einen Branch-Namen",
+	"unexpected arguments":                                              "unerwartete Argumente",
+	"invalid worktreePath in config: %w":                                "ungültiger worktreePath in der Konfiguration: %w",
+	"-n must be a positive number":                                      "-n muss eine positive Zahl sein",
+	"unsupported shell %q; use bash, zsh or fish":                       "nicht unterstützte Shell %q; verwende bash, zsh oder fish",
+	"unknown profile %q; available: %s":                                 "unbekanntes Profil %q; verfügbar: %s",
+	"unknown action %q; expected list, apply, pop or drop":              "unbekannte Aktion %q; erwartet list, apply, pop oder drop",
+	"unknown --editor %q; supported: nvim":                              "unbekannter --editor %q; unterstützt: nvim",
+	"undo takes no arguments":                                           "undo nimmt keine Argumente",
+	"sync takes no arguments":                                           "sync nimmt keine Argumente",
+	"stats takes no arguments":                                          "stats nimmt keine Argumente",
+	"show takes no arguments":                                           "show nimmt keine Argumente",
+	"self-update takes no arguments":                                    "self-update nimmt keine Argumente",
+	"prune takes no arguments":                                          "prune nimmt keine Argumente",
+	"prompt takes no arguments":                                         "prompt nimmt keine Argumente",
+	"list takes no arguments":                                           "list nimmt keine Argumente",
+	"install takes no arguments":                                        "install nimmt keine Argumente",
+	"gen-openapi takes no arguments":                                    "gen-openapi nimmt keine Argumente",
+	"gen-docs takes no arguments":                                       "gen-docs nimmt keine Argumente",
+	"edit takes no arguments":                                           "edit nimmt keine Argumente",
+	"several branch templates; pick one of %s with --template":          "mehrere Branch-Vorlagen; wähle eine von %s mit --template",
+	"only %d recent branches":                                           "nur %d zuletzt genutzte Branches",
+	"no value for %s (pass --set %s=...)":                               "kein Wert für %s (übergib --set %s=...)",
+	"no items on stdin":                                                 "keine Einträge auf stdin",
+	"no branchTemplates in config":                                      "keine branchTemplates in der Konfiguration",
+	"no branch template %q; available: %s":                              "keine Branch-Vorlage %q; verfügbar: %s",
+	"n must be a stash number, as in stash@{n}":                         "n muss eine Stash-Nummer sein, wie in stash@{n}",
+	"n must be a positive number":                                       "n muss eine positive Zahl sein",
+	"invalid rowFormat in config: %w":                                   "ungültiges rowFormat in der Konfiguration: %w",
+	"invalid issueBranch in config: %w":                                 "ungültiger issueBranch in der Konfiguration: %w",
+	"invalid issue number %q":                                           "ungültige Issue-Nummer %q",
+	"invalid column %s in config: %w":                                   "ungültige Spalte %s in der Konfiguration: %w",
+	"invalid branchTemplates.%s in config: %w":                          "ungültige branchTemplates.%s in der Konfiguration: %w",
+	"invalid --scope; use local|remote|all":                             "ungültiger --scope; verwende local|remote|all",
+	"invalid --format: %w":                                              "ungültiges --format: %w",
+	"expected one ref":                                                  "erwartet eine Referenz",
+	"expected one issue number":                                         "erwartet eine Issue-Nummer",
+	"expected at most one stash number":                                 "erwartet höchstens eine Stash-Nummer",
+	"expected at most one remote":                                       "erwartet höchstens ein Remote",
+	"expected at most one file":                                         "erwartet höchstens eine Datei",
+	"expected at most one count":                                        "erwartet höchstens eine Anzahl",
+	"expected at most one branch":                                       "erwartet höchstens einen Branch",
+	"expected at least one branch name":                                 "erwartet mindestens einen Branch-Namen",
+	"expected a shell: bash, zsh or fish":                               "erwartet eine Shell: bash, zsh oder fish",
+	"expected a hook name: post-checkout":                               "erwartet einen Hook-Namen: post-checkout",
+	"expected [old] <new>":                                              "erwartet [alt] <neu>",
+	"a template generates the name; no branch name expected":            "eine Vorlage erzeugt den Namen; kein Branch-Name erwartet",
+	"HEAD is detached; name the branch":                                 "HEAD ist losgelöst; nenne den Branch",
+	"HEAD is detached; name the branch to reset":                        "HEAD ist losgelöst; nenne den zurückzusetzenden Branch",
+	"HEAD is detached; name the branch to push":                         "HEAD ist losgelöst; nenne den zu pushenden Branch",
+	"--switch must be a positive number":                                "--switch muss eine positive Zahl sein",
+	"--stdin cannot be combined with --accessible":                      "--stdin kann nicht mit --accessible kombiniert werden",
+	"--stalest must be a positive number":                               "--stalest muss eine positive Zahl sein",
+	"--stale must not be negative":                                      "--stale darf nicht negativ sein",
+	"--set %q: expected name=value":                                     "--set %q: erwartet Name=Wert",
+	"--json only applies to show":                                       "--json gilt nur für show",
+	"--json only applies to list":                                       "--json gilt nur für list",
+	"--json and --format are mutually exclusive":                        "--json und --format schließen sich gegenseitig aus",
+	"--history takes no remote":                                         "--history nimmt kein Remote",
+	"--from requires --create":                                          "--from erfordert --create",
+	"--from renames the branches matching it; no branch names expected": "--from benennt die passenden Branches um; keine Branch-Namen erwartet",
+	"--from and --to go together":                                       "--from und --to gehören zusammen",
+	"--format must be csv or json, not %q":                              "--format muss csv oder json sein, nicht %q",
+	"--create takes the branch name as its value; no pattern expected":  "--create nimmt den Branch-Namen als Wert; kein Muster erwartet",
+	"--abort takes no branch":                                           "--abort nimmt keinen Branch",
+
+	// The picker.
+	"deleted %s":         "%s gelöscht",
+	"Invalid filter: %v": "Ungültiger Filter: %v",
+	"%d branches match %s %q. Page %d of %d.": "%d Branches passen zu %s %q. Seite %d von %d.",
+	"%d branches. Page %d of %d.":             "%d Branches. Seite %d von %d.",
+	"(current)":                               "(aktuell)",
+	"Type a number to switch, text to filter, n or p to change page, c to clear the filter, q to quit: ": "Nummer zum Wechseln, Text zum Filtern, n oder p zum Blättern, c zum Zurücksetzen des Filters, q zum Beenden: ",
+	"Already on the last page.":                                           "Bereits auf der letzten Seite.",
+	"Already on the first page.":                                          "Bereits auf der ersten Seite.",
+	"No branch numbered %d on this page.":                                 "Kein Branch mit der Nummer %d auf dieser Seite.",
+	"Could not switch to %s: %v":                                          "Konnte nicht zu %s wechseln: %v",
+	"Switched to %s.":                                                     "Zu %s gewechselt.",
+	"%q is not a number of commits":                                       "%q ist keine Anzahl von Commits",
+	"cherry-picking from %s…":                                             "Cherry-Pick von %s…",
+	"resolve the conflicts, then run git cherry-pick --continue":          "löse die Konflikte, dann führe git cherry-pick --continue aus",
+	"cherry-pick aborted":                                                 "Cherry-Pick abgebrochen",
+	"cherry-picked %d commit(s) from %s":                                  "%d Commit(s) von %s übernommen",
+	"Cherry-pick the last commits of %s, how many? ":                      "Wie viele der letzten Commits von %s übernehmen? ",
+	"no branch numbered %d on this page":                                  "kein Branch mit der Nummer %d auf dieser Seite",
+	"%d: j/k move %d rows, h/l %d pages; enter takes row %d; esc cancels": "%d: j/k bewegt %d Zeilen, h/l %d Seiten; Enter nimmt Zeile %d; Esc bricht ab",
+	"%s is a remote branch":                                               "%s ist ein Remote-Branch",
+	"%s is checked out; switch away before deleting it":                   "%s ist ausgecheckt; wechsle vor dem Löschen zu einem anderen Branch",
+	"%d branches (%s)":                                                    "%d Branches (%s)",
+	"Delete %s?":                                                          "%s löschen?",
+	"Delete %s? %d not merged into HEAD; restore commands are printed on exit.": "%s löschen? %d nicht in HEAD gemergt; Befehle zum Wiederherstellen werden beim Beenden ausgegeben.",
+	"deleted %s; restore it with git branch %s %s":                              "%s gelöscht; wiederherstellen mit git branch %s %s",
+	"deleted %d of %d branches; restore commands are printed on exit":           "%d von %d Branches gelöscht; Befehle zum Wiederherstellen werden beim Beenden ausgegeben",
+	"%s is checked out; highlight the branch to compare HEAD with":              "%s ist ausgecheckt; markiere den Branch, mit dem HEAD verglichen werden soll",
+	"diff of %s: %w":                        "Diff von %s: %w",
+	"none":                                  "keiner",
+	"%s (gone)":                             "%s (verschwunden)",
+	"%s (%d ahead, %d behind)":              "%s (%d voraus, %d zurück)",
+	"%d ahead, %d behind":                   "%d voraus, %d zurück",
+	"not merged into %s":                    "nicht in %s gemergt",
+	"merged into %s":                        "in %s gemergt",
+	"description":                           "Beschreibung",
+	"looking up…":                           "wird nachgeschlagen…",
+	"reflog":                                "Reflog",
+	"start of a name":                       "Namensanfang",
+	"no branch on this page starts with %q": "kein Branch auf dieser Seite beginnt mit %q",
+	"up":                                    "hoch",
+	"down":                                  "runter",
+	"prev page":                             "vorige Seite",
+	"next page":                             "nächste Seite",
+	"switch":                                "wechseln",
+	"detach at":                             "losgelöst auschecken",
+	"select":                                "auswählen",
+	"mark":                                  "markieren",
+	"actions":                               "Aktionen",
+	"delete (marked)":                       "löschen (markierte)",
+	"undo":                                  "rückgängig",
+	"filter":                                "filtern",
+	"jump to name":                          "zu Namen springen",
+	"sort by column":                        "nach Spalte sortieren",
+	"preview":                               "Vorschau",
+	"resize preview":                        "Vorschaugröße ändern",
+	"open diff":                             "Diff öffnen",
+	"clear filter":                          "Filter zurücksetzen",
+	"next profile":                          "nächstes Profil",
+	"branch from issue":                     "Branch aus Issue",
+	"open in worktree":                      "in Arbeitsverzeichnis öffnen",
+	"create branch here":                    "Branch hier erstellen",
+	"new branch from template":              "neuer Branch aus Vorlage",
+	"stashes":                               "Stashes",
+	"other repository":                      "anderes Repository",
+	"branch details":                        "Branch-Details",
+	"rebase onto":                           "rebasen auf",
+	"cherry-pick":                           "Cherry-Pick",
+	"push":                                  "pushen",
+	"track remote":                          "Remote folgen",
+	"untrack":                               "nicht mehr folgen",
+	"reset to upstream":                     "auf Upstream zurücksetzen",
+	"fetch full history":                    "vollständige Historie abrufen",
+	"more keys":                             "weitere Tasten",
+	"suspend":                               "anhalten",
+	"cancel git":                            "git abbrechen",
+	"previous repo":                         "voriges Repository",
+	"next repo":                             "nächstes Repository",
+	"quit":                                  "beenden",
+	"done":                                  "fertig",
+	"clear & back":                          "leeren & zurück",
+	"next match":                            "nächster Treffer",
+	"toggle":                                "umschalten",
+	"toggle all":                            "alle umschalten",
+	"delete marked":                         "markierte löschen",
+	"yes":                                   "ja",
+	"no":                                    "nein",
+	"create & switch":                       "erstellen & wechseln",
+	"back":                                  "zurück",
+	"stash changes & switch":                "Änderungen stashen & wechseln",
+	"switch, merging changes in":            "wechseln, Änderungen mitnehmen",
+	"discard changes & switch":              "Änderungen verwerfen & wechseln",
+	"fetch & retry":                         "abrufen & erneut versuchen",
+	"retry":                                 "erneut versuchen",
+	"apply":                                 "anwenden",
+	"pop":                                   "anwenden & entfernen",
+	"drop":                                  "verwerfen",
+	"open":                                  "öffnen",
+	"press f to filter":                     "f drücken zum Filtern",
+	"number":                                "Nummer",
+	"fetching %s":                           "rufe %s ab",
+	"CI status: %v":                         "CI-Status: %v",
+	"fetch canceled":                        "Abrufen abgebrochen",
+	"fetch failed: %v":                      "Abrufen fehlgeschlagen: %v",
+	"fetched the full history":              "vollständige Historie abgerufen",
+	"pull requests: %v":                     "Pull-Requests: %v",
+	"worktree canceled":                     "Arbeitsverzeichnis abgebrochen",
+	"rebase stopped: finish it with git rebase --continue, or --abort": "Rebase angehalten: mit git rebase --continue abschließen oder mit --abort abbrechen",
+	"rebase onto %s: %w":       "Rebase auf %s: %w",
+	"rebased onto %s":          "auf %s rebased",
+	"%s is not a local branch": "%s ist kein lokaler Branch",
+	"name":                     "Name",
+	"%s is checked out; highlight the branch to rebase it onto":   "%s ist ausgecheckt; markiere den Branch, auf den rebased werden soll",
+	"%s is checked out; highlight the branch to cherry-pick from": "%s ist ausgecheckt; markiere den Branch, von dem übernommen werden soll",
+	"wait for %s to finish or cancel it":                          "warte, bis %s fertig ist, oder brich es ab",
+	"opening a worktree for %s":                                   "öffne ein Arbeitsverzeichnis für %s",
+	"creating a branch for issue #%d…":                            "erstelle einen Branch für Issue #%d…",
+	"no branch template %q":                                       "keine Branch-Vorlage %q",
+	"New branch from template: ":                                  "Neuer Branch aus Vorlage: ",
+	"New %s branch, %s: ":                                         "Neuer %s-Branch, %s: ",
+	"columns: %v":                                                 "Spalten: %v",
+	"saving the preview width: %v":                                "Speichern der Vorschaubreite: %v",
+	"canceling…":                                                  "breche ab…",
+	"esc: cancel":                                                 "Esc: abbrechen",
+	"Prune branches: %d of %d marked for deletion":                "Branches aufräumen: %d von %d zum Löschen markiert",
+	"Deleting…":                                                   "Lösche…",
+	"Delete %d branches? ":                                        "%d Branches löschen? ",
+	"pushed %s and set its upstream":                              "%s gepusht und Upstream gesetzt",
+	"pushed %s":                                                   "%s gepusht",
+	"Untracked files would be overwritten:":                       "Unversionierte Dateien würden überschrieben:",
+	"Your local changes would be overwritten:":                    "Deine lokalen Änderungen würden überschrieben:",
+	"  …and %d more":                                              "  …und %d weitere",
+	"cancel":                                                      "abbrechen",
+	"no other repositories known yet":                             "noch keine anderen Repositories bekannt",
+	"%s has no upstream to reset to":                              "%s hat keinen Upstream zum Zurücksetzen",
+	"%s is already at %s":                                         "%s ist bereits auf %s",
+	"Reset %s to %s (%s)":                                         "%s auf %s (%s) zurücksetzen",
+	", dropping %d commit(s) only on %s":                          ", verwirft %d Commit(s), die nur auf %s sind",
+	"Uncommitted changes are discarded too.":                      "Nicht committete Änderungen werden ebenfalls verworfen.",
+	"reset %s to %s (was %s)":                                     "%s auf %s zurückgesetzt (war %s)",
+	"no changes vs %s":                                            "keine Änderungen gegenüber %s",
+	"%s vs %s":                                                    "%s gegenüber %s",
+	"applied %s":                                                  "%s angewendet",
+	"popped %s":                                                   "%s angewendet und entfernt",
+	"dropped %s":                                                  "%s verworfen",
+	"(detached)":                                                  "(losgelöst)",
+	"no stashes":                                                  "keine Stashes",
+	"Changes were stashed when leaving %s (%s). Restore them?": "Beim Verlassen von %s wurden Änderungen gestasht (%s). Wiederherstellen?",
+	"Age":                             "Alter",
+	"Subject":                         "Betreff",
+	"unknown theme %q; available: %s": "unbekanntes Farbschema %q; verfügbar: %s",
+	"%s already tracks %s":            "%s folgt bereits %s",
+	"%s tracks %s":                    "%s folgt %s",
+	"%s is a remote branch; untrack the local branch instead": "%s ist ein Remote-Branch; beende stattdessen das Folgen beim lokalen Branch",
+	"%s has no upstream":     "%s hat keinen Upstream",
+	"%s no longer tracks %s": "%s folgt %s nicht mehr",
+	"undid the %s":           "rückgängig gemacht: %s",
+	"Branch from issue #%s":  "Branch aus Issue #%s",
+	"New branch at %s: ":     "Neuer Branch bei %s: ",
+	"Cherry-picking from %s stopped on conflicts. Abort it?":   "Cherry-Pick von %s hat bei Konflikten angehalten. Abbrechen?",
+	"The remote %s has diverged. Force the push (with lease)?": "Der Remote-Branch %s ist abgewichen. Push erzwingen (mit Lease)?",
+	"Jump to: ":               "Springen zu: ",
+	"Cannot switch to %s":     "Kann nicht zu %s wechseln",
+	"Open another repository": "Anderes Repository öffnen",
+	"Actions":                 "Aktionen",
+	"Error: %v":               "Fehler: %v",
+	"Timed out — retry?":      "Zeitüberschreitung — erneut versuchen?",
+	"%d marked for deletion (d: delete, x: unmark)":                                    "%d zum Löschen markiert (d: löschen, x: Markierung aufheben)",
+	"shallow clone: ages, counts and merges may be incomplete (H: fetch full history)": "flacher Klon: Alter, Anzahlen und Merges sind evtl. unvollständig (H: vollständige Historie abrufen)",
+	"fetching %s…": "rufe %s ab…",
+	"pushing %s…":  "pushe %s…",
+	"jump to ":     "springen zu ",
+	"issue #":      "Issue #",
+	"new branch ":  "neuer Branch ",
+	"stashes (%d)  a:apply p:pop d:drop esc:back": "Stashes (%d)  a:anwenden p:anwenden & entfernen d:verwerfen Esc:zurück",
+	"open repository  enter:open esc:back":        "Repository öffnen  Enter:öffnen Esc:zurück",
+	"esc:back":                                    "Esc:zurück",
+	"actions  enter:run esc:back":                 "Aktionen  Enter:ausführen Esc:zurück",
+	"?:keys q:quit":                               "?:Tasten q:beenden",
+	"(name off policy)":                           "(Name verletzt Richtlinie)",
+	"tracks %s":                                   "folgt %s",
+	"no upstream":                                 "kein Upstream",
+	"key %s":                                      "Schlüssel %s",
+	"signed by %s (%s)":                           "signiert von %s (%s)",
+	"history cut off by the shallow clone":        "Historie durch flachen Klon abgeschnitten",
+	"name does not follow the naming policy":      "Name folgt nicht der Namensrichtlinie",
+	"the full history":                            "die vollständige Historie",
+	"all remotes":                                 "alle Remotes",
+	"y/n":                                         "y/n",
+	"Stashes (%d)":                                "Stashes (%d)",
+	"Branch %s":                                   "Branch %s",
+	"Filter [%s] (%s): ":                          "Filter [%s] (%s): ",
+	"Filter (%s): ":                               "Filter (%s): ",
+
+	"hook: %s": "Hook: %s",
+
+	// Command summaries.
+	"Print branches matching pattern":                                                               "Branches ausgeben, die zum Muster passen",
+	"Switch to a branch, or check out a ref with a detached HEAD":                                   "Zu einem Branch wechseln oder eine Referenz mit losgelöstem HEAD auschecken",
+	"Create a branch (named by a branch template without name) and switch to it":                    "Einen Branch erstellen (ohne Namen per Branch-Vorlage benannt) und zu ihm wechseln",
+	"Create a branch for a GitHub issue and switch to it":                                           "Einen Branch für ein GitHub-Issue erstellen und zu ihm wechseln",
+	"Delete local branches":                                                                         "Lokale Branches löschen",
+	"Rename a local branch (default: the current one), or all matching a pattern":                   "Einen lokalen Branch umbenennen (standardmäßig den aktuellen) oder alle, die zu einem Muster passen",
+	"Undo the last switch, delete or rename, one step further back each time":                       "Den letzten Wechsel, das letzte Löschen oder Umbenennen rückgängig machen, jedes Mal einen Schritt weiter zurück",
+	"Show the branch operations made with gotobranch, newest first":                                 "Die mit gotobranch ausgeführten Branch-Operationen anzeigen, neueste zuerst",
+	"Apply the head commit(s) of a branch onto the current one":                                     "Die letzten Commits eines Branches auf den aktuellen anwenden",
+	"Pick merged, gone or stale branches to delete":                                                 "Gemergte, verschwundene oder veraltete Branches zum Löschen auswählen",
+	"Fetch, fast-forward the default branch and report newly prunable branches":                     "Abrufen, den Standard-Branch vorspulen und neu aufräumbare Branches melden",
+	"Push a branch (default: the current one), setting its upstream if it has none":                 "Einen Branch pushen (standardmäßig den aktuellen) und seinen Upstream setzen, falls er keinen hat",
+	"Track a remote branch (creating the local one), or stop tracking with --unset":                 "Einem Remote-Branch folgen (und den lokalen erstellen) oder mit --unset nicht mehr folgen",
+	"Hard-reset a local branch (default: the current one) to its upstream":                          "Einen lokalen Branch (standardmäßig den aktuellen) hart auf seinen Upstream zurücksetzen",
+	"Fetch remotes and prune deleted remote branches":                                               "Remotes abrufen und auf dem Remote gelöschte Branches entfernen",
+	"Summarize branches by prefix, age, author and merge status":                                    "Branches nach Präfix, Alter, Autor und Merge-Status zusammenfassen",
+	"Write branches with all their metadata to a CSV or JSON file":                                  "Branches mit allen Metadaten in eine CSV- oder JSON-Datei schreiben",
+	"Print or switch to recently checked out branches":                                              "Zuletzt ausgecheckte Branches ausgeben oder zu ihnen wechseln",
+	"List stashes with the branch they were made on, or apply, pop or drop one":                     "Stashes mit dem Branch auflisten, auf dem sie entstanden, oder einen anwenden, entnehmen oder verwerfen",
+	"Print a one-line status (branch, ahead/behind, dirty) for shell prompts":                       "Einen einzeiligen Status (Branch, voraus/zurück, geändert) für Shell-Prompts ausgeben",
+	"Open the picker in a tmux popup":                                                               "Die Auswahl in einem tmux-Popup öffnen",
+	"Print branches as tab-separated lines for fzf (--pipeline shows how)":                          "Branches als tabulatorgetrennte Zeilen für fzf ausgeben (--pipeline zeigt, wie)",
+	"Print the log and diffstat of a ref, e.g. for an fzf preview window":                           "Log und Diffstat einer Referenz ausgeben, z. B. für ein fzf-Vorschaufenster",
+	"Serve the branch API over HTTP on localhost for editors and dashboards":                        "Die Branch-API per HTTP auf localhost für Editoren und Dashboards bereitstellen",
+	"Serve branch tools to AI coding assistants over the Model Context Protocol (stdio)":            "Branch-Werkzeuge über das Model Context Protocol (stdio) für KI-Coding-Assistenten bereitstellen",
+	"Print a shell wrapper function (bash, zsh, fish)":                                              "Eine Shell-Wrapper-Funktion ausgeben (bash, zsh, fish)",
+	"Set up the git goto alias and shell wrapper (--uninstall removes them)":                        "Den git-goto-Alias und den Shell-Wrapper einrichten (--uninstall entfernt sie)",
+	"Replace this binary with the latest release":                                                   "Dieses Binary durch das neueste Release ersetzen",
+	"Print version and build information":                                                           "Version und Build-Informationen ausgeben",
+	"Show this help":                                                                                "Diese Hilfe anzeigen",
+	"Show the settings in effect and where they come from, validate the config files or edit yours": "Die geltenden Einstellungen und ihre Herkunft zeigen, die Konfigurationsdateien prüfen oder deine bearbeiten",
+
+	// Column titles, branch details and hints.
+	"Name":         "Name",
+	"Commits":      "Commits",
+	"Used":         "Genutzt",
+	"ref":          "Referenz",
+	"commit":       "Commit",
+	"subject":      "Betreff",
+	"author":       "Autor",
+	"authored":     "verfasst",
+	"committer":    "Committer",
+	"committed":    "committet",
+	"upstream":     "Upstream",
+	"vs HEAD":      "gegenüber HEAD",
+	"merge":        "Merge",
+	"pull request": "Pull-Request",
+	"worktree":     "Arbeitsverzeichnis",
+	"commit or stash your changes first, merge them in with git switch --merge, or discard them":             "committe oder stashe deine Änderungen zuerst, übernimm sie mit git switch --merge, oder verwirf sie",
+	"check the name, or fetch if the branch is new on the remote":                                            "prüfe den Namen, oder rufe ab, falls der Branch auf dem Remote neu ist",
+	"pick another name, or switch to the existing branch":                                                    "wähle einen anderen Namen, oder wechsle zum bestehenden Branch",
+	"the branches have diverged; merge or rebase instead":                                                    "die Branches sind auseinandergelaufen; merge oder rebase stattdessen",
+	"merge it first, or force the deletion to drop its commits":                                              "merge ihn zuerst, oder erzwinge das Löschen, um seine Commits zu verwerfen",
+	"switch that worktree to another branch first":                                                           "wechsle in diesem Arbeitsverzeichnis zuerst zu einem anderen Branch",
+	"configure a credential helper or ssh-agent, or run the command in a terminal":                           "richte einen Credential-Helper oder ssh-agent ein, oder führe den Befehl in einem Terminal aus",
+	"run gotobranch inside a git repository, or point --repo at one":                                         "führe gotobranch in einem Git-Repository aus, oder verweise mit --repo auf eines",
+	"check that the remote is reachable, or raise localTimeout/networkTimeout":                               "prüfe, ob das Remote erreichbar ist, oder erhöhe localTimeout/networkTimeout",
+	"resolve the conflicts and run git cherry-pick --continue, or abort with gotobranch cherry-pick --abort": "löse die Konflikte und führe git cherry-pick --continue aus, oder brich mit gotobranch cherry-pick --abort ab",
+	"pick a name matching namePolicy in the config":                                                          "wähle einen Namen, der zu namePolicy in der Konfiguration passt",
+	"edit the files to resolve the conflicts, then git add them":                                             "bearbeite die Dateien, um die Konflikte zu lösen, dann füge sie mit git add hinzu",
+
+	// Flags.
+	"Cherry-pick the last `count` commits of the branch that the current one lacks":                             "Die letzten `count` Commits des Branches übernehmen, die dem aktuellen fehlen",
+	"Give up a cherry-pick stopped on conflicts":                                                                "Einen bei Konflikten angehaltenen Cherry-Pick aufgeben",
+	"File to write; its extension (.csv or .json) picks the format unless --format is given. - is stdout":       "Zieldatei; ihre Endung (.csv oder .json) bestimmt das Format, sofern --format fehlt. - ist stdout",
+	"csv or json (default: from --out, else csv)":                                                               "csv oder json (standardmäßig aus --out, sonst csv)",
+	"Branch that merged is measured against (default: the default branch)":                                      "Branch, gegen den gemergt gemessen wird (standardmäßig der Standard-Branch)",
+	"Keep remote-tracking branches that were deleted on the remote":                                             "Remote-Tracking-Branches behalten, die auf dem Remote gelöscht wurden",
+	"Fetch the history a shallow clone lacks":                                                                   "Die Historie abrufen, die einem flachen Klon fehlt",
+	"Run 'git fetch --prune' first (all remotes, or --fetch=<remote>)":                                          "Zuerst 'git fetch --prune' ausführen (alle Remotes oder --fetch=<remote>)",
+	"Print a ready-made fzf command line instead of the branches":                                               "Eine fertige fzf-Befehlszeile statt der Branches ausgeben",
+	"Color the output (for fzf --ansi)":                                                                         "Die Ausgabe einfärben (für fzf --ansi)",
+	"Directory to write man/ and md/ into":                                                                      "Verzeichnis, in das man/ und md/ geschrieben werden",
+	"Show the operations in every repository, not just this one":                                                "Die Operationen in allen Repositories zeigen, nicht nur in diesem",
+	"Print the operations as JSON lines, oldest first":                                                          "Die Operationen als JSON-Zeilen ausgeben, älteste zuerst",
+	"Show the last `n` operations":                                                                              "Die letzten `n` Operationen zeigen",
+	"Shell whose startup file gets the wrapper function: bash|zsh|fish":                                         "Shell, deren Startdatei die Wrapper-Funktion erhält: bash|zsh|fish",
+	"Name of the shell function to define":                                                                      "Name der zu definierenden Shell-Funktion",
+	"Do not ask before each step":                                                                               "Nicht vor jedem Schritt fragen",
+	"Remove everything install sets up":                                                                         "Alles entfernen, was install einrichtet",
+	"Start the branch at this ref instead of the default branch":                                                "Den Branch bei dieser Referenz statt beim Standard-Branch beginnen",
+	"Print the branch name without creating it":                                                                 "Den Branch-Namen ausgeben, ohne ihn zu erstellen",
+	"Print the ListBranchesResponse as JSON":                                                                    "Die ListBranchesResponse als JSON ausgeben",
+	"Go template evaluated per branch, e.g. '{{.Name}}\\t{{.HeadCommitSHA | short}}'":                           "Go-Vorlage, die pro Branch ausgewertet wird, z. B. '{{.Name}}\\t{{.HeadCommitSHA | short}}'",
+	"Print only this 1-based page (default: all branches)":                                                      "Nur diese Seite ausgeben, ab 1 gezählt (standardmäßig alle Branches)",
+	"Page size used with --page":                                                                                "Seitengröße für --page",
+	"Look up the CI status of each branch's head commit (GitHub or GitLab)":                                     "Den CI-Status des letzten Commits jedes Branches nachschlagen (GitHub oder GitLab)",
+	"Verify the signature of each branch's head commit (runs gpg or ssh-keygen)":                                "Die Signatur des letzten Commits jedes Branches prüfen (führt gpg oder ssh-keygen aus)",
+	"Count each branch's commits that the default branch lacks":                                                 "Die Commits jedes Branches zählen, die dem Standard-Branch fehlen",
+	"Look up each branch's GitHub pull request (via gh, or GH_TOKEN/GITHUB_TOKEN)":                              "Den GitHub-Pull-Request jedes Branches nachschlagen (über gh oder GH_TOKEN/GITHUB_TOKEN)",
+	"Path to git repository (defaults to CWD)":                                                                  "Pfad zum Git-Repository (standardmäßig das aktuelle Verzeichnis)",
+	"Apply a profile from the config (query, scope, sort, match, exclude)":                                      "Ein Profil aus der Konfiguration anwenden (query, scope, sort, match, exclude)",
+	"Branch scope: local|remote|all":                                                                            "Branch-Bereich: local|remote|all",
+	"Sort by frecency|name|recency|commits, optionally with :asc or :desc":                                      "Sortieren nach frecency|name|recency|commits, optional mit :asc oder :desc",
+	"How the pattern matches: contains|glob|regex|fuzzy":                                                        "Wie das Muster passt: contains|glob|regex|fuzzy",
+	"Filter query, e.g. 'author:alice before:2024-01-01 merged:false feat'":                                     "Filterabfrage, z. B. 'author:alice before:2024-01-01 merged:false feat'",
+	"Only branches whose head commit author name or email contains this (\"me\" for your user.email)":           "Nur Branches, deren letzter Commit von einem Autor stammt, dessen Name oder E-Mail dies enthält (\"me\" für deine user.email)",
+	"Only branches whose head commit is no older than this date or age (2024-01-31, 2w, 6mo)":                   "Nur Branches, deren letzter Commit nicht älter als dieses Datum oder Alter ist (2024-01-31, 2w, 6mo)",
+	"Only branches whose head commit is no newer than this date or age":                                         "Nur Branches, deren letzter Commit nicht neuer als dieses Datum oder Alter ist",
+	"Color theme: default|mono|deuteranopia|high-contrast":                                                      "Farbschema: default|mono|deuteranopia|high-contrast",
+	"Hide branches matching this glob (repeatable; adds to the config's list, an empty value clears it)":        "Branches ausblenden, die zu diesem Glob passen (wiederholbar; ergänzt die Liste der Konfiguration, ein leerer Wert leert sie)",
+	"Log every git command, its duration and exit status to stderr":                                             "Jeden Git-Befehl mit Dauer und Exit-Status auf stderr protokollieren",
+	"Shorthand for --verbose":                                                                                   "Kurzform für --verbose",
+	"Delete even if not fully merged":                                                                           "Auch löschen, wenn nicht vollständig gemergt",
+	"Shorthand for --force":                                                                                     "Kurzform für --force",
+	"Rename every local branch matching this pattern, e.g. 'old/*' (with --to)":                                 "Jeden lokalen Branch umbenennen, der zu diesem Muster passt, z. B. 'old/*' (mit --to)",
+	"New names for the branches matching --from, with * replaced by what it matched, e.g. 'new/*'":              "Neue Namen für die zu --from passenden Branches, * ersetzt durch das Gefundene, z. B. 'new/*'",
+	"With --from, only print the renames":                                                                       "Mit --from nur die Umbenennungen ausgeben",
+	"With --from, push renamed branches that have an upstream under their new names and track those":            "Mit --from umbenannte Branches mit Upstream unter ihren neuen Namen pushen und diesen folgen",
+	"Branch merged candidates are compared against (default: current branch)":                                   "Branch, mit dem die gemergten Kandidaten verglichen werden (standardmäßig der aktuelle Branch)",
+	"Also offer branches without commits for this many `days` (0 disables)":                                     "Auch Branches ohne Commits seit so vielen `days` anbieten (0 schaltet ab)",
+	"Only print what would be deleted":                                                                          "Nur ausgeben, was gelöscht würde",
+	"Do not ask for confirmation":                                                                               "Nicht um Bestätigung bitten",
+	"Also delete gone and stale branches that are not fully merged":                                             "Auch nicht vollständig gemergte verschwundene und veraltete Branches löschen",
+	"Ask for confirmation on the command line instead of opening the selection UI":                              "Auf der Befehlszeile um Bestätigung bitten statt die Auswahloberfläche zu öffnen",
+	"Go template for the line, with fields Branch, Detached, SHA, Upstream, Ahead, Behind, Dirty and Untracked": "Go-Vorlage für die Zeile, mit den Feldern Branch, Detached, SHA, Upstream, Ahead, Behind, Dirty und Untracked",
+	"Reuse a cached status this long while HEAD, the index and refs are unchanged":                              "Einen zwischengespeicherten Status so lange wiederverwenden, wie HEAD, Index und Referenzen unverändert sind",
+	"Overwrite the remote branch if it is where it was last fetched (--force-with-lease)":                       "Den Remote-Branch überschreiben, wenn er noch auf dem zuletzt abgerufenen Stand ist (--force-with-lease)",
+	"Switch to the `n`th most recent branch instead of listing":                                                 "Zum `n`-t zuletzt genutzten Branch wechseln statt aufzulisten",
+	"Shorthand for --yes":                        "Kurzform für --yes",
+	"Only report whether an update is available": "Nur melden, ob ein Update verfügbar ist",
+	"Install a release even if its signature cannot be verified, checking its checksums only": "Ein Release auch installieren, wenn seine Signatur nicht geprüft werden kann, und nur seine Prüfsummen prüfen",
+	"Install the latest release even if it is not newer (e.g. over a development build)":      "Das neueste Release installieren, auch wenn es nicht neuer ist (z. B. über einen Entwicklungs-Build)",
+	"Loopback address to listen on": "Loopback-Adresse, auf der gelauscht wird",
+	"Bearer token clients must send (default: $GOTOBRANCH_TOKEN, else a random one printed at startup)": "Bearer-Token, das Clients senden müssen (standardmäßig $GOTOBRANCH_TOKEN, sonst ein zufälliges, beim Start ausgegebenes)",
+	"File to write, or - for stdout":                                                                                          "Zieldatei oder - für stdout",
+	"Print the stashes as JSON":                                                                                               "Die Stashes als JSON ausgeben",
+	"Print the statistics as JSON":                                                                                            "Die Statistik als JSON ausgeben",
+	"Branch merged counts are measured against (default: the default branch)":                                                 "Branch, gegen den gemergte Branches gezählt werden (standardmäßig der Standard-Branch)",
+	"How many of the oldest branches (or with --self, slowest runs) to list":                                                  "Wie viele der ältesten Branches (oder mit --self der langsamsten Läufe) aufgelistet werden",
+	"Summarize gotobranch's own recorded metrics (see the metrics setting) instead":                                           "Stattdessen die eigenen aufgezeichneten Metriken von gotobranch zusammenfassen (siehe die Einstellung metrics)",
+	"Check out the branch, tag or commit with a detached HEAD instead of switching to a local branch":                         "Den Branch, Tag oder Commit mit losgelöstem HEAD auschecken statt zu einem lokalen Branch zu wechseln",
+	"Shorthand for --detach":                                                                                                  "Kurzform für --detach",
+	"Start the branch at this ref instead of HEAD":                                                                            "Den Branch bei dieser Referenz statt bei HEAD beginnen",
+	"Generate the name with this branch template from the config, asking for its variables":                                   "Den Namen mit dieser Branch-Vorlage aus der Konfiguration erzeugen und nach ihren Variablen fragen",
+	"Shorthand for --template":                                                                                                "Kurzform für --template",
+	"Set a template variable instead of being asked, as name=value (repeatable)":                                              "Eine Vorlagenvariable setzen statt danach zu fragen, als name=wert (wiederholbar)",
+	"With a template, print the name without creating the branch":                                                             "Mit einer Vorlage den Namen ausgeben, ohne den Branch zu erstellen",
+	"Open the prune UI for the branches that became merged or gone":                                                           "Die Aufräum-Oberfläche für die Branches öffnen, die gemergt wurden oder verschwanden",
+	"Stop the branch tracking its upstream":                                                                                   "Den Branch seinem Upstream nicht mehr folgen lassen",
+	"Page size for pagination":                                                                                                "Seitengröße beim Blättern",
+	"Plain prompt-and-response mode for screen readers (no full-screen UI)":                                                   "Schlichter Frage-Antwort-Modus für Screenreader (keine Vollbild-Oberfläche)",
+	"Always open the picker, even if the pattern matches a single branch":                                                     "Immer die Auswahl öffnen, auch wenn das Muster genau einen Branch trifft",
+	"Shorthand for --interactive":                                                                                             "Kurzform für --interactive",
+	"Create this branch and switch to it (switches if it already exists)":                                                     "Diesen Branch erstellen und zu ihm wechseln (wechselt, falls er schon existiert)",
+	"Shorthand for --create":                                                                                                  "Kurzform für --create",
+	"Ref to start a branch created with --create at (default: HEAD)":                                                          "Referenz, bei der ein mit --create erstellter Branch beginnt (standardmäßig HEAD)",
+	"Print the matching branches instead of opening the picker (default when stdout is not a terminal)":                       "Die passenden Branches ausgeben statt die Auswahl zu öffnen (Standard, wenn stdout kein Terminal ist)",
+	"With --no-tui, print the list as JSON":                                                                                   "Mit --no-tui die Liste als JSON ausgeben",
+	"Show each branch's GitHub pull request (via gh, or GH_TOKEN/GITHUB_TOKEN)":                                               "Den GitHub-Pull-Request jedes Branches zeigen (über gh oder GH_TOKEN/GITHUB_TOKEN)",
+	"Show the CI status of each branch's head commit (GitHub or GitLab)":                                                      "Den CI-Status des letzten Commits jedes Branches zeigen (GitHub oder GitLab)",
+	"Show whether each branch's head commit is signed, and by whom":                                                           "Zeigen, ob der letzte Commit jedes Branches signiert ist, und von wem",
+	"Show how many commits each branch has that the default branch lacks":                                                     "Zeigen, wie viele Commits jeder Branch hat, die dem Standard-Branch fehlen",
+	"Show the branches in aligned columns under a header (ignores rowFormat)":                                                 "Die Branches in ausgerichteten Spalten unter einer Kopfzeile zeigen (ignoriert rowFormat)",
+	"Show the highlighted branch's commits and changes next to the list (v toggles it, < and > resize it)":                    "Commits und Änderungen des markierten Branches neben der Liste zeigen (v schaltet um, < und > ändern die Größe)",
+	"Inside tmux, open the picker in a popup (ignored outside tmux)":                                                          "Innerhalb von tmux die Auswahl in einem Popup öffnen (außerhalb von tmux ignoriert)",
+	"Speak the JSON-lines protocol of an editor plugin on stdin/stdout instead of drawing the picker (nvim)":                  "Das JSON-Zeilen-Protokoll eines Editor-Plugins auf stdin/stdout sprechen statt die Auswahl zu zeichnen (nvim)",
+	"Also open this repository in the picker, as a tab switched to with [ and ] (repeatable; adds to the config's workspace)": "Auch dieses Repository in der Auswahl öffnen, als Tab, zu dem [ und ] wechseln (wiederholbar; ergänzt den workspace der Konfiguration)",
+	"Pick from newline-separated items read from stdin and print the selection":                                               "Aus zeilenweise von stdin gelesenen Einträgen auswählen und die Auswahl ausgeben",
+	"Only print what would be undone":                                                                                         "Nur ausgeben, was rückgängig gemacht würde",
+	"Check GitHub for a newer release":                                                                                        "Auf GitHub nach einem neueren Release suchen",
+	"Print the settings as JSON":                                                                                              "Die Einstellungen als JSON ausgeben",
+}
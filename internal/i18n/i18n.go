@@ -0,0 +1,111 @@
+// Package i18n translates gotobranch's user-facing messages: the picker's
+// prompts, hints, help and errors, and the command line's help, prompts and
+// error reports. Output meant for scripts (list, --json, --format) and
+// messages from git itself are never translated.
+//
+// Messages are looked up by their English text, as gettext does, so the
+// source reads as English and a message a catalog lacks falls back to it.
+// Format strings are translated before formatting, keeping their verbs:
+//
+//	m.notice = i18n.Sprintf("deleted %s", name)
+//
+// The locale is chosen once at startup with SetLocale, from the config's
+// locale or else the LC_ALL, LC_MESSAGES and LANG environment variables.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// catalogs maps a locale to its translations, by English message.
+var catalogs = map[string]map[string]string{
+	"de": de,
+}
+
+// current is the catalog in use; nil for English.
+var current atomic.Pointer[map[string]string]
+
+// Locales returns the locales with a translation, and "en".
+func Locales() []string {
+	res := []string{"en"}
+	for l := range catalogs {
+		res = append(res, l)
+	}
+	sort.Strings(res[1:])
+	return res
+}
+
+// Supported reports whether locale, e.g. "de" or "de_DE.UTF-8", has a
+// translation or is English.
+func Supported(locale string) bool {
+	l := normalize(locale)
+	return l == "" || l == "en" || catalog(l) != nil
+}
+
+// SetLocale translates messages into locale from now on, or leaves them in
+// English when locale is empty, English, or has no translation.
+func SetLocale(locale string) {
+	c := catalog(normalize(locale))
+	current.Store(&c)
+}
+
+// Detect returns the configured locale when set, or else the first of
+// LC_ALL, LC_MESSAGES and LANG set, as the C library picks the locale of
+// messages.
+func Detect(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l := os.Getenv(v); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// normalize turns a POSIX locale such as de_DE.UTF-8@euro into de_de, and
+// C and POSIX into "".
+func normalize(locale string) string {
+	l, _, _ := strings.Cut(locale, ".")
+	l, _, _ = strings.Cut(l, "@")
+	l = strings.ToLower(strings.ReplaceAll(l, "-", "_"))
+	if l == "c" || l == "posix" {
+		return ""
+	}
+	return l
+}
+
+// catalog returns the catalog for the normalized locale l, falling back
+// from a territory (de_at) to its language (de).
+func catalog(l string) map[string]string {
+	if c, ok := catalogs[l]; ok {
+		return c
+	}
+	lang, _, _ := strings.Cut(l, "_")
+	return catalogs[lang]
+}
+
+// T returns msg in the current locale.
+func T(msg string) string {
+	if c := current.Load(); c != nil && *c != nil {
+		if t, ok := (*c)[msg]; ok {
+			return t
+		}
+	}
+	return msg
+}
+
+// Sprintf formats args with format in the current locale.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf is fmt.Errorf with format in the current locale; %w still wraps.
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
@@ -0,0 +1,136 @@
+// Package logging writes gotobranch's diagnostics to a file in the state
+// directory (see package paths), when the log setting enables it. The
+// picker owns the terminal, so a file is the only place its diagnostics
+// can go; the command line logs there too, to keep everything in one place.
+//
+// Records are written as text, one per line, e.g.
+//
+//	time=2024-05-01T10:00:00.000+02:00 level=DEBUG msg=git pid=4242 args="branch --list" dir=/src/app status="exit 0" duration=3.2ms
+//
+// The file is rotated to gotobranch.1.log, replacing the one rotated
+// before, once it grows past 4 MiB.
+package logging
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// File is the name of the log in the state directory.
+const File = "gotobranch.log"
+
+// maxSize is the size past which the log is rotated.
+const maxSize = 4 << 20
+
+// Discard drops every record; it stands in for a logger until one is set.
+var Discard = slog.New(discardHandler{})
+
+// ParseLevel parses the log setting: debug, info, warn or error, the least
+// severe level logged.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	err := l.UnmarshalText([]byte(strings.ToLower(s)))
+	return l, err
+}
+
+// Path returns the location of the log.
+func Path() (string, error) {
+	dir, err := paths.State()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, File), nil
+}
+
+// Open returns a logger appending the records of level and above to the
+// log, and a function closing it.
+func Open(level slog.Level) (*slog.Logger, func() error, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, nil, err
+	}
+	w := &rotating{path: path}
+	if err := w.open(); err != nil {
+		return nil, nil, err
+	}
+	h := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	return slog.New(h).With("pid", os.Getpid()), w.Close, nil
+}
+
+// rotating appends to the file at path, rotating it past maxSize. Other
+// gotobranch processes may be appending to the same file; each record is
+// written in one write, so they do not interleave.
+type rotating struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+}
+
+func (w *rotating) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, fi.Size()
+	return nil
+}
+
+func (w *rotating) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size+int64(len(p)) > maxSize {
+		w.f.Close()
+		old := strings.TrimSuffix(w.path, ".log") + ".1.log"
+		// Another process may have rotated it already.
+		if err := os.Rename(w.path, old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			w.f = nil
+			return 0, err
+		}
+		if err := w.open(); err != nil {
+			w.f = nil
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the file; later records are dropped.
+func (w *rotating) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
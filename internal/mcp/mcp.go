@@ -0,0 +1,177 @@
+// Package mcp serves branch tools over the Model Context Protocol, so that
+// coding assistants can look at and switch branches through gotobranch
+// rather than running git themselves.
+//
+// The protocol is JSON-RPC 2.0 with one message per line on stdin and
+// stdout. Tools that lose work are guarded: protected branches (see
+// core.Protected) cannot be deleted at all, and deleting any other branch
+// needs a confirm argument the assistant is told to set only after asking
+// the user.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Options configures the server.
+type Options struct {
+	RepoPath  string
+	Exclude   []string // hidden from list_branches, like --exclude
+	Protected []string // globs of branches delete_branch refuses
+	Version   string   // reported to clients
+	// OnSwitch, if set, is called after switch_branch switched.
+	OnSwitch func()
+}
+
+// protocolVersions are the protocol revisions spoken, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	codeParse          = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve answers requests read from r on w until r is exhausted.
+func Serve(r io.Reader, w io.Writer, opts Options) error {
+	s := &server{opts: opts, enc: json.NewEncoder(w)}
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 64*1024), 4<<20)
+	for in.Scan() {
+		line := strings.TrimSpace(in.Text())
+		if line == "" {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := s.reply(response{ID: json.RawMessage("null"), Error: &rpcError{codeParse, err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.ID == nil {
+			// Notifications (initialized, cancelled) need no answer.
+			continue
+		}
+		res := response{ID: req.ID}
+		if req.JSONRPC != "2.0" {
+			res.Error = &rpcError{codeInvalidRequest, `jsonrpc must be "2.0"`}
+		} else {
+			res.Result, res.Error = s.handle(req)
+		}
+		if err := s.reply(res); err != nil {
+			return err
+		}
+	}
+	return in.Err()
+}
+
+type server struct {
+	opts Options
+	enc  *json.Encoder
+}
+
+func (s *server) reply(res response) error {
+	res.JSONRPC = "2.0"
+	return s.enc.Encode(res)
+}
+
+func (s *server) handle(req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &p)
+		version := protocolVersions[0]
+		for _, v := range protocolVersions {
+			if v == p.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "gotobranch", "version": s.opts.Version},
+			"instructions":    "Use these tools to inspect and switch git branches. Never set confirm on delete_branch without asking the user first.",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+		call, ok := s.calls()[p.Name]
+		if !ok {
+			return nil, &rpcError{codeInvalidParams, "unknown tool " + p.Name}
+		}
+		if len(p.Arguments) == 0 {
+			p.Arguments = json.RawMessage("{}")
+		}
+		text, err := call(p.Arguments)
+		if err != nil {
+			// Failures are results, so the model sees them and can react.
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "unknown method " + req.Method}
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// toJSON renders v for a tool result.
+func toJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decode reads tool arguments into v, rejecting unknown ones so that typos
+// do not silently drop a setting.
+func decode(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(strings.NewReader(string(args)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
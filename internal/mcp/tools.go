@@ -0,0 +1,262 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// listLimit is how many branches list_branches returns by default.
+const listLimit = 100
+
+// tools describes the tools for tools/list.
+var tools = []map[string]any{
+	{
+		"name":        "list_branches",
+		"description": "List git branches, most recently committed first, with head commit, author and upstream.",
+		"inputSchema": object(map[string]any{
+			"pattern": prop("string", "Case-insensitive substring of the branch name."),
+			"query":   prop("string", "Filter query: author:<text>, since:<time>, until:<time>, merged:<bool>, remote:<bool> terms (times like 2w or 2024-01-31); -term negates."),
+			"scope":   enumProp("Which branches to list (default local).", "local", "remote", "all"),
+			"limit":   prop("integer", fmt.Sprintf("Maximum number of branches (default %d).", listLimit)),
+		}),
+		"annotations": map[string]any{"readOnlyHint": true},
+	},
+	{
+		"name":        "branch_details",
+		"description": "Show one branch in detail: upstream and ahead/behind counts, whether it is merged into the default branch, whether it is protected, its recent commits and its changes against the default branch.",
+		"inputSchema": object(map[string]any{
+			"name": prop("string", "Branch name, e.g. feat/login or origin/feat/login."),
+		}, "name"),
+		"annotations": map[string]any{"readOnlyHint": true},
+	},
+	{
+		"name":        "switch_branch",
+		"description": "Switch the working tree to a branch. Git refuses if uncommitted changes would be overwritten.",
+		"inputSchema": object(map[string]any{
+			"name":   prop("string", "Branch to switch to. A remote branch name without its remote creates a tracking branch."),
+			"create": prop("boolean", "Create the branch if it does not exist."),
+			"from":   prop("string", "With create, the ref to start the new branch at (default HEAD)."),
+		}, "name"),
+		"annotations": map[string]any{"readOnlyHint": false, "destructiveHint": false},
+	},
+	{
+		"name":        "delete_branch",
+		"description": "Delete a local branch. Protected branches are refused. Ask the user before deleting and only then set confirm.",
+		"inputSchema": object(map[string]any{
+			"name":    prop("string", "Local branch to delete."),
+			"force":   prop("boolean", "Delete even if not merged; the branch's unmerged commits are lost."),
+			"confirm": prop("boolean", "Set only once the user has agreed to delete this branch."),
+		}, "name"),
+		"annotations": map[string]any{"readOnlyHint": false, "destructiveHint": true},
+	},
+}
+
+func object(props map[string]any, required ...string) map[string]any {
+	o := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		o["required"] = required
+	}
+	return o
+}
+
+func prop(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+func enumProp(description string, values ...string) map[string]any {
+	return map[string]any{"type": "string", "enum": values, "description": description}
+}
+
+// calls maps tool names to their implementations, which return the text of
+// the result.
+func (s *server) calls() map[string]func(json.RawMessage) (string, error) {
+	return map[string]func(json.RawMessage) (string, error){
+		"list_branches":  s.listBranches,
+		"branch_details": s.branchDetails,
+		"switch_branch":  s.switchBranch,
+		"delete_branch":  s.deleteBranch,
+	}
+}
+
+func (s *server) listBranches(args json.RawMessage) (string, error) {
+	var a struct {
+		Pattern string `json:"pattern"`
+		Query   string `json:"query"`
+		Scope   string `json:"scope"`
+		Limit   int    `json:"limit"`
+	}
+	if err := decode(args, &a); err != nil {
+		return "", err
+	}
+	scope, err := parseScope(a.Scope)
+	if err != nil {
+		return "", err
+	}
+	if a.Limit <= 0 {
+		a.Limit = listLimit
+	}
+	resp, err := core.ListBranches(core.ListBranchesRequest{
+		RepoPath: s.opts.RepoPath,
+		Pattern:  a.Pattern,
+		Query:    a.Query,
+		Exclude:  s.opts.Exclude,
+		Scope:    scope,
+		Page:     1,
+		PageSize: a.Limit,
+	})
+	if err != nil {
+		return "", err
+	}
+	out := map[string]any{"branches": resp.Items, "total": resp.Total}
+	if resp.Items == nil {
+		out["branches"] = []core.Branch{}
+	}
+	if resp.HasNext {
+		out["note"] = fmt.Sprintf("Only the first %d of %d branches are shown; narrow with pattern or query, or raise limit.", len(resp.Items), resp.Total)
+	}
+	return toJSON(out)
+}
+
+func (s *server) branchDetails(args json.RawMessage) (string, error) {
+	var a struct {
+		Name string `json:"name"`
+	}
+	if err := decode(args, &a); err != nil {
+		return "", err
+	}
+	b, err := s.find(a.Name)
+	if err != nil {
+		return "", err
+	}
+	details, err := core.Details(s.opts.RepoPath, []core.Branch{b}, "")
+	if err != nil {
+		return "", err
+	}
+	out := struct {
+		core.BranchDetails
+		Protected bool   `json:"protected"`
+		Log       string `json:"log"`
+	}{BranchDetails: details[0], Protected: !b.IsRemote && core.Protected(s.opts.RepoPath, b.Name, s.opts.Protected)}
+	if out.Log, err = core.Preview(s.opts.RepoPath, b.FullRef, false); err != nil {
+		return "", err
+	}
+	return toJSON(out)
+}
+
+// find returns the local or remote branch called name.
+func (s *server) find(name string) (core.Branch, error) {
+	if strings.TrimSpace(name) == "" {
+		return core.Branch{}, errors.New("name is required")
+	}
+	resp, err := core.ListBranches(core.ListBranchesRequest{
+		RepoPath: s.opts.RepoPath,
+		Scope:    core.ScopeAll,
+		PageSize: 1 << 30,
+	})
+	if err != nil {
+		return core.Branch{}, err
+	}
+	for _, b := range resp.Items {
+		if b.Name == name || b.FullRef == name {
+			return b, nil
+		}
+	}
+	return core.Branch{}, fmt.Errorf("no branch named %q; use list_branches to find it", name)
+}
+
+func (s *server) switchBranch(args json.RawMessage) (string, error) {
+	var a struct {
+		Name   string `json:"name"`
+		Create bool   `json:"create"`
+		From   string `json:"from"`
+	}
+	if err := decode(args, &a); err != nil {
+		return "", err
+	}
+	if a.From != "" && !a.Create {
+		return "", errors.New("from requires create")
+	}
+	var (
+		prev    string
+		created bool
+		err     error
+	)
+	if a.Create {
+		prev, created, err = core.CreateOrSwitch(s.opts.RepoPath, a.Name, a.From)
+	} else {
+		prev, err = core.Checkout(s.opts.RepoPath, a.Name, false)
+	}
+	if err != nil && !core.PostHookFailed(err) {
+		return "", err
+	}
+	if s.opts.OnSwitch != nil {
+		s.opts.OnSwitch()
+	}
+	msg := fmt.Sprintf("Switched to '%s'", a.Name)
+	if created {
+		msg = fmt.Sprintf("Switched to a new branch '%s'", a.Name)
+	}
+	if prev != "" {
+		msg += fmt.Sprintf(" (from '%s')", prev)
+	}
+	return msg + "." + hookWarning(err), nil
+}
+
+func (s *server) deleteBranch(args json.RawMessage) (string, error) {
+	var a struct {
+		Name    string `json:"name"`
+		Force   bool   `json:"force"`
+		Confirm bool   `json:"confirm"`
+	}
+	if err := decode(args, &a); err != nil {
+		return "", err
+	}
+	if !core.LocalBranchExists(s.opts.RepoPath, a.Name) {
+		return "", fmt.Errorf("no local branch named %q", a.Name)
+	}
+	if core.Protected(s.opts.RepoPath, a.Name, s.opts.Protected) {
+		return "", fmt.Errorf("%s is protected and cannot be deleted through this tool", a.Name)
+	}
+	if !a.Confirm {
+		return "", fmt.Errorf("not deleted: ask the user whether to delete %s, then call delete_branch again with confirm set to true", a.Name)
+	}
+	sha, err := core.DeleteBranch(s.opts.RepoPath, a.Name, a.Force)
+	if err != nil && !core.PostHookFailed(err) {
+		if core.ErrorKind(err) == core.KindNotMerged {
+			return "", fmt.Errorf("%s has unmerged commits and was not deleted; deleting it anyway needs force, which loses them", a.Name)
+		}
+		return "", err
+	}
+	return fmt.Sprintf("Deleted %s (was %s). Restore it with: git branch %s %s", a.Name, shortSHA(sha), a.Name, sha) + hookWarning(err), nil
+}
+
+// hookWarning mentions a post hook that failed after the operation.
+func hookWarning(err error) string {
+	if err == nil {
+		return ""
+	}
+	return "\nWarning: " + err.Error()
+}
+
+func parseScope(s string) (core.Scope, error) {
+	switch s {
+	case "", "local":
+		return core.ScopeLocal, nil
+	case "remote":
+		return core.ScopeRemote, nil
+	case "all":
+		return core.ScopeAll, nil
+	}
+	return 0, fmt.Errorf("scope must be local, remote or all, not %q", s)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,197 @@
+// Package metrics records, when the config's metrics setting opts in, how
+// long each run of gotobranch takes, how long the git commands it runs
+// take, and which features it uses, so that slow paths in a repository can
+// be found (see `gotobranch stats --self`). Runs are kept in the state
+// directory (see package state) and never uploaded.
+package metrics
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/state"
+)
+
+// Recorder collects the metrics of the current run. Its methods may be
+// called concurrently.
+type Recorder struct {
+	start time.Time
+
+	mu       sync.Mutex
+	git      map[string]*state.GitTiming
+	features []string
+}
+
+// New starts recording a run.
+func New() *Recorder {
+	return &Recorder{start: time.Now(), git: map[string]*state.GitTiming{}}
+}
+
+// Git records a git command that ran for d, for core.SetGitTimer.
+func (r *Recorder) Git(args []string, d time.Duration) {
+	name := gitCommand(args)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.git[name]
+	if t == nil {
+		t = &state.GitTiming{Command: name}
+		r.git[name] = t
+	}
+	t.Calls++
+	t.Total += d
+	t.Max = max(t.Max, d)
+}
+
+// Use records that feature was used, e.g. "--ci" or "key: delete". Each
+// feature is recorded once per run.
+func (r *Recorder) Use(feature string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.features, feature) {
+		r.features = append(r.features, feature)
+	}
+}
+
+// Finish records the run of command in repo, which ended with exit code
+// exit.
+func (r *Recorder) Finish(command, repo string, exit int) error {
+	r.mu.Lock()
+	run := state.Run{
+		At:       r.start,
+		Command:  command,
+		Repo:     repo,
+		Duration: time.Since(r.start),
+		Exit:     exit,
+		Features: r.features,
+	}
+	for _, t := range r.git {
+		run.Git = append(run.Git, *t)
+	}
+	r.mu.Unlock()
+	sort.Slice(run.Git, func(i, j int) bool { return run.Git[i].Total > run.Git[j].Total })
+	return state.RecordRun(run)
+}
+
+// gitCommand returns the git command args run, skipping global options
+// such as -c key=value.
+func gitCommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "-c" || a == "-C":
+			i++
+		case !strings.HasPrefix(a, "-"):
+			return a
+		}
+	}
+	return "git"
+}
+
+// Summary sums up recorded runs.
+type Summary struct {
+	Runs     int            `json:"runs"`
+	Since    time.Time      `json:"since"`              // the first run
+	Commands []CommandStats `json:"commands"`           // the slowest first, by 90th percentile
+	Git      []GitStats     `json:"git"`                // the most time first
+	Features []FeatureCount `json:"features,omitempty"` // the most used first
+	Slowest  []state.Run    `json:"slowest"`
+}
+
+// CommandStats are the latencies of the runs of a command.
+type CommandStats struct {
+	Command string        `json:"command"`
+	Runs    int           `json:"runs"`
+	Median  time.Duration `json:"median"`
+	P90     time.Duration `json:"p90"`
+	Max     time.Duration `json:"max"`
+}
+
+// GitStats sum up a git command over all runs.
+type GitStats struct {
+	Command string        `json:"command"`
+	Calls   int           `json:"calls"`
+	Total   time.Duration `json:"total"`
+	Mean    time.Duration `json:"mean"`
+	Max     time.Duration `json:"max"`
+}
+
+// FeatureCount is how many runs used a feature.
+type FeatureCount struct {
+	Feature string `json:"feature"`
+	Runs    int    `json:"runs"`
+}
+
+// Summarize sums up runs, listing the slowest n of them.
+func Summarize(runs []state.Run, n int) Summary {
+	s := Summary{Runs: len(runs), Commands: []CommandStats{}, Git: []GitStats{}, Slowest: []state.Run{}}
+	if len(runs) == 0 {
+		return s
+	}
+	s.Since = runs[0].At
+	durations := map[string][]time.Duration{}
+	git := map[string]*GitStats{}
+	features := map[string]int{}
+	for _, r := range runs {
+		durations[r.Command] = append(durations[r.Command], r.Duration)
+		for _, t := range r.Git {
+			g := git[t.Command]
+			if g == nil {
+				g = &GitStats{Command: t.Command}
+				git[t.Command] = g
+			}
+			g.Calls += t.Calls
+			g.Total += t.Total
+			g.Max = max(g.Max, t.Max)
+		}
+		for _, f := range r.Features {
+			features[f]++
+		}
+	}
+	for cmd, ds := range durations {
+		slices.Sort(ds)
+		s.Commands = append(s.Commands, CommandStats{
+			Command: cmd,
+			Runs:    len(ds),
+			Median:  percentile(ds, 50),
+			P90:     percentile(ds, 90),
+			Max:     ds[len(ds)-1],
+		})
+	}
+	sort.Slice(s.Commands, func(i, j int) bool {
+		if s.Commands[i].P90 != s.Commands[j].P90 {
+			return s.Commands[i].P90 > s.Commands[j].P90
+		}
+		return s.Commands[i].Command < s.Commands[j].Command
+	})
+	for _, g := range git {
+		g.Mean = g.Total / time.Duration(g.Calls)
+		s.Git = append(s.Git, *g)
+	}
+	sort.Slice(s.Git, func(i, j int) bool {
+		if s.Git[i].Total != s.Git[j].Total {
+			return s.Git[i].Total > s.Git[j].Total
+		}
+		return s.Git[i].Command < s.Git[j].Command
+	})
+	for f, c := range features {
+		s.Features = append(s.Features, FeatureCount{Feature: f, Runs: c})
+	}
+	sort.Slice(s.Features, func(i, j int) bool {
+		if s.Features[i].Runs != s.Features[j].Runs {
+			return s.Features[i].Runs > s.Features[j].Runs
+		}
+		return s.Features[i].Feature < s.Features[j].Feature
+	})
+	slowest := slices.Clone(runs)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+	s.Slowest = slowest[:min(n, len(slowest))]
+	return s
+}
+
+// percentile returns the p-th percentile of sorted ds, by the nearest rank.
+func percentile(ds []time.Duration, p int) time.Duration {
+	i := (len(ds)*p + 99) / 100
+	return ds[max(i-1, 0)]
+}
@@ -0,0 +1,131 @@
+// Package paths locates the directories gotobranch keeps its files in, as
+// each platform expects:
+//
+//   - Linux and other Unix systems: the XDG base directories,
+//     ~/.config/gotobranch, ~/.local/state/gotobranch and
+//     ~/.cache/gotobranch
+//   - macOS: ~/Library/Application Support/gotobranch for the config and
+//     state, ~/Library/Caches/gotobranch for caches
+//   - Windows: %AppData%\gotobranch for the config,
+//     %LocalAppData%\gotobranch for state, and its cache subdirectory for
+//     caches
+//
+// XDG_CONFIG_HOME, XDG_STATE_HOME and XDG_CACHE_HOME override these on
+// every platform.
+//
+// Earlier versions used the Linux config and state directories everywhere;
+// on macOS and Windows, files found there are moved to the platform's
+// directories the first time those are looked up. Caches are not moved;
+// they are rebuilt as needed.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+var configOnce, stateOnce sync.Once
+
+// Config returns the directory of the config file.
+func Config() (string, error) {
+	dir, err := base("XDG_CONFIG_HOME", os.UserConfigDir)
+	if err != nil {
+		return "", err
+	}
+	return settle(&configOnce, filepath.Join(dir, "gotobranch"), "XDG_CONFIG_HOME", ".config")
+}
+
+// State returns the directory of the files recording what gotobranch
+// remembers between runs, such as switches, the undo journal and the
+// audit log.
+func State() (string, error) {
+	dir, err := base("XDG_STATE_HOME", stateHome)
+	if err != nil {
+		return "", err
+	}
+	return settle(&stateOnce, filepath.Join(dir, "gotobranch"), "XDG_STATE_HOME", ".local", "state")
+}
+
+// Cache returns the directory of data that can be fetched or computed
+// again, such as pull requests and CI statuses.
+func Cache() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "gotobranch"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		// %LocalAppData%\gotobranch holds the state.
+		return filepath.Join(dir, "gotobranch", "cache"), nil
+	}
+	return filepath.Join(dir, "gotobranch"), nil
+}
+
+// base returns the directory named by the environment variable env, or
+// else the platform's.
+func base(env string, platform func() (string, error)) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return dir, nil
+	}
+	return platform()
+}
+
+// stateHome is the platform's directory for state, which Go has no
+// function for.
+func stateHome() (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return os.UserConfigDir()
+	case "windows":
+		return os.UserCacheDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// settle returns dir, first moving into it, once per run, the files in the
+// directory used before when env is unset: ~/<legacy...>/gotobranch.
+func settle(once *sync.Once, dir, env string, legacy ...string) (string, error) {
+	once.Do(func() {
+		if os.Getenv(env) != "" {
+			return
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		migrate(filepath.Join(append(append([]string{home}, legacy...), "gotobranch")...), dir)
+	})
+	return dir, nil
+}
+
+// migrate moves the files in old into dir, except those dir already has
+// one of the same name for, and removes old if that empties it. It is best
+// effort: a file that cannot be moved is left where it is.
+func migrate(old, dir string) {
+	if old == dir {
+		return
+	}
+	entries, err := os.ReadDir(old)
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	for _, e := range entries {
+		to := filepath.Join(dir, e.Name())
+		if _, err := os.Lstat(to); err == nil {
+			continue
+		}
+		_ = os.Rename(filepath.Join(old, e.Name()), to)
+	}
+	_ = os.Remove(old) // fails unless empty
+}
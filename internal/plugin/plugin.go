@@ -0,0 +1,352 @@
+// Package plugin runs external programs that add actions and columns to the
+// picker. A plugin called jira is an executable named gotobranch-jira on
+// PATH; it is only run once enabled by listing its name in the config's
+// plugins.
+//
+// A plugin is run with one of these arguments:
+//
+//	manifest          print what the plugin offers, as JSON:
+//	                  {"actions": [{"id": "open", "title": "open in Jira", "key": "J"}],
+//	                   "columns": [{"id": "ticket", "title": "Ticket", "width": 10}],
+//	                   "cacheFor": "10m"}
+//	column <id>       read "name<TAB>sha" lines, one per branch, and print
+//	                  "name<TAB>value" lines for the branches with a value
+//	action <id>       act on the branch in the environment, attached to the
+//	                  terminal
+//
+// Actions and columns get these variables added to the environment:
+//
+//	GOTOBRANCH_REPO    the repository's top-level directory
+//	GOTOBRANCH_BRANCH  actions: the branch acted on
+//	GOTOBRANCH_REF     actions: its full ref
+//	GOTOBRANCH_SHA     actions: its head commit
+//
+// Manifests and column values must come within Timeout. Column values are
+// cached on disk by branch and head commit for the manifest's cacheFor
+// (DefaultCacheFor when unset), so a plugin is only asked about a branch
+// again once it moves or the value expires.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// Prefix is what the names of plugin executables start with.
+const Prefix = "gotobranch-"
+
+// Timeout bounds a manifest or column run; the plugin is killed after it.
+var Timeout = 5 * time.Second
+
+// waitDelay is how long to wait for the output of a plugin killed for
+// taking too long, which its own children may hold open.
+const waitDelay = time.Second
+
+// DefaultCacheFor is how long column values are reused when the manifest
+// does not say.
+const DefaultCacheFor = 10 * time.Minute
+
+// maxCached bounds a plugin's cache file; the oldest entries are dropped.
+const maxCached = 2000
+
+// Plugin is an enabled plugin and what its manifest offers.
+type Plugin struct {
+	Name string // as listed in the config, e.g. jira
+	Path string // the executable
+
+	Actions  []Action
+	Columns  []Column
+	CacheFor time.Duration
+}
+
+// Action is an action a plugin offers on a branch.
+type Action struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Key   string `json:"key"` // a key running it from the list; optional
+}
+
+// Column is a column of per-branch values a plugin offers.
+type Column struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Width int    `json:"width"` // in terminal columns; 12 when unset
+}
+
+// manifest is what `<plugin> manifest` prints.
+type manifest struct {
+	Actions  []Action `json:"actions"`
+	Columns  []Column `json:"columns"`
+	CacheFor string   `json:"cacheFor"`
+}
+
+// Load finds the executables of the plugins called names and reads their
+// manifests. A plugin that is missing or prints no valid manifest fails
+// loading as a whole, so that a misconfigured plugin is noticed.
+func Load(names []string) ([]*Plugin, error) {
+	var res []*Plugin
+	for _, name := range names {
+		p, err := load(name)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", name, err)
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func load(name string) (*Plugin, error) {
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("no %s%s on PATH", Prefix, name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "manifest")
+	cmd.Stderr, cmd.WaitDelay = &stderr, waitDelay
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, runError(ctx, "manifest", err, stderr.String())
+	}
+	var mf manifest
+	if err := json.Unmarshal(out, &mf); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	p := &Plugin{Name: name, Path: path, Actions: mf.Actions, Columns: mf.Columns, CacheFor: DefaultCacheFor}
+	if mf.CacheFor != "" {
+		if p.CacheFor, err = time.ParseDuration(mf.CacheFor); err != nil {
+			return nil, fmt.Errorf("invalid manifest: cacheFor: %w", err)
+		}
+	}
+	for _, a := range p.Actions {
+		if a.ID == "" || a.Title == "" {
+			return nil, errors.New("invalid manifest: actions need an id and a title")
+		}
+	}
+	for i, c := range p.Columns {
+		if c.ID == "" || strings.ContainsAny(c.ID, "\t\n") {
+			return nil, fmt.Errorf("invalid manifest: invalid column id %q", c.ID)
+		}
+		if c.Title == "" {
+			p.Columns[i].Title = c.ID
+		}
+		if c.Width <= 0 {
+			p.Columns[i].Width = 12
+		}
+	}
+	return p, nil
+}
+
+// ColumnID is how column id of the plugin is referred to in branch
+// templates and core.Branch.Columns, e.g. jira.ticket.
+func (p *Plugin) ColumnID(id string) string {
+	return p.Name + "." + id
+}
+
+// ActionCommand returns the command running action id on b in repoPath,
+// for the caller to attach to the terminal.
+func (p *Plugin) ActionCommand(id, repoPath string, b core.Branch) *exec.Cmd {
+	cmd := exec.Command(p.Path, "action", id)
+	cmd.Dir = topLevel(repoPath)
+	sha := ""
+	if b.HeadCommitSHA != nil {
+		sha = *b.HeadCommitSHA
+	}
+	cmd.Env = append(os.Environ(),
+		"GOTOBRANCH_REPO="+cmd.Dir,
+		"GOTOBRANCH_BRANCH="+b.Name,
+		"GOTOBRANCH_REF="+b.FullRef,
+		"GOTOBRANCH_SHA="+sha,
+	)
+	return cmd
+}
+
+// Values returns the values of the plugin's columns for branches, keyed by
+// full ref and then by ColumnID. Branches without a value are left out.
+// Cached values are reused; the rest are asked for with one run per column.
+func (p *Plugin) Values(ctx context.Context, repoPath string, branches []core.Branch) (map[string]map[string]string, error) {
+	res := map[string]map[string]string{}
+	set := func(ref, col, v string) {
+		if v == "" {
+			return
+		}
+		if res[ref] == nil {
+			res[ref] = map[string]string{}
+		}
+		res[ref][p.ColumnID(col)] = v
+	}
+	c := loadCache(p.Name)
+	defer c.save()
+	dir := topLevel(repoPath)
+	for _, col := range p.Columns {
+		var missing []core.Branch
+		for _, b := range branches {
+			if v, ok := c.get(cacheKey(col.ID, b), p.CacheFor); ok {
+				set(b.FullRef, col.ID, v)
+			} else {
+				missing = append(missing, b)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		vals, err := p.column(ctx, dir, col.ID, missing)
+		if err != nil {
+			return res, fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+		for _, b := range missing {
+			v := vals[b.Name]
+			set(b.FullRef, col.ID, v)
+			c.put(cacheKey(col.ID, b), v)
+		}
+	}
+	return res, nil
+}
+
+// column runs `<plugin> column id` for branches and returns the values it
+// printed by branch name.
+func (p *Plugin) column(ctx context.Context, dir, id string, branches []core.Branch) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	var in strings.Builder
+	for _, b := range branches {
+		sha := ""
+		if b.HeadCommitSHA != nil {
+			sha = *b.HeadCommitSHA
+		}
+		fmt.Fprintf(&in, "%s\t%s\n", b.Name, sha)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path, "column", id)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOTOBRANCH_REPO="+dir)
+	cmd.Stdin = strings.NewReader(in.String())
+	cmd.Stderr, cmd.WaitDelay = &stderr, waitDelay
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, runError(ctx, "column "+id, err, stderr.String())
+	}
+	vals := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if name, v, ok := strings.Cut(sc.Text(), "\t"); ok {
+			vals[name] = strings.TrimSpace(v)
+		}
+	}
+	return vals, nil
+}
+
+// runError describes a failed run of a plugin, naming the timeout when
+// the plugin was killed for taking too long.
+func runError(ctx context.Context, what string, err error, stderr string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s took longer than %s", what, Timeout)
+	}
+	if line := lastLine(stderr); line != "" {
+		return fmt.Errorf("%s failed: %v: %s", what, err, line)
+	}
+	return fmt.Errorf("%s failed: %v", what, err)
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// topLevel returns the top-level directory of the repository at repoPath,
+// or repoPath itself if it cannot be found.
+func topLevel(repoPath string) string {
+	if top, err := core.TopLevel(repoPath); err == nil {
+		return top
+	}
+	return repoPath
+}
+
+// cacheKey identifies the value of column id for b as it is now: a moved
+// branch is asked about again.
+func cacheKey(id string, b core.Branch) string {
+	sha := ""
+	if b.HeadCommitSHA != nil {
+		sha = *b.HeadCommitSHA
+	}
+	return id + "\t" + b.FullRef + "\t" + sha
+}
+
+// cache holds the column values looked up by one plugin.
+type cache struct {
+	path    string
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Value string    `json:"value"`
+	At    time.Time `json:"at"`
+}
+
+// loadCache reads the cache of the plugin called name. A missing or
+// unreadable cache is simply empty.
+func loadCache(name string) *cache {
+	c := &cache{entries: map[string]cacheEntry{}}
+	dir, err := paths.Cache()
+	if err != nil {
+		return c
+	}
+	c.path = filepath.Join(dir, "plugins", name+".json")
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+func (c *cache) get(key string, ttl time.Duration) (string, bool) {
+	e, ok := c.entries[key]
+	if !ok || time.Since(e.At) > ttl {
+		return "", false
+	}
+	return e.Value, true
+}
+
+func (c *cache) put(key, value string) {
+	c.entries[key] = cacheEntry{Value: value, At: time.Now()}
+	c.dirty = true
+}
+
+// save writes the cache back if it changed. Failing to cache is not worth
+// reporting; the values are simply asked for again next time.
+func (c *cache) save() {
+	if !c.dirty || c.path == "" {
+		return
+	}
+	if len(c.entries) > maxCached {
+		keys := make([]string, 0, len(c.entries))
+		for k := range c.entries {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].At.After(c.entries[keys[j]].At) })
+		for _, k := range keys[maxCached:] {
+			delete(c.entries, k)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(c.path), 0o755) == nil {
+		_ = os.WriteFile(c.path, data, 0o644)
+	}
+}
@@ -0,0 +1,181 @@
+// Package prompt looks up working tree statuses for shell prompts, which
+// run gotobranch before every command and so must not be slow.
+//
+// Statuses are cached per working tree in the prompt directory of the cache
+// (see package paths), together with a fingerprint of the files git changes
+// when HEAD, the index or the relevant refs move. A cached status is reused while the
+// fingerprint matches and it is younger than a time-to-live, which bounds
+// how long edits to tracked files (which change none of those files) go
+// unnoticed. Reusing a status runs no git process at all.
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// DefaultTTL is how long a cached status is reused at most.
+const DefaultTTL = 5 * time.Second
+
+type entry struct {
+	Key    string          `json:"key"`
+	At     time.Time       `json:"at"`
+	Status core.WorkStatus `json:"status"`
+}
+
+// Status returns the status of the working tree containing dir; ok is false
+// when dir is not inside one.
+func Status(dir string, ttl time.Duration) (s core.WorkStatus, ok bool, err error) {
+	top, gitDir, commonDir, found := locate(dir)
+	if !found {
+		return s, false, nil
+	}
+	path := cachePath(top)
+	cached, hit := load(path)
+	// Fingerprint before running git, so that changes made meanwhile show
+	// up as a mismatch next time rather than being cached as seen.
+	key := fingerprint(gitDir, commonDir, cached.Status.Upstream)
+	if hit && cached.Key == key && time.Since(cached.At) < ttl {
+		return cached.Status, true, nil
+	}
+	if s, err = core.Status(top); err != nil {
+		return s, false, err
+	}
+	if s.Upstream != cached.Status.Upstream {
+		key = fingerprint(gitDir, commonDir, s.Upstream)
+	}
+	save(path, entry{Key: key, At: time.Now(), Status: s})
+	return s, true, nil
+}
+
+// locate finds the working tree containing dir and its git directories the
+// way git does, without running it: the nearest .git, which is either the
+// git directory or, in linked worktrees and submodules, a file pointing to
+// it.
+func locate(dir string) (top, gitDir, commonDir string, ok bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", "", false
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if fi, err := os.Stat(dotGit); err == nil {
+			gitDir = dotGit
+			if !fi.IsDir() {
+				data, err := os.ReadFile(dotGit)
+				if err != nil {
+					return "", "", "", false
+				}
+				target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !found {
+					return "", "", "", false
+				}
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(dir, target)
+				}
+				gitDir = target
+			}
+			commonDir = gitDir
+			if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+				commonDir = strings.TrimSpace(string(data))
+				if !filepath.IsAbs(commonDir) {
+					commonDir = filepath.Join(gitDir, commonDir)
+				}
+			}
+			return dir, gitDir, commonDir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", "", false
+		}
+		dir = parent
+	}
+}
+
+// fingerprint describes the files whose changes can change the status:
+// HEAD, the index, the current branch's ref, its upstream's ref and the
+// packed refs holding either.
+func fingerprint(gitDir, commonDir, upstream string) string {
+	head, _ := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	var b strings.Builder
+	b.Write(head)
+	files := []string{
+		filepath.Join(gitDir, "HEAD"),
+		filepath.Join(gitDir, "index"),
+		filepath.Join(gitDir, "FETCH_HEAD"),
+		filepath.Join(commonDir, "packed-refs"),
+	}
+	if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: "); ok {
+		files = append(files, filepath.Join(commonDir, filepath.FromSlash(ref)))
+	}
+	if upstream != "" {
+		files = append(files,
+			filepath.Join(commonDir, "refs", "remotes", filepath.FromSlash(upstream)),
+			filepath.Join(commonDir, "refs", "heads", filepath.FromSlash(upstream)))
+	}
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil {
+			fmt.Fprintf(&b, "|%d.%d", fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			b.WriteString("|-")
+		}
+	}
+	return b.String()
+}
+
+// cachePath returns the cache file of the working tree top, or "" when
+// there is no cache directory.
+func cachePath(top string) string {
+	dir, err := paths.Cache()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(top))
+	return filepath.Join(dir, "prompt", hex.EncodeToString(sum[:8])+".json")
+}
+
+func load(path string) (entry, bool) {
+	var e entry
+	if path == "" {
+		return e, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &e) != nil {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// save writes e to path. Failing to cache only costs speed, so errors are
+// ignored. The file is replaced atomically since prompts of several shells
+// may race.
+func save(path string, e entry) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil || os.MkdirAll(filepath.Dir(path), 0o755) != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".prompt-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	if cerr := tmp.Close(); werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
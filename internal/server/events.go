@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/watch"
+)
+
+// BranchDelta is how the branches in a stream's view changed. Branches are
+// told apart by full ref.
+type BranchDelta struct {
+	Added   []core.Branch `json:"added"`
+	Removed []string      `json:"removed"` // full refs
+	Updated []core.Branch `json:"updated"` // moved, or became (no longer) current
+}
+
+// heartbeat is how often an idle stream sends a comment, so that proxies
+// and clients do not time it out.
+const heartbeat = 30 * time.Second
+
+// events streams the branches matching the listBranches filters as
+// server-sent events: a "snapshot" with all of them, then a "delta" after
+// each change to the refs. Pagination parameters are ignored.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.listRequest(w, r.URL.Query())
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem(w, http.StatusInternalServerError, "Streaming unsupported", "The connection cannot stream events.")
+		return
+	}
+	req.Page, req.PageSize = 1, 1<<30
+	prev, err := core.ListBranches(req)
+	if err != nil {
+		gitProblem(w, err)
+		return
+	}
+	changes, err := watch.Refs(r.Context(), req.RepoPath)
+	if err != nil {
+		gitProblem(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(event string, v any) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	if prev.Items == nil {
+		prev.Items = []core.Branch{}
+	}
+	if !send("snapshot", prev) {
+		return
+	}
+	tick := time.NewTicker(heartbeat)
+	defer tick.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-tick.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+			cur, err := core.ListBranches(req)
+			if err != nil {
+				// The repository may be mid-update; the next change retries.
+				continue
+			}
+			d := diffBranches(prev.Items, cur.Items)
+			prev = cur
+			if len(d.Added)+len(d.Removed)+len(d.Updated) == 0 {
+				continue
+			}
+			if !send("delta", d) {
+				return
+			}
+		}
+	}
+}
+
+// diffBranches returns the delta turning old into cur.
+func diffBranches(old, cur []core.Branch) BranchDelta {
+	d := BranchDelta{Added: []core.Branch{}, Removed: []string{}, Updated: []core.Branch{}}
+	before := make(map[string]core.Branch, len(old))
+	for _, b := range old {
+		before[b.FullRef] = b
+	}
+	for _, b := range cur {
+		o, ok := before[b.FullRef]
+		delete(before, b.FullRef)
+		switch {
+		case !ok:
+			d.Added = append(d.Added, b)
+		case o.IsCurrent != b.IsCurrent || deref(o.HeadCommitSHA) != deref(b.HeadCommitSHA) || deref(o.Upstream) != deref(b.Upstream):
+			d.Updated = append(d.Updated, b)
+		}
+	}
+	for _, b := range old {
+		if _, gone := before[b.FullRef]; gone {
+			d.Removed = append(d.Removed, b.FullRef)
+		}
+	}
+	return d
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
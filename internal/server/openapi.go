@@ -0,0 +1,301 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// schemas are the types the API exchanges, by component name. Their
+// schemas are derived from the Go types, so the document cannot drift from
+// what the handlers encode.
+var schemas = []struct {
+	name string
+	typ  reflect.Type
+}{
+	{"Branch", reflect.TypeFor[core.Branch]()},
+	{"PullRequest", reflect.TypeFor[core.PullRequest]()},
+	{"Signature", reflect.TypeFor[core.Signature]()},
+	{"Divergence", reflect.TypeFor[core.Divergence]()},
+	{"Tracking", reflect.TypeFor[core.Tracking]()},
+	{"ListBranchesResponse", reflect.TypeFor[core.ListBranchesResponse]()},
+	{"CheckoutRequest", reflect.TypeFor[checkoutRequest]()},
+	{"CheckoutResponse", reflect.TypeFor[checkoutResponse]()},
+	{"DeleteBranchResponse", reflect.TypeFor[deleteResponse]()},
+	{"BranchDelta", reflect.TypeFor[BranchDelta]()},
+	{"Problem", reflect.TypeFor[problemDetails]()},
+}
+
+// enums constrain string fields, keyed by "Schema.field".
+var enums = map[string][]any{
+	"Branch.ciStatus":   {core.CISuccess, core.CIFailure, core.CIPending},
+	"PullRequest.state": {"open", "closed", "merged"},
+	"Signature.status": {
+		core.SigGood, core.SigUntrusted, core.SigExpired, core.SigRevoked,
+		core.SigBad, core.SigUnverified, core.SigUnsigned,
+	},
+	"PullRequest.review": {
+		"approved", "changes_requested", "review_required", "",
+	},
+}
+
+// APIVersion is the version of the API contract, bumped when it changes.
+const APIVersion = "0.4.0"
+
+// OpenAPI returns the OpenAPI 3.1 document describing Handler.
+func OpenAPI() map[string]any {
+	components := map[string]any{}
+	for _, s := range schemas {
+		components[s.name] = structSchema(s.name, s.typ)
+	}
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "gotobranch API",
+			"version":     APIVersion,
+			"description": "Lists, switches and deletes the branches of git repositories, as served by `gotobranch serve`. Generated from the Go types; do not edit.",
+		},
+		"servers": []any{map[string]any{
+			"url":         "http://127.0.0.1:9999",
+			"description": "`gotobranch serve`, which only listens on loopback addresses.",
+		}},
+		"security": []any{map[string]any{"bearerAuth": []any{}}},
+		"paths":    paths(),
+		"components": map[string]any{
+			"schemas": components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "The token given to (or printed by) `gotobranch serve`; see GOTOBRANCH_TOKEN.",
+				},
+			},
+		},
+	}
+}
+
+func paths() map[string]any {
+	repoPath := param("query", "repoPath", str(), "Absolute path to the git repository. Defaults to the server's repository.")
+	return map[string]any{
+		"/branches": map[string]any{
+			"get": operation("listBranches", "List branches with optional filtering and pagination.",
+				[]any{
+					repoPath,
+					param("query", "pattern", str(), "Filter applied to branch names per match."),
+					param("query", "match", withDefault(enum("contains", "glob", "regex", "fuzzy"), "contains"), "How pattern is applied to branch names. All modes are case-insensitive."),
+					param("query", "query", str(), "Filter query applied on top of pattern: author:, before:, after:, since:, until:, merged:, remote: terms and words; a leading - negates a term."),
+					param("query", "exclude", map[string]any{"type": "array", "items": str()}, "Globs of branch names to hide."),
+					param("query", "scope", withDefault(enum("local", "remote", "all"), "local"), "Whether to include local, remote, or all branches."),
+					param("query", "sortBy", withDefault(enum("frecency", "name", "recency", "commits"), "frecency"), "Sort by how often and how recently branches were switched to (with gotobranch, or as recorded by its post-checkout hook; branches never switched to follow, newest first), by name, by last commit time, or by the number of commits not on the default branch."),
+					param("query", "sortDir", enum("asc", "desc"), "Sort direction; ascending for name and descending otherwise by default."),
+					param("query", "page", integer(1, 0, 1), "1-based page number."),
+					param("query", "pageSize", integer(1, maxPageSize, 50), "Items per page."),
+				}, nil,
+				response("200", "Paginated list of branches.", "ListBranchesResponse"),
+				response("400", "Invalid parameters.", "Problem"),
+			),
+		},
+		"/events": map[string]any{
+			"get": operation("streamBranches", "Stream the branches matching the listBranches filters as server-sent events: a \"snapshot\" event with a ListBranchesResponse holding all of them, then a \"delta\" event with a BranchDelta whenever refs change. page and pageSize are ignored.",
+				[]any{
+					repoPath,
+					param("query", "pattern", str(), "As for listBranches."),
+					param("query", "match", withDefault(enum("contains", "glob", "regex", "fuzzy"), "contains"), "As for listBranches."),
+					param("query", "query", str(), "As for listBranches."),
+					param("query", "exclude", map[string]any{"type": "array", "items": str()}, "As for listBranches."),
+					param("query", "scope", withDefault(enum("local", "remote", "all"), "local"), "As for listBranches."),
+				}, nil,
+				[2]any{"200", map[string]any{
+					"description": "An endless event stream.",
+					"content":     map[string]any{"text/event-stream": map[string]any{"schema": str()}},
+				}},
+				response("400", "Invalid parameters.", "Problem"),
+			),
+		},
+		"/current-branch": map[string]any{
+			"get": operation("getCurrentBranch", "Get the current branch of a repository.",
+				[]any{repoPath}, nil,
+				response("200", "The current branch.", "Branch"),
+				response("404", "Not on any branch (detached HEAD).", "Problem"),
+			),
+		},
+		"/checkout": map[string]any{
+			"post": operation("checkoutBranch", "Switch to a branch, creating it when create is set and it does not exist.",
+				nil, ref("CheckoutRequest"),
+				response("200", "The switch happened.", "CheckoutResponse"),
+				response("400", "Invalid request body.", "Problem"),
+				response("409", "Uncommitted changes would be overwritten.", "Problem"),
+			),
+		},
+		"/branches/{name}": map[string]any{
+			"delete": operation("deleteBranch", "Delete a local branch.",
+				[]any{
+					param("path", "name", str(), "Short branch name; may contain slashes."),
+					repoPath,
+					param("query", "force", map[string]any{"type": "boolean", "default": false}, "Delete the branch even if it is not fully merged."),
+				}, nil,
+				response("200", "The branch was deleted; `git branch <name> <sha>` restores it.", "DeleteBranchResponse"),
+				response("404", "No such local branch.", "Problem"),
+				response("409", "The branch is not fully merged, or is checked out.", "Problem"),
+			),
+		},
+		"/openapi.json": map[string]any{
+			"get": map[string]any{
+				"operationId": "getOpenAPI",
+				"summary":     "This document. Needs no token.",
+				"security":    []any{},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "The OpenAPI document.",
+						"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"type": "object"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func operation(id, summary string, params []any, body map[string]any, responses ...[2]any) map[string]any {
+	op := map[string]any{"operationId": id, "summary": summary}
+	if params != nil {
+		op["parameters"] = params
+	}
+	if body != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": body}},
+		}
+	}
+	res := map[string]any{
+		"401": map[string]any{"description": "Missing or wrong bearer token.", "content": problemContent()},
+	}
+	for _, r := range responses {
+		res[r[0].(string)] = r[1]
+	}
+	op["responses"] = res
+	return op
+}
+
+func response(status, description, schema string) [2]any {
+	content := map[string]any{"application/json": map[string]any{"schema": ref(schema)}}
+	if schema == "Problem" {
+		content = problemContent()
+	}
+	return [2]any{status, map[string]any{"description": description, "content": content}}
+}
+
+func problemContent() map[string]any {
+	return map[string]any{"application/problem+json": map[string]any{"schema": ref("Problem")}}
+}
+
+func param(in, name string, schema map[string]any, description string) map[string]any {
+	p := map[string]any{"in": in, "name": name, "schema": schema, "description": description}
+	if in == "path" {
+		p["required"] = true
+	}
+	if schema["type"] == "array" {
+		p["explode"] = true
+	}
+	return p
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func str() map[string]any { return map[string]any{"type": "string"} }
+
+// enum returns a schema for a string that is one of values.
+func enum(values ...string) map[string]any {
+	return map[string]any{"type": "string", "enum": values}
+}
+
+// withDefault sets the default of schema to def.
+func withDefault(schema map[string]any, def any) map[string]any {
+	schema["default"] = def
+	return schema
+}
+
+// integer returns an integer schema; a zero max means unbounded.
+func integer(min, max, def int) map[string]any {
+	s := map[string]any{"type": "integer", "minimum": min, "default": def}
+	if max > 0 {
+		s["maximum"] = max
+	}
+	return s
+}
+
+// structSchema derives the schema of struct type t from its JSON encoding:
+// fields without omitempty are required, pointers may be null, and other
+// schema types are referenced by name.
+func structSchema(name string, t reflect.Type) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if f.Anonymous {
+				walk(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			tag, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if tag == "-" {
+				continue
+			}
+			if tag == "" {
+				tag = f.Name
+			}
+			s := typeSchema(f.Type)
+			if e, ok := enums[name+"."+tag]; ok {
+				s["enum"] = e
+			}
+			props[tag] = s
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, tag)
+			}
+		}
+	}
+	walk(t)
+	return map[string]any{"type": "object", "required": required, "properties": props}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func typeSchema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	for _, s := range schemas {
+		if s.typ == t {
+			return ref(s.name)
+		}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := typeSchema(t.Elem())
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"oneOf": []any{s, map[string]any{"type": "null"}}}
+		}
+		s["type"] = []any{s["type"], "null"}
+		return s
+	case reflect.String:
+		return str()
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	}
+	return map[string]any{}
+}
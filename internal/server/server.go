@@ -0,0 +1,340 @@
+// Package server exposes the core operations over HTTP, following the
+// contract in spec/openapi.yaml, so that editor plugins and dashboards can
+// list and switch branches through a running gotobranch.
+//
+// Every request must carry the server's bearer token. Errors are reported as
+// RFC 7807 problem details.
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/config"
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Options configures the handler.
+type Options struct {
+	// RepoPath is the repository used when a request names none.
+	RepoPath string
+	// Token is the bearer token clients must send.
+	Token string
+	// Exclude hides branches from listings, like --exclude.
+	Exclude []string
+	// OnSwitch, if set, is called with the repository after a checkout.
+	OnSwitch func(repoPath string)
+}
+
+// maxPageSize bounds pageSize, as in the spec.
+const maxPageSize = 200
+
+// Handler returns the HTTP handler for opts.
+func Handler(opts Options) http.Handler {
+	s := &server{opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /branches", s.listBranches)
+	mux.HandleFunc("GET /current-branch", s.currentBranch)
+	mux.HandleFunc("GET /events", s.events)
+	mux.HandleFunc("POST /checkout", s.checkout)
+	// Branch names contain slashes, so the name is the rest of the path.
+	mux.HandleFunc("DELETE /branches/{name...}", s.deleteBranch)
+	// Clients need the document to learn how to authenticate.
+	root := http.NewServeMux()
+	spec := OpenAPI()
+	root.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
+	})
+	root.Handle("/", s.authorize(mux))
+	return root
+}
+
+// NewToken returns a random token for servers started without one.
+func NewToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CheckLoopback returns an error unless addr (host:port) only listens on
+// the loopback interface. Switching branches is not for the network to do.
+func CheckLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("refusing to listen on %s; use a loopback address such as 127.0.0.1", addr)
+}
+
+type server struct {
+	opts Options
+}
+
+func (s *server) authorize(next http.Handler) http.Handler {
+	want := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if s.opts.Token == "" || subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			problem(w, http.StatusUnauthorized, "Unauthorized", "Send the server's token as Authorization: Bearer <token>.")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) repo(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return s.opts.RepoPath
+}
+
+func (s *server) listBranches(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.listRequest(w, r.URL.Query())
+	if !ok {
+		return
+	}
+	resp, err := core.ListBranches(req)
+	if err != nil {
+		gitProblem(w, err)
+		return
+	}
+	if resp.Items == nil {
+		resp.Items = []core.Branch{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// listRequest reads the listBranches parameters from q. It reports invalid
+// ones to w and returns false.
+func (s *server) listRequest(w http.ResponseWriter, q url.Values) (core.ListBranchesRequest, bool) {
+	req := core.ListBranchesRequest{
+		RepoPath: s.repo(q.Get("repoPath")),
+		Pattern:  q.Get("pattern"),
+		Query:    q.Get("query"),
+		Exclude:  append(append([]string(nil), s.opts.Exclude...), q["exclude"]...),
+		Page:     1,
+		PageSize: 50,
+	}
+	var err error
+	if m := q.Get("match"); m != "" {
+		if req.Match, err = core.ParseMatchMode(m); err != nil {
+			problem(w, http.StatusBadRequest, "Invalid match", err.Error())
+			return req, false
+		}
+	}
+	switch q.Get("scope") {
+	case "", "local":
+		req.Scope = core.ScopeLocal
+	case "remote":
+		req.Scope = core.ScopeRemote
+	case "all":
+		req.Scope = core.ScopeAll
+	default:
+		problem(w, http.StatusBadRequest, "Invalid scope", "scope must be local, remote or all.")
+		return req, false
+	}
+	sort := q.Get("sortBy")
+	if sort == "" {
+		sort = "frecency" // as on the command line, whose scores main hands core for serve too
+	}
+	if dir := q.Get("sortDir"); dir != "" {
+		sort += ":" + dir
+	}
+	if req.SortBy, req.SortDir, err = config.ParseSort(sort); err != nil {
+		problem(w, http.StatusBadRequest, "Invalid sort", err.Error())
+		return req, false
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+		max  int
+	}{{"page", &req.Page, 0}, {"pageSize", &req.PageSize, maxPageSize}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || (p.max > 0 && n > p.max) {
+			problem(w, http.StatusBadRequest, "Invalid "+p.name, fmt.Sprintf("%s must be a positive integer%s.", p.name, maxNote(p.max)))
+			return req, false
+		}
+		*p.dst = n
+	}
+	if _, err := core.NewMatcher(req.Match, req.Pattern); err != nil {
+		problem(w, http.StatusBadRequest, "Invalid pattern", err.Error())
+		return req, false
+	}
+	if _, err := core.CompileQuery(req.RepoPath, req.Query, req.Match); err != nil {
+		problem(w, http.StatusBadRequest, "Invalid query", err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+func maxNote(max int) string {
+	if max == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" no larger than %d", max)
+}
+
+func (s *server) currentBranch(w http.ResponseWriter, r *http.Request) {
+	b, err := core.GetCurrentBranch(s.repo(r.URL.Query().Get("repoPath")))
+	if err != nil {
+		if errors.Is(err, core.ErrDetachedHead) {
+			problem(w, http.StatusNotFound, "Detached HEAD", "Repository is in a detached HEAD state.")
+			return
+		}
+		gitProblem(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+// checkoutRequest is the CheckoutRequest schema. TrackRemote is implied:
+// git sets up tracking whenever a branch is created from a remote one.
+type checkoutRequest struct {
+	RepoPath    string `json:"repoPath,omitempty"`
+	Name        string `json:"name"`
+	Create      bool   `json:"create,omitempty"`
+	TrackRemote *bool  `json:"trackRemote,omitempty"`
+}
+
+type checkoutResponse struct {
+	Switched       bool         `json:"switched"`
+	PreviousBranch *string      `json:"previousBranch"`
+	CurrentBranch  *core.Branch `json:"currentBranch"`
+	Warning        string       `json:"warning,omitempty"` // a post-switch hook failed
+}
+
+func (s *server) checkout(w http.ResponseWriter, r *http.Request) {
+	var req checkoutRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		problem(w, http.StatusBadRequest, "Invalid request body", "name is required.")
+		return
+	}
+	repo := s.repo(req.RepoPath)
+	var (
+		prev string
+		err  error
+	)
+	if req.Create {
+		prev, _, err = core.CreateOrSwitch(repo, req.Name, "")
+	} else {
+		prev, err = core.Checkout(repo, req.Name, false)
+	}
+	if errors.Is(err, core.ErrOptionLike) {
+		problem(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if err != nil && !core.PostHookFailed(err) {
+		gitProblem(w, err)
+		return
+	}
+	if s.opts.OnSwitch != nil {
+		s.opts.OnSwitch(repo)
+	}
+	resp := checkoutResponse{Switched: true}
+	if err != nil {
+		resp.Warning = err.Error()
+	}
+	if prev != "" {
+		resp.PreviousBranch = &prev
+	}
+	if cur, err := core.GetCurrentBranch(repo); err == nil {
+		resp.CurrentBranch = cur
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type deleteResponse struct {
+	Name    string `json:"name"`
+	SHA     string `json:"sha"`
+	Warning string `json:"warning,omitempty"` // a post-delete hook failed
+}
+
+func (s *server) deleteBranch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	force, _ := strconv.ParseBool(q.Get("force"))
+	name := r.PathValue("name")
+	sha, err := core.DeleteBranch(s.repo(q.Get("repoPath")), name, force)
+	if err != nil && !core.PostHookFailed(err) {
+		if strings.HasPrefix(err.Error(), "no such local branch") {
+			problem(w, http.StatusNotFound, "No such branch", err.Error())
+			return
+		}
+		gitProblem(w, err)
+		return
+	}
+	resp := deleteResponse{Name: name, SHA: sha}
+	if err != nil {
+		resp.Warning = err.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// gitProblem reports a failed git operation, telling conflicts with the
+// state of the repository apart from other failures.
+func gitProblem(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	switch core.ErrorKind(err) {
+	case core.KindLocalChanges:
+		problem(w, http.StatusConflict, "Working tree has uncommitted changes", msg)
+	case core.KindNotMerged:
+		problem(w, http.StatusConflict, "Branch is not fully merged", msg)
+	case core.KindCheckedOut:
+		problem(w, http.StatusConflict, "Branch is checked out", msg)
+	case core.KindBranchExists:
+		problem(w, http.StatusConflict, "Branch exists", msg)
+	case core.KindNotFound, core.KindNotRepository:
+		problem(w, http.StatusNotFound, "Not found", msg)
+	default:
+		problem(w, http.StatusInternalServerError, "Git failed", msg)
+	}
+}
+
+// problemDetails is an RFC 7807 problem details body.
+type problemDetails struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// problem writes an RFC 7807 problem details response.
+func problem(w http.ResponseWriter, status int, title, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problemDetails{Type: "about:blank", Title: title, Status: status, Detail: detail})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,115 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// AuditEntry records a change gotobranch made to the branches of the
+// repository whose top-level directory is Repo.
+type AuditEntry struct {
+	At   time.Time `json:"at"`
+	Repo string    `json:"repo"`
+	User string    `json:"user,omitempty"` // the system user
+	Git  string    `json:"git,omitempty"`  // git's user.email in Repo
+	Via  string    `json:"via,omitempty"`  // the gotobranch command, e.g. switch or picker
+	core.Operation
+}
+
+// maxAuditSize is the size past which the audit log is rotated: it is
+// renamed to the next free audit.<n>.jsonl, counting from 1, and a new log
+// started. Entries are never rewritten or dropped, and archives never
+// deleted.
+var maxAuditSize int64 = 8 << 20
+
+// auditPaths returns the audit log and its archives, oldest first.
+func auditPaths() (cur string, archives []string, err error) {
+	if cur, err = file("audit.jsonl"); err != nil {
+		return "", nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(cur), "audit.*.jsonl"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, m := range matches {
+		if archiveNumber(m) > 0 {
+			archives = append(archives, m)
+		}
+	}
+	slices.SortFunc(archives, func(a, b string) int { return archiveNumber(a) - archiveNumber(b) })
+	return cur, archives, nil
+}
+
+// archiveNumber returns n for an audit.<n>.jsonl path, or 0.
+func archiveNumber(path string) int {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "audit."), ".jsonl"))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// RecordAudit appends e to the audit log.
+func RecordAudit(e AuditEntry) error {
+	path, archives, err := auditPaths()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if fi, err := os.Stat(path); err == nil && fi.Size() >= maxAuditSize {
+		n := 1
+		if len(archives) > 0 {
+			n = archiveNumber(archives[len(archives)-1]) + 1
+		}
+		next := filepath.Join(filepath.Dir(path), fmt.Sprintf("audit.%d.jsonl", n))
+		// Another process may have rotated it already.
+		if err := os.Rename(path, next); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Audit returns the audit log entries for repo, or for every repository
+// when repo is empty, oldest first.
+func Audit(repo string) ([]AuditEntry, error) {
+	path, archives, err := auditPaths()
+	if err != nil {
+		return nil, err
+	}
+	var res []AuditEntry
+	for _, p := range append(archives, path) {
+		all, err := read[AuditEntry](p)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range all {
+			if repo == "" || e.Repo == repo {
+				res = append(res, e)
+			}
+		}
+	}
+	return res, nil
+}
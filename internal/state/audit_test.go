@@ -0,0 +1,48 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// TestAuditRotation checks that rotating the audit log keeps every archive
+// and that Audit still returns all entries in order.
+func TestAuditRotation(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	t.Setenv("HOME", dir)
+	defer func(size int64) { maxAuditSize = size }(maxAuditSize)
+	maxAuditSize = 1 // every entry goes to a new log
+
+	start := time.Date(2024, 1, 31, 15, 4, 5, 0, time.UTC)
+	const n = 12
+	for i := range n {
+		e := AuditEntry{At: start.Add(time.Duration(i) * time.Minute), Repo: "/src/app", Operation: core.Operation{Branch: "main"}}
+		if err := RecordAudit(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i < n; i++ {
+		if _, err := os.Stat(filepath.Join(dir, "gotobranch", fmt.Sprintf("audit.%d.jsonl", i))); err != nil {
+			t.Errorf("archive %d: %v", i, err)
+		}
+	}
+
+	all, err := Audit("/src/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != n {
+		t.Fatalf("Audit returned %d entries, want %d", len(all), n)
+	}
+	for i, e := range all {
+		if want := start.Add(time.Duration(i) * time.Minute); !e.At.Equal(want) {
+			t.Errorf("entry %d is from %v, want %v", i, e.At, want)
+		}
+	}
+}
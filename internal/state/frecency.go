@@ -0,0 +1,112 @@
+package state
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FrecencyHalfLife is how long it takes a branch's frecency to halve
+// without switches to it.
+const FrecencyHalfLife = 7 * 24 * time.Hour
+
+// minFrecency is the score below which a branch is forgotten: a single
+// switch about seven half-lives ago.
+const minFrecency = 0.01
+
+// frecency is the frecency of a branch as of At: each switch adds 1 to a
+// score that decays with FrecencyHalfLife.
+type frecency struct {
+	Repo     string    `json:"repo"`
+	Branch   string    `json:"branch"`
+	Score    float64   `json:"score"`
+	Switches int       `json:"switches"`
+	At       time.Time `json:"at"`
+}
+
+// decayed returns the score of f at now.
+func (f frecency) decayed(now time.Time) float64 {
+	return f.Score * math.Exp2(-now.Sub(f.At).Hours()/FrecencyHalfLife.Hours())
+}
+
+func frecencyPath() (string, error) {
+	return file("frecency.jsonl")
+}
+
+// loadFrecency reads the frecency store, first building it from the
+// switches recorded so far if there is none yet.
+func loadFrecency() (string, []frecency, error) {
+	path, err := frecencyPath()
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		all, err := read[frecency](path)
+		return path, all, err
+	}
+	switches, err := Path()
+	if err != nil {
+		return "", nil, err
+	}
+	past, err := read[Switch](switches)
+	if err != nil {
+		return "", nil, err
+	}
+	var all []frecency
+	for _, s := range past {
+		all = bump(all, s)
+	}
+	return path, all, nil
+}
+
+// bump adds the switch s to all.
+func bump(all []frecency, s Switch) []frecency {
+	for i, f := range all {
+		if f.Repo == s.Repo && f.Branch == s.Branch {
+			all[i] = frecency{Repo: s.Repo, Branch: s.Branch, Score: f.decayed(s.At) + 1, Switches: f.Switches + 1, At: s.At}
+			return all
+		}
+	}
+	return append(all, frecency{Repo: s.Repo, Branch: s.Branch, Score: 1, Switches: 1, At: s.At})
+}
+
+// recordFrecency adds the switch s to the frecency store, forgetting the
+// branches not switched to for long.
+func recordFrecency(s Switch) error {
+	path, all, err := loadFrecency()
+	if err != nil {
+		return err
+	}
+	all = bump(all, s)
+	kept := all[:0]
+	for _, f := range all {
+		if f.decayed(s.At) >= minFrecency {
+			kept = append(kept, f)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return write(path, kept)
+}
+
+// Frecency returns the frecency of the branches switched to in repo at
+// now, by branch name: the switches to each, each counting for less the
+// longer ago it was, halving every FrecencyHalfLife. Branches never
+// switched to are left out.
+func Frecency(repo string, now time.Time) (map[string]float64, error) {
+	_, all, err := loadFrecency()
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]float64{}
+	for _, f := range all {
+		if f.Repo == repo {
+			res[f.Branch] = f.decayed(now)
+		}
+	}
+	return res, nil
+}
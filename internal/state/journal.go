@@ -0,0 +1,69 @@
+package state
+
+import (
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Action records a change to the branches of the repository whose
+// top-level directory is Repo, for undoing it.
+type Action struct {
+	Repo string    `json:"repo"`
+	At   time.Time `json:"at"`
+	core.Action
+}
+
+// maxActions bounds the journal like maxSwitches bounds the switch log.
+const maxActions = 1000
+
+// RecordAction appends a to the journal.
+func RecordAction(a Action) error {
+	path, err := file("journal.jsonl")
+	if err != nil {
+		return err
+	}
+	all, err := read[Action](path)
+	if err != nil {
+		return err
+	}
+	return appendBounded(path, all, a, maxActions)
+}
+
+// LastAction returns the most recent action recorded for repo; ok is false
+// when there is none.
+func LastAction(repo string) (a Action, ok bool, err error) {
+	path, err := file("journal.jsonl")
+	if err != nil {
+		return a, false, err
+	}
+	all, err := read[Action](path)
+	if err != nil {
+		return a, false, err
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Repo == repo {
+			return all[i], true, nil
+		}
+	}
+	return a, false, nil
+}
+
+// ForgetAction removes the most recent record of a from the journal, once
+// it has been undone.
+func ForgetAction(a Action) error {
+	path, err := file("journal.jsonl")
+	if err != nil {
+		return err
+	}
+	all, err := read[Action](path)
+	if err != nil {
+		return err
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Repo == a.Repo && all[i].At.Equal(a.At) && all[i].Action == a.Action {
+			return write(path, append(all[:i], all[i+1:]...))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import "time"
+
+// Run records a run of gotobranch, when metrics are enabled (see package
+// metrics). Nothing recorded leaves the machine.
+type Run struct {
+	At       time.Time     `json:"at"`
+	Command  string        `json:"command"` // e.g. list, or picker without a command
+	Repo     string        `json:"repo,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Exit     int           `json:"exit"` // the exit code
+	Git      []GitTiming   `json:"git,omitempty"`
+	Features []string      `json:"features,omitempty"` // e.g. "--ci" or "key: delete"
+}
+
+// GitTiming sums up the runs of one git command, e.g. for-each-ref, during
+// a Run.
+type GitTiming struct {
+	Command string        `json:"command"`
+	Calls   int           `json:"calls"`
+	Total   time.Duration `json:"total"`
+	Max     time.Duration `json:"max"`
+}
+
+// maxRuns bounds the metrics log like maxSwitches.
+const maxRuns = 2000
+
+// RecordRun appends r to the metrics log.
+func RecordRun(r Run) error {
+	path, err := file("metrics.jsonl")
+	if err != nil {
+		return err
+	}
+	all, err := read[Run](path)
+	if err != nil {
+		return err
+	}
+	return appendBounded(path, all, r, maxRuns)
+}
+
+// Runs returns the recorded runs, oldest first.
+func Runs() ([]Run, error) {
+	path, err := file("metrics.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	return read[Run](path)
+}
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Settings are preferences changed from within the picker, kept for the
+// next run.
+type Settings struct {
+	// PreviewWidth is the share of the terminal width, in percent, that
+	// the preview pane takes; 0 when never changed.
+	PreviewWidth int `json:"previewWidth,omitempty"`
+}
+
+// LoadSettings returns the saved settings, zero when none were saved.
+func LoadSettings() (Settings, error) {
+	path, err := file("settings.jsonl")
+	if err != nil {
+		return Settings{}, err
+	}
+	all, err := read[Settings](path)
+	if err != nil || len(all) == 0 {
+		return Settings{}, err
+	}
+	return all[len(all)-1], nil
+}
+
+// SaveSettings replaces the saved settings with s.
+func SaveSettings(s Settings) error {
+	path, err := file("settings.jsonl")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return write(path, []Settings{s})
+}
@@ -0,0 +1,184 @@
+// Package state records what gotobranch needs to remember between runs:
+// when branches were switched to, including switches made with plain git
+// (reported by the post-checkout hook that `gotobranch install` sets up),
+// how frecently each branch was switched to, the journal of changes to
+// branches that `gotobranch undo` reverses, the audit log of every change
+// to branches that `gotobranch history` shows, preferences set in the
+// picker, and metrics when enabled.
+//
+// Events are appended as JSON lines to files in the state directory (see
+// package paths: ~/.local/state/gotobranch on Linux).
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/paths"
+)
+
+// Switch records that Branch was checked out in the repository whose
+// top-level directory is Repo.
+type Switch struct {
+	Repo   string    `json:"repo"`
+	Branch string    `json:"branch"`
+	At     time.Time `json:"at"`
+}
+
+// A switch to the branch last switched to in the same repository less than
+// dedupWindow ago is the same switch reported twice, by gotobranch and by
+// the hook.
+const dedupWindow = 5 * time.Second
+
+// maxSwitches bounds the log; older switches are dropped when it is
+// exceeded by half again.
+const maxSwitches = 5000
+
+// Path returns the file switches are recorded in.
+func Path() (string, error) {
+	return file("switches.jsonl")
+}
+
+// file returns the path of the state file name.
+func file(name string) (string, error) {
+	dir, err := paths.State()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Record appends s to the log and adds it to the branch's frecency (see
+// Frecency).
+func Record(s Switch) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	all, err := read[Switch](path)
+	if err != nil {
+		return err
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Repo != s.Repo {
+			continue
+		}
+		if all[i].Branch == s.Branch && s.At.Sub(all[i].At) < dedupWindow {
+			return nil
+		}
+		break
+	}
+	if err := recordFrecency(s); err != nil {
+		return err
+	}
+	return appendBounded(path, all, s, maxSwitches)
+}
+
+// appendBounded appends v to the log at path, which holds all; once that
+// exceeds limit by half again, the oldest entries are dropped.
+func appendBounded[T any](path string, all []T, v T, limit int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if len(all) >= limit*3/2 {
+		return write(path, append(all[len(all)-limit+1:], v))
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Switches returns the switches recorded for repo, oldest first.
+func Switches(repo string) ([]Switch, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	all, err := read[Switch](path)
+	if err != nil {
+		return nil, err
+	}
+	var res []Switch
+	for _, s := range all {
+		if s.Repo == repo {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+// Repos returns the repositories switches were recorded in, the most
+// recently used first.
+func Repos() ([]string, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	all, err := read[Switch](path)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var res []string
+	for i := len(all) - 1; i >= 0; i-- {
+		if r := all[i].Repo; r != "" && !seen[r] {
+			seen[r] = true
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// read loads the log at path. A missing file is an empty log, and lines
+// that do not parse (e.g. cut short by a crash) are skipped.
+func read[T any](path string) ([]T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var res []T
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var v T
+		if json.Unmarshal(sc.Bytes(), &v) == nil {
+			res = append(res, v)
+		}
+	}
+	return res, sc.Err()
+}
+
+// write replaces the log at path with entries.
+func write[T any](path string, entries []T) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(tmp)
+	for _, v := range entries {
+		if err := enc.Encode(v); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
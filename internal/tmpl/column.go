@@ -0,0 +1,153 @@
+package tmpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// ColumnTimeout bounds the command of a computed column for one branch.
+var ColumnTimeout = 5 * time.Second
+
+// Column is a computed column: a template over Row rendering a value for
+// each branch, e.g. `{{match "[A-Z]+-[0-9]+" .Name}}` for the ticket in
+// its name. A column can also run a program per branch, with arguments
+// that are templates too, and use what it printed as .Output.
+type Column struct {
+	Name    string
+	t       *template.Template
+	program string
+	args    []*template.Template
+}
+
+// ColumnData is what a computed column's template is evaluated against:
+// the branch's Row, and the trimmed output of the column's command.
+type ColumnData struct {
+	Row
+	Output string
+}
+
+// ParseColumn compiles the computed column called name from its template
+// and command, which may be empty. The command's program is taken as is.
+func ParseColumn(name, text string, command []string) (*Column, error) {
+	t, err := Parse(name, text)
+	if err != nil {
+		return nil, err
+	}
+	c := &Column{Name: name, t: t}
+	if len(command) == 0 {
+		return c, nil
+	}
+	c.program = command[0]
+	for i, arg := range command[1:] {
+		at, err := Parse(fmt.Sprintf("%s argument %d", name, i+1), arg)
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, at)
+	}
+	return c, nil
+}
+
+// Value renders the column for r, running its command, if any, in
+// repoPath. A command exiting with an error leaves .Output empty; one that
+// cannot be started or takes longer than ColumnTimeout fails the value.
+func (c *Column) Value(ctx context.Context, repoPath string, r Row) (string, error) {
+	data := ColumnData{Row: r}
+	if c.program != "" {
+		out, err := c.run(ctx, repoPath, r)
+		if err != nil {
+			return "", fmt.Errorf("column %s: %w", c.Name, err)
+		}
+		data.Output = out
+	}
+	var b strings.Builder
+	if err := c.t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.ReplaceAll(b.String(), "\n", " ")), nil
+}
+
+func (c *Column) run(ctx context.Context, repoPath string, r Row) (string, error) {
+	args := make([]string, len(c.args))
+	for i, t := range c.args {
+		var b strings.Builder
+		if err := t.Execute(&b, r); err != nil {
+			return "", err
+		}
+		args[i] = b.String()
+	}
+	bin := c.program
+	if bin == "git" {
+		bin = core.GitBin
+	}
+	ctx, cancel := context.WithTimeout(ctx, ColumnTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir, cmd.Stdout, cmd.WaitDelay = repoPath, &stdout, time.Second
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s took longer than %s", c.program, ColumnTimeout)
+		}
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			// Like grep finding nothing, or a commit without notes.
+			return "", nil
+		}
+		return "", fmt.Errorf("%s: %w", c.program, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// patterns caches the regular expressions of match and replace, which are
+// evaluated for every row.
+var patterns sync.Map
+
+func pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(expr, re)
+	return re, nil
+}
+
+// match returns the first match of expr in s, or of its first group if it
+// has one, or "".
+func match(expr, s string) (string, error) {
+	re, err := pattern(expr)
+	if err != nil {
+		return "", err
+	}
+	m := re.FindStringSubmatch(s)
+	switch {
+	case m == nil:
+		return "", nil
+	case len(m) > 1:
+		return m[1], nil
+	}
+	return m[0], nil
+}
+
+// replace replaces the matches of expr in s with repl, in which $1 stands
+// for the first group.
+func replace(expr, repl, s string) (string, error) {
+	re, err := pattern(expr)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, repl), nil
+}
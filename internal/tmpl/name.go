@@ -0,0 +1,88 @@
+package tmpl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// NameTemplate generates branch names from variables filled in when the
+// branch is created, e.g. "feat/{{ticket}}-{{.summary | slug}}". A bare
+// {{name}} is shorthand for {{.name}}.
+type NameTemplate struct {
+	Name string
+	Vars []string // the variables, in order of first use
+	t    *template.Template
+}
+
+// bareVar matches the {{name}} shorthand.
+var bareVar = regexp.MustCompile(`\{\{-?\s*([A-Za-z_][A-Za-z0-9_]*)\s*-?\}\}`)
+
+// ParseName compiles the branch name template text called name.
+func ParseName(name, text string) (*NameTemplate, error) {
+	text = bareVar.ReplaceAllString(text, "{{.$1}}")
+	t, err := Parse(name, text)
+	if err != nil {
+		return nil, err
+	}
+	nt := &NameTemplate{Name: name, t: t}
+	seen := map[string]bool{}
+	walk(t.Tree.Root, func(v string) {
+		if !seen[v] {
+			seen[v] = true
+			nt.Vars = append(nt.Vars, v)
+		}
+	})
+	return nt, nil
+}
+
+// Render generates the name from vals, which must hold every variable.
+func (n *NameTemplate) Render(vals map[string]string) (string, error) {
+	var b strings.Builder
+	if err := n.t.Execute(&b, vals); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(b.String())
+	if name == "" {
+		return "", fmt.Errorf("template %s rendered an empty name", n.Name)
+	}
+	return name, nil
+}
+
+// walk calls fn with the variable of every {{.var}} field under node.
+func walk(node parse.Node, fn func(string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walk(c, fn)
+		}
+	case *parse.ActionNode:
+		walk(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walk(c, fn)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			walk(a, fn)
+		}
+	case *parse.FieldNode:
+		fn(n.Ident[0])
+	case *parse.IfNode:
+		walk(n.Pipe, fn)
+		walk(n.List, fn)
+		walk(n.ElseList, fn)
+	case *parse.WithNode:
+		walk(n.Pipe, fn)
+		walk(n.List, fn)
+		walk(n.ElseList, fn)
+	}
+}
@@ -0,0 +1,251 @@
+// Package tmpl renders branches through user-supplied text/templates.
+//
+// Templates are evaluated against Row, a flattened view of core.Branch in
+// which optional fields are plain values (empty when unknown), so formats
+// like `{{.Name}} {{.HeadCommitSHA | short}}` work without nil checks.
+package tmpl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kvnloughead/gotobranch/internal/ci"
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Row is the data passed to a branch template.
+type Row struct {
+	Index         int // 1-based position in the full (unpaginated) list
+	Name          string
+	FullRef       string
+	IsCurrent     bool
+	IsRemote      bool
+	Upstream      string
+	HeadCommitSHA string
+	HeadCommitAt  time.Time
+	Subject       string
+	Age           string // e.g. "3d", empty if the commit date is unknown
+	Shallow       bool   // history cut off by a shallow clone
+	OffPolicy     bool   // the name does not match the configured naming policy
+
+	// Pull request fields are zero unless pull requests were looked up and
+	// the branch has one.
+	PR       int // pull request number
+	PRTitle  string
+	PRState  string // open, closed or merged
+	PRReview string // approved, changes_requested, review_required or empty
+	PRURL    string
+
+	// CI status of the head commit, when looked up: success, failure or
+	// pending, and the same as a glyph (✓, ✗, ●). Empty without CI.
+	CIStatus string
+	CI       string
+
+	// Signature of the head commit, when looked up: whether it is signed,
+	// the status (good, untrusted, expired, revoked, bad, unverified or
+	// unsigned), the signer, and a glyph (🔏 good, ⚠ a problem, ? not
+	// verifiable, nothing unsigned).
+	Signed    bool
+	SigStatus string
+	Signer    string
+	Sig       string
+
+	// Divergence from HEAD, when looked up: commits only on the branch
+	// (Ahead) and only on HEAD (Behind), and both as "3↕7". Divergence is
+	// empty for branches at HEAD.
+	Ahead      int
+	Behind     int
+	Divergence string
+
+	// Commits only on the branch and not on the default branch, when
+	// counted, and the same as text: "12", or "1000+" when the count hit
+	// core.MaxCommitCount. CommitCount is empty when not counted.
+	Commits     int
+	CommitCount string
+
+	// Columns are the values of extra columns, such as those of plugins,
+	// by column id; see Column.
+	Columns map[string]string
+}
+
+// Column returns the value of the extra column id, e.g.
+// {{.Column "jira.ticket"}}, or "" when the branch has none.
+func (r Row) Column(id string) string {
+	return r.Columns[id]
+}
+
+// NewRow flattens b for template evaluation.
+func NewRow(b core.Branch, index int) Row {
+	r := Row{
+		Index:     index,
+		Name:      b.Name,
+		FullRef:   b.FullRef,
+		IsCurrent: b.IsCurrent,
+		IsRemote:  b.IsRemote,
+		Shallow:   b.Shallow,
+		OffPolicy: !core.FollowsPolicy(b),
+		Columns:   b.Columns,
+	}
+	if b.Upstream != nil {
+		r.Upstream = *b.Upstream
+	}
+	if b.HeadCommitSHA != nil {
+		r.HeadCommitSHA = *b.HeadCommitSHA
+	}
+	if b.HeadCommitAt != nil {
+		r.HeadCommitAt = *b.HeadCommitAt
+		r.Age = Age(*b.HeadCommitAt, time.Now())
+	}
+	if b.LastCommitMessage != nil {
+		r.Subject = *b.LastCommitMessage
+	}
+	r.CIStatus, r.CI = b.CIStatus, ci.Glyph(b.CIStatus)
+	if s := b.Signature; s != nil {
+		r.Signed, r.SigStatus, r.Signer, r.Sig = s.Signed(), s.Status, s.Signer, sigGlyph(s.Status)
+	}
+	if d := b.Divergence; d != nil {
+		r.Ahead, r.Behind = d.Ahead, d.Behind
+		if d.Ahead > 0 || d.Behind > 0 {
+			r.Divergence = fmt.Sprintf("%d↕%d", d.Ahead, d.Behind)
+		}
+	}
+	if n := b.Commits; n != nil {
+		r.Commits, r.CommitCount = *n, strconv.Itoa(*n)
+		if *n >= core.MaxCommitCount {
+			r.CommitCount += "+"
+		}
+	}
+	if pr := b.PullRequest; pr != nil {
+		r.PR, r.PRTitle, r.PRState, r.PRReview, r.PRURL = pr.Number, pr.Title, pr.State, pr.Review, pr.URL
+	}
+	return r
+}
+
+// sigGlyph renders a signature status for a narrow column.
+func sigGlyph(status string) string {
+	switch status {
+	case core.SigGood, core.SigUntrusted:
+		return "🔏"
+	case core.SigExpired, core.SigRevoked, core.SigBad:
+		return "⚠"
+	case core.SigUnverified:
+		return "?"
+	}
+	return ""
+}
+
+// Funcs are the helper functions available to every branch template.
+var Funcs = template.FuncMap{
+	// trunc shortens s to n terminal columns, marking the cut with an
+	// ellipsis.
+	"trunc": func(n int, s string) string {
+		if n <= 0 {
+			return s
+		}
+		return runewidth.Truncate(s, n, "…")
+	},
+	// pad right-pads s with spaces to n terminal columns.
+	"pad": func(n int, s string) string {
+		return runewidth.FillRight(s, n)
+	},
+	// short abbreviates a commit SHA.
+	"short": func(sha string) string {
+		if len(sha) > 7 {
+			return sha[:7]
+		}
+		return sha
+	},
+	// ago renders a time relative to now, e.g. "5h".
+	"ago": func(t time.Time) string { return Age(t, time.Now()) },
+	// slug turns free text into a branch name component: lower case words
+	// joined by dashes, at most 40 runes, cut at a word boundary.
+	"slug": Slug,
+	// date renders t as YYYY-MM-DD, or "" when unknown.
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.DateOnly)
+	},
+	// match returns the first match of a regular expression in s, or of
+	// its first group, e.g. {{match "^[a-z]+/([A-Z]+-[0-9]+)" .Name}}.
+	"match": match,
+	// replace replaces the matches of a regular expression, e.g.
+	// {{replace "^feat/" "" .Name}}.
+	"replace": replace,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+}
+
+// Slug turns s into lower case ASCII letters and digits separated by single
+// dashes, e.g. "Fix: crash on start!" becomes "fix-crash-on-start". The
+// result is cut to 40 runes at a dash.
+func Slug(s string) string {
+	const maxLen = 40
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxLen {
+		slug = slug[:maxLen]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// Parse compiles a branch template.
+func Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs).Option("missingkey=error").Parse(text)
+}
+
+// Execute renders t for a single row.
+func Execute(t *template.Template, r Row) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Age renders the coarse duration between t and now: "now", "5m", "3h",
+// "2d", "6w", "4mo" or "2y". A zero t renders as "".
+func Age(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d/(7*24*time.Hour)))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo", int(d/(30*24*time.Hour)))
+	default:
+		return fmt.Sprintf("%dy", int(d/(365*24*time.Hour)))
+	}
+}
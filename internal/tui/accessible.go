@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// ErrCancelled is returned when the user leaves without switching.
+var ErrCancelled = errors.New("cancelled")
+
+// RunAccessible is a screen-reader friendly alternative to the Bubble Tea
+// program. It never repaints: each page is printed once as a numbered list
+// followed by a prompt, and every answer is read as a whole line. It returns
+// ErrCancelled if the user quits without switching.
+func RunAccessible(opts Options, in io.Reader, out io.Writer) error {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 50
+	}
+	if opts.RowFormat == "" {
+		opts.RowFormat = DefaultRowFormat
+	}
+	rowTmpl, err := tmpl.Parse("row", opts.RowFormat)
+	if err != nil {
+		return err
+	}
+
+	pattern := opts.Pattern
+	page := 1
+	sc := bufio.NewScanner(in)
+	for {
+		resp, err := core.ListBranches(core.ListBranchesRequest{
+			RepoPath: opts.RepoPath,
+			Query:    pattern,
+			Match:    opts.Match,
+			Exclude:  opts.Exclude,
+			Scope:    opts.Scope,
+			SortBy:   opts.SortBy,
+			SortDir:  opts.SortDir,
+			Page:     page,
+			PageSize: opts.PageSize,
+
+			Signatures: opts.Signatures,
+			Commits:    opts.Commits,
+		})
+		if err != nil && pattern != "" {
+			fmt.Fprintln(out, i18n.Sprintf("Invalid filter: %v", err))
+			pattern, page = "", 1
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		pages := max((resp.Total+opts.PageSize-1)/opts.PageSize, 1)
+		if pattern != "" {
+			fmt.Fprintln(out, i18n.Sprintf("%d branches match %s %q. Page %d of %d.", resp.Total, opts.Match, pattern, page, pages))
+		} else {
+			fmt.Fprintln(out, i18n.Sprintf("%d branches. Page %d of %d.", resp.Total, page, pages))
+		}
+		start := (page - 1) * opts.PageSize
+		for i, b := range resp.Items {
+			line, err := tmpl.Execute(rowTmpl, tmpl.NewRow(b, start+i+1))
+			if err != nil {
+				return err
+			}
+			if b.IsCurrent {
+				line += " " + i18n.T("(current)")
+			}
+			fmt.Fprintln(out, line)
+		}
+		fmt.Fprint(out, i18n.T("Type a number to switch, text to filter, n or p to change page, c to clear the filter, q to quit: "))
+
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			if err := sc.Err(); err != nil {
+				return err
+			}
+			return ErrCancelled
+		}
+		answer := strings.TrimSpace(sc.Text())
+		switch answer {
+		case "":
+			continue
+		case "q":
+			return ErrCancelled
+		case "n":
+			if resp.HasNext {
+				page++
+			} else {
+				fmt.Fprintln(out, i18n.T("Already on the last page."))
+			}
+			continue
+		case "p":
+			if resp.HasPrev {
+				page--
+			} else {
+				fmt.Fprintln(out, i18n.T("Already on the first page."))
+			}
+			continue
+		case "c":
+			pattern, page = "", 1
+			continue
+		}
+		if n, err := strconv.Atoi(answer); err == nil {
+			idx := n - 1 - start
+			if idx < 0 || idx >= len(resp.Items) {
+				fmt.Fprintln(out, i18n.Sprintf("No branch numbered %d on this page.", n))
+				continue
+			}
+			name := resp.Items[idx].Name
+			_, err := core.Checkout(opts.RepoPath, name, false)
+			if err != nil && !core.PostHookFailed(err) {
+				fmt.Fprintln(out, i18n.Sprintf("Could not switch to %s: %v", name, err))
+				continue
+			}
+			fmt.Fprintln(out, i18n.Sprintf("Switched to %s.", name))
+			return err
+		}
+		pattern, page = answer, 1
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// cherryPickMsg reports a cherry-pick from the branch from, or its abort.
+type cherryPickMsg struct {
+	from    string
+	n       int
+	aborted bool
+	err     error
+}
+
+// askCherryPick asks how many of the head commits of b to cherry-pick.
+func (m Model) askCherryPick(b core.Branch) (Model, tea.Cmd) {
+	m.mode = modeCherryPick
+	m.pickFrom = b.Name
+	m.error, m.notice = nil, ""
+	m.branchInput = textinput.New()
+	m.branchInput.Placeholder = "1"
+	m.branchInput.CharLimit = 4
+	return m, m.branchInput.Focus()
+}
+
+// updateCherryPick handles keys while typing the number of commits to
+// cherry-pick.
+func (m Model) updateCherryPick(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.error = nil
+		m.branchInput.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Apply):
+		n := 1
+		if v := strings.TrimSpace(m.branchInput.Value()); v != "" {
+			var err error
+			if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+				m.error = i18n.Errorf("%q is not a number of commits", v)
+				return m, nil
+			}
+		}
+		m.mode = modeSelect
+		m.error = nil
+		m.branchInput.Blur()
+		repo, from := m.RepoPath, m.pickFrom
+		m.notice = i18n.Sprintf("cherry-picking from %s…", from)
+		return m, func() tea.Msg {
+			commits, err := core.TipCommits(repo, from, n)
+			if err == nil {
+				err = core.CherryPick(repo, commits...)
+			}
+			return cherryPickMsg{from: from, n: len(commits), err: err}
+		}
+	}
+	var cmd tea.Cmd
+	m.branchInput, cmd = m.branchInput.Update(msg)
+	return m, cmd
+}
+
+// updateConflict handles the answer to whether to abort a cherry-pick that
+// stopped on conflicts. Keeping it leaves the conflicts to resolve outside
+// the picker.
+func (m Model) updateConflict(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.No):
+		m.mode = modeSelect
+		m.error = nil
+		m.notice = i18n.T("resolve the conflicts, then run git cherry-pick --continue")
+		return m, nil
+	case key.Matches(msg, m.keys.Yes):
+		m.mode = modeSelect
+		m.error = nil
+		repo, from := m.RepoPath, m.pickFrom
+		return m, func() tea.Msg {
+			return cherryPickMsg{from: from, aborted: true, err: core.AbortCherryPick(repo)}
+		}
+	}
+	return m, nil
+}
+
+// cherryPicked handles the outcome of a cherry-pick or its abort.
+func (m Model) cherryPicked(msg cherryPickMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.aborted && msg.err == nil:
+		m.notice = i18n.T("cherry-pick aborted")
+	case core.ErrorKind(msg.err) == core.KindConflict:
+		m.notice = ""
+		m.error = msg.err
+		m.mode = modeConflict
+	case msg.err != nil:
+		m.notice = ""
+		m.error = msg.err
+	default:
+		m.notice = i18n.Sprintf("cherry-picked %d commit(s) from %s", msg.n, msg.from)
+	}
+	return m, m.refreshList()
+}
+
+// cherryPickPrompt is the header while asking how many commits to pick.
+func (m Model) cherryPickPrompt() string {
+	return i18n.Sprintf("Cherry-pick the last commits of %s, how many? ", m.pickFrom)
+}
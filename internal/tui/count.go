@@ -0,0 +1,34 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// maxCount bounds the count typed before a motion, which is far more rows
+// than any page holds anyway.
+const maxCount = 99999
+
+// countDigit reports the digit msg types, if it is one.
+func countDigit(msg tea.KeyMsg) (int, bool) {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || msg.Runes[0] < '0' || msg.Runes[0] > '9' {
+		return 0, false
+	}
+	return int(msg.Runes[0] - '0'), true
+}
+
+// counted returns the row the count typed before Enter numbers, as an index
+// into the page's items: rows are numbered across pages, as shown.
+func (m Model) counted(n int) (int, error) {
+	idx := n - 1 - m.paginator.Page*m.paginator.PerPage
+	if idx < 0 || idx >= len(m.items) {
+		return 0, i18n.Errorf("no branch numbered %d on this page", n)
+	}
+	return idx, nil
+}
+
+// countHint describes the count being typed, for the footer.
+func (m Model) countHint() string {
+	return i18n.Sprintf("%d: j/k move %d rows, h/l %d pages; enter takes row %d; esc cancels", m.count, m.count, m.count, m.count)
+}
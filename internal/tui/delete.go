@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// deletePlanMsg carries the branches to delete, with those HEAD does not
+// contain, to ask before deleting them.
+type deletePlanMsg struct {
+	names    []string
+	unmerged int
+	err      error
+}
+
+// deleteMsg reports a batch delete.
+type deleteMsg []PruneResult
+
+// toggleMark marks the highlighted local branch for deletion, or unmarks it.
+func (m Model) toggleMark() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	switch {
+	case b.IsRemote:
+		m.error = i18n.Errorf("%s is a remote branch", b.Name)
+		return m, nil
+	case m.marked[b.Name]:
+		delete(m.marked, b.Name)
+	default:
+		m.marked[b.Name] = true
+	}
+	m.error = nil
+	if m.cursor < len(m.items)-1 {
+		m.cursor++
+		return m, m.lookupHighlighted()
+	}
+	return m, nil
+}
+
+// planDelete looks up which of the marked branches, or the highlighted one
+// if none is marked, are not merged into HEAD, to ask before deleting them.
+func (m Model) planDelete() (tea.Model, tea.Cmd) {
+	// Marks on other pages, or hidden by the filter, count too.
+	names := slices.Sorted(maps.Keys(m.marked))
+	if len(names) == 0 && len(m.items) > 0 {
+		b := m.items[m.cursor]
+		if b.IsRemote {
+			m.error = i18n.Errorf("%s is a remote branch", b.Name)
+			return m, nil
+		}
+		names = []string{b.Name}
+	}
+	if len(names) == 0 {
+		return m, nil
+	}
+	if cur := m.current(); slices.Contains(names, cur) {
+		m.error = i18n.Errorf("%s is checked out; switch away before deleting it", cur)
+		return m, nil
+	}
+	m.error, m.notice = nil, ""
+	repo := m.RepoPath
+	return m, func() tea.Msg {
+		merged, err := core.MergedInto(repo, "HEAD", "refs/heads/")
+		if err != nil {
+			return deletePlanMsg{err: err}
+		}
+		unmerged := 0
+		for _, name := range names {
+			if !merged["refs/heads/"+name] {
+				unmerged++
+			}
+		}
+		return deletePlanMsg{names: names, unmerged: unmerged}
+	}
+}
+
+// confirmDelete asks whether to delete the planned branches.
+func (m Model) confirmDelete(msg deletePlanMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.error = msg.err
+		return m, nil
+	}
+	what := msg.names[0]
+	if len(msg.names) > 1 {
+		what = i18n.Sprintf("%d branches (%s)", len(msg.names), strings.Join(msg.names, ", "))
+	}
+	q := i18n.Sprintf("Delete %s?", what)
+	if msg.unmerged > 0 {
+		q = i18n.Sprintf("Delete %s? %d not merged into HEAD; restore commands are printed on exit.", what, msg.unmerged)
+	}
+	names, repo := msg.names, m.RepoPath
+	m.ask(q, func(m *Model) tea.Cmd {
+		return func() tea.Msg {
+			res := make([]PruneResult, 0, len(names))
+			for _, name := range names {
+				// Confirmed, so unmerged branches are forced; the SHAs
+				// allow undoing it.
+				sha, err := core.DeleteBranch(repo, name, true)
+				res = append(res, PruneResult{Name: name, SHA: sha, Err: err})
+			}
+			return deleteMsg(res)
+		}
+	})
+	return m, nil
+}
+
+// deleted handles the outcome of a batch delete. The picker keeps the
+// results so the caller can print how to restore the branches.
+func (m Model) deleted(msg deleteMsg) (tea.Model, tea.Cmd) {
+	n := 0
+	var failed []string
+	for _, r := range msg {
+		if r.Deleted() {
+			n++
+			delete(m.marked, r.Name)
+			m.deletions = append(m.deletions, r)
+		}
+		if r.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.Name, r.Err))
+		}
+	}
+	m.error = nil
+	if len(failed) > 0 {
+		m.error = fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	switch {
+	case n == 1 && len(msg) == 1:
+		r := msg[0]
+		m.notice = i18n.Sprintf("deleted %s; restore it with git branch %s %s", r.Name, r.Name, shortSHA(r.SHA))
+	case n > 0:
+		m.notice = i18n.Sprintf("deleted %d of %d branches; restore commands are printed on exit", n, len(msg))
+	}
+	return m, m.refreshList()
+}
+
+// Deleted returns the branches deleted in the picker, with their SHAs.
+func (m Model) Deleted() []PruneResult {
+	return m.deletions
+}
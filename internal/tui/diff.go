@@ -0,0 +1,41 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// diffMsg reports the end of viewing a branch's diff.
+type diffMsg struct {
+	ref string
+	err error
+}
+
+// openDiff hands the terminal to git to show the full diff of the
+// highlighted branch against HEAD in the pager or diff tool, coming back
+// to the picker when it exits.
+func (m Model) openDiff() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	if b.IsCurrent {
+		m.error = i18n.Errorf("%s is checked out; highlight the branch to compare HEAD with", b.Name)
+		return m, nil
+	}
+	m.error, m.notice = nil, ""
+	cmd := core.DiffCommand(m.RepoPath, b.Name, m.diffPager, m.diffTool)
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return diffMsg{ref: b.Name, err: err}
+	})
+}
+
+// diffDone reports a diff that could not be shown.
+func (m Model) diffDone(msg diffMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.error = i18n.Errorf("diff of %s: %w", msg.ref, msg.err)
+	}
+	return m, nil
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// infoWidth is the widest the branch detail dialog gets.
+const infoWidth = 96
+
+// infoMsg carries what was looked up about the branch ref for the detail
+// dialog.
+type infoMsg struct {
+	ref  string
+	info core.BranchInfo
+	err  error
+}
+
+// startInfo opens the detail dialog for the highlighted branch; the facts
+// its row does not carry are looked up meanwhile.
+func (m Model) startInfo() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	m.mode = modeInfo
+	m.error, m.notice = nil, ""
+	m.infoFor, m.info, m.infoLoaded = b, core.BranchInfo{}, false
+	repo := m.RepoPath
+	return m, func() tea.Msg {
+		info, err := core.Info(repo, b)
+		return infoMsg{ref: b.FullRef, info: info, err: err}
+	}
+}
+
+// infoLookedUp shows what was looked up, unless the dialog was closed or
+// opened for another branch since.
+func (m Model) infoLookedUp(msg infoMsg) (tea.Model, tea.Cmd) {
+	if m.mode != modeInfo || msg.ref != m.infoFor.FullRef {
+		return m, nil
+	}
+	m.info, m.infoLoaded, m.error = msg.info, true, msg.err
+	return m, nil
+}
+
+// updateInfo handles keys in the branch detail dialog.
+func (m Model) updateInfo(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Info), key.Matches(msg, m.keys.Quit):
+		m.mode = modeSelect
+		m.error = nil
+		return m, nil
+	}
+	return m, nil
+}
+
+// infoRows renders the branch detail dialog in place of the list: a line
+// per field, then the latest reflog entries.
+func (m Model) infoRows(limit int) []string {
+	width := min(m.width, infoWidth) - 4 // border and padding
+	if m.width == 0 {
+		width = infoWidth - 4
+	}
+	var lines []string
+	add := func(s string) { lines = append(lines, truncate(s, width)) }
+	field := func(label, value string) {
+		if value != "" {
+			add(fmt.Sprintf("%-12s %s", i18n.T(label), value))
+		}
+	}
+	b, info := m.infoFor, m.info
+	field("ref", b.FullRef)
+	if b.HeadCommitSHA != nil {
+		field("commit", *b.HeadCommitSHA)
+	}
+	if b.LastCommitMessage != nil {
+		field("subject", *b.LastCommitMessage)
+	}
+	field("author", info.Author)
+	field("authored", infoDate(info.AuthoredAt))
+	if info.Committer != info.Author {
+		field("committer", info.Committer)
+	}
+	field("committed", infoDate(info.CommittedAt))
+	switch {
+	case b.IsRemote:
+	case b.Upstream == nil:
+		field("upstream", i18n.T("none"))
+	case b.Tracking != nil && b.Tracking.Gone:
+		field("upstream", i18n.Sprintf("%s (gone)", *b.Upstream))
+	case b.Tracking != nil:
+		field("upstream", i18n.Sprintf("%s (%d ahead, %d behind)", *b.Upstream, b.Tracking.Ahead, b.Tracking.Behind))
+	default:
+		field("upstream", *b.Upstream)
+	}
+	if d := b.Divergence; d != nil && !b.IsCurrent {
+		field("vs HEAD", i18n.Sprintf("%d ahead, %d behind", d.Ahead, d.Behind))
+	}
+	if info.Base != "" {
+		merged := i18n.Sprintf("not merged into %s", info.Base)
+		if info.Merged {
+			merged = i18n.Sprintf("merged into %s", info.Base)
+		}
+		field("merge", merged)
+	}
+	if pr := b.PullRequest; pr != nil {
+		field("pull request", fmt.Sprintf("#%d %s (%s) %s", pr.Number, pr.Title, pr.State, pr.URL))
+	}
+	field("worktree", info.Worktree)
+	if info.Description != "" {
+		for i, line := range strings.Split(info.Description, "\n") {
+			label := ""
+			if i == 0 {
+				label = i18n.T("description")
+			}
+			add(fmt.Sprintf("%-12s %s", label, line))
+		}
+	}
+	switch {
+	case !m.infoLoaded:
+		add("")
+		add(i18n.T("looking up…"))
+	case len(info.Reflog) > 0:
+		add("")
+		add(i18n.T("reflog"))
+		for _, e := range info.Reflog {
+			add(fmt.Sprintf("  %s %s %s", e.SHA, infoDate(e.At), e.Subject))
+		}
+	}
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Render(strings.Join(lines, "\n"))
+	out := strings.Split(box, "\n")
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// infoDate renders t as a date and time, or "" for the zero time.
+func infoDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// startJump asks for the start of a branch name to move the cursor to,
+// leaving the filter alone.
+func (m Model) startJump() (tea.Model, tea.Cmd) {
+	m.mode = modeJump
+	m.error, m.notice = nil, ""
+	m.branchInput = textinput.New()
+	m.branchInput.Placeholder = i18n.T("start of a name")
+	return m, m.branchInput.Focus()
+}
+
+// updateJump handles keys while typing the start of a name. Each edit moves
+// the cursor to the first branch on the page, from the highlighted one on,
+// starting with what has been typed; the jump key moves on to the next one.
+func (m Model) updateJump(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Apply), key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.error = nil
+		m.branchInput.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Next):
+		return m.jump(1)
+	}
+	prev := m.branchInput.Value()
+	var cmd tea.Cmd
+	m.branchInput, cmd = m.branchInput.Update(msg)
+	if m.branchInput.Value() == prev {
+		return m, cmd
+	}
+	next, jumpCmd := m.jump(0)
+	return next, tea.Batch(cmd, jumpCmd)
+}
+
+// jump moves the cursor to the first branch at least from rows below it,
+// wrapping around the page, whose name or last path segment starts with the
+// typed prefix, ignoring case.
+func (m Model) jump(from int) (tea.Model, tea.Cmd) {
+	prefix := strings.ToLower(m.branchInput.Value())
+	if prefix == "" || len(m.items) == 0 {
+		m.error = nil
+		return m, nil
+	}
+	for i := range len(m.items) {
+		idx := (m.cursor + from + i) % len(m.items)
+		name := strings.ToLower(m.items[idx].Name)
+		if strings.HasPrefix(name, prefix) || strings.HasPrefix(name[strings.LastIndex(name, "/")+1:], prefix) {
+			m.error = nil
+			if idx == m.cursor {
+				return m, nil
+			}
+			m.cursor = idx
+			return m, m.lookupHighlighted()
+		}
+	}
+	m.error = i18n.Errorf("no branch on this page starts with %q", m.branchInput.Value())
+	return m, nil
+}
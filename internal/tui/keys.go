@@ -0,0 +1,270 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// mode is the input mode the model is currently in. Keys are interpreted
+// differently per mode, and the footer only advertises bindings that apply.
+type mode int
+
+const (
+	modeSelect      mode = iota // moving the cursor and acting on branches
+	modeFilter                  // typing into the filter input
+	modeMultiSelect             // marking several branches for a batch action
+	modeConfirm                 // answering a yes/no question
+	modeIssue                   // typing the number of an issue to branch from
+	modeNewBranch               // typing the name of a branch to create at a detached HEAD
+	modeTemplate                // filling in a branch template's variables
+	modeRemedy                  // choosing a way out of a failed switch
+	modeRetry                   // asking whether to retry a timed out git command
+	modeStash                   // browsing the stashes
+	modeApplyStash              // asking whether to restore the stash left on the branch switched to
+	modeCherryPick              // typing the number of commits to cherry-pick
+	modeConflict                // asking whether to abort a cherry-pick stopped on conflicts
+	modeForcePush               // asking whether to force a rejected push
+	modeJump                    // typing the start of a branch name to move to
+	modeRepo                    // choosing a repository to switch the picker to
+	modeInfo                    // reading everything known about a branch
+	modeMenu                    // choosing an action on a branch from a menu
+)
+
+type keyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PrevPage key.Binding
+	NextPage key.Binding
+	Switch   key.Binding
+	Detach   key.Binding
+	Mark     key.Binding
+	Menu     key.Binding
+	Delete   key.Binding
+	Undo     key.Binding
+	Pick     key.Binding
+	Filter   key.Binding
+	Jump     key.Binding
+	Sort     key.Binding
+	Preview  key.Binding
+	Split    key.Binding
+	Diff     key.Binding
+	Clear    key.Binding
+	Profile  key.Binding
+	Issue    key.Binding
+	Worktree key.Binding
+	Here     key.Binding
+	Template key.Binding
+	History  key.Binding
+	Stashes  key.Binding
+	Repos    key.Binding
+	Info     key.Binding
+	Rebase   key.Binding
+	Pluck    key.Binding
+	Push     key.Binding
+	Track    key.Binding
+	Untrack  key.Binding
+	Reset    key.Binding
+	Help     key.Binding
+	Suspend  key.Binding
+	Abort    key.Binding
+	PrevTab  key.Binding
+	NextTab  key.Binding
+	Quit     key.Binding
+
+	// Filter mode
+	Apply  key.Binding
+	Cancel key.Binding
+
+	// Jump mode
+	Next key.Binding
+
+	// Multi-select mode
+	Toggle    key.Binding
+	ToggleAll key.Binding
+	Submit    key.Binding
+
+	// Confirm mode
+	Yes key.Binding
+	No  key.Binding
+
+	// Issue mode
+	Create key.Binding
+	Back   key.Binding
+
+	// Remedy mode
+	Stash   key.Binding
+	Merge   key.Binding
+	Discard key.Binding
+	Refetch key.Binding
+
+	// Retry mode
+	Retry key.Binding
+
+	// Stash mode
+	StashApply key.Binding
+	StashPop   key.Binding
+	StashDrop  key.Binding
+
+	// Repository mode
+	Open key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		PrevPage: key.NewBinding(key.WithKeys("pgup", "left", "h"), key.WithHelp("h/pgup", "prev page")),
+		NextPage: key.NewBinding(key.WithKeys("pgdown", "right", "l"), key.WithHelp("l/pgdn", "next page")),
+		Switch:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "switch")),
+		Detach:   key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "detach at"), key.WithDisabled()),
+		Pick:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select"), key.WithDisabled()),
+		Mark:     key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "mark"), key.WithDisabled()),
+		Menu:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "actions")),
+		Delete:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete (marked)"), key.WithDisabled()),
+		Undo:     key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo"), key.WithDisabled()),
+		Filter:   key.NewBinding(key.WithKeys("f", "/"), key.WithHelp("f", "filter")),
+		Jump:     key.NewBinding(key.WithKeys("'"), key.WithHelp("'", "jump to name")),
+		Sort:     key.NewBinding(key.WithKeys("f1", "f2", "f3", "f4"), key.WithHelp("f1-f4", "sort by column"), key.WithDisabled()),
+		Preview:  key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "preview"), key.WithDisabled()),
+		Split:    key.NewBinding(key.WithKeys("<", ">"), key.WithHelp("</>", "resize preview"), key.WithDisabled()),
+		Diff:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open diff"), key.WithDisabled()),
+		Clear:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "clear filter")),
+		Profile:  key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "next profile"), key.WithDisabled()),
+		Issue:    key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "branch from issue"), key.WithDisabled()),
+		Worktree: key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "open in worktree"), key.WithDisabled()),
+		Here:     key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "create branch here"), key.WithDisabled()),
+		Template: key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new branch from template"), key.WithDisabled()),
+		Stashes:  key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "stashes"), key.WithDisabled()),
+		Repos:    key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "other repository"), key.WithDisabled()),
+		Info:     key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "branch details"), key.WithDisabled()),
+		Rebase:   key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "rebase onto"), key.WithDisabled()),
+		Pluck:    key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "cherry-pick"), key.WithDisabled()),
+		Push:     key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "push"), key.WithDisabled()),
+		Track:    key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "track remote"), key.WithDisabled()),
+		Untrack:  key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "untrack"), key.WithDisabled()),
+		Reset:    key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "reset to upstream"), key.WithDisabled()),
+		History:  key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "fetch full history"), key.WithDisabled()),
+		Help:     key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "more keys")),
+		Suspend:  key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "suspend")),
+		Abort:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel git"), key.WithDisabled()),
+		PrevTab:  key.NewBinding(key.WithKeys("["), key.WithHelp("[", "previous repo"), key.WithDisabled()),
+		NextTab:  key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next repo"), key.WithDisabled()),
+		Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+
+		Apply:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "done")),
+		Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear & back")),
+
+		Next: key.NewBinding(key.WithKeys("'"), key.WithHelp("'", "next match")),
+
+		Toggle:    key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space", "toggle")),
+		ToggleAll: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "toggle all")),
+		Submit:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "delete marked")),
+
+		Yes: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "yes")),
+		No:  key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "no")),
+
+		Create: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "create & switch")),
+		Back:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+
+		Stash:   key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "stash changes & switch")),
+		Merge:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "switch, merging changes in")),
+		Discard: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "discard changes & switch")),
+		Refetch: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "fetch & retry")),
+
+		Retry: key.NewBinding(key.WithKeys("r", "y", "enter"), key.WithHelp("r", "retry")),
+
+		StashApply: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "apply")),
+		StashPop:   key.NewBinding(key.WithKeys("p", "enter"), key.WithHelp("p", "pop")),
+		StashDrop:  key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "drop")),
+
+		Open: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+	}
+}
+
+// modeKeys adapts keyMap to help.KeyMap for a single mode so the footer can
+// render the bindings relevant to what the user is doing right now.
+type modeKeys struct {
+	keys keyMap
+	mode mode
+}
+
+func (k modeKeys) ShortHelp() []key.Binding {
+	switch k.mode {
+	case modeFilter:
+		return []key.Binding{k.keys.Apply, k.keys.Cancel, k.keys.Clear}
+	case modeMultiSelect:
+		return []key.Binding{k.keys.Up, k.keys.Down, k.keys.Toggle, k.keys.ToggleAll, k.keys.Submit, k.keys.Quit}
+	case modeConfirm:
+		return []key.Binding{k.keys.Yes, k.keys.No}
+	case modeIssue, modeNewBranch:
+		return []key.Binding{k.keys.Create, k.keys.Back}
+	case modeTemplate:
+		return []key.Binding{k.keys.Apply, k.keys.Back}
+	case modeRemedy:
+		return []key.Binding{k.keys.Stash, k.keys.Merge, k.keys.Discard, k.keys.Refetch, k.keys.Back}
+	case modeRetry:
+		return []key.Binding{k.keys.Retry, k.keys.Back}
+	case modeStash:
+		return []key.Binding{k.keys.Up, k.keys.Down, k.keys.StashApply, k.keys.StashPop, k.keys.StashDrop, k.keys.Back}
+	case modeApplyStash:
+		return []key.Binding{k.keys.Yes, k.keys.No}
+	case modeCherryPick:
+		return []key.Binding{k.keys.Apply, k.keys.Back}
+	case modeConflict, modeForcePush:
+		return []key.Binding{k.keys.Yes, k.keys.No}
+	case modeJump:
+		return []key.Binding{k.keys.Next, k.keys.Apply, k.keys.Back}
+	case modeRepo:
+		return []key.Binding{k.keys.Up, k.keys.Down, k.keys.Open, k.keys.Back}
+	case modeInfo:
+		return []key.Binding{k.keys.Back}
+	case modeMenu:
+		return []key.Binding{k.keys.Up, k.keys.Down, k.keys.Apply, k.keys.Back}
+	default:
+		return []key.Binding{k.keys.Up, k.keys.Down, k.keys.Pick, k.keys.Switch, k.keys.Menu, k.keys.Here, k.keys.Filter, k.keys.Help, k.keys.Quit}
+	}
+}
+
+func (k modeKeys) FullHelp() [][]key.Binding {
+	switch k.mode {
+	case modeFilter, modeMultiSelect, modeConfirm, modeIssue, modeNewBranch, modeTemplate, modeRemedy, modeRetry, modeStash, modeApplyStash, modeCherryPick, modeConflict, modeForcePush, modeJump, modeRepo, modeInfo, modeMenu:
+		return [][]key.Binding{k.ShortHelp()}
+	default:
+		return [][]key.Binding{
+			{k.keys.Up, k.keys.Down, k.keys.PrevPage, k.keys.NextPage, k.keys.PrevTab, k.keys.NextTab},
+			{k.keys.Pick, k.keys.Switch, k.keys.Menu, k.keys.Detach, k.keys.Here, k.keys.Template, k.keys.Worktree, k.keys.Filter, k.keys.Jump, k.keys.Sort, k.keys.Preview, k.keys.Split, k.keys.Diff, k.keys.Info, k.keys.Clear, k.keys.Profile, k.keys.Issue},
+			{k.keys.Mark, k.keys.Delete, k.keys.Undo, k.keys.Push, k.keys.Track, k.keys.Untrack, k.keys.Reset, k.keys.Rebase, k.keys.Pluck, k.keys.Stashes, k.keys.Repos, k.keys.History, k.keys.Help, k.keys.Suspend, k.keys.Abort, k.keys.Quit},
+		}
+	}
+}
+
+// localizedKeys is modeKeys with the descriptions of the bindings in the
+// current locale, for showing. The keymap itself stays in English, as
+// Options.Used reports it.
+type localizedKeys struct {
+	modeKeys
+}
+
+func (k localizedKeys) ShortHelp() []key.Binding {
+	return localize(k.modeKeys.ShortHelp())
+}
+
+func (k localizedKeys) FullHelp() [][]key.Binding {
+	groups := k.modeKeys.FullHelp()
+	res := make([][]key.Binding, len(groups))
+	for i, g := range groups {
+		res[i] = localize(g)
+	}
+	return res
+}
+
+// localize returns copies of bindings with their descriptions translated.
+func localize(bindings []key.Binding) []key.Binding {
+	res := make([]key.Binding, len(bindings))
+	for i, b := range bindings {
+		b.SetHelp(b.Help().Key, i18n.T(b.Help().Desc))
+		res[i] = b
+	}
+	return res
+}
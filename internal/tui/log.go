@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"log/slog"
+
+	"github.com/kvnloughead/gotobranch/internal/logging"
+)
+
+func (m Model) logger() *slog.Logger {
+	if m.log == nil {
+		return logging.Discard
+	}
+	return m.log
+}
+
+// logUpdate logs what handling a message changed, m being the model before
+// and next after: a new error at warn level and a new notice at info level.
+// Errors are compared by their text, as not all of them are comparable.
+func (m Model) logUpdate(next Model) {
+	if next.error != nil && (m.error == nil || next.error.Error() != m.error.Error()) {
+		m.logger().Warn("picker error", "repo", next.RepoPath, "err", next.error)
+	}
+	if next.notice != "" && next.notice != m.notice {
+		m.logger().Info("picker notice", "repo", next.RepoPath, "notice", next.notice)
+	}
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// menuActions returns the bindings of the actions on the highlighted branch
+// that the action menu offers, in the order it lists them. Only enabled
+// ones are offered, so the menu follows the keymap as it changes.
+func (k keyMap) menuActions() []key.Binding {
+	all := []key.Binding{
+		k.Pick, k.Switch, k.Detach, k.Worktree, k.Info, k.Diff, k.Preview,
+		k.Mark, k.Delete, k.Push, k.Track, k.Untrack, k.Reset, k.Rebase, k.Pluck,
+	}
+	res := make([]key.Binding, 0, len(all))
+	for _, b := range all {
+		if b.Enabled() {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// menuEntry is an action the action menu offers.
+type menuEntry struct {
+	key.Binding
+	plugin int // the plugin action it runs, or -1 for the picker's own
+}
+
+// startMenu opens the action menu for the highlighted branch.
+func (m Model) startMenu() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	m.mode = modeMenu
+	m.error = nil
+	m.menu, m.menuCursor, m.menuFor = nil, 0, m.items[m.cursor].Name
+	for _, b := range m.keys.menuActions() {
+		m.menu = append(m.menu, menuEntry{Binding: b, plugin: -1})
+	}
+	for i, b := range m.pluginKeys {
+		m.menu = append(m.menu, menuEntry{Binding: b, plugin: i})
+	}
+	return m, nil
+}
+
+// updateMenu handles keys in the action menu: enter runs the highlighted
+// action, and an action's own key runs it directly, as it would outside
+// the menu. Plugin actions follow the picker's own.
+func (m Model) updateMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Menu):
+		m.mode = modeSelect
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.menuCursor > 0 {
+			m.menuCursor--
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		if m.menuCursor < len(m.menu)-1 {
+			m.menuCursor++
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Apply):
+		if m.menuCursor < len(m.menu) {
+			m.mode = modeSelect
+			if e := m.menu[m.menuCursor]; e.plugin >= 0 {
+				return m.runPlugin(e.plugin)
+			}
+			return m.updateSelect(keyPress(m.menu[m.menuCursor].Keys()[0]))
+		}
+		return m, nil
+	}
+	for _, e := range m.menu {
+		if key.Matches(msg, e.Binding) {
+			m.mode = modeSelect
+			return m.updateSelect(msg)
+		}
+	}
+	return m, nil
+}
+
+// keyPress is the key message for a key as bindings name it, such as "d",
+// "D" or "enter".
+func keyPress(k string) tea.KeyMsg {
+	for t, name := range keyNames {
+		if name == k {
+			return tea.KeyMsg{Type: t}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// keyNames are the named keys the action menu's bindings use.
+var keyNames = map[tea.KeyType]string{
+	tea.KeyEnter: "enter",
+	tea.KeyTab:   "tab",
+}
+
+// menuRows renders the action menu in place of the list, each action with
+// its key, below the branch it acts on.
+func (m Model) menuRows(limit int) []string {
+	width := m.width - 4 // border and padding
+	lines := []string{truncate(m.menuFor, width), ""}
+	for i, b := range m.menu {
+		prefix := "  "
+		if i == m.menuCursor {
+			prefix = "> "
+		}
+		lines = append(lines, truncate(fmt.Sprintf("%s%-6s %s", prefix, b.Help().Key, i18n.T(b.Help().Desc)), width))
+	}
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Render(strings.Join(lines, "\n"))
+	out := strings.Split(box, "\n")
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
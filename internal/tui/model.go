@@ -1,14 +1,24 @@
 package tui
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
-	"gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
 )
 
 type Model struct {
@@ -23,6 +33,132 @@ type Model struct {
 	error error
 
 	cursor int // index within current page items
+	count  int // typed before a motion or Enter; 0 for none
+	mode   mode
+	keys   keyMap
+	help   help.Model
+
+	width  int
+	height int
+
+	rowTmpl *template.Template
+	table   bool // rows in the table layout, see tableColumns
+
+	source   []core.Branch // picker items; nil when listing the repository
+	picked   string
+	switched string
+	hookErr  error // a post-switch hook failed or a merge conflicted after switching
+
+	match   core.MatchMode
+	exclude []string
+	sortBy  string
+	sortDir string
+	theme   theme
+
+	profiles []Profile
+	profile  int // index into profiles
+
+	updateCheck func() string
+	notice      string // one-line message shown above the key hints
+	hookLine    string // the last line the hooks printed, until a key is pressed
+
+	lookupPulls func() (map[string]core.PullRequest, error)
+	pulls       map[string]core.PullRequest
+
+	issueBranch func(n int) (string, error)
+	issueInput  textinput.Model
+
+	openWorktree func(ctx context.Context, b core.Branch, report func(core.Progress)) (string, error)
+	worktree     string
+
+	listRepos   func() ([]string, error)
+	repoOptions func(dir string) (Options, error)
+	repos       []string
+	repoCursor  int
+
+	infoFor    core.Branch // the branch the detail dialog describes
+	info       core.BranchInfo
+	infoLoaded bool
+
+	menu       []menuEntry // the actions the action menu offers
+	menuCursor int
+	menuFor    string // the branch they act on
+
+	undo func() (core.Action, error)
+
+	head        core.HeadState
+	branchInput textinput.Model // the name of a branch to create at HEAD
+	nameErr     error           // why the name typed cannot be given to a new branch
+
+	stashes     []core.Stash
+	stashCursor int
+	offered     core.Stash // the stash offered for restoring after a switch
+
+	pickFrom string // the branch being cherry-picked from
+
+	question string                 // asked in confirm mode
+	onYes    func(m *Model) tea.Cmd // run when the question is answered yes
+
+	pushing   string  // the branch being pushed
+	forcePush pushMsg // the rejected push offered to be forced
+
+	templates []BranchTemplate
+	tmpl      int               // the template being filled in; -1 while choosing one
+	tmplVar   int               // the variable being asked for
+	tmplVals  map[string]string // the variables answered so far
+
+	remedyFor string         // the branch a failed switch offers remedies for
+	remedyErr *core.GitError // why the switch failed
+
+	retry func(m *Model) tea.Cmd // reruns a timed out command in retry mode
+
+	lookupCI   func(shas []string) (map[string]string, error)
+	ciStatuses map[string]string // by SHA; replaced, never modified, as list commands read it
+
+	divergence map[string]core.Divergence // by SHA, from divHead; replaced like ciStatuses
+	divHead    string
+	ciAsked    map[string]bool
+
+	plugins       []PluginAction
+	pluginKeys    []key.Binding // for plugins, with the keys they may use
+	columns       []Column
+	lookupColumns func(branches []core.Branch) (map[string]map[string]string, error)
+	columnValues  map[string]map[string]string // by full ref; replaced like ciStatuses
+	columnsAsked  map[string]bool              // by columnKey
+
+	used func(feature string)
+	log  *slog.Logger
+
+	signatures bool
+	commits    bool
+	shortStat  shortStatMsg // the highlighted branch's changes, see lookupShortStat
+
+	marked     map[string]bool // local branches marked for deletion, by name
+	deletions  []PruneResult   // branches deleted so far, see Deleted
+	policyWarn bool
+	staleAfter time.Duration
+
+	previewOn        bool
+	previewWidth     int        // percent of the width, see resizePreview
+	preview          previewMsg // the highlighted branch's preview, see lookupPreview
+	savePreviewWidth func(int) error
+
+	diffPager string
+	diffTool  string
+
+	scrollLines int
+	scrollView  bool
+	scroll      int // rows the list is scrolled by the wheel past the cursor's window
+
+	clone core.CloneInfo
+
+	fetching    bool
+	fetchRemote string
+	history     bool // the fetch is for the history a shallow clone lacks
+	spinner     spinner.Model
+
+	task     *task // the git operation running in the background, if any
+	progress core.Progress
 }
 
 type listMsg struct {
@@ -34,20 +170,238 @@ type listMsg struct {
 	err   error
 }
 
-type switchMsg struct{ err error }
+type switchMsg struct {
+	name string
+	err  error
+}
+
+// detachMsg reports checking out ref with a detached HEAD.
+type detachMsg switchMsg
+
+// issueMsg reports the branch created for an issue and switched to.
+type issueMsg switchMsg
+
+// headMsg reports what HEAD points to.
+type headMsg core.HeadState
+
+// worktreeMsg reports the working tree a branch was opened in.
+type worktreeMsg struct {
+	dir string
+	err error
+}
+
+// rebaseMsg reports the end of an interactive rebase.
+type rebaseMsg struct {
+	onto    string
+	stopped bool // the rebase waits for --continue or --abort
+	err     error
+}
+
+type noticeMsg string
+
+// HookOutputMsg is a line the configured hooks printed, which the picker
+// shows while it owns the terminal.
+type HookOutputMsg string
+
+// BusyMsg tells the model that git started (true) or stopped (false)
+// waiting for another git process to release a lock; see core.SetBusy.
+type BusyMsg bool
+
+// busyNotice is shown while git waits for a lock.
+const busyNotice = "repository busy: waiting for another git process…"
+
+type fetchMsg struct {
+	err         error
+	interactive bool // the fetch ran attached to the terminal
+}
+
+// cloneMsg reports how much history the repository has.
+type cloneMsg core.CloneInfo
+
+type ciMsg struct {
+	shas     []string // the head commits asked about
+	statuses map[string]string
+	err      error
+}
+
+// divergenceMsg carries divergences from the HEAD commit head.
+type divergenceMsg struct {
+	head   string
+	values map[string]core.Divergence
+}
+
+type pullsMsg struct {
+	pulls map[string]core.PullRequest
+	err   error
+}
 
 type Options struct {
 	RepoPath string
 	Scope    core.Scope
 	PageSize int
-	Pattern  string
+	Pattern  string // initial filter, a query as understood by core.ParseQuery
+	Match    core.MatchMode
+	Exclude  []string // globs of branches to hide (see core.Excluded)
+
+	// SortBy and SortDir order the list as in core.ListBranchesRequest.
+	// Empty means newest first.
+	SortBy  string
+	SortDir string
+
+	// Theme names a built-in color theme (see CheckTheme).
+	Theme string
+
+	// RowFormat is a text/template for each branch row (see package tmpl).
+	// Empty means DefaultRowFormat.
+	RowFormat string
+
+	// Table lays the rows out in aligned columns under a header of their
+	// titles (name, age, divergence, commits when counted, subject)
+	// instead of through RowFormat.
+	Table bool
+
+	// Fetch runs `git fetch --prune` for FetchRemote (all remotes when
+	// empty) in the background at startup, refreshing the list afterwards.
+	Fetch       bool
+	FetchRemote string
+
+	// Profiles are the views the profile key cycles through, starting after
+	// Profiles[Profile], which should describe the initial settings above.
+	// The key is disabled with fewer than two profiles.
+	Profiles []Profile
+	Profile  int
+
+	// UpdateCheck, if set, runs in the background at startup; a non-empty
+	// result is shown in the footer.
+	UpdateCheck func() string
+
+	// PullRequests, if set, runs in the background at startup; the pull
+	// requests it returns, keyed by head branch name, are then shown with
+	// their branches.
+	PullRequests func() (map[string]core.PullRequest, error)
+
+	// IssueBranch, if set, enables the issue key: it creates a branch for
+	// issue n, switches to it and returns its name.
+	IssueBranch func(n int) (string, error)
+
+	// Worktree, if set, enables the worktree key: it returns the working
+	// tree the highlighted branch is checked out in, creating one if needed
+	// and reporting git's progress meanwhile; it should stop when ctx is
+	// done. The picker then quits; read the directory back with Worktree.
+	Worktree func(ctx context.Context, b core.Branch, report func(core.Progress)) (string, error)
+
+	// Undo, if set, enables the undo key: it reverses the last switch,
+	// delete or rename and returns what it undid.
+	Undo func() (core.Action, error)
+
+	// Repos and OpenRepo, if both set, enable the repository key: Repos
+	// lists the known repositories' working trees, and OpenRepo returns
+	// the options for one of them, which the picker then starts over with.
+	Repos    func() ([]string, error)
+	OpenRepo func(dir string) (Options, error)
+
+	// CIStatuses, if set, looks up the CI status of head commits (keyed by
+	// SHA) as their branches are first shown.
+	CIStatuses func(shas []string) (map[string]string, error)
+
+	// PluginActions are offered in the action menu, and run from the list
+	// by their keys where the picker does not use them.
+	PluginActions []PluginAction
+
+	// Columns are extra columns, shown in the table layout and after the
+	// default row format; ColumnValues, if set, looks up their values (by
+	// full ref, then column id) as branches are first shown at their head
+	// commits.
+	Columns      []Column
+	ColumnValues func(branches []core.Branch) (map[string]map[string]string, error)
+
+	// BranchTemplates, if any, enable the template key, which asks for a
+	// template's variables and creates the branch it names.
+	BranchTemplates []BranchTemplate
+
+	// Signatures verifies the signatures of the head commits shown.
+	Signatures bool
+
+	// Commits counts the commits of the branches shown that the default
+	// branch lacks.
+	Commits bool
+
+	// PolicyWarn highlights branches whose names do not follow
+	// core.NamePolicy.
+	PolicyWarn bool
+
+	// StaleAfter is how long a branch can go without commits before its
+	// row is colored as stale; 0 never colors rows as stale. Rows are also
+	// colored for the current branch and by how local branches compare
+	// with their upstreams.
+	StaleAfter time.Duration
+
+	// Preview shows the preview pane at startup: the highlighted branch's
+	// recent commits and changes, as core.Preview renders them, to the
+	// right of the list. PreviewWidth is its share of the width in percent
+	// (50 when 0); when the user resizes it, SavePreviewWidth, if set, is
+	// called to keep the new width for the next run.
+	Preview          bool
+	PreviewWidth     int
+	SavePreviewWidth func(percent int) error
+
+	// ScrollLines is how many rows a notch of the mouse wheel moves (1 when
+	// 0), and ScrollView makes the wheel scroll the list rather than move
+	// the cursor; any key brings the cursor back into view.
+	ScrollLines int
+	ScrollView  bool
+
+	// DiffPager and DiffTool, if set, show the full diffs the diff key
+	// opens (see core.DiffCommand); git's own pager shows them otherwise.
+	DiffPager string
+	DiffTool  string
+
+	// Used, if set, is called with the actions taken in the list, as
+	// "key: " and the help text of their key, e.g. "key: delete", and
+	// "plugin: " and the title of plugin actions, for metrics.
+	Used func(feature string)
+
+	// Logger, if set, receives the picker's diagnostics: the errors and
+	// notices it shows and the actions taken (see logUpdate).
+	Logger *slog.Logger
+
+	// Items, when non-nil, turns the model into a generic picker over these
+	// entries instead of listing the repository's branches (see
+	// core.ResolveItems). Enter picks an item and quits; read it back with
+	// Picked. Items that are local branches can still be switched to.
+	Items []core.Branch
+}
+
+// BranchTemplate generates branch names from variables.
+type BranchTemplate struct {
+	Name string
+	Vars []string // asked for in this order
+	// Render returns the name for the variables' values, or why it is not
+	// a valid one.
+	Render func(vals map[string]string) (string, error)
+}
+
+// Profile is a named combination of filter, scope and ordering. The unnamed
+// profile stands for the settings without any profile applied.
+type Profile struct {
+	Name    string
+	Query   string
+	Scope   core.Scope
+	SortBy  string
+	SortDir string
+	Match   core.MatchMode
+	Exclude []string
 }
 
+// DefaultRowFormat reproduces the classic "  3. * main" row, followed by the
+// branch's divergence from HEAD, commit count, CI status, signature and pull
+// request, if known.
+const DefaultRowFormat = `{{printf "%3d" .Index}}. {{if .IsCurrent}}* {{end}}{{.Name}}{{if .Divergence}} {{.Divergence}}{{end}}{{if .CommitCount}} ({{.CommitCount}}){{end}}{{if .CI}} {{.CI}}{{end}}{{if .Sig}} {{.Sig}}{{end}}{{if .PR}}  #{{.PR}} {{.PRState}}{{end}}`
+
 func New(opts Options) Model {
 	inp := textinput.New()
-	inp.Placeholder = "Filter pattern (type to filter)"
+	inp.Placeholder = i18n.T("press f to filter")
 	inp.SetValue(opts.Pattern)
-	inp.Focus()
 
 	p := paginator.New()
 	if opts.PageSize <= 0 {
@@ -60,25 +414,319 @@ func New(opts Options) Model {
 		Scope:     opts.Scope,
 		input:     inp,
 		paginator: p,
+		keys:      defaultKeyMap(),
+		help:      help.New(),
+		match:     opts.Match,
+		exclude:   opts.Exclude,
+		sortBy:    opts.SortBy,
+		sortDir:   opts.SortDir,
+		theme:     lookupTheme(opts.Theme),
+		table:     opts.Table,
+
+		profiles:     opts.Profiles,
+		profile:      opts.Profile,
+		updateCheck:  opts.UpdateCheck,
+		lookupPulls:  opts.PullRequests,
+		lookupCI:     opts.CIStatuses,
+		issueBranch:  opts.IssueBranch,
+		openWorktree: opts.Worktree,
+		listRepos:    opts.Repos,
+		repoOptions:  opts.OpenRepo,
+		undo:         opts.Undo,
+		ciAsked:      map[string]bool{},
+		plugins:      opts.PluginActions,
+		used:         opts.Used,
+		log:          opts.Logger,
+		columns:      opts.Columns,
+		columnsAsked: map[string]bool{},
+		marked:       map[string]bool{},
+		signatures:   opts.Signatures,
+		commits:      opts.Commits,
+		templates:    opts.BranchTemplates,
+		policyWarn:   opts.PolicyWarn,
+		staleAfter:   opts.StaleAfter,
+		fetching:     opts.Fetch,
+		fetchRemote:  opts.FetchRemote,
+		spinner:      spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+
+		previewOn:        opts.Preview,
+		previewWidth:     opts.PreviewWidth,
+		savePreviewWidth: opts.SavePreviewWidth,
+
+		diffPager: opts.DiffPager,
+		diffTool:  opts.DiffTool,
+
+		scrollLines: max(opts.ScrollLines, 1),
+		scrollView:  opts.ScrollView,
+	}
+	m.help.Styles = m.theme.help
+	if m.sortBy == "" {
+		m.sortBy, m.sortDir = "frecency", "desc"
+	}
+	if m.previewWidth == 0 {
+		m.previewWidth = defaultPreviewWidth
+	}
+	m.previewWidth = min(max(m.previewWidth, minPreviewWidth), maxPreviewWidth)
+	if len(m.profiles) > 1 && opts.Items == nil {
+		m.keys.Profile.SetEnabled(true)
+	}
+	if m.issueBranch != nil && opts.Items == nil {
+		m.keys.Issue.SetEnabled(true)
+		m.issueInput = textinput.New()
+		m.issueInput.Placeholder = i18n.T("number")
+		m.issueInput.CharLimit = 10
+	}
+	if m.undo != nil && opts.Items == nil {
+		m.keys.Undo.SetEnabled(true)
+	}
+	if m.openWorktree != nil && opts.Items == nil {
+		m.keys.Worktree.SetEnabled(true)
+	}
+	if m.listRepos != nil && m.repoOptions != nil && opts.Items == nil {
+		m.keys.Repos.SetEnabled(true)
+	}
+	if opts.Items == nil {
+		m.keys.Sort.SetEnabled(true)
+		m.keys.Preview.SetEnabled(true)
+		m.keys.Split.SetEnabled(true)
+		m.keys.Diff.SetEnabled(true)
+		m.keys.Info.SetEnabled(true)
+		m.keys.Stashes.SetEnabled(true)
+		m.keys.Detach.SetEnabled(true)
+		m.keys.Mark.SetEnabled(true)
+		m.keys.Delete.SetEnabled(true)
+		m.keys.Rebase.SetEnabled(true)
+		m.keys.Pluck.SetEnabled(true)
+		m.keys.Push.SetEnabled(true)
+		m.keys.Track.SetEnabled(true)
+		m.keys.Untrack.SetEnabled(true)
+		m.keys.Reset.SetEnabled(true)
+	}
+	if len(m.templates) > 0 && opts.Items == nil {
+		m.keys.Template.SetEnabled(true)
+	}
+	if opts.Items != nil {
+		m.source = opts.Items
+		m.keys.Pick.SetEnabled(true)
+		m.keys.Switch.SetKeys("s")
+		m.keys.Switch.SetHelp("s", "switch")
+	}
+	if len(m.columns) > 0 {
+		m.lookupColumns = opts.ColumnValues
+	}
+	if opts.Items == nil {
+		m.pluginKeys = m.keys.pluginBindings(m.plugins)
+	} else {
+		m.plugins = nil
+	}
+	if opts.RowFormat == "" {
+		opts.RowFormat = columnsRowFormat(DefaultRowFormat, m.columns)
+	}
+	t, err := tmpl.Parse("row", opts.RowFormat)
+	if err != nil {
+		// Callers validate RowFormat up front; keep the UI usable regardless.
+		t, _ = tmpl.Parse("row", DefaultRowFormat)
 	}
+	m.rowTmpl = t
 	return m
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.refreshList()
+	cmds := []tea.Cmd{m.refreshList()}
+	if m.source == nil {
+		cmds = append(cmds, m.loadHead(), m.loadClone())
+	}
+	if m.updateCheck != nil {
+		check := m.updateCheck
+		cmds = append(cmds, func() tea.Msg { return noticeMsg(check()) })
+	}
+	if m.fetching {
+		cmds = append(cmds, m.spinner.Tick, m.fetch())
+	}
+	if m.lookupPulls != nil {
+		lookup := m.lookupPulls
+		cmds = append(cmds, func() tea.Msg {
+			pulls, err := lookup()
+			return pullsMsg{pulls: pulls, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetch updates remote-tracking branches; the list is shown from local data
+// in the meantime and refreshed when it completes. Git must not prompt for
+// credentials while the UI owns the terminal, so a fetch that needs them
+// fails and is retried by fetchInteractively.
+func (m Model) fetch() tea.Cmd {
+	repo, remote := m.RepoPath, m.fetchRemote
+	if m.history {
+		return runTask(i18n.Sprintf("fetching %s", m.fetchTarget()), func(ctx context.Context, report func(core.Progress)) tea.Msg {
+			return fetchMsg{err: core.FetchHistory(ctx, repo, report)}
+		})
+	}
+	return runTask(i18n.Sprintf("fetching %s", m.fetchTarget()), func(ctx context.Context, report func(core.Progress)) tea.Msg {
+		return fetchMsg{err: core.FetchProgress(ctx, repo, remote, true, report)}
+	})
+}
+
+// fetchInteractively hands the terminal to git for a fetch, so that it can
+// ask for credentials or a passphrase, and takes it back afterwards.
+func (m Model) fetchInteractively() tea.Cmd {
+	cmd := core.FetchCommand(m.RepoPath, m.fetchRemote, true)
+	if m.history {
+		cmd = core.FetchHistoryCommand(m.RepoPath)
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return fetchMsg{err: err, interactive: true}
+	})
+}
+
+// rebase hands the terminal to `git rebase -i onto`, so that the todo list
+// is edited in the user's editor, and refreshes the list afterwards.
+func (m Model) rebase(onto string) tea.Cmd {
+	repo := m.RepoPath
+	return tea.ExecProcess(core.RebaseCommand(repo, onto), func(err error) tea.Msg {
+		return rebaseMsg{onto: onto, stopped: core.RebaseInProgress(repo), err: err}
+	})
+}
+
+// loadHead looks up HEAD, so that a detached HEAD can be shown and
+// branched from. Failing to look it up is not worth an error; the list
+// itself will report a broken repository.
+func (m Model) loadHead() tea.Cmd {
+	return func() tea.Msg {
+		h, _ := core.Head(m.RepoPath)
+		return headMsg(h)
+	}
+}
+
+// loadClone looks up whether the clone is shallow, to flag its incomplete
+// history and offer to fetch the rest.
+func (m Model) loadClone() tea.Cmd {
+	return func() tea.Msg {
+		c, _ := core.Clone(m.RepoPath)
+		return cloneMsg(c)
+	}
+}
+
+// detached reports whether HEAD is known to be detached at a commit.
+func (m Model) detached() bool {
+	return m.head.Detached() && m.head.SHA != ""
+}
+
+// lookupCIStatuses asks for the CI status of the head commits on the
+// current page that were not asked about before, so only what is seen is
+// looked up.
+func (m Model) lookupCIStatuses() tea.Cmd {
+	if m.lookupCI == nil {
+		return nil
+	}
+	var shas []string
+	for _, b := range m.items {
+		if b.HeadCommitSHA != nil && !m.ciAsked[*b.HeadCommitSHA] {
+			m.ciAsked[*b.HeadCommitSHA] = true
+			shas = append(shas, *b.HeadCommitSHA)
+		}
+	}
+	if len(shas) == 0 {
+		return nil
+	}
+	lookup := m.lookupCI
+	return func() tea.Msg {
+		statuses, err := lookup(shas)
+		return ciMsg{shas: shas, statuses: statuses, err: err}
+	}
+}
+
+// lookupDivergences compares the head commits on the current page with
+// HEAD, skipping those compared before unless HEAD has moved since.
+func (m Model) lookupDivergences() tea.Cmd {
+	if m.source != nil {
+		return nil
+	}
+	var shas []string
+	for _, b := range m.items {
+		if b.HeadCommitSHA != nil {
+			shas = append(shas, *b.HeadCommitSHA)
+		}
+	}
+	if len(shas) == 0 {
+		return nil
+	}
+	repo, known, knownHead := m.RepoPath, m.divergence, m.divHead
+	return func() tea.Msg {
+		h, err := core.Head(repo)
+		if err != nil || h.SHA == "" {
+			return nil
+		}
+		var todo []string
+		for _, sha := range shas {
+			if _, ok := known[sha]; !ok || h.SHA != knownHead {
+				todo = append(todo, sha)
+			}
+		}
+		if len(todo) == 0 {
+			return nil
+		}
+		return divergenceMsg{head: h.SHA, values: core.Divergences(repo, h.SHA, todo)}
+	}
+}
+
+// Picked returns the item chosen in picker mode, or "" if none was.
+func (m Model) Picked() string {
+	return m.picked
+}
+
+// Switched returns the branch the user switched to, or "" if they quit
+// without switching.
+func (m Model) Switched() string {
+	return m.switched
+}
+
+// Worktree returns the working tree the user opened a branch in, or "" if
+// they did not.
+func (m Model) Worktree() string {
+	return m.worktree
+}
+
+// HookErr returns the failure of a post-switch hook that ran after
+// Switched, or the conflicts left by switching with local changes merged
+// in, if any.
+func (m Model) HookErr() error {
+	return m.hookErr
 }
 
 func (m Model) refreshList() tea.Cmd {
+	req := core.ListBranchesRequest{
+		RepoPath: m.RepoPath,
+		Query:    strings.TrimSpace(m.input.Value()),
+		Match:    m.match,
+		Exclude:  m.exclude,
+		Scope:    m.Scope,
+		SortBy:   m.sortBy,
+		SortDir:  m.sortDir,
+		Page:     m.paginator.Page + 1,
+		PageSize: m.paginator.PerPage,
+
+		PullRequests: m.pulls,
+		CIStatuses:   m.ciStatuses,
+		Columns:      m.columnValues,
+		Divergences:  m.divergence,
+		Signatures:   m.signatures,
+		Commits:      m.commits,
+		Tracking:     true,
+	}
+	if m.source != nil {
+		// Keep the order items were given in.
+		req.SortBy = ""
+		items := append([]core.Branch(nil), m.source...)
+		return func() tea.Msg {
+			resp := core.PageBranches(items, req)
+			return listMsg{items: resp.Items, total: resp.Total}
+		}
+	}
 	return func() tea.Msg {
-		resp, err := core.ListBranches(core.ListBranchesRequest{
-			RepoPath: m.RepoPath,
-			Pattern:  strings.TrimSpace(m.input.Value()),
-			Scope:    m.Scope,
-			SortBy:   "recency",
-			SortDir:  "desc",
-			Page:     m.paginator.Page + 1,
-			PageSize: m.paginator.PerPage,
-		})
+		resp, err := core.ListBranches(req)
 		if err != nil {
 			return listMsg{err: err}
 		}
@@ -87,47 +735,74 @@ func (m Model) refreshList() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n, ok := next.(Model); ok {
+		m.logUpdate(n)
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "enter":
-			// Switch to highlighted item (top of current page)
-			idx := m.cursor
-			if len(m.items) == 0 {
-				return m, nil
-			}
-			name := m.items[idx].Name
-			return m, func() tea.Msg {
-				_, err := core.Checkout(m.RepoPath, name, false)
-				return switchMsg{err: err}
-			}
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
-			}
-			return m, nil
-		case "down", "j":
-			if m.cursor < len(m.items)-1 {
-				m.cursor++
-			}
-			return m, nil
-		case "tab":
-			// Clear pattern
-			m.input.SetValue("")
-			return m, m.refreshList()
-		case "pgup", "left", "h":
-			if m.paginator.Page > 0 {
-				m.paginator.PrevPage()
-				m.cursor = 0
-				return m, m.refreshList()
-			}
-		case "pgdn", "right", "l":
-			m.paginator.NextPage()
-			m.cursor = 0
-			return m, m.refreshList()
+		m.scroll = 0
+		m.hookLine = ""
+		if text, ok := pastedText(msg); ok {
+			return m.paste(text)
+		}
+		if m.mode == modeFilter {
+			return m.updateFilter(msg)
+		}
+		if m.mode == modeIssue {
+			return m.updateIssue(msg)
+		}
+		if m.mode == modeNewBranch {
+			return m.updateNewBranch(msg)
 		}
+		if m.mode == modeTemplate {
+			return m.updateTemplate(msg)
+		}
+		if m.mode == modeStash {
+			return m.updateStash(msg)
+		}
+		if m.mode == modeApplyStash {
+			return m.updateApplyStash(msg)
+		}
+		if m.mode == modeCherryPick {
+			return m.updateCherryPick(msg)
+		}
+		if m.mode == modeConflict {
+			return m.updateConflict(msg)
+		}
+		if m.mode == modeForcePush {
+			return m.updateForcePush(msg)
+		}
+		if m.mode == modeConfirm {
+			return m.updateConfirm(msg)
+		}
+		if m.mode == modeRemedy {
+			return m.updateRemedy(msg)
+		}
+		if m.mode == modeRetry {
+			return m.updateRetry(msg)
+		}
+		if m.mode == modeJump {
+			return m.updateJump(msg)
+		}
+		if m.mode == modeRepo {
+			return m.updateRepos(msg)
+		}
+		if m.mode == modeInfo {
+			return m.updateInfo(msg)
+		}
+		if m.mode == modeMenu {
+			return m.updateMenu(msg)
+		}
+		return m.updateSelect(msg)
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case listMsg:
 		// listMsg tells the model to update the list of items
 		m.error = msg.err
@@ -137,55 +812,801 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// ensure it is always visible.
 			m.items = msg.items
 			m.total = msg.total
-			perPage := m.paginator.PerPage
-			if perPage <= 0 {
-				perPage = 50
-			}
-			m.paginator.SetTotalPages((m.total + perPage - 1) / perPage)
+			m.scroll = 0
+			m.paginator.SetTotalPages(m.total)
 			if len(m.items) == 0 {
 				m.cursor = 0
 			} else if m.cursor >= len(m.items) {
 				m.cursor = len(m.items) - 1
 			}
+			return m, tea.Batch(m.lookupCIStatuses(), m.lookupColumnValues(), m.lookupDivergences(), m.lookupHighlighted())
 		}
+		m.offerRetry(msg.err, func(m *Model) tea.Cmd { return m.refreshList() })
+		return m, nil
+
+	case ciMsg:
+		if msg.err != nil {
+			m.notice = i18n.Sprintf("CI status: %v", msg.err)
+			// Ask again about the commits left without a status, on the
+			// next page change or when retrying.
+			for _, sha := range msg.shas {
+				if _, ok := msg.statuses[sha]; !ok {
+					delete(m.ciAsked, sha)
+				}
+			}
+			m.offerRetry(msg.err, func(m *Model) tea.Cmd { return m.lookupCIStatuses() })
+		}
+		if len(msg.statuses) == 0 {
+			return m, nil
+		}
+		merged := make(map[string]string, len(m.ciStatuses)+len(msg.statuses))
+		for sha, status := range m.ciStatuses {
+			merged[sha] = status
+		}
+		for sha, status := range msg.statuses {
+			merged[sha] = status
+		}
+		m.ciStatuses = merged
+		return m, m.refreshList()
+
+	case undoMsg:
+		return m.undone(msg)
+
+	case deletePlanMsg:
+		return m.confirmDelete(msg)
+
+	case deleteMsg:
+		return m.deleted(msg)
+
+	case shortStatMsg:
+		return m.shortStatDone(msg)
+
+	case previewMsg:
+		return m.previewDone(msg)
+
+	case diffMsg:
+		return m.diffDone(msg)
+
+	case columnsMsg:
+		return m.columnsLookedUp(msg)
+
+	case pluginMsg:
+		return m.pluginDone(msg)
+
+	case reposMsg:
+		return m.reposLoaded(msg)
+
+	case infoMsg:
+		return m.infoLookedUp(msg)
+
+	case divergenceMsg:
+		if msg.head != m.divHead {
+			m.divergence, m.divHead = msg.values, msg.head
+			return m, m.refreshList()
+		}
+		if len(msg.values) == 0 {
+			return m, nil
+		}
+		merged := make(map[string]core.Divergence, len(m.divergence)+len(msg.values))
+		for sha, d := range m.divergence {
+			merged[sha] = d
+		}
+		for sha, d := range msg.values {
+			merged[sha] = d
+		}
+		m.divergence = merged
+		return m, m.refreshList()
+
+	case taskMsg:
+		return m.taskStarted(msg)
+
+	case progressMsg:
+		return m.progressed(msg)
+
+	case fetchMsg:
+		m.taskDone()
+		if errors.Is(msg.err, core.ErrAuthRequired) && !msg.interactive {
+			return m, m.fetchInteractively()
+		}
+		m.fetching = false
+		history := m.history
+		m.history = false
+		if errors.Is(msg.err, core.ErrCanceled) {
+			m.notice = i18n.T("fetch canceled")
+			return m, nil
+		}
+		if errors.Is(msg.err, core.ErrTimeout) {
+			m.error = msg.err
+			m.offerRetry(msg.err, func(m *Model) tea.Cmd {
+				m.fetching, m.history = true, history
+				return tea.Batch(m.spinner.Tick, m.fetch())
+			})
+			return m, nil
+		}
+		if msg.err != nil {
+			m.notice = i18n.Sprintf("fetch failed: %v", msg.err)
+			return m, nil
+		}
+		if history {
+			m.clone.Shallow = false
+			m.keys.History.SetEnabled(false)
+			m.notice = i18n.T("fetched the full history")
+		}
+		return m, m.refreshList()
+
+	case pullsMsg:
+		if msg.err != nil {
+			m.notice = i18n.Sprintf("pull requests: %v", msg.err)
+			return m, nil
+		}
+		m.pulls = msg.pulls
+		return m, m.refreshList()
+
+	case spinner.TickMsg:
+		if !m.fetching && m.pushing == "" && m.task == nil {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case cloneMsg:
+		m.clone = core.CloneInfo(msg)
+		m.keys.History.SetEnabled(m.clone.Shallow)
+		return m, nil
+
+	case headMsg:
+		m.head = core.HeadState(msg)
+		m.keys.Here.SetEnabled(m.detached())
+		return m, nil
+
+	case noticeMsg:
+		m.notice = string(msg)
+		return m, nil
+
+	case HookOutputMsg:
+		m.hookLine = string(msg)
+		return m, nil
+
+	case BusyMsg:
+		if msg {
+			m.notice = i18n.T(busyNotice)
+		} else if m.notice == i18n.T(busyNotice) {
+			m.notice = ""
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		m.input.Width = max(msg.Width-len(m.filterLabel())-3, 0)
+		// Widening the terminal can bring the preview pane back.
+		return m, m.lookupPreview()
+
+	case tea.ResumeMsg:
+		// The terminal may have been resized while we were suspended.
+		cmds := []tea.Cmd{tea.WindowSize(), m.refreshList()}
+		if m.source == nil {
+			cmds = append(cmds, m.loadHead())
+		}
+		return m, tea.Batch(cmds...)
+
+	case issueMsg:
+		m.notice = ""
+		return m.Update(switchMsg(msg))
+
+	case stashesMsg:
+		m.stashes, m.error = msg.stashes, msg.err
+		m.stashCursor = max(min(m.stashCursor, len(m.stashes)-1), 0)
+		return m, nil
+
+	case stashDoneMsg:
+		if m.mode == modeApplyStash {
+			// The switch happened; only restoring the changes failed.
+			m.mode = modeSelect
+			m.error = i18n.Errorf("restoring %s: %w", m.offered.Ref(), msg.err)
+			return m, nil
+		}
+		if msg.err != nil {
+			m.error = msg.err
+		} else {
+			m.notice = msg.what
+		}
+		return m, tea.Batch(m.loadStashes(), m.refreshList())
+
+	case stashOfferMsg:
+		if !msg.ok {
+			return m, tea.Quit
+		}
+		m.offered = msg.stash
+		m.mode = modeApplyStash
+		return m, nil
+
+	case worktreeMsg:
+		m.taskDone()
+		m.notice = ""
+		if errors.Is(msg.err, core.ErrCanceled) {
+			m.notice = i18n.T("worktree canceled")
+			return m, nil
+		}
+		if msg.err != nil {
+			m.error = msg.err
+			return m, nil
+		}
+		m.worktree = msg.dir
+		return m, tea.Quit
+
+	case pushMsg:
+		return m.pushed(msg)
+
+	case resetPlanMsg:
+		return m.confirmReset(msg)
+
+	case resetMsg:
+		return m.reset(msg)
+
+	case trackMsg:
+		if msg.err != nil {
+			m.error = msg.err
+			return m, nil
+		}
+		m.notice = msg.notice
+		return m, m.refreshList()
+
+	case cherryPickMsg:
+		return m.cherryPicked(msg)
+
+	case rebaseMsg:
+		switch {
+		case msg.stopped:
+			m.notice = i18n.T("rebase stopped: finish it with git rebase --continue, or --abort")
+		case msg.err != nil:
+			m.error = i18n.Errorf("rebase onto %s: %w", msg.onto, msg.err)
+		default:
+			m.notice = i18n.Sprintf("rebased onto %s", msg.onto)
+		}
+		return m, tea.Batch(m.refreshList(), m.loadHead())
+
+	case detachMsg:
+		if msg.err == nil || core.PostHookFailed(msg.err) {
+			m.switched, m.hookErr = msg.name, msg.err
+			return m, tea.Quit
+		}
+		m.error = msg.err
 		return m, nil
 
 	case switchMsg:
+		if msg.err == nil || core.PostHookFailed(msg.err) || errors.Is(msg.err, core.ErrMergeConflicts) {
+			m.switched, m.hookErr = msg.name, msg.err
+			if m.source != nil {
+				return m, tea.Quit
+			}
+			return m, m.findStash(msg.name)
+		}
 		m.error = msg.err
-		if msg.err == nil {
-			return m, tea.Quit
+		m.offerRemedies(msg.name, msg.err)
+		name := msg.name
+		m.offerRetry(msg.err, func(m *Model) tea.Cmd {
+			return func() tea.Msg {
+				_, err := core.Checkout(m.RepoPath, name, false)
+				return switchMsg{name: name, err: err}
+			}
+		})
+	}
+	return m, nil
+}
+
+// updateSelect handles keys while moving through the list. Digits typed
+// first make a count, as in vim: the motion that follows repeats that many
+// times, and Enter acts on the row with that number instead.
+func (m Model) updateSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if d, ok := countDigit(msg); ok && (m.count > 0 || d > 0) {
+		m.count = min(m.count*10+d, maxCount)
+		return m, nil
+	}
+	count := m.count
+	m.count = 0
+	if count > 0 && msg.String() == "esc" {
+		return m, nil
+	}
+	if i, ok := m.pluginKey(msg); ok {
+		return m.runPlugin(i)
+	}
+	m.recordUse(msg)
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Abort):
+		return m.abortTask()
+	case key.Matches(msg, m.keys.Suspend):
+		// Hand the terminal back to the shell; Bubble Tea restores the
+		// alternate screen and raw mode when the process is resumed.
+		return m, tea.Suspend
+	case key.Matches(msg, m.keys.Pick):
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		idx := m.cursor
+		if count > 0 {
+			var err error
+			if idx, err = m.counted(count); err != nil {
+				m.error = err
+				return m, nil
+			}
+		}
+		m.picked = m.items[idx].Name
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Switch):
+		// Switch to highlighted item, or the numbered one
+		idx := m.cursor
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		if count > 0 {
+			var err error
+			if idx, err = m.counted(count); err != nil {
+				m.error = err
+				return m, nil
+			}
+		}
+		if m.source != nil && (m.items[idx].FullRef == "" || m.items[idx].IsRemote) {
+			m.error = i18n.Errorf("%s is not a local branch", m.items[idx].Name)
+			return m, nil
+		}
+		name := strings.TrimPrefix(m.items[idx].FullRef, "refs/heads/")
+		return m, func() tea.Msg {
+			_, err := core.Checkout(m.RepoPath, name, false)
+			return switchMsg{name: name, err: err}
+		}
+	case key.Matches(msg, m.keys.Detach):
+		if len(m.items) == 0 {
+			return m, nil
 		}
+		ref := m.items[m.cursor].Name
+		return m, func() tea.Msg {
+			_, err := core.Detach(m.RepoPath, ref)
+			return detachMsg{name: ref, err: err}
+		}
+	case key.Matches(msg, m.keys.Undo):
+		return m.startUndo()
+	case key.Matches(msg, m.keys.Mark):
+		return m.toggleMark()
+	case key.Matches(msg, m.keys.Delete):
+		return m.planDelete()
+	case key.Matches(msg, m.keys.Here):
+		m.mode = modeNewBranch
+		m.nameErr = nil
+		m.branchInput = textinput.New()
+		m.branchInput.Placeholder = i18n.T("name")
+		return m, m.branchInput.Focus()
+	case key.Matches(msg, m.keys.Stashes):
+		m.mode = modeStash
+		m.notice = ""
+		return m, m.loadStashes()
+	case key.Matches(msg, m.keys.Repos):
+		return m.startRepos()
+	case key.Matches(msg, m.keys.Template):
+		m.mode = modeTemplate
+		m.nameErr = nil
+		m.tmpl, m.tmplVar, m.tmplVals = -1, 0, map[string]string{}
+		if len(m.templates) == 1 {
+			m.tmpl = 0
+		}
+		return m, m.askTemplate()
+	case key.Matches(msg, m.keys.History):
+		if m.fetching {
+			return m, nil
+		}
+		m.fetching, m.history = true, true
+		return m, tea.Batch(m.spinner.Tick, m.fetch())
+	case key.Matches(msg, m.keys.Rebase):
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		b := m.items[m.cursor]
+		if b.IsCurrent {
+			m.error = i18n.Errorf("%s is checked out; highlight the branch to rebase it onto", b.Name)
+			return m, nil
+		}
+		m.error, m.notice = nil, ""
+		return m, m.rebase(b.Name)
+	case key.Matches(msg, m.keys.Push):
+		return m.startPush()
+	case key.Matches(msg, m.keys.Track):
+		return m.track()
+	case key.Matches(msg, m.keys.Untrack):
+		return m.untrack()
+	case key.Matches(msg, m.keys.Reset):
+		return m.planReset()
+	case key.Matches(msg, m.keys.Pluck):
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		if b := m.items[m.cursor]; b.IsCurrent {
+			m.error = i18n.Errorf("%s is checked out; highlight the branch to cherry-pick from", b.Name)
+			return m, nil
+		}
+		return m.askCherryPick(m.items[m.cursor])
+	case key.Matches(msg, m.keys.Worktree):
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		if m.task != nil {
+			m.error = i18n.Errorf("wait for %s to finish or cancel it", m.task.label)
+			return m, nil
+		}
+		b := m.items[m.cursor]
+		open := m.openWorktree
+		return m, tea.Batch(m.spinner.Tick, runTask(i18n.Sprintf("opening a worktree for %s", b.Name), func(ctx context.Context, report func(core.Progress)) tea.Msg {
+			dir, err := open(ctx, b, report)
+			return worktreeMsg{dir: dir, err: err}
+		}))
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor = max(m.cursor-max(count, 1), 0)
+			return m, m.lookupHighlighted()
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.items)-1 {
+			m.cursor = min(m.cursor+max(count, 1), len(m.items)-1)
+			return m, m.lookupHighlighted()
+		}
+	case key.Matches(msg, m.keys.Filter):
+		m.mode = modeFilter
+		return m, m.input.Focus()
+	case key.Matches(msg, m.keys.Jump):
+		return m.startJump()
+	case key.Matches(msg, m.keys.Sort):
+		return m.sortKey(msg)
+	case key.Matches(msg, m.keys.Preview):
+		return m.togglePreview()
+	case key.Matches(msg, m.keys.Split):
+		return m.resizePreview(msg)
+	case key.Matches(msg, m.keys.Diff):
+		return m.openDiff()
+	case key.Matches(msg, m.keys.Info):
+		return m.startInfo()
+	case key.Matches(msg, m.keys.Menu):
+		return m.startMenu()
+	case key.Matches(msg, m.keys.Clear):
+		m.input.SetValue("")
+		m.paginator.Page = 0
+		return m, m.refreshList()
+	case key.Matches(msg, m.keys.Profile):
+		m.profile = (m.profile + 1) % len(m.profiles)
+		p := m.profiles[m.profile]
+		m.input.SetValue(p.Query)
+		m.Scope, m.sortBy, m.sortDir, m.match, m.exclude = p.Scope, p.SortBy, p.SortDir, p.Match, p.Exclude
+		if m.width > 0 {
+			m.input.Width = max(m.width-len(m.filterLabel())-3, 0)
+		}
+		m.paginator.Page = 0
+		m.cursor = 0
+		return m, m.refreshList()
+	case key.Matches(msg, m.keys.Issue):
+		m.mode = modeIssue
+		m.issueInput.SetValue("")
+		return m, m.issueInput.Focus()
+	case key.Matches(msg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+	case key.Matches(msg, m.keys.PrevPage):
+		if m.paginator.Page > 0 {
+			m.paginator.Page = max(m.paginator.Page-max(count, 1), 0)
+			m.cursor = 0
+			return m, m.refreshList()
+		}
+	case key.Matches(msg, m.keys.NextPage):
+		if !m.paginator.OnLastPage() {
+			m.paginator.Page = min(m.paginator.Page+max(count, 1), m.paginator.TotalPages-1)
+			m.cursor = 0
+			return m, m.refreshList()
+		}
+	}
+	return m, nil
+}
+
+// updateIssue handles keys while asking for the issue to create a branch
+// for.
+func (m Model) updateIssue(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.issueInput.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Create):
+		n, err := strconv.Atoi(m.issueInput.Value())
+		if err != nil || n <= 0 {
+			return m, nil
+		}
+		m.mode = modeSelect
+		m.issueInput.Blur()
+		m.notice = i18n.Sprintf("creating a branch for issue #%d…", n)
+		create := m.issueBranch
+		return m, func() tea.Msg {
+			name, err := create(n)
+			return issueMsg{name: name, err: err}
+		}
+	case msg.Type == tea.KeyRunes && strings.Trim(string(msg.Runes), "0123456789") != "":
+		// Issue numbers only.
+		return m, nil
 	}
 	var cmd tea.Cmd
-	m.input, cmd = m.input.Update(msg)
-	if _, ok := msg.(tea.KeyMsg); ok {
-		return m, tea.Batch(cmd, m.refreshList())
+	m.issueInput, cmd = m.issueInput.Update(msg)
+	return m, cmd
+}
+
+// updateNewBranch handles keys while asking for the name of a branch to
+// create at the detached HEAD.
+func (m Model) updateNewBranch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.branchInput.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Create):
+		name := strings.TrimSpace(m.branchInput.Value())
+		if name == "" || m.nameErr != nil {
+			return m, nil
+		}
+		m.mode = modeSelect
+		m.branchInput.Blur()
+		return m, func() tea.Msg {
+			_, _, err := core.CreateOrSwitch(m.RepoPath, name, "")
+			return switchMsg{name: name, err: err}
+		}
+	}
+	var cmd tea.Cmd
+	m.branchInput, cmd = m.branchInput.Update(msg)
+	m.nameErr = nil
+	if name := strings.TrimSpace(m.branchInput.Value()); name != "" {
+		m.nameErr = checkNewName(name)
 	}
 	return m, cmd
 }
 
-func (m Model) View() string {
-	var b strings.Builder
-	fmt.Fprintf(&b, "Filter: %s\n", m.input.View())
-	b.WriteString("\n")
-	if m.error != nil {
-		fmt.Fprintf(&b, "Error: %v\n\n", m.error)
+// checkNewName reports why name cannot be given to a new branch, as the
+// user types it.
+func checkNewName(name string) error {
+	if err := core.CheckBranchName(name); err != nil {
+		return err
 	}
-	start := m.paginator.Page * m.paginator.PerPage
-	for i, it := range m.items {
-		prefix := "  "
-		if i == m.cursor {
-			prefix = "> "
+	return core.CheckName(name)
+}
+
+// askTemplate prompts for the template to use or its next variable, or,
+// when all are answered, creates the branch they name.
+func (m *Model) askTemplate() tea.Cmd {
+	m.branchInput = textinput.New()
+	if m.tmpl < 0 {
+		names := make([]string, len(m.templates))
+		for i, t := range m.templates {
+			names[i] = t.Name
 		}
-		line := it.Name
-		if it.IsCurrent {
-			line = "* " + line
+		m.branchInput.Placeholder = strings.Join(names, ", ")
+		return m.branchInput.Focus()
+	}
+	t := m.templates[m.tmpl]
+	if m.tmplVar < len(t.Vars) {
+		return m.branchInput.Focus()
+	}
+	m.mode = modeSelect
+	name, err := t.Render(m.tmplVals)
+	if err != nil {
+		m.error = err
+		return nil
+	}
+	return func() tea.Msg {
+		_, _, err := core.CreateOrSwitch(m.RepoPath, name, "")
+		return switchMsg{name: name, err: err}
+	}
+}
+
+// updateTemplate handles keys while choosing a branch template and filling
+// in its variables.
+func (m Model) updateTemplate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.branchInput.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Apply):
+		value := strings.TrimSpace(m.branchInput.Value())
+		if m.tmpl < 0 {
+			m.tmpl = m.findTemplate(value)
+			if m.tmpl < 0 {
+				m.error = i18n.Errorf("no branch template %q", value)
+				return m, nil
+			}
+			m.error = nil
+			return m, m.askTemplate()
+		}
+		if m.nameErr != nil {
+			return m, nil
+		}
+		m.tmplVals[m.templates[m.tmpl].Vars[m.tmplVar]] = value
+		m.tmplVar++
+		cmd := m.askTemplate()
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.branchInput, cmd = m.branchInput.Update(msg)
+	m.nameErr = m.checkTemplateName()
+	return m, cmd
+}
+
+// checkTemplateName reports why the branch the template being filled in
+// would name is invalid, once the value typed is that of its last
+// variable.
+func (m Model) checkTemplateName() error {
+	value := strings.TrimSpace(m.branchInput.Value())
+	if m.tmpl < 0 || value == "" {
+		return nil
+	}
+	t := m.templates[m.tmpl]
+	if m.tmplVar != len(t.Vars)-1 {
+		return nil
+	}
+	vals := make(map[string]string, len(m.tmplVals)+1)
+	for k, v := range m.tmplVals {
+		vals[k] = v
+	}
+	vals[t.Vars[m.tmplVar]] = value
+	_, err := t.Render(vals)
+	return err
+}
+
+// findTemplate returns the index of the template named, or uniquely
+// prefixed, by s, or -1.
+func (m Model) findTemplate(s string) int {
+	found := -1
+	for i, t := range m.templates {
+		switch {
+		case t.Name == s:
+			return i
+		case s != "" && strings.HasPrefix(t.Name, s):
+			if found >= 0 {
+				return -1
+			}
+			found = i
 		}
-		fmt.Fprintf(&b, "%s%3d. %s\n", prefix, start+i+1, line)
 	}
-	b.WriteString("\n")
-	b.WriteString(m.paginator.View())
-	b.WriteString("\n")
-	b.WriteString("↑/k ↓/j: move • Enter: switch • Tab: clear • PgUp/PgDn or h/l: pages • q: quit\n")
-	return b.String()
+	return found
+}
+
+// templatePrompt is the header line while filling in a branch template.
+func (m Model) templatePrompt() string {
+	if m.tmpl < 0 {
+		return i18n.T("New branch from template: ")
+	}
+	t := m.templates[m.tmpl]
+	return i18n.Sprintf("New %s branch, %s: ", t.Name, t.Vars[min(m.tmplVar, len(t.Vars)-1)])
+}
+
+// offerRemedies switches to remedy mode if err, a failed switch to name,
+// has remedies that apply to switching (see core.GitError.Remedies).
+func (m *Model) offerRemedies(name string, err error) {
+	var ge *core.GitError
+	if name == "" || !errors.As(err, &ge) {
+		return
+	}
+	bindings := map[core.Remedy]*key.Binding{
+		core.RemedyStash: &m.keys.Stash,
+		core.RemedyMerge: &m.keys.Merge,
+		core.RemedyForce: &m.keys.Discard,
+		core.RemedyFetch: &m.keys.Refetch,
+	}
+	for _, b := range bindings {
+		b.SetEnabled(false)
+	}
+	offered := false
+	for _, r := range ge.Remedies() {
+		if b, ok := bindings[r]; ok {
+			b.SetEnabled(true)
+			offered = true
+		}
+	}
+	if offered {
+		m.mode = modeRemedy
+		m.remedyFor, m.remedyErr = name, ge
+	}
+}
+
+// offerRetry switches to retry mode if err is a timeout, so that the user
+// can run the command again (with retry) or give up, rather than the picker
+// appearing to hang or silently missing data.
+func (m *Model) offerRetry(err error, retry func(m *Model) tea.Cmd) {
+	if !errors.Is(err, core.ErrTimeout) {
+		return
+	}
+	m.mode = modeRetry
+	m.retry = retry
+}
+
+// updateRetry handles keys while asking whether to retry a timed out
+// command.
+func (m Model) updateRetry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.error = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Retry):
+		m.mode = modeSelect
+		m.error = nil
+		retry := m.retry
+		m.retry = nil
+		return m, retry(&m)
+	}
+	return m, nil
+}
+
+// updateRemedy handles keys while choosing a way out of a failed switch.
+func (m Model) updateRemedy(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var r core.Remedy
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.error = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Stash):
+		r = core.RemedyStash
+	case key.Matches(msg, m.keys.Merge):
+		r = core.RemedyMerge
+	case key.Matches(msg, m.keys.Discard):
+		r = core.RemedyForce
+	case key.Matches(msg, m.keys.Refetch):
+		r = core.RemedyFetch
+	default:
+		return m, nil
+	}
+	m.mode = modeSelect
+	m.error = nil
+	name := m.remedyFor
+	return m, func() tea.Msg {
+		_, err := core.SwitchWith(m.RepoPath, name, r)
+		return switchMsg{name: name, err: err}
+	}
+}
+
+// updateFilter handles keys while the filter input is focused. Every edit
+// re-runs the listing from the first page.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Apply):
+		m.mode = modeSelect
+		m.input.Blur()
+		return m, nil
+	case key.Matches(msg, m.keys.Cancel):
+		m.mode = modeSelect
+		m.input.Blur()
+		m.input.SetValue("")
+		m.paginator.Page = 0
+		return m, m.refreshList()
+	case key.Matches(msg, m.keys.Clear):
+		m.input.SetValue("")
+		m.paginator.Page = 0
+		return m, m.refreshList()
+	}
+	prev := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.paginator.Page = 0
+		m.cursor = 0
+		return m, tea.Batch(cmd, m.refreshList())
+	}
+	return m, cmd
 }
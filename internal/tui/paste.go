@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pastedText returns the text msg carries if it is a bracketed paste
+// rather than a key press. Line breaks are dropped, so that a name copied
+// with its newline does not gain a space in an input. (Terminals that do
+// not bracket pastes send the text as it would be typed; the characters
+// arrive together, matching no key, and line breaks as enter.)
+func pastedText(msg tea.KeyMsg) (string, bool) {
+	if msg.Type != tea.KeyRunes || !msg.Paste {
+		return "", false
+	}
+	var b strings.Builder
+	for _, line := range strings.FieldsFunc(string(msg.Runes), func(r rune) bool { return r == '\n' || r == '\r' }) {
+		b.WriteString(strings.TrimSpace(line))
+	}
+	return b.String(), true
+}
+
+// paste inserts text into the input of the current mode as a whole, never
+// as keys: pasting "quit" into the list must not quit. Pasting while moving
+// through the list starts filtering by the text.
+func (m Model) paste(text string) (tea.Model, tea.Cmd) {
+	if text == "" {
+		return m, nil
+	}
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true}
+	switch m.mode {
+	case modeSelect:
+		m.mode, m.count = modeFilter, 0
+		focus := m.input.Focus()
+		next, cmd := m.updateFilter(msg)
+		return next, tea.Batch(focus, cmd)
+	case modeFilter:
+		return m.updateFilter(msg)
+	case modeNewBranch:
+		return m.updateNewBranch(msg)
+	case modeTemplate:
+		return m.updateTemplate(msg)
+	case modeJump:
+		return m.updateJump(msg)
+	case modeCherryPick:
+		return m.updateCherryPick(msg)
+	case modeIssue:
+		// "#42", as issues are usually written.
+		msg.Runes = []rune(strings.TrimPrefix(text, "#"))
+		return m.updateIssue(msg)
+	}
+	return m, nil
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// PluginAction is an action on a branch that an external program, such as
+// a plugin, carries out.
+type PluginAction struct {
+	Title string
+	Key   string // runs it from the list unless the picker uses the key; optional
+
+	// Command returns the command carrying out the action on b, which is
+	// given the terminal.
+	Command func(repoPath string, b core.Branch) *exec.Cmd
+}
+
+// Column is an extra column of per-branch values, such as a plugin's.
+type Column struct {
+	ID    string // the key of its values in core.Branch.Columns
+	Title string
+	Width int
+}
+
+// columnsMsg carries the extra column values of the branches with the
+// full refs asked, by full ref and column id.
+type columnsMsg struct {
+	asked  []string
+	values map[string]map[string]string
+	err    error
+}
+
+// pluginMsg reports the end of a plugin action.
+type pluginMsg struct {
+	title, branch string
+	err           error
+}
+
+// pluginBindings returns a binding for each of actions, with its key unless
+// the picker already uses it, so that a plugin cannot take over a key.
+// Actions without a key are only offered in the action menu.
+func (k keyMap) pluginBindings(actions []PluginAction) []key.Binding {
+	taken := map[string]bool{}
+	for _, group := range (modeKeys{keys: k, mode: modeSelect}).FullHelp() {
+		for _, b := range group {
+			for _, s := range b.Keys() {
+				taken[s] = true
+			}
+		}
+	}
+	res := make([]key.Binding, len(actions))
+	for i, a := range actions {
+		// Digits count the lines a key moves by.
+		if a.Key != "" && !taken[a.Key] && (len(a.Key) != 1 || a.Key[0] < '0' || a.Key[0] > '9') {
+			res[i] = key.NewBinding(key.WithKeys(a.Key), key.WithHelp(a.Key, a.Title))
+			taken[a.Key] = true
+		} else {
+			res[i] = key.NewBinding(key.WithHelp("", a.Title))
+		}
+	}
+	return res
+}
+
+// pluginKey returns the plugin action msg runs, if any.
+func (m Model) pluginKey(msg tea.KeyMsg) (int, bool) {
+	for i, b := range m.pluginKeys {
+		if key.Matches(msg, b) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// runPlugin hands the terminal to plugin action i on the highlighted
+// branch, refreshing the list afterwards since it may have changed it.
+func (m Model) runPlugin(i int) (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 || i >= len(m.plugins) {
+		return m, nil
+	}
+	a, b := m.plugins[i], m.items[m.cursor]
+	m.error, m.notice = nil, ""
+	if m.used != nil {
+		m.used("plugin: " + a.Title)
+	}
+	return m, tea.ExecProcess(a.Command(m.RepoPath, b), func(err error) tea.Msg {
+		return pluginMsg{title: a.Title, branch: b.Name, err: err}
+	})
+}
+
+// pluginDone reports a failed plugin action and refreshes the list.
+func (m Model) pluginDone(msg pluginMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.error = fmt.Errorf("%s on %s: %w", msg.title, msg.branch, msg.err)
+	}
+	return m, m.refreshList()
+}
+
+// lookupColumnValues asks for the extra column values of the branches on
+// the current page not asked about at their head commit before.
+func (m Model) lookupColumnValues() tea.Cmd {
+	if m.lookupColumns == nil {
+		return nil
+	}
+	var todo []core.Branch
+	var refs []string
+	for _, b := range m.items {
+		if k := columnKey(b); !m.columnsAsked[k] {
+			m.columnsAsked[k] = true
+			todo, refs = append(todo, b), append(refs, b.FullRef)
+		}
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+	lookup := m.lookupColumns
+	return func() tea.Msg {
+		values, err := lookup(todo)
+		return columnsMsg{asked: refs, values: values, err: err}
+	}
+}
+
+// columnKey identifies b at its head commit, for lookupColumnValues.
+func columnKey(b core.Branch) string {
+	if b.HeadCommitSHA == nil {
+		return b.FullRef
+	}
+	return b.FullRef + "@" + *b.HeadCommitSHA
+}
+
+// columnsLookedUp merges in looked up column values and shows them.
+func (m Model) columnsLookedUp(msg columnsMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.notice = i18n.Sprintf("columns: %v", msg.err)
+	}
+	if msg.err != nil && len(msg.values) == 0 {
+		return m, nil
+	}
+	merged := make(map[string]map[string]string, len(m.columnValues)+len(msg.asked))
+	for ref, vals := range m.columnValues {
+		merged[ref] = vals
+	}
+	for _, ref := range msg.asked {
+		// Branches left without values lose those of their old head.
+		if vals, ok := msg.values[ref]; ok {
+			merged[ref] = vals
+		} else if msg.err == nil {
+			delete(merged, ref)
+		}
+	}
+	m.columnValues = merged
+	return m, m.refreshList()
+}
+
+// columnsRowFormat extends the default row format with the extra columns'
+// values, each after a space when the branch has one.
+func columnsRowFormat(format string, cols []Column) string {
+	for _, c := range cols {
+		format += fmt.Sprintf("{{with .Column %s}} {{.}}{{end}}", strconv.Quote(c.ID))
+	}
+	return format
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+const (
+	// previewMinWidth is the terminal width below which the preview pane
+	// collapses, leaving the list too little room otherwise.
+	previewMinWidth = 100
+
+	// The preview pane takes between minPreviewWidth and maxPreviewWidth
+	// percent of the width, changed in steps of previewStep.
+	defaultPreviewWidth = 50
+	minPreviewWidth     = 20
+	maxPreviewWidth     = 80
+	previewStep         = 10
+)
+
+// previewMsg carries the preview of the branch at commit sha.
+type previewMsg struct {
+	sha  string
+	text string
+	err  error
+}
+
+// lookupHighlighted looks up what is shown about the highlighted branch
+// once the cursor reaches it: its changes for details and its preview.
+func (m Model) lookupHighlighted() tea.Cmd {
+	return tea.Batch(m.lookupShortStat(), m.lookupPreview())
+}
+
+// previewShown reports whether the preview pane is drawn.
+func (m Model) previewShown() bool {
+	return m.previewOn && m.source == nil && m.branchesShown() && !m.compact() && m.width >= previewMinWidth && m.height > 0
+}
+
+// lookupPreview renders the preview of the highlighted branch in the
+// background, while the pane is shown.
+func (m Model) lookupPreview() tea.Cmd {
+	if !m.previewShown() || m.cursor >= len(m.items) {
+		return nil
+	}
+	b := m.items[m.cursor]
+	if b.HeadCommitSHA == nil || m.preview.sha == *b.HeadCommitSHA {
+		return nil
+	}
+	repo, ref, sha := m.RepoPath, b.Name, *b.HeadCommitSHA
+	return func() tea.Msg {
+		text, err := core.Preview(repo, ref, false)
+		return previewMsg{sha: sha, text: text, err: err}
+	}
+}
+
+// previewDone keeps the preview if its branch is still highlighted.
+func (m Model) previewDone(msg previewMsg) (tea.Model, tea.Cmd) {
+	if m.cursor < len(m.items) {
+		if sha := m.items[m.cursor].HeadCommitSHA; sha != nil && *sha == msg.sha {
+			m.preview = msg
+		}
+	}
+	return m, nil
+}
+
+// togglePreview shows or hides the preview pane.
+func (m Model) togglePreview() (tea.Model, tea.Cmd) {
+	m.previewOn = !m.previewOn
+	return m, m.lookupPreview()
+}
+
+// resizePreview moves the divider between the list and the preview pane a
+// step left ("<"), widening the pane, or right (">"), and saves the width.
+func (m Model) resizePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.previewOn {
+		return m, nil
+	}
+	w := m.previewWidth
+	if msg.String() == "<" {
+		w = min(w+previewStep, maxPreviewWidth)
+	} else {
+		w = max(w-previewStep, minPreviewWidth)
+	}
+	if w == m.previewWidth {
+		return m, nil
+	}
+	m.previewWidth = w
+	if m.savePreviewWidth == nil {
+		return m, nil
+	}
+	save := m.savePreviewWidth
+	return m, func() tea.Msg {
+		if err := save(w); err != nil {
+			return noticeMsg(i18n.Sprintf("saving the preview width: %v", err))
+		}
+		return nil
+	}
+}
+
+// split divides the width between the list and the preview pane.
+func (m Model) split() (list, pane int) {
+	pane = m.width * m.previewWidth / 100
+	return m.width - pane - 3, pane // 3 for " │ "
+}
+
+// previewRows renders up to limit lines of the list, narrowed to make room
+// for the preview pane to their right.
+func (m Model) previewRows(limit int) []string {
+	left, pane := m.split()
+	narrow := m
+	narrow.width = left
+	list := narrow.listRows(limit)
+	for i, line := range list {
+		list[i] = lipgloss.NewStyle().Width(left).Render(line)
+	}
+	for len(list) < limit {
+		list = append(list, strings.Repeat(" ", left))
+	}
+	var text []string
+	if m.cursor < len(m.items) {
+		if sha := m.items[m.cursor].HeadCommitSHA; sha != nil && m.preview.sha == *sha {
+			text = strings.Split(strings.TrimRight(m.preview.text, "\n"), "\n")
+			if m.preview.err != nil {
+				text = []string{m.preview.err.Error()}
+			}
+		}
+	}
+	out := make([]string, len(list))
+	for i, line := range list {
+		var right string
+		if i < len(text) {
+			right = truncate(strings.ReplaceAll(text[i], "\t", "    "), pane)
+		}
+		out[i] = line + " │ " + right
+	}
+	return out
+}
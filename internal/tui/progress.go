@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// progressBarWidth is the width of the bar drawn for progress lines with a
+// percentage, brackets excluded.
+const progressBarWidth = 20
+
+// task is a long-running git operation, such as a fetch, running in the
+// background: it sends the progress git reports, then the message with its
+// result, on ch, and stops early when canceled.
+type task struct {
+	label  string // what it does, e.g. "fetching origin"
+	ch     chan tea.Msg
+	cancel context.CancelFunc
+}
+
+// taskMsg reports that t started.
+type taskMsg struct{ t *task }
+
+// progressMsg carries a progress line of the task sending on ch.
+type progressMsg struct {
+	ch       chan tea.Msg
+	progress core.Progress
+}
+
+// runTask starts run in the background as a task described by label. Its
+// progress is shown under the list until it finishes with the message run
+// returns, and the abort key cancels it through ctx.
+func runTask(label string, run func(ctx context.Context, report func(core.Progress)) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		t := &task{label: label, ch: make(chan tea.Msg, 16), cancel: cancel}
+		go func() {
+			defer cancel()
+			report := func(p core.Progress) {
+				select {
+				case t.ch <- progressMsg{ch: t.ch, progress: p}:
+				default: // the UI is behind; it will catch up on a later line
+				}
+			}
+			t.ch <- run(ctx, report)
+			close(t.ch)
+		}()
+		return taskMsg{t: t}
+	}
+}
+
+// waitTask waits for the next message from a task.
+func waitTask(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// taskStarted shows t's progress from now on.
+func (m Model) taskStarted(msg taskMsg) (tea.Model, tea.Cmd) {
+	m.task, m.progress = msg.t, core.Progress{Percent: -1}
+	m.keys.Abort.SetEnabled(true)
+	return m, waitTask(msg.t.ch)
+}
+
+// progressed shows the latest progress line of the running task and waits
+// for the next one.
+func (m Model) progressed(msg progressMsg) (tea.Model, tea.Cmd) {
+	if m.task != nil && m.task.ch == msg.ch {
+		m.progress = msg.progress
+	}
+	return m, waitTask(msg.ch)
+}
+
+// taskDone forgets the task that just finished; its result is handled by
+// the caller.
+func (m *Model) taskDone() {
+	m.task = nil
+	m.keys.Abort.SetEnabled(false)
+}
+
+// abortTask cancels the running task; its result reports the cancellation.
+func (m Model) abortTask() (tea.Model, tea.Cmd) {
+	if m.task != nil {
+		m.task.cancel()
+		m.notice = i18n.T("canceling…")
+	}
+	return m, nil
+}
+
+// progressView describes the running task and how far git got, e.g.
+// "fetching origin… Receiving objects [████░░░░] 45% (450/1000) · esc: cancel".
+func (m Model) progressView() string {
+	s := m.spinner.View() + " " + m.task.label + "…"
+	if phase, rest, ok := m.progress.Phase(); ok {
+		filled := progressBarWidth * min(m.progress.Percent, 100) / 100
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+		s += strings.TrimRight(fmt.Sprintf(" %s [%s] %d%% %s", phase, bar, m.progress.Percent, rest), " ")
+	} else if m.progress.Line != "" {
+		s += " " + m.progress.Line
+	}
+	return s + " · " + i18n.T("esc: cancel")
+}
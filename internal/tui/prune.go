@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// PruneResult is the outcome of deleting one prune candidate. SHA is the
+// commit the branch pointed to, so it can be restored.
+type PruneResult struct {
+	Name   string
+	Reason core.PruneReason
+	SHA    string
+	Err    error
+}
+
+// Deleted reports whether the branch was deleted, which it was even if only
+// the post-delete hook failed.
+func (r PruneResult) Deleted() bool {
+	return r.Err == nil || core.PostHookFailed(r.Err)
+}
+
+// PruneModel lists prune candidates, all marked for deletion, lets the user
+// unmark the branches to keep and deletes the rest once confirmed.
+type PruneModel struct {
+	RepoPath string
+
+	candidates []core.PruneCandidate
+	marked     []bool
+	cursor     int
+	mode       mode
+	keys       keyMap
+	help       help.Model
+
+	width  int
+	height int
+
+	deleting bool
+	results  []PruneResult
+}
+
+type pruneMsg []PruneResult
+
+// NewPrune returns a PruneModel over candidates.
+func NewPrune(repoPath string, candidates []core.PruneCandidate, themeName string) PruneModel {
+	m := PruneModel{
+		RepoPath:   repoPath,
+		candidates: candidates,
+		marked:     make([]bool, len(candidates)),
+		mode:       modeMultiSelect,
+		keys:       defaultKeyMap(),
+		help:       help.New(),
+	}
+	m.help.Styles = lookupTheme(themeName).help
+	for i := range m.marked {
+		m.marked[i] = true
+	}
+	return m
+}
+
+// Results returns what was deleted, or nil if the user quit without
+// confirming.
+func (m PruneModel) Results() []PruneResult {
+	return m.results
+}
+
+func (m PruneModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PruneModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.deleting {
+			return m, nil
+		}
+		if m.mode == modeConfirm {
+			return m.updateConfirm(msg)
+		}
+		return m.updateMarking(msg)
+
+	case pruneMsg:
+		m.results = msg
+		return m, tea.Quit
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+	}
+	return m, nil
+}
+
+// updateMarking handles keys while choosing which branches to delete.
+func (m PruneModel) updateMarking(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.candidates)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, m.keys.Toggle):
+		if len(m.marked) > 0 {
+			m.marked[m.cursor] = !m.marked[m.cursor]
+		}
+	case key.Matches(msg, m.keys.ToggleAll):
+		// Mark everything unless everything is already marked.
+		all := m.markedCount() == len(m.marked)
+		for i := range m.marked {
+			m.marked[i] = !all
+		}
+	case key.Matches(msg, m.keys.Submit):
+		if m.markedCount() > 0 {
+			m.mode = modeConfirm
+		}
+	}
+	return m, nil
+}
+
+// updateConfirm handles the final yes/no before anything is deleted.
+func (m PruneModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Yes):
+		m.deleting = true
+		return m, m.prune()
+	case key.Matches(msg, m.keys.No):
+		m.mode = modeMultiSelect
+	}
+	return m, nil
+}
+
+// prune deletes the marked branches. Merged branches are deleted safely;
+// gone and stale ones usually are not merged, so they are forced, which the
+// user has just confirmed. The SHAs in the results allow undoing it.
+func (m PruneModel) prune() tea.Cmd {
+	var todo []core.PruneCandidate
+	for i, c := range m.candidates {
+		if m.marked[i] {
+			todo = append(todo, c)
+		}
+	}
+	return func() tea.Msg {
+		res := make([]PruneResult, 0, len(todo))
+		for _, c := range todo {
+			force := c.Reason != core.PruneMerged
+			sha, err := core.DeleteBranch(m.RepoPath, c.Branch.Name, force)
+			res = append(res, PruneResult{Name: c.Branch.Name, Reason: c.Reason, SHA: sha, Err: err})
+		}
+		return pruneMsg(res)
+	}
+}
+
+func (m PruneModel) markedCount() int {
+	n := 0
+	for _, ok := range m.marked {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+func (m PruneModel) View() string {
+	var b strings.Builder
+	b.WriteString(i18n.Sprintf("Prune branches: %d of %d marked for deletion", m.markedCount(), len(m.candidates)) + "\n\n")
+
+	// Header, blank line, blank line before the status and the help footer.
+	limit := len(m.candidates)
+	if m.height > 0 {
+		limit = max(m.height-5, 1)
+	}
+	start := 0
+	if m.cursor >= limit {
+		start = m.cursor - limit + 1
+	}
+	end := min(start+limit, len(m.candidates))
+	for i := start; i < end; i++ {
+		c := m.candidates[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		box := "[ ]"
+		if m.marked[i] {
+			box = "[x]"
+		}
+		line := fmt.Sprintf("%s%s %-6s %s", cursor, box, c.Reason, c.Branch.Name)
+		b.WriteString(truncate(line, m.width) + "\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.deleting:
+		b.WriteString(i18n.T("Deleting…") + "\n")
+	case m.mode == modeConfirm:
+		b.WriteString(i18n.Sprintf("Delete %d branches? ", m.markedCount()))
+	}
+	b.WriteString(m.help.View(localizedKeys{modeKeys{keys: m.keys, mode: m.mode}}))
+	return b.String()
+}
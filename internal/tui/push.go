@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// pushMsg reports a push of branch.
+type pushMsg struct {
+	branch      string
+	upstream    bool // the push set the branch's upstream
+	force       bool
+	interactive bool // git had the terminal to prompt on
+	err         error
+}
+
+// push pushes branch in the background, without letting git prompt; see
+// fetch.
+func (m Model) push(branch string, upstream, force bool) tea.Cmd {
+	repo := m.RepoPath
+	return func() tea.Msg {
+		err := core.PushNoPrompt(repo, branch, upstream, force)
+		return pushMsg{branch: branch, upstream: upstream, force: force, err: err}
+	}
+}
+
+// pushInteractively hands the terminal to git for a push that needs
+// credentials, like fetchInteractively.
+func (m Model) pushInteractively(msg pushMsg) tea.Cmd {
+	cmd, err := core.PushCommand(m.RepoPath, msg.branch, msg.upstream, msg.force)
+	if err != nil {
+		return func() tea.Msg { return pushMsg{branch: msg.branch, interactive: true, err: err} }
+	}
+	repo := m.RepoPath
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err == nil {
+			core.Pushed(repo, msg.branch, msg.force)
+		}
+		msg.err, msg.interactive = err, true
+		return msg
+	})
+}
+
+// startPush pushes the highlighted branch, setting its upstream when it has
+// none.
+func (m Model) startPush() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 || m.pushing != "" {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	if b.IsRemote {
+		m.error = i18n.Errorf("%s is a remote branch", b.Name)
+		return m, nil
+	}
+	m.error, m.notice = nil, ""
+	m.pushing = b.Name
+	return m, tea.Batch(m.spinner.Tick, m.push(b.Name, b.Upstream == nil, false))
+}
+
+// pushed handles the outcome of a push.
+func (m Model) pushed(msg pushMsg) (tea.Model, tea.Cmd) {
+	if errors.Is(msg.err, core.ErrAuthRequired) && !msg.interactive {
+		return m, m.pushInteractively(msg)
+	}
+	m.pushing = ""
+	switch {
+	case errors.Is(msg.err, core.ErrTimeout):
+		m.error = msg.err
+		m.offerRetry(msg.err, func(m *Model) tea.Cmd {
+			m.pushing = msg.branch
+			return tea.Batch(m.spinner.Tick, m.push(msg.branch, msg.upstream, msg.force))
+		})
+		return m, nil
+	case core.ErrorKind(msg.err) == core.KindNotFastForward && !msg.force:
+		m.error = msg.err
+		m.mode = modeForcePush
+		m.forcePush = msg
+		return m, nil
+	case msg.err != nil:
+		m.error = msg.err
+		return m, nil
+	case msg.upstream:
+		m.notice = i18n.Sprintf("pushed %s and set its upstream", msg.branch)
+	default:
+		m.notice = i18n.Sprintf("pushed %s", msg.branch)
+	}
+	return m, m.refreshList()
+}
+
+// updateForcePush handles the answer to whether to force a rejected push.
+func (m Model) updateForcePush(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.No):
+		m.mode = modeSelect
+		m.error = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Yes):
+		m.mode = modeSelect
+		m.error = nil
+		p := m.forcePush
+		m.pushing = p.branch
+		return m, tea.Batch(m.spinner.Tick, m.push(p.branch, p.upstream, true))
+	}
+	return m, nil
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+const (
+	// remedyFiles is how many of the files blocking a switch are listed.
+	remedyFiles = 8
+
+	// remedyWidth is the widest the remedy dialog gets.
+	remedyWidth = 76
+)
+
+// remedyRows renders the dialog offering ways out of a failed switch in
+// place of the list: why git refused, then the choices.
+func (m Model) remedyRows(limit int) []string {
+	width := min(m.width, remedyWidth) - 4 // border and padding
+	if m.width == 0 {
+		width = remedyWidth - 4
+	}
+	var lines []string
+	add := func(s string) { lines = append(lines, truncate(s, width)) }
+	files := m.remedyErr.Files()
+	switch {
+	case m.remedyErr.Kind == core.KindLocalChanges && strings.Contains(m.remedyErr.Output, "untracked working tree files"):
+		add(i18n.T("Untracked files would be overwritten:"))
+	case m.remedyErr.Kind == core.KindLocalChanges:
+		add(i18n.T("Your local changes would be overwritten:"))
+	default:
+		add(gitReason(m.remedyErr.Output))
+		files = nil
+	}
+	for i, f := range files {
+		if i == remedyFiles {
+			add(i18n.Sprintf("  …and %d more", len(files)-i))
+			break
+		}
+		add("  " + f)
+	}
+	add("")
+	for _, k := range (modeKeys{keys: m.keys, mode: modeRemedy}).ShortHelp() {
+		if !k.Enabled() {
+			continue
+		}
+		desc := i18n.T(k.Help().Desc)
+		if k.Help().Key == m.keys.Back.Help().Key {
+			desc = i18n.T("cancel")
+		}
+		add(fmt.Sprintf("%-4s %s", k.Help().Key, desc))
+	}
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Render(strings.Join(lines, "\n"))
+	out := strings.Split(box, "\n")
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// gitReason picks the line of git's output saying why it failed.
+func gitReason(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		for _, p := range []string{"error: ", "fatal: "} {
+			if rest, ok := strings.CutPrefix(line, p); ok {
+				return rest
+			}
+		}
+	}
+	return strings.TrimSpace(output)
+}
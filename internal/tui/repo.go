@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// reposMsg carries the repositories the repository switcher offers.
+type reposMsg struct {
+	repos []string
+	err   error
+}
+
+// startRepos opens the list of known repositories to switch the picker to.
+func (m Model) startRepos() (tea.Model, tea.Cmd) {
+	m.mode = modeRepo
+	m.error, m.notice = nil, ""
+	m.repos, m.repoCursor = nil, 0
+	list, repo := m.listRepos, m.RepoPath
+	return m, func() tea.Msg {
+		repos, err := list()
+		if err != nil {
+			return reposMsg{err: err}
+		}
+		// Leave out the repository shown already.
+		cur, _ := core.TopLevel(repo)
+		res := make([]string, 0, len(repos))
+		for _, r := range repos {
+			if r != cur {
+				res = append(res, r)
+			}
+		}
+		return reposMsg{repos: res}
+	}
+}
+
+// reposLoaded shows the repositories found.
+func (m Model) reposLoaded(msg reposMsg) (tea.Model, tea.Cmd) {
+	if m.mode != modeRepo {
+		return m, nil
+	}
+	m.repos, m.error = msg.repos, msg.err
+	return m, nil
+}
+
+// updateRepos handles keys in the repository switcher.
+func (m Model) updateRepos(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.error = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.repoCursor > 0 {
+			m.repoCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.repoCursor < len(m.repos)-1 {
+			m.repoCursor++
+		}
+	case key.Matches(msg, m.keys.Open):
+		if m.repoCursor < len(m.repos) {
+			return m.openRepo(m.repos[m.repoCursor])
+		}
+	}
+	return m, nil
+}
+
+// openRepo replaces the picker with one for the repository at dir, which
+// starts over: its list, filter and cursor are those of a fresh start.
+func (m Model) openRepo(dir string) (tea.Model, tea.Cmd) {
+	opts, err := m.repoOptions(dir)
+	if err != nil {
+		m.error = err
+		return m, nil
+	}
+	next := New(opts)
+	next.keys.PrevTab.SetEnabled(m.keys.PrevTab.Enabled())
+	next.keys.NextTab.SetEnabled(m.keys.NextTab.Enabled())
+	size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+	return next, tea.Batch(next.Init(), func() tea.Msg { return size })
+}
+
+// repoRows renders the repository switcher like rows renders branches.
+func (m Model) repoRows(limit int) []string {
+	from, to := 0, len(m.repos)
+	if (m.width > 0 || m.height > 0) && limit < len(m.repos) {
+		limit = max(limit, 1)
+		from = max(m.repoCursor-limit+1, 0)
+		to = from + limit
+	}
+	home, _ := os.UserHomeDir()
+	lines := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		prefix := "  "
+		if i == m.repoCursor {
+			prefix = "> "
+		}
+		path := m.repos[i]
+		if home != "" && strings.HasPrefix(path, home+string(filepath.Separator)) {
+			path = "~" + path[len(home):]
+		}
+		lines = append(lines, m.truncate(fmt.Sprintf("%s%-20s %s", prefix, filepath.Base(m.repos[i]), path)))
+	}
+	if len(lines) == 0 && m.repos != nil {
+		lines = append(lines, "  "+i18n.T("no other repositories known yet"))
+	}
+	return lines
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// resetPlanMsg carries what resetting a branch to its upstream would do.
+type resetPlanMsg struct {
+	plan core.UpstreamReset
+	err  error
+}
+
+// resetMsg reports a reset of a branch to its upstream.
+type resetMsg struct {
+	plan core.UpstreamReset
+	err  error
+}
+
+// planReset looks up what resetting the highlighted local branch to its
+// upstream would drop, to ask before doing it.
+func (m Model) planReset() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	switch {
+	case b.IsRemote:
+		m.error = i18n.Errorf("%s is a remote branch", b.Name)
+		return m, nil
+	case b.Upstream == nil:
+		m.error = i18n.Errorf("%s has no upstream to reset to", b.Name)
+		return m, nil
+	}
+	m.error, m.notice = nil, ""
+	repo := m.RepoPath
+	return m, func() tea.Msg {
+		plan, err := core.PlanUpstreamReset(repo, b.Name)
+		return resetPlanMsg{plan: plan, err: err}
+	}
+}
+
+// confirmReset asks whether to carry out the planned reset.
+func (m Model) confirmReset(msg resetPlanMsg) (tea.Model, tea.Cmd) {
+	p := msg.plan
+	switch {
+	case msg.err != nil:
+		m.error = msg.err
+		return m, nil
+	case p.From == p.To:
+		m.notice = i18n.Sprintf("%s is already at %s", p.Branch, p.Upstream)
+		return m, nil
+	}
+	q := i18n.Sprintf("Reset %s to %s (%s)", p.Branch, p.Upstream, shortSHA(p.To))
+	if p.Dropped > 0 {
+		q += i18n.Sprintf(", dropping %d commit(s) only on %s", p.Dropped, p.Branch)
+	}
+	q += "?"
+	if m.current() == p.Branch {
+		q += " " + i18n.T("Uncommitted changes are discarded too.")
+	}
+	repo := m.RepoPath
+	m.ask(q, func(m *Model) tea.Cmd {
+		return func() tea.Msg { return resetMsg{plan: p, err: core.ResetToUpstream(repo, p)} }
+	})
+	return m, nil
+}
+
+// reset handles the outcome of a reset to upstream.
+func (m Model) reset(msg resetMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.error = msg.err
+		return m, nil
+	}
+	m.notice = i18n.Sprintf("reset %s to %s (was %s)", msg.plan.Branch, msg.plan.Upstream, shortSHA(msg.plan.From))
+	return m, m.refreshList()
+}
+
+// ask switches to confirm mode with question q; yes runs if the user agrees.
+func (m *Model) ask(q string, yes func(m *Model) tea.Cmd) {
+	m.mode = modeConfirm
+	m.question = q
+	m.onYes = yes
+}
+
+// updateConfirm handles the answer to the question asked by ask.
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.No):
+		m.mode = modeSelect
+		m.onYes = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Yes):
+		m.mode = modeSelect
+		yes := m.onYes
+		m.onYes = nil
+		return m, yes(&m)
+	}
+	return m, nil
+}
+
+// current returns the name of the checked-out branch among the items, or "".
+func (m Model) current() string {
+	for _, b := range m.items {
+		if b.IsCurrent && !b.IsRemote {
+			return b.Name
+		}
+	}
+	return ""
+}
+
+// shortSHA abbreviates sha for display.
+func shortSHA(sha string) string {
+	return sha[:min(7, len(sha))]
+}
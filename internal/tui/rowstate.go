@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// rowState is what a row is colored by, see theme.state.
+type rowState int
+
+const (
+	rowPlain    rowState = iota
+	rowCurrent           // checked out
+	rowGone              // its upstream was deleted on the remote
+	rowDiverged          // both ahead of and behind its upstream
+	rowAhead             // has commits to push
+	rowBehind            // has commits to pull
+	rowStale             // no commits for longer than staleAfter
+)
+
+// glyph marks rows in state s, so that no theme tells states apart by
+// color alone. The current branch has its "*" already.
+func (s rowState) glyph() string {
+	switch s {
+	case rowGone:
+		return "⊘"
+	case rowDiverged:
+		return "⇅"
+	case rowAhead:
+		return "↑"
+	case rowBehind:
+		return "↓"
+	case rowStale:
+		return "~"
+	}
+	return " "
+}
+
+// rowState tells the state of b, the first that applies in the order of
+// the constants: being checked out matters more than being stale.
+func (m Model) rowState(b core.Branch) rowState {
+	switch t := b.Tracking; {
+	case b.IsCurrent:
+		return rowCurrent
+	case t != nil && t.Gone:
+		return rowGone
+	case t != nil && t.Ahead > 0 && t.Behind > 0:
+		return rowDiverged
+	case t != nil && t.Ahead > 0:
+		return rowAhead
+	case t != nil && t.Behind > 0:
+		return rowBehind
+	case m.staleAfter > 0 && b.HeadCommitAt != nil && time.Since(*b.HeadCommitAt) > m.staleAfter:
+		return rowStale
+	}
+	return rowPlain
+}
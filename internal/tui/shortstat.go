@@ -0,0 +1,57 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// shortStatMsg carries what the branch at commit sha changes relative to
+// the default branch, base.
+type shortStatMsg struct {
+	sha  string
+	base string
+	stat core.ShortStat
+	err  error
+}
+
+// lookupShortStat sums up the changes of the highlighted branch in the
+// background, for details. Only the highlighted branch is looked up, as the
+// cursor reaches it or the list is refreshed; core remembers the results.
+func (m Model) lookupShortStat() tea.Cmd {
+	if m.source != nil || m.cursor >= len(m.items) {
+		return nil
+	}
+	b := m.items[m.cursor]
+	if b.HeadCommitSHA == nil {
+		return nil
+	}
+	repo, sha := m.RepoPath, *b.HeadCommitSHA
+	return func() tea.Msg {
+		stat, base, err := core.BranchShortStat(repo, sha)
+		return shortStatMsg{sha: sha, base: base, stat: stat, err: err}
+	}
+}
+
+// shortStatDone keeps the summary if its branch is still highlighted.
+func (m Model) shortStatDone(msg shortStatMsg) (tea.Model, tea.Cmd) {
+	if m.cursor < len(m.items) {
+		if sha := m.items[m.cursor].HeadCommitSHA; sha != nil && *sha == msg.sha {
+			m.shortStat = msg
+		}
+	}
+	return m, nil
+}
+
+// shortStatDetail describes the changes of b for details, once looked up.
+func (m Model) shortStatDetail(b core.Branch) string {
+	s := m.shortStat
+	if b.HeadCommitSHA == nil || s.sha != *b.HeadCommitSHA || s.err != nil {
+		return ""
+	}
+	if s.stat == (core.ShortStat{}) {
+		return i18n.Sprintf("no changes vs %s", s.base)
+	}
+	return i18n.Sprintf("%s vs %s", s.stat, s.base)
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// sortColumn is a column of the header that the list can be sorted by.
+type sortColumn struct {
+	title string
+	by    string // core.ListBranchesRequest.SortBy
+	dir   string // the direction it starts in
+	key   string // the function key that sorts by it
+}
+
+// sortColumns are the header's columns, in order.
+var sortColumns = []sortColumn{
+	{title: "Name", by: "name", dir: "asc", key: "f1"},
+	{title: "Age", by: "recency", dir: "desc", key: "f2"},
+	{title: "Commits", by: "commits", dir: "desc", key: "f3"},
+	{title: "Used", by: "frecency", dir: "desc", key: "f4"},
+}
+
+// headerSpan is where a column's title is drawn in the header line.
+type headerSpan struct {
+	col      sortColumn
+	from, to int // terminal columns
+}
+
+// sortColumnFor returns the column sorting by by.
+func sortColumnFor(by string) sortColumn {
+	for _, c := range sortColumns {
+		if c.by == by {
+			return c
+		}
+	}
+	return sortColumn{by: by}
+}
+
+// header lays out the header line: the titles of the sortable columns, the
+// one sorted by marked with an arrow for its direction. The table layout
+// has a title over each of its columns instead.
+func (m Model) header() (string, []headerSpan) {
+	if m.table {
+		return m.tableHeader()
+	}
+	var b strings.Builder
+	b.WriteString("   ")
+	var spans []headerSpan
+	for i, c := range sortColumns {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		title := i18n.T(c.title)
+		if c.by == m.sortBy {
+			title += map[string]string{"asc": " ↑", "desc": " ↓"}[m.sortDir]
+		}
+		from := runewidth.StringWidth(b.String())
+		b.WriteString(title)
+		spans = append(spans, headerSpan{col: c, from: from, to: from + runewidth.StringWidth(title)})
+	}
+	return b.String(), spans
+}
+
+// headerView renders the header line, the sorted column bold.
+func (m Model) headerView() string {
+	line, spans := m.header()
+	rs := []rune(line)
+	var b strings.Builder
+	at := 0
+	for _, s := range spans {
+		if s.col.by != m.sortBy {
+			continue
+		}
+		from, to := runeAt(rs, s.from), runeAt(rs, s.to)
+		b.WriteString(string(rs[at:from]))
+		b.WriteString(m.theme.sorted.Render(string(rs[from:to])))
+		at = to
+	}
+	b.WriteString(string(rs[at:]))
+	return b.String()
+}
+
+// runeAt returns the index of the first of rs drawn at terminal column col
+// or after it.
+func runeAt(rs []rune, col int) int {
+	w := 0
+	for i, r := range rs {
+		if w >= col {
+			return i
+		}
+		w += runewidth.RuneWidth(r)
+	}
+	return len(rs)
+}
+
+// showHeader reports whether the header line is drawn: over the branches of
+// the repository, which a picker over given items is not, and with room.
+func (m Model) showHeader() bool {
+	return m.source == nil && m.branchesShown() && !m.compact()
+}
+
+// headerY is the screen row of the header line; see View.
+func (m Model) headerY() int {
+	if m.error != nil {
+		return 4
+	}
+	return 2
+}
+
+// sortByColumn sorts the list by c, or reverses it if it already is.
+func (m Model) sortByColumn(c sortColumn) (tea.Model, tea.Cmd) {
+	if m.sortBy == c.by {
+		m.sortDir = map[string]string{"asc": "desc", "desc": "asc"}[m.sortDir]
+	} else {
+		m.sortBy, m.sortDir = c.by, c.dir
+	}
+	m.paginator.Page = 0
+	m.cursor = 0
+	return m, m.refreshList()
+}
+
+// sortKey sorts by the column whose function key msg is.
+func (m Model) sortKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	for _, c := range sortColumns {
+		if msg.String() == c.key {
+			return m.sortByColumn(c)
+		}
+	}
+	return m, nil
+}
+
+// updateMouse sorts by the column whose title is clicked and moves the
+// cursor, or scrolls the list, with the wheel.
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.mode != modeSelect && m.mode != modeFilter {
+		return m, nil
+	}
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp && msg.Action == tea.MouseActionPress:
+		return m.wheel(-m.scrollLines)
+	case msg.Button == tea.MouseButtonWheelDown && msg.Action == tea.MouseActionPress:
+		return m.wheel(m.scrollLines)
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionRelease:
+		if !m.showHeader() || msg.Y != m.headerY() {
+			return m, nil
+		}
+		_, spans := m.header()
+		for _, s := range spans {
+			if msg.X >= s.from && msg.X < s.to {
+				return m.sortByColumn(s.col)
+			}
+		}
+	}
+	return m, nil
+}
+
+// wheel moves the cursor by n rows, or scrolls the list by as many with
+// ScrollView, within the page.
+func (m Model) wheel(n int) (tea.Model, tea.Cmd) {
+	if m.scrollView {
+		m.scroll = min(max(m.scroll+n, -m.cursor), len(m.items)-1-m.cursor)
+		return m, nil
+	}
+	cursor := min(max(m.cursor+n, 0), max(len(m.items)-1, 0))
+	if cursor == m.cursor {
+		return m, nil
+	}
+	m.cursor = cursor
+	return m, m.lookupHighlighted()
+}
@@ -0,0 +1,24 @@
+package tui
+
+import "testing"
+
+func TestRuneAt(t *testing.T) {
+	// 名前 takes four columns, as a translated title may.
+	rs := []rune("  名前 ↑  Age")
+	tests := []struct{ col, want int }{
+		{0, 0},
+		{2, 2},  // 名
+		{3, 3},  // within 名, so the rune after it
+		{4, 3},  // 前
+		{6, 4},  // the space after 前
+		{7, 5},  // ↑
+		{10, 8}, // A
+		{13, 11},
+		{20, 11},
+	}
+	for _, tt := range tests {
+		if got := runeAt(rs, tt.col); got != tt.want {
+			t.Errorf("runeAt(%q, %d) = %d, want %d", string(rs), tt.col, got, tt.want)
+		}
+	}
+}
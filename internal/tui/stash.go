@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// stashesMsg carries the stash list for the stash pane.
+type stashesMsg struct {
+	stashes []core.Stash
+	err     error
+}
+
+// stashDoneMsg reports an apply, pop or drop from the stash pane.
+type stashDoneMsg struct {
+	what string // e.g. "applied stash@{0}"
+	err  error
+}
+
+// stashOfferMsg reports the stash left on a branch just switched to, if
+// any (see core.StashFor).
+type stashOfferMsg struct {
+	stash core.Stash
+	ok    bool
+}
+
+func (m Model) loadStashes() tea.Cmd {
+	return func() tea.Msg {
+		stashes, err := core.Stashes(m.RepoPath)
+		return stashesMsg{stashes: stashes, err: err}
+	}
+}
+
+// findStash looks for a stash to offer after switching to name.
+func (m Model) findStash(name string) tea.Cmd {
+	return func() tea.Msg {
+		s, ok := core.StashFor(m.RepoPath, name)
+		return stashOfferMsg{stash: s, ok: ok}
+	}
+}
+
+// updateStash handles keys in the stash pane.
+func (m Model) updateStash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Back):
+		m.mode = modeSelect
+		m.error = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Up):
+		if m.stashCursor > 0 {
+			m.stashCursor--
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		if m.stashCursor < len(m.stashes)-1 {
+			m.stashCursor++
+		}
+		return m, nil
+	}
+	if m.stashCursor >= len(m.stashes) {
+		return m, nil
+	}
+	s := m.stashes[m.stashCursor]
+	var (
+		what string
+		run  func() error
+	)
+	switch {
+	case key.Matches(msg, m.keys.StashApply):
+		what, run = i18n.Sprintf("applied %s", s.Ref()), func() error { return core.ApplyStash(m.RepoPath, s, false) }
+	case key.Matches(msg, m.keys.StashPop):
+		what, run = i18n.Sprintf("popped %s", s.Ref()), func() error { return core.ApplyStash(m.RepoPath, s, true) }
+	case key.Matches(msg, m.keys.StashDrop):
+		what, run = i18n.Sprintf("dropped %s", s.Ref()), func() error { return core.DropStash(m.RepoPath, s) }
+	default:
+		return m, nil
+	}
+	m.error = nil
+	return m, func() tea.Msg {
+		return stashDoneMsg{what: what, err: run()}
+	}
+}
+
+// updateApplyStash handles the answer to the offer to apply the stash left
+// on the branch just switched to. Either way the picker is done.
+func (m Model) updateApplyStash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "ctrl+c", key.Matches(msg, m.keys.No):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Yes):
+		s := m.offered
+		return m, func() tea.Msg {
+			if err := core.ApplyStash(m.RepoPath, s, true); err != nil {
+				return stashDoneMsg{err: err}
+			}
+			return tea.Quit()
+		}
+	}
+	return m, nil
+}
+
+// stashRows renders the stash pane like rows renders branches.
+func (m Model) stashRows(limit int) []string {
+	from, to := 0, len(m.stashes)
+	if (m.width > 0 || m.height > 0) && limit < len(m.stashes) {
+		limit = max(limit, 1)
+		from = max(m.stashCursor-limit+1, 0)
+		to = from + limit
+	}
+	now := time.Now()
+	lines := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		s := m.stashes[i]
+		prefix := "  "
+		if i == m.stashCursor {
+			prefix = "> "
+		}
+		branch := s.Branch
+		if branch == "" {
+			branch = i18n.T("(detached)")
+		}
+		var age string
+		if s.At != nil {
+			age = tmpl.Age(*s.At, now)
+		}
+		lines = append(lines, m.truncate(fmt.Sprintf("%s%-10s %-4s %s: %s", prefix, s.Ref(), age, branch, s.Message)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  "+i18n.T("no stashes"))
+	}
+	return lines
+}
+
+// stashOffer is the question asked after switching to a branch with a
+// stash left on it.
+func (m Model) stashOffer() string {
+	return i18n.Sprintf("Changes were stashed when leaving %s (%s). Restore them?", m.offered.Branch, m.offered.Ref())
+}
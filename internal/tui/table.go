@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// tableColumn is a column of the table layout.
+type tableColumn struct {
+	title string
+	width int    // terminal columns; 0 takes the rest of the line
+	by    string // the sort it stands for; "" if it cannot be sorted by
+	cell  func(r tmpl.Row) string
+}
+
+// tableIndent is the width of the "   3. " before the cells of a row.
+const tableIndent = 3 + 5
+
+// tableColumns are the columns of the table layout: Commits only when
+// commits are counted, and the extra columns before Subject.
+func (m Model) tableColumns() []tableColumn {
+	cols := []tableColumn{
+		{title: i18n.T("Name"), width: 32, by: "name", cell: func(r tmpl.Row) string {
+			if r.IsCurrent {
+				return "* " + r.Name
+			}
+			return r.Name
+		}},
+		{title: i18n.T("Age"), width: 5, by: "recency", cell: func(r tmpl.Row) string { return r.Age }},
+		{title: "↑↓", width: 7, cell: func(r tmpl.Row) string { return r.Divergence }},
+	}
+	if m.commits {
+		cols = append(cols, tableColumn{title: i18n.T("Commits"), width: 7, by: "commits", cell: func(r tmpl.Row) string { return r.CommitCount }})
+	}
+	for _, c := range m.columns {
+		id := c.ID
+		cols = append(cols, tableColumn{title: c.Title, width: max(c.Width, 1), cell: func(r tmpl.Row) string { return r.Column(id) }})
+	}
+	return append(cols, tableColumn{title: i18n.T("Subject"), cell: func(r tmpl.Row) string { return r.Subject }})
+}
+
+// tableRow renders r in the table layout, each cell cut or padded to its
+// column, so the rows line up under the header.
+func (m Model) tableRow(r tmpl.Row) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%3d. ", r.Index)
+	for i, c := range m.tableColumns() {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		if c.width == 0 {
+			b.WriteString(c.cell(r))
+			continue
+		}
+		b.WriteString(runewidth.FillRight(runewidth.Truncate(c.cell(r), c.width, "…"), c.width))
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// tableHeader lays out the header line of the table layout, each title
+// over its column.
+func (m Model) tableHeader() (string, []headerSpan) {
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", tableIndent))
+	at := tableIndent
+	var spans []headerSpan
+	for i, c := range m.tableColumns() {
+		if i > 0 {
+			b.WriteString("  ")
+			at += 2
+		}
+		title := c.title
+		if c.by != "" && c.by == m.sortBy {
+			title += map[string]string{"asc": " ↑", "desc": " ↓"}[m.sortDir]
+		}
+		w := runewidth.StringWidth(title)
+		if c.by != "" {
+			spans = append(spans, headerSpan{col: sortColumnFor(c.by), from: at, to: at + w})
+		}
+		if c.width > 0 {
+			title = runewidth.FillRight(title, c.width)
+			w = c.width
+		}
+		b.WriteString(title)
+		at += w
+	}
+	return strings.TrimRight(b.String(), " "), spans
+}
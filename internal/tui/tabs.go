@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Tabs shows a picker per repository, one at a time, with a line of tabs
+// above it; [ and ] switch between them. Every picker keeps its own list,
+// filter and cursor, and its background work (listing, fetching) carries on
+// while another tab is shown.
+type Tabs struct {
+	tabs   []Model
+	active int
+}
+
+// tabMsg carries a message produced by the commands of tab.
+type tabMsg struct {
+	tab int
+	msg tea.Msg
+}
+
+// tabQuitMsg reports that tab asked to quit, e.g. after a switch.
+type tabQuitMsg struct{ tab int }
+
+// teaPackage is where Bubble Tea's own messages (quitting, running a
+// process, batches) are defined; the program has to see those itself.
+var teaPackage = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// NewTabs creates a picker for each of opts, showing the first.
+func NewTabs(opts []Options) Tabs {
+	t := Tabs{tabs: make([]Model, len(opts))}
+	for i, o := range opts {
+		m := New(o)
+		m.keys.PrevTab.SetEnabled(len(opts) > 1)
+		m.keys.NextTab.SetEnabled(len(opts) > 1)
+		t.tabs[i] = m
+	}
+	return t
+}
+
+// Models returns the pickers, in the order of the options they were
+// created from.
+func (t Tabs) Models() []Model {
+	return t.tabs
+}
+
+// Active returns the index of the picker shown last, the one that quit.
+func (t Tabs) Active() int {
+	return t.active
+}
+
+func (t Tabs) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, len(t.tabs))
+	for i, m := range t.tabs {
+		cmds[i] = forTab(i, m.Init())
+	}
+	return tea.Batch(cmds...)
+}
+
+// forTab wraps cmd so that the messages it produces reach tab i.
+func forTab(i int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			for j, c := range msg {
+				msg[j] = forTab(i, c)
+			}
+			return msg
+		case tea.QuitMsg:
+			return tabQuitMsg{tab: i}
+		default:
+			if reflect.TypeOf(msg).PkgPath() == teaPackage {
+				// Messages arising from these, such as the end of a
+				// process run with ExecProcess, go to the active tab,
+				// which cannot change meanwhile.
+				return msg
+			}
+			return tabMsg{tab: i, msg: msg}
+		}
+	}
+}
+
+// update passes msg to tab i.
+func (t Tabs) update(i int, msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := t.tabs[i].Update(msg)
+	t.tabs[i] = m.(Model)
+	return t, forTab(i, cmd)
+}
+
+func (t Tabs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tabMsg:
+		return t.update(msg.tab, msg.msg)
+	case tabQuitMsg:
+		t.active = msg.tab
+		return t, tea.Quit
+	case tea.WindowSizeMsg:
+		msg.Height-- // the tab line
+		cmds := make([]tea.Cmd, len(t.tabs))
+		for i := range t.tabs {
+			var m tea.Model
+			m, cmds[i] = t.tabs[i].Update(msg)
+			t.tabs[i] = m.(Model)
+			cmds[i] = forTab(i, cmds[i])
+		}
+		return t, tea.Batch(cmds...)
+	case tea.MouseMsg:
+		if msg.Y == 0 {
+			return t, nil
+		}
+		msg.Y--
+		return t.update(t.active, msg)
+	case tea.KeyMsg:
+		m := t.tabs[t.active]
+		if m.mode == modeSelect && m.count == 0 {
+			switch {
+			case key.Matches(msg, m.keys.PrevTab):
+				t.active = (t.active + len(t.tabs) - 1) % len(t.tabs)
+				return t, nil
+			case key.Matches(msg, m.keys.NextTab):
+				t.active = (t.active + 1) % len(t.tabs)
+				return t, nil
+			}
+		}
+	}
+	return t.update(t.active, msg)
+}
+
+func (t Tabs) View() string {
+	m := t.tabs[t.active]
+	names := make([]string, len(t.tabs))
+	for i, tab := range t.tabs {
+		name := " " + filepath.Base(tab.RepoPath) + " "
+		if i == t.active {
+			name = lipgloss.NewStyle().Reverse(true).Render(name)
+		}
+		names[i] = name
+	}
+	line := strings.Join(names, "│")
+	if m.width > 0 {
+		line = lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+	}
+	return line + "\n" + m.View()
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// theme holds the styles the view draws with.
+type theme struct {
+	help   help.Styles
+	warn   lipgloss.Style // rows needing attention, e.g. off the naming policy
+	match  lipgloss.Style // the characters of a name the filter matched
+	sorted lipgloss.Style // the header of the column sorted by
+
+	// state styles rows by their rowState; rows in other states are
+	// unstyled.
+	state map[rowState]lipgloss.Style
+}
+
+// themes are the built-in color themes, selectable by name.
+var themes = map[string]func() theme{
+	"default": func() theme {
+		return theme{
+			help:   help.New().Styles,
+			warn:   lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#B35C00", Dark: "#F5B041"}),
+			match:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#0969DA", Dark: "#58A6FF"}),
+			sorted: lipgloss.NewStyle().Bold(true),
+			state: map[rowState]lipgloss.Style{
+				rowCurrent:  lipgloss.NewStyle().Bold(true),
+				rowGone:     lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#CF222E", Dark: "#F85149"}),
+				rowDiverged: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#8250DF", Dark: "#BC8CFF"}),
+				rowAhead:    lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#1A7F37", Dark: "#3FB950"}),
+				rowBehind:   lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9A6700", Dark: "#D29922"}),
+				rowStale:    lipgloss.NewStyle().Faint(true),
+			},
+		}
+	},
+	// deuteranopia keeps to the Okabe-Ito palette, whose colors stay
+	// apart with red-green color blindness: blue and orange instead of
+	// green and red.
+	"deuteranopia": func() theme {
+		return theme{
+			help:   help.New().Styles,
+			warn:   lipgloss.NewStyle().Foreground(lipgloss.Color("#D55E00")),
+			match:  lipgloss.NewStyle().Bold(true).Underline(true),
+			sorted: lipgloss.NewStyle().Bold(true),
+			state: map[rowState]lipgloss.Style{
+				rowCurrent:  lipgloss.NewStyle().Bold(true),
+				rowGone:     lipgloss.NewStyle().Foreground(lipgloss.Color("#D55E00")),
+				rowDiverged: lipgloss.NewStyle().Foreground(lipgloss.Color("#CC79A7")),
+				rowAhead:    lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0072B2", Dark: "#56B4E9"}),
+				rowBehind:   lipgloss.NewStyle().Foreground(lipgloss.Color("#E69F00")),
+				rowStale:    lipgloss.NewStyle().Faint(true),
+			},
+		}
+	},
+	// high-contrast uses the terminal's bright colors, in bold, and
+	// full black or white for text instead of grays.
+	"high-contrast": func() theme {
+		text := lipgloss.AdaptiveColor{Light: "0", Dark: "15"}
+		bold := lipgloss.NewStyle().Bold(true)
+		return theme{
+			help: help.Styles{
+				Ellipsis:       bold.Foreground(text),
+				ShortKey:       bold.Foreground(text),
+				ShortDesc:      lipgloss.NewStyle().Foreground(text),
+				ShortSeparator: bold.Foreground(text),
+				FullKey:        bold.Foreground(text),
+				FullDesc:       lipgloss.NewStyle().Foreground(text),
+				FullSeparator:  bold.Foreground(text),
+			},
+			warn:   bold.Foreground(lipgloss.Color("11")).Underline(true),
+			match:  bold.Reverse(true),
+			sorted: bold.Underline(true),
+			state: map[rowState]lipgloss.Style{
+				rowCurrent:  bold.Foreground(text),
+				rowGone:     bold.Foreground(lipgloss.Color("9")),
+				rowDiverged: bold.Foreground(lipgloss.Color("13")),
+				rowAhead:    bold.Foreground(lipgloss.Color("10")),
+				rowBehind:   bold.Foreground(lipgloss.Color("11")),
+				rowStale:    lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+			},
+		}
+	},
+	// mono uses no colors at all, for terminals or users that prefer it.
+	"mono": func() theme {
+		plain := lipgloss.NewStyle()
+		return theme{help: help.Styles{
+			Ellipsis:       plain,
+			ShortKey:       plain.Bold(true),
+			ShortDesc:      plain,
+			ShortSeparator: plain,
+			FullKey:        plain.Bold(true),
+			FullDesc:       plain,
+			FullSeparator:  plain,
+		}, warn: plain.Underline(true), match: plain.Bold(true), sorted: plain.Bold(true), state: map[rowState]lipgloss.Style{
+			rowCurrent:  plain.Bold(true),
+			rowGone:     plain.Strikethrough(true),
+			rowDiverged: plain.Italic(true),
+			rowStale:    plain.Faint(true),
+		}}
+	},
+}
+
+// CheckTheme reports whether name is a built-in theme. The empty name
+// selects the default theme.
+func CheckTheme(name string) error {
+	if _, ok := themes[name]; ok || name == "" {
+		return nil
+	}
+	names := make([]string, 0, len(themes))
+	for n := range themes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return i18n.Errorf("unknown theme %q; available: %s", name, strings.Join(names, ", "))
+}
+
+func lookupTheme(name string) theme {
+	if t, ok := themes[name]; ok {
+		return t()
+	}
+	return themes["default"]()
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// trackMsg reports tracking or untracking a remote branch.
+type trackMsg struct {
+	notice string
+	err    error
+}
+
+// track makes the highlighted branch, or the local branch of a remote one,
+// track its remote branch.
+func (m Model) track() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	if !b.IsRemote && b.Upstream != nil {
+		m.error = i18n.Errorf("%s already tracks %s", b.Name, *b.Upstream)
+		return m, nil
+	}
+	m.error = nil
+	repo := m.RepoPath
+	return m, func() tea.Msg {
+		local, upstream, err := core.Track(repo, b)
+		return trackMsg{notice: i18n.Sprintf("%s tracks %s", local, upstream), err: err}
+	}
+}
+
+// untrack unsets the upstream of the highlighted local branch.
+func (m Model) untrack() (tea.Model, tea.Cmd) {
+	if len(m.items) == 0 {
+		return m, nil
+	}
+	b := m.items[m.cursor]
+	switch {
+	case b.IsRemote:
+		m.error = i18n.Errorf("%s is a remote branch; untrack the local branch instead", b.Name)
+		return m, nil
+	case b.Upstream == nil:
+		m.error = i18n.Errorf("%s has no upstream", b.Name)
+		return m, nil
+	}
+	m.error = nil
+	repo, upstream := m.RepoPath, *b.Upstream
+	return m, func() tea.Msg {
+		err := core.Untrack(repo, b.Name)
+		return trackMsg{notice: i18n.Sprintf("%s no longer tracks %s", b.Name, upstream), err: err}
+	}
+}
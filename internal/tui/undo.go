@@ -0,0 +1,35 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+)
+
+// undoMsg reports undoing the last journaled action.
+type undoMsg struct {
+	action core.Action
+	err    error
+}
+
+// startUndo reverses the last switch, delete or rename in the background.
+func (m Model) startUndo() (tea.Model, tea.Cmd) {
+	m.error, m.notice = nil, ""
+	undo := m.undo
+	return m, func() tea.Msg {
+		a, err := undo()
+		return undoMsg{action: a, err: err}
+	}
+}
+
+// undone handles the outcome of an undo.
+func (m Model) undone(msg undoMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil && !core.PostHookFailed(msg.err) {
+		m.error = msg.err
+		return m, nil
+	}
+	m.error = msg.err
+	m.notice = i18n.Sprintf("undid the %s", msg.action)
+	return m, tea.Batch(m.refreshList(), m.loadHead())
+}
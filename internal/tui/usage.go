@@ -0,0 +1,22 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recordUse reports the action msg takes in the list to Options.Used, and
+// logs it at debug level. Moving around is not worth recording.
+func (m Model) recordUse(msg tea.KeyMsg) {
+	for _, group := range (modeKeys{keys: m.keys, mode: modeSelect}).FullHelp()[1:] {
+		for _, b := range group {
+			if key.Matches(msg, b) {
+				m.logger().Debug("picker action", "repo", m.RepoPath, "action", b.Help().Desc)
+				if m.used != nil {
+					m.used("key: " + b.Help().Desc)
+				}
+				return
+			}
+		}
+	}
+}
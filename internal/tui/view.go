@@ -0,0 +1,429 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+	"github.com/kvnloughead/gotobranch/internal/i18n"
+	"github.com/kvnloughead/gotobranch/internal/tmpl"
+)
+
+// Below these dimensions the full layout (blank separators, paginator line,
+// multi-line help) no longer fits, so View switches to a compact layout with
+// a single status line.
+const (
+	compactHeight = 12
+	compactWidth  = 50
+)
+
+func (m Model) View() string {
+	if m.compact() {
+		return m.compactView()
+	}
+	var b strings.Builder
+	switch m.mode {
+	case modeIssue:
+		b.WriteString(i18n.Sprintf("Branch from issue #%s", m.issueInput.View()) + "\n")
+	case modeNewBranch:
+		b.WriteString(i18n.Sprintf("New branch at %s: ", shortSHA(m.head.SHA)) + m.branchInput.View() + m.nameProblem() + "\n")
+	case modeTemplate:
+		fmt.Fprintf(&b, "%s%s%s\n", m.templatePrompt(), m.branchInput.View(), m.nameProblem())
+	case modeStash:
+		b.WriteString(i18n.Sprintf("Stashes (%d)", len(m.stashes)) + "\n")
+	case modeApplyStash:
+		fmt.Fprintf(&b, "%s\n", m.stashOffer())
+	case modeCherryPick:
+		fmt.Fprintf(&b, "%s%s\n", m.cherryPickPrompt(), m.branchInput.View())
+	case modeConflict:
+		b.WriteString(i18n.Sprintf("Cherry-picking from %s stopped on conflicts. Abort it?", m.pickFrom) + "\n")
+	case modeConfirm:
+		fmt.Fprintf(&b, "%s\n", m.question)
+	case modeForcePush:
+		b.WriteString(i18n.Sprintf("The remote %s has diverged. Force the push (with lease)?", m.forcePush.branch) + "\n")
+	case modeJump:
+		b.WriteString(i18n.T("Jump to: ") + m.branchInput.View() + "\n")
+	case modeRemedy:
+		b.WriteString(i18n.Sprintf("Cannot switch to %s", m.remedyFor) + "\n")
+	case modeRepo:
+		b.WriteString(i18n.T("Open another repository") + "\n")
+	case modeInfo:
+		b.WriteString(i18n.Sprintf("Branch %s", m.infoFor.Name) + "\n")
+	case modeMenu:
+		b.WriteString(i18n.T("Actions") + "\n")
+	default:
+		fmt.Fprintf(&b, "%s%s\n", m.filterLabel(), m.input.View())
+	}
+	b.WriteString("\n")
+	if m.error != nil && m.mode != modeRemedy {
+		// The remedy dialog explains the error itself.
+		b.WriteString(i18n.Sprintf("Error: %v", m.error) + "\n")
+		if m.mode == modeRetry {
+			b.WriteString(i18n.T("Timed out — retry?") + "\n")
+		} else {
+			b.WriteString("\n")
+		}
+	}
+	footer := m.help.View(localizedKeys{modeKeys{keys: m.keys, mode: m.mode}})
+	if d := m.details(); d != "" && m.branchesShown() {
+		footer = m.truncate(d) + "\n" + footer
+	}
+	if m.notice != "" {
+		footer = m.truncate(m.notice) + "\n" + footer
+	}
+	if m.hookLine != "" {
+		footer = m.truncate(i18n.Sprintf("hook: %s", m.hookLine)) + "\n" + footer
+	}
+	if m.count > 0 && m.mode == modeSelect {
+		footer = m.truncate(m.countHint()) + "\n" + footer
+	}
+	if n := len(m.marked); n > 0 && m.mode == modeSelect {
+		footer = i18n.Sprintf("%d marked for deletion (d: delete, x: unmark)", n) + "\n" + footer
+	}
+	if m.detached() {
+		footer = "HEAD: " + m.head.String() + "\n" + footer
+	}
+	if m.clone.Shallow && !m.fetching {
+		footer = i18n.T("shallow clone: ages, counts and merges may be incomplete (H: fetch full history)") + "\n" + footer
+	}
+	switch {
+	case m.task != nil:
+		footer = m.truncate(m.progressView()) + "\n" + footer
+	case m.fetching:
+		footer = m.spinner.View() + " " + i18n.Sprintf("fetching %s…", m.fetchTarget()) + "\n" + footer
+	}
+	if m.pushing != "" {
+		footer = m.spinner.View() + " " + i18n.Sprintf("pushing %s…", m.pushing) + "\n" + footer
+	}
+	chrome := 4 + strings.Count(footer, "\n") + 1
+	if m.error != nil && m.mode != modeRemedy {
+		chrome += 2
+	}
+	if m.showHeader() {
+		// At headerY, where clicks on it are looked for.
+		b.WriteString(m.truncate(m.headerView()))
+		b.WriteString("\n")
+		chrome++
+	}
+	var rows []string
+	if m.previewShown() {
+		rows = m.previewRows(m.height - chrome)
+	} else {
+		rows = m.listRows(m.height - chrome)
+	}
+	for _, line := range rows {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.paginator.View())
+	b.WriteString("\n")
+	b.WriteString(footer)
+	b.WriteString("\n")
+	return b.String()
+}
+
+// compactView renders a single status line followed by as many rows as fit.
+// Errors replace the status line rather than taking extra rows. With a single
+// row available only the highlighted branch is shown.
+func (m Model) compactView() string {
+	if m.height <= 1 {
+		rows := m.rows(1)
+		if len(rows) == 0 {
+			return ""
+		}
+		return rows[0]
+	}
+	var b strings.Builder
+	var status string
+	switch {
+	case m.mode == modeRemedy || m.mode == modeRetry || m.mode == modeConflict || m.mode == modeForcePush:
+		var keys []string
+		for _, k := range (localizedKeys{modeKeys{keys: m.keys, mode: m.mode}}).ShortHelp() {
+			if k.Enabled() {
+				keys = append(keys, k.Help().Key+":"+k.Help().Desc)
+			}
+		}
+		status = strings.Join(keys, " ") + "  " + i18n.Sprintf("error: %v", m.error)
+	case m.error != nil:
+		status = i18n.Sprintf("error: %v", m.error)
+	case m.mode == modeFilter:
+		status = m.match.String() + " /" + m.input.Value() + "▏"
+	case m.mode == modeJump:
+		status = i18n.T("jump to ") + m.branchInput.Value() + "▏"
+	case m.mode == modeIssue:
+		status = i18n.T("issue #") + m.issueInput.Value() + "▏"
+	case m.mode == modeNewBranch:
+		status = i18n.T("new branch ") + m.branchInput.Value() + "▏" + m.nameProblem()
+	case m.mode == modeConfirm:
+		status = m.question + " " + i18n.T("y/n")
+	case m.mode == modeCherryPick:
+		status = strings.ToLower(m.cherryPickPrompt()) + m.branchInput.Value() + "▏"
+	case m.mode == modeStash:
+		status = i18n.Sprintf("stashes (%d)  a:apply p:pop d:drop esc:back", len(m.stashes))
+	case m.mode == modeRepo:
+		status = i18n.T("open repository  enter:open esc:back")
+	case m.mode == modeInfo:
+		status = m.infoFor.Name + "  " + i18n.T("esc:back")
+	case m.mode == modeMenu:
+		status = i18n.T("actions  enter:run esc:back")
+	case m.mode == modeApplyStash:
+		status = m.stashOffer() + " " + i18n.T("y/n")
+	case m.mode == modeTemplate:
+		status = strings.ToLower(m.templatePrompt()) + m.branchInput.Value() + "▏" + m.nameProblem()
+	default:
+		status = fmt.Sprintf("[%d/%d] %s", m.paginator.Page+1, max(m.paginator.TotalPages, 1), m.input.Value())
+		if m.fetching || m.pushing != "" || m.task != nil {
+			status = m.spinner.View() + " " + status
+		}
+		if m.task != nil && m.progress.Percent >= 0 {
+			status += fmt.Sprintf(" %d%%", m.progress.Percent)
+		}
+		if m.detached() {
+			status += " " + m.head.String()
+		}
+		if m.count > 0 {
+			status += fmt.Sprintf(" %d…", m.count)
+		}
+		status += "  " + i18n.T("?:keys q:quit")
+	}
+	b.WriteString(m.truncate(strings.ReplaceAll(status, "\n", " ")))
+	lines := m.listRows(m.height - 1)
+	if m.help.ShowAll {
+		// There is no room for both; "?" swaps the list for the key reference.
+		lines = strings.Split(m.help.View(localizedKeys{modeKeys{keys: m.keys, mode: m.mode}}), "\n")
+		lines = lines[:min(len(lines), m.height-1)]
+		for i, line := range lines {
+			lines[i] = m.truncate(line)
+		}
+	}
+	for _, line := range lines {
+		b.WriteString("\n")
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func (m Model) compact() bool {
+	if m.width == 0 && m.height == 0 {
+		// No size reported yet; assume a regular terminal.
+		return false
+	}
+	return m.height < compactHeight || m.width < compactWidth
+}
+
+// branchesShown reports whether the list shows branches rather than what
+// another mode lists in their place, such as stashes.
+func (m Model) branchesShown() bool {
+	return m.mode != modeStash && m.mode != modeRemedy && m.mode != modeRepo && m.mode != modeInfo && m.mode != modeMenu
+}
+
+// listRows renders the lines of the list shown in the current mode.
+func (m Model) listRows(limit int) []string {
+	switch m.mode {
+	case modeStash:
+		return m.stashRows(limit)
+	case modeRemedy:
+		return m.remedyRows(limit)
+	case modeRepo:
+		return m.repoRows(limit)
+	case modeInfo:
+		return m.infoRows(limit)
+	case modeMenu:
+		return m.menuRows(limit)
+	}
+	return m.rows(limit)
+}
+
+// rows renders the branch lines for the current page, truncated to the
+// terminal width and windowed to at most limit lines around the cursor,
+// unless the wheel scrolled the list past it. A limit <= 0 while the
+// terminal size is known still shows the cursor row.
+func (m Model) rows(limit int) []string {
+	from, to := 0, len(m.items)
+	if (m.width > 0 || m.height > 0) && limit < len(m.items) {
+		limit = max(limit, 1)
+		from = min(max(m.cursor-limit+1+m.scroll, 0), len(m.items)-limit)
+		to = from + limit
+	}
+	start := m.paginator.Page * m.paginator.PerPage
+	query := strings.TrimSpace(m.input.Value())
+	lines := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		it := m.items[i]
+		cursor, mark, state := " ", " ", m.rowState(it)
+		if i == m.cursor {
+			cursor = ">"
+		}
+		if m.marked[it.Name] && !it.IsRemote {
+			mark = "x"
+		}
+		prefix := cursor + mark + state.glyph()
+		warn := m.policyWarn && !core.FollowsPolicy(it)
+		var line string
+		var err error
+		if row := tmpl.NewRow(it, start+i+1); m.table {
+			line = m.tableRow(row)
+		} else {
+			line, err = tmpl.Execute(m.rowTmpl, row)
+		}
+		if err != nil {
+			line = fmt.Sprintf("%3d. %s (%v)", start+i+1, it.Name, err)
+		}
+		line = strings.ReplaceAll(line, "\n", " ")
+		if warn {
+			line += "  " + i18n.T("(name off policy)")
+		}
+		// Found before truncating: the state glyph is several bytes wide, so a
+		// line cut to a column or two can be shorter than the prefix.
+		at := strings.Index(line, it.Name)
+		full := prefix + line
+		line = m.truncate(full)
+		if at >= 0 {
+			at += len(prefix)
+			if end := at + len(it.Name); end > len(line) || line[:end] != full[:end] {
+				at = -1 // cut off
+			}
+		}
+		// Styled after truncating, which would count escape codes.
+		base, hi := plain, m.theme.match.Render
+		style, styled := m.theme.state[state]
+		if warn {
+			style, styled = m.theme.warn.Inherit(style), true
+		}
+		if styled {
+			base, hi = style.Render, m.theme.match.Inherit(style).Render
+		}
+		var marks []int
+		if query != "" && at >= 0 {
+			marks = core.QueryMatchIndexes(query, m.match, it.Name)
+		}
+		lines = append(lines, highlight(line, at, marks, base, hi))
+	}
+	return lines
+}
+
+// highlight renders line with base, except for the characters at the
+// offsets marks, counted from at, which get hi.
+func highlight(line string, at int, marks []int, base, hi func(...string) string) string {
+	if len(marks) == 0 {
+		return base(line)
+	}
+	var b strings.Builder
+	from, lit := 0, false
+	flush := func(to int) {
+		if to > from {
+			if lit {
+				b.WriteString(hi(line[from:to]))
+			} else {
+				b.WriteString(base(line[from:to]))
+			}
+		}
+		from = to
+	}
+	for i := range line {
+		on := len(marks) > 0 && marks[0]+at == i
+		if on {
+			marks = marks[1:]
+		}
+		if on != lit {
+			flush(i)
+			lit = on
+		}
+	}
+	flush(len(line))
+	return b.String()
+}
+
+// plain renders strs as they are, like an unstyled lipgloss.Style.Render.
+func plain(strs ...string) string {
+	return strings.Join(strs, " ")
+}
+
+// truncate cuts s to the terminal width, marking the cut with an ellipsis.
+func (m Model) truncate(s string) string {
+	return truncate(s, m.width)
+}
+
+// truncate cuts s to width terminal columns (no limit when width <= 0),
+// marking the cut with an ellipsis. Wide characters such as CJK and emoji
+// count as two columns.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return runewidth.Truncate(s, width, "…")
+}
+
+// details describes the highlighted branch beyond what its row shows: the
+// upstream of a local branch, its pull request, if known, e.g. "#42 Fix
+// crash on start (open, approved)", who signed its head commit, what it
+// changes relative to the default branch, whether a shallow clone cut its
+// history off and whether its name breaks the naming policy.
+func (m Model) details() string {
+	if m.cursor >= len(m.items) {
+		return ""
+	}
+	b := m.items[m.cursor]
+	var parts []string
+	switch {
+	case b.IsRemote:
+	case b.Upstream != nil:
+		parts = append(parts, i18n.Sprintf("tracks %s", *b.Upstream))
+	default:
+		parts = append(parts, i18n.T("no upstream"))
+	}
+	if pr := b.PullRequest; pr != nil {
+		status := pr.State
+		if pr.Review != "" {
+			status += ", " + strings.ReplaceAll(pr.Review, "_", " ")
+		}
+		parts = append(parts, fmt.Sprintf("#%d %s (%s)", pr.Number, pr.Title, status))
+	}
+	if s := b.Signature; s != nil && s.Signed() {
+		signer := s.Signer
+		if signer == "" {
+			signer = i18n.Sprintf("key %s", s.Key)
+		}
+		parts = append(parts, i18n.Sprintf("signed by %s (%s)", signer, s.Status))
+	}
+	if s := m.shortStatDetail(b); s != "" {
+		parts = append(parts, s)
+	}
+	if b.Shallow {
+		parts = append(parts, i18n.T("history cut off by the shallow clone"))
+	}
+	if m.policyWarn && !core.FollowsPolicy(b) {
+		parts = append(parts, i18n.T("name does not follow the naming policy"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (m Model) fetchTarget() string {
+	if m.history {
+		return i18n.T("the full history")
+	}
+	if m.fetchRemote == "" {
+		return i18n.T("all remotes")
+	}
+	return m.fetchRemote
+}
+
+// nameProblem follows a branch name being typed with why it is invalid,
+// if it is; the name cannot be submitted meanwhile.
+func (m Model) nameProblem() string {
+	if m.nameErr == nil {
+		return ""
+	}
+	return m.theme.warn.Render("  ✗ " + m.nameErr.Error())
+}
+
+// filterLabel names the filter and its match mode, e.g. "Filter (glob): ".
+func (m Model) filterLabel() string {
+	if len(m.profiles) > 0 && m.profiles[m.profile].Name != "" {
+		return i18n.Sprintf("Filter [%s] (%s): ", m.profiles[m.profile].Name, m.match)
+	}
+	return i18n.Sprintf("Filter (%s): ", m.match)
+}
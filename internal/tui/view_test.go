@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// TestRowsNarrow checks that rows with a multibyte state glyph and a
+// highlighted match render at any width, down to a single column.
+func TestRowsNarrow(t *testing.T) {
+	m := New(Options{RepoPath: t.TempDir()})
+	m.items = []core.Branch{
+		{Name: "feat/login", Tracking: &core.Tracking{Ahead: 1, Behind: 2}},
+		{Name: "fix/crash", Tracking: &core.Tracking{Gone: true}},
+		{Name: "main", IsCurrent: true},
+	}
+	m.input.SetValue("i")
+	for width := 1; width <= 40; width++ {
+		next, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 20})
+		rows := next.(Model).rows(len(m.items))
+		if len(rows) != len(m.items) {
+			t.Fatalf("width %d: got %d rows, want %d", width, len(rows), len(m.items))
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Release assets are plain binaries named by AssetName, next to a
+// sha256sum-style ChecksumsAsset and, for signed releases, its detached
+// ed25519 signature SignatureAsset. `make release` produces this layout,
+// signing with RELEASE_KEY and embedding its public half as PublicKey.
+const (
+	ChecksumsAsset = "checksums.txt"
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// PublicKey is the base64 ed25519 key release checksums are signed with. It
+// is set at build time
+// (-ldflags "-X github.com/kvnloughead/gotobranch/internal/update.PublicKey=...");
+// builds without one cannot verify releases, and only install them when
+// told to (see Download).
+var PublicKey string
+
+// ErrUnverified is wrapped by Download errors for releases whose signature
+// cannot be checked: the build has no PublicKey, or the release no
+// SignatureAsset.
+var ErrUnverified = errors.New("cannot verify the release signature")
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// AssetName is the name of the release binary for goos/goarch.
+func AssetName(goos, goarch string) string {
+	name := "gotobranch_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Asset returns the release asset called name.
+func (r Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Download fetches the release binary for the running platform and verifies
+// it against the release checksums and their signature. With insecure, a
+// release that cannot be verified (see ErrUnverified) is checked against
+// its checksums only; a bad signature is refused regardless.
+func Download(ctx context.Context, r Release, insecure bool) ([]byte, error) {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	bin, ok := r.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s/%s", r.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	sums, ok := r.Asset(ChecksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", r.TagName, ChecksumsAsset)
+	}
+	sumData, err := fetch(ctx, sums.URL)
+	if err != nil {
+		return nil, err
+	}
+	sig, signed := r.Asset(SignatureAsset)
+	switch {
+	case PublicKey == "" && !insecure:
+		return nil, fmt.Errorf("%w: this build has no release key", ErrUnverified)
+	case !signed && !insecure:
+		return nil, fmt.Errorf("%w: release %s is not signed", ErrUnverified, r.TagName)
+	case PublicKey != "" && signed:
+		sigData, err := fetch(ctx, sig.URL)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifySignature(sumData, sigData); err != nil {
+			return nil, err
+		}
+	}
+	data, err := fetch(ctx, bin.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(sumData, name, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifyChecksum checks data against the entry for name in sums, which is in
+// sha256sum format ("<hex>  <name>" per line).
+func verifyChecksum(sums []byte, name string, data []byte) error {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		want, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return fmt.Errorf("%s: bad checksum for %s", ChecksumsAsset, name)
+		}
+		got := sha256.Sum256(data)
+		if !bytes.Equal(got[:], want) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s has no entry for %s", ChecksumsAsset, name)
+}
+
+func verifySignature(data, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid built-in release public key")
+	}
+	if s, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err == nil {
+		sig = s
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+		return fmt.Errorf("bad signature on %s", ChecksumsAsset)
+	}
+	return nil
+}
+
+// Replace atomically replaces the executable at exe with data: the new
+// binary is written next to it and renamed over it, so an interrupted update
+// leaves the old one in place.
+func Replace(exe string, data []byte) error {
+	exe, err := filepath.EvalSymlinks(exe)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".gotobranch-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		// A running executable cannot be replaced, but it can be renamed.
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), exe)
+}
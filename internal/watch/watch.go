@@ -0,0 +1,104 @@
+// Package watch reports changes to the refs of a repository: branches
+// created, deleted or moved, remote-tracking branches fetched, and HEAD
+// switched.
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Debounce is how long the refs must be quiet before a change is
+// reported, so that a fetch updating many refs is reported once.
+const Debounce = 150 * time.Millisecond
+
+// Refs watches the refs of the repository at repoPath until ctx is done. A
+// value is sent on the returned channel after each burst of changes; the
+// channel is closed when watching stops. Changes arriving while the
+// previous one has not been received yet are merged into it.
+func Refs(ctx context.Context, repoPath string) (<-chan struct{}, error) {
+	gitDir, commonDir, err := core.GitDirs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// HEAD lives in the worktree's git directory, packed-refs and refs/ in
+	// the common one. Files are replaced by renames, so the directories are
+	// watched rather than the files.
+	dirs := []string{gitDir, commonDir}
+	refs := filepath.Join(commonDir, "refs")
+	_ = filepath.WalkDir(refs, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for _, dir := range dirs {
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		defer close(changed)
+		defer w.Close()
+		timer := time.NewTimer(Debounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				// New directories below refs/, e.g. for a feat/ prefix, must be
+				// watched too.
+				if ev.Has(fsnotify.Create) && strings.HasPrefix(ev.Name, refs) {
+					if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+						_ = w.Add(ev.Name)
+					}
+				}
+				if relevant(ev.Name, gitDir, commonDir, refs) {
+					timer.Reset(Debounce)
+				}
+			case <-w.Errors:
+				// Overflowing event queues lose events; report a change so that
+				// the listener looks again.
+				timer.Reset(Debounce)
+			case <-timer.C:
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return changed, nil
+}
+
+// relevant reports whether a change to path may have changed a ref. Lock
+// files come and go around every update; the rename that ends it counts.
+func relevant(path, gitDir, commonDir, refs string) bool {
+	if strings.HasSuffix(path, ".lock") {
+		return false
+	}
+	switch path {
+	case filepath.Join(gitDir, "HEAD"), filepath.Join(commonDir, "packed-refs"):
+		return true
+	}
+	return strings.HasPrefix(path, refs+string(filepath.Separator))
+}
@@ -0,0 +1,81 @@
+package gotobranch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Errors that operations' errors wrap, for errors.Is.
+var (
+	ErrNotRepository = core.ErrNotRepository // the directory is not inside a git repository
+	ErrDetachedHead  = core.ErrDetachedHead  // HEAD is not on a branch
+	ErrTimeout       = core.ErrTimeout       // git ran longer than its timeout
+	ErrCanceled      = core.ErrCanceled      // the context was done
+	ErrAuthRequired  = core.ErrAuthRequired  // the remote needs credentials
+)
+
+// ErrorKind says why git failed.
+type ErrorKind string
+
+const (
+	KindUnknown        ErrorKind = ""
+	KindBranchExists   ErrorKind = "branch-exists"    // creating a branch that exists
+	KindNotFound       ErrorKind = "not-found"        // no such branch, ref or path
+	KindLocalChanges   ErrorKind = "local-changes"    // uncommitted changes would be overwritten
+	KindNotFastForward ErrorKind = "not-fast-forward" // the branch has diverged from the one it should follow
+	KindNotMerged      ErrorKind = "not-merged"       // deleting a branch with unmerged commits
+	KindCheckedOut     ErrorKind = "checked-out"      // the branch is checked out in another worktree
+	KindAuth           ErrorKind = "auth"             // the remote needs credentials
+	KindNotRepository  ErrorKind = "not-repository"   // not inside a git repository
+	KindTimeout        ErrorKind = "timeout"          // git ran longer than its timeout and was killed
+	KindCanceled       ErrorKind = "canceled"         // the context was done
+)
+
+// Error is a failed operation.
+type Error struct {
+	Op     string    // the operation, e.g. "switch"
+	Kind   ErrorKind // why git failed, if known
+	Output string    // what git printed, if it ran
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return "gotobranch: " + e.Op + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Hint suggests what to do about e, e.g. a git command to run first, or
+// returns "".
+func (e *Error) Hint() string {
+	return core.Hint(e.Err)
+}
+
+// wrap turns err from op into an *Error; nil stays nil.
+func wrap(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Op: op, Err: err}
+	var ge *core.GitError
+	if errors.As(err, &ge) {
+		e.Kind, e.Output = ErrorKind(ge.Kind), ge.Output
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded), errors.Is(err, core.ErrCanceled):
+		e.Kind = KindCanceled
+		if !errors.Is(err, core.ErrCanceled) {
+			e.Err = fmt.Errorf("%w: %w", ErrCanceled, err)
+		}
+	case errors.Is(err, core.ErrNotRepository):
+		e.Kind = KindNotRepository
+	case errors.Is(err, core.ErrAuthRequired):
+		e.Kind = KindAuth
+	case errors.Is(err, core.ErrTimeout):
+		e.Kind = KindTimeout
+	}
+	return e
+}
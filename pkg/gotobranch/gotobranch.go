@@ -0,0 +1,286 @@
+// Package gotobranch lists, switches, creates, renames and deletes the
+// branches of a git repository the way the gotobranch command does, for
+// programs that would otherwise shell out to it.
+//
+// The API is stable: within a major version, exported names keep their
+// meaning and signatures, and option structs only gain fields whose zero
+// values keep the old behavior. The implementation underneath (the
+// command's internal packages) is free to change.
+//
+// Every operation runs git in the repository and takes a context. Fetch
+// stops git when the context is done; the other operations, which are
+// local and brief, check it before starting and then run to completion, so
+// that a branch is never left half switched or half renamed.
+//
+// Failures are reported as *Error, which says what kind of failure git
+// reported (see ErrorKind); errors.Is also matches the Err* values.
+//
+// Unlike the command, the package runs no configured hooks, keeps no
+// journal for undo and applies no branch naming policy.
+package gotobranch
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kvnloughead/gotobranch/internal/core"
+)
+
+// Repo is a git repository, addressed by a directory inside its working
+// tree.
+type Repo struct {
+	path string
+}
+
+// Open returns the repository whose working tree contains dir (the working
+// directory when empty), or an error wrapping ErrNotRepository.
+func Open(dir string) (*Repo, error) {
+	if err := core.CheckRepo(dir); err != nil {
+		return nil, wrap("open", err)
+	}
+	top, err := core.TopLevel(dir)
+	if err != nil {
+		return nil, wrap("open", err)
+	}
+	return &Repo{path: top}, nil
+}
+
+// Path returns the root of the repository's working tree.
+func (r *Repo) Path() string {
+	return r.path
+}
+
+// Branch is a local or remote-tracking branch.
+type Branch struct {
+	Name     string // short name, e.g. feat/login or origin/feat/login
+	Ref      string // full ref, e.g. refs/heads/feat/login
+	Current  bool   // checked out in this working tree
+	Remote   bool   // a remote-tracking branch
+	Upstream string // the upstream of a local branch, e.g. origin/feat/login; "" for none
+
+	// Ahead and Behind compare a local branch with its upstream;
+	// UpstreamGone is set when the upstream was deleted on the remote.
+	Ahead, Behind int
+	UpstreamGone  bool
+
+	SHA         string    // the head commit
+	CommittedAt time.Time // when the head commit was made
+	Subject     string    // the head commit's subject line
+	Author      string    // the head commit's author name
+	AuthorEmail string
+}
+
+// Scope selects the branches listed.
+type Scope int
+
+const (
+	Local  Scope = iota // local branches
+	Remote              // remote-tracking branches
+	All                 // both
+)
+
+// Match is how a pattern is matched against branch names.
+type Match int
+
+const (
+	Contains Match = iota // the pattern appears anywhere in the name
+	Glob                  // the whole name matches a glob, e.g. release/1.*
+	Regex                 // the name matches a regular expression (RE2 syntax)
+	Fuzzy                 // the pattern's characters appear in order, e.g. "fl" for feat/login
+)
+
+// ListOptions select and order the branches List returns. The zero value
+// lists all local branches, most recently committed to first.
+type ListOptions struct {
+	Scope Scope
+
+	// Query filters the branches, as the command's filter does: words
+	// matched against names with Match, and terms such as author:alice,
+	// age:<2w, merged or gone.
+	Query string
+	Match Match
+
+	// Exclude hides branches whose names match any of these globs.
+	Exclude []string
+
+	// SortBy is "recency" (the default), "name" or "commits" (commits the
+	// default branch lacks). Branches come newest, or with the most
+	// commits, first, and names in alphabetical order, unless Reverse.
+	SortBy  string
+	Reverse bool
+
+	// Page (from 1) and PageSize page through the list; a PageSize of 0
+	// returns every branch.
+	Page     int
+	PageSize int
+}
+
+// List returns the branches opts select, and how many there are in all
+// pages.
+func (r *Repo) List(ctx context.Context, opts ListOptions) ([]Branch, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, wrap("list", err)
+	}
+	req := core.ListBranchesRequest{
+		RepoPath: r.path,
+		Query:    opts.Query,
+		Match:    core.MatchMode(opts.Match),
+		Exclude:  opts.Exclude,
+		Scope:    core.Scope(opts.Scope),
+		SortBy:   opts.SortBy,
+		Page:     max(opts.Page, 1),
+		PageSize: opts.PageSize,
+		Tracking: true,
+		Commits:  opts.SortBy == "commits",
+	}
+	if req.SortBy == "" {
+		req.SortBy = "recency"
+	}
+	asc := req.SortBy == "name"
+	if opts.Reverse {
+		asc = !asc
+	}
+	req.SortDir = map[bool]string{true: "asc", false: "desc"}[asc]
+	if req.PageSize <= 0 {
+		req.Page, req.PageSize = 1, 1<<31-1
+	}
+	resp, err := core.ListBranches(req)
+	if err != nil {
+		return nil, 0, wrap("list", err)
+	}
+	res := make([]Branch, len(resp.Items))
+	for i, b := range resp.Items {
+		res[i] = fromCore(b)
+	}
+	return res, resp.Total, nil
+}
+
+// Current returns the branch checked out, or an error wrapping
+// ErrDetachedHead.
+func (r *Repo) Current(ctx context.Context) (Branch, error) {
+	if err := ctx.Err(); err != nil {
+		return Branch{}, wrap("current", err)
+	}
+	b, err := core.GetCurrentBranch(r.path)
+	if err != nil {
+		return Branch{}, wrap("current", err)
+	}
+	return fromCore(*b), nil
+}
+
+// DefaultBranch returns the name of the default branch, e.g. main.
+func (r *Repo) DefaultBranch(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", wrap("default branch", err)
+	}
+	name, err := core.DefaultBranch(r.path)
+	return name, wrap("default branch", err)
+}
+
+// SwitchOptions change how Switch switches.
+type SwitchOptions struct {
+	// Create creates the branch, from From (HEAD when empty), if there is
+	// no local branch by that name yet.
+	Create bool
+	From   string
+}
+
+// Switch checks out the local branch name, or creates it to track the
+// remote branch of that name if there is one. It returns the branch
+// checked out before, "" if HEAD was detached. Local changes that the
+// switch would overwrite make it fail with KindLocalChanges.
+func (r *Repo) Switch(ctx context.Context, name string, opts SwitchOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", wrap("switch", err)
+	}
+	if opts.Create {
+		prev, _, err := core.CreateOrSwitch(r.path, name, opts.From)
+		return prev, wrap("switch", err)
+	}
+	prev, err := core.Checkout(r.path, name, false)
+	return prev, wrap("switch", err)
+}
+
+// DeleteOptions change how Delete deletes.
+type DeleteOptions struct {
+	// Force deletes the branch even if its commits are not merged.
+	Force bool
+}
+
+// Delete deletes the local branch name and returns the SHA it pointed to,
+// with which `git branch <name> <sha>` restores it. Without Force, a
+// branch with unmerged commits fails with KindNotMerged.
+func (r *Repo) Delete(ctx context.Context, name string, opts DeleteOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", wrap("delete", err)
+	}
+	sha, err := core.DeleteBranch(r.path, name, opts.Force)
+	return sha, wrap("delete", err)
+}
+
+// Rename renames the local branch from (the current one when empty) to to.
+func (r *Repo) Rename(ctx context.Context, from, to string) error {
+	if err := ctx.Err(); err != nil {
+		return wrap("rename", err)
+	}
+	return wrap("rename", core.RenameBranch(r.path, from, to))
+}
+
+// Progress is a progress line git printed, such as "Receiving objects:
+// 45% (450/1000)", with its percentage, or -1 when it has none.
+type Progress struct {
+	Line    string
+	Percent int
+}
+
+// FetchOptions change what Fetch fetches.
+type FetchOptions struct {
+	Remote string // the remote to fetch from; all remotes when empty
+	Prune  bool   // delete remote-tracking branches deleted on the remote
+
+	// Progress, if set, is called with every progress line git reports.
+	Progress func(Progress)
+}
+
+// Fetch updates the remote-tracking branches. Git never prompts for
+// credentials; a remote that needs them fails with KindAuth. Canceling ctx
+// stops git, failing with an error wrapping ErrCanceled.
+func (r *Repo) Fetch(ctx context.Context, opts FetchOptions) error {
+	var report func(core.Progress)
+	if opts.Progress != nil {
+		report = func(p core.Progress) { opts.Progress(Progress{Line: p.Line, Percent: p.Percent}) }
+	}
+	return wrap("fetch", core.FetchProgress(ctx, r.path, opts.Remote, opts.Prune, report))
+}
+
+func fromCore(b core.Branch) Branch {
+	res := Branch{
+		Name:    b.Name,
+		Ref:     b.FullRef,
+		Current: b.IsCurrent,
+		Remote:  b.IsRemote,
+	}
+	if b.Upstream != nil {
+		res.Upstream = *b.Upstream
+	}
+	if t := b.Tracking; t != nil {
+		res.Ahead, res.Behind, res.UpstreamGone = t.Ahead, t.Behind, t.Gone
+	}
+	if b.HeadCommitSHA != nil {
+		res.SHA = *b.HeadCommitSHA
+	}
+	if b.HeadCommitAt != nil {
+		res.CommittedAt = *b.HeadCommitAt
+	}
+	if b.LastCommitMessage != nil {
+		res.Subject = strings.TrimSpace(*b.LastCommitMessage)
+	}
+	if b.Author != nil {
+		res.Author = *b.Author
+	}
+	if b.AuthorEmail != nil {
+		res.AuthorEmail = *b.AuthorEmail
+	}
+	return res
+}